@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"errors"
+	"path/filepath"
+
+	"github.com/25smoking/Gwxapkg/internal/ui"
+	"github.com/25smoking/Gwxapkg/pkg/wxapkg"
+)
+
+// ExecuteBatch 并发处理多个 AppID，输出按 AppID 前缀区分，不使用进度条
+func ExecuteBatch(jobs []wxapkg.Options, concurrency int) []wxapkg.BatchResult {
+	for i := range jobs {
+		jobs[i].Observer = &batchObserver{appID: jobs[i].AppID}
+	}
+
+	results := wxapkg.RunBatch(jobs, concurrency)
+
+	ui.PrintDivider()
+	failed := 0
+	for _, item := range results {
+		switch {
+		case errors.Is(item.Err, wxapkg.ErrNoInput):
+			failed++
+			ui.Warning("[%s] 未找到任何文件", item.AppID)
+		case item.Err != nil:
+			failed++
+			ui.Error("[%s] %v", item.AppID, item.Err)
+		default:
+			printBatchSummary(item.Result)
+		}
+	}
+	if failed > 0 {
+		ui.Warning("批量处理完成，%d/%d 个小程序失败", failed, len(results))
+	}
+	return results
+}
+
+func printBatchSummary(result *wxapkg.Result) {
+	ui.Success("[%s] 输出目录: %s", result.AppID, filepath.Clean(result.OutputDir))
+
+	var details []interface{}
+	format := "[%s]    - 包数: %d | 失败: %d"
+	details = append(details, result.AppID, len(result.InputFiles), len(result.PackageErrors))
+	if result.Incremental {
+		format += " | 增量跳过: %d"
+		details = append(details, len(result.SkippedFiles))
+	}
+	if report := result.Scan; report != nil {
+		format += " | 接口数: %d | 高风险: %d"
+		details = append(details, len(report.APIEndpoints), report.Summary.HighRisk)
+	}
+	if manifest := result.Routes; manifest != nil {
+		format += " | 页面数: %d"
+		details = append(details, manifest.Summary.TotalPages)
+	}
+	ui.Info(format, details...)
+}
+
+// batchObserver 多个流水线同时输出时，逐行打印带 AppID 前缀的事件
+type batchObserver struct {
+	appID string
+}
+
+func (o *batchObserver) Start(appID string, inputFiles []string) {
+	ui.Info("[%s] 开始处理 %d 个包", appID, len(inputFiles))
+}
+
+func (o *batchObserver) Stage(step, total int, title string) {
+	ui.Step(step, total, "[%s] %s", o.appID, title)
+}
+
+func (o *batchObserver) PackageDone(file string, err error) {
+	if err != nil {
+		ui.Error("[%s] %v", o.appID, err)
+	}
+}
+
+func (o *batchObserver) Warning(message string) {
+	ui.Warning("[%s] %s", o.appID, message)
+}
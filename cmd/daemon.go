@@ -0,0 +1,616 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	internalcmd "github.com/25smoking/Gwxapkg/internal/cmd"
+	"github.com/25smoking/Gwxapkg/internal/locator"
+	"github.com/25smoking/Gwxapkg/internal/ui"
+	"github.com/25smoking/Gwxapkg/pkg/wxapkg"
+)
+
+// DaemonEventLogName 未指定 -log 时事件日志的文件名
+const DaemonEventLogName = "daemon_events.jsonl"
+
+// daemon 事件类型，写入 JSON Lines 日志的 event 字段
+const (
+	DaemonEventStart   = "start"
+	DaemonEventWatch   = "watch"
+	DaemonEventChange  = "change"
+	DaemonEventProcess = "process"
+	DaemonEventDone    = "done"
+	DaemonEventError   = "error"
+	DaemonEventStop    = "stop"
+)
+
+// watchDepth 基础路径下需要监听的目录层数：AppID 目录与版本目录
+const watchDepth = 2
+
+// DaemonOptions daemon 子命令参数
+type DaemonOptions struct {
+	// Scan 定位缓存目录与小程序的扫描选项
+	Scan locator.ScanOptions
+	// Settle 某个 AppID 的包集合保持不变多久后才开始处理，避免分包下载到一半就解包
+	Settle time.Duration
+	// Rescan 定期重新收集基础路径并全量比对，兜底新出现的缓存目录与 fsnotify 丢失的事件
+	Rescan time.Duration
+	// Initial 为 true 时启动后立即处理所有已缓存的小程序，否则只处理启动后发生变化的
+	Initial bool
+	// Concurrency 同时处理的小程序数量；同一 AppID 不会并发处理
+	Concurrency int
+	// Archive 每次处理前把包文件归档到输出目录的版本库
+	Archive bool
+	// OutputRoot 非空时每个 AppID 写入 <OutputRoot>/<AppID>，否则使用默认输出目录
+	OutputRoot string
+	// LogPath JSON Lines 事件日志路径，为空时写入 <OutputRoot> 或 ~/.gwxapkg 下的 daemon_events.jsonl
+	LogPath string
+	// Pipeline 流水线参数模板，AppID、Input、OutputDir 与 Observer 按小程序填充
+	Pipeline wxapkg.Options
+}
+
+// DaemonEvent 事件日志中的一行
+type DaemonEvent struct {
+	Time       time.Time `json:"time"`
+	Event      string    `json:"event"`
+	AppID      string    `json:"appid,omitempty"`
+	AppName    string    `json:"app_name,omitempty"`
+	Version    string    `json:"version,omitempty"`
+	Path       string    `json:"path,omitempty"`
+	Files      []string  `json:"files,omitempty"`
+	Added      []string  `json:"added,omitempty"`
+	Removed    []string  `json:"removed,omitempty"`
+	OutputDir  string    `json:"output_dir,omitempty"`
+	DurationMS int64     `json:"duration_ms,omitempty"`
+	// Skipped 增量处理时内容未变化而跳过的包文件
+	Skipped []string `json:"skipped,omitempty"`
+	// Completeness 分包完整性状态及仍缺失的分包 root
+	Completeness       string   `json:"completeness,omitempty"`
+	MissingSubpackages []string `json:"missing_subpackages,omitempty"`
+	Endpoints          int      `json:"endpoints,omitempty"`
+	HighRisk           int      `json:"high_risk,omitempty"`
+	Pages              int      `json:"pages,omitempty"`
+	Message            string   `json:"message,omitempty"`
+	Error              string   `json:"error,omitempty"`
+}
+
+// programSnapshot 某个 AppID 当前最新版本的包集合；Fingerprint 由文件路径、大小与修改时间组成
+type programSnapshot struct {
+	Program     locator.MiniProgramInfo
+	Fingerprint string
+}
+
+type pendingProgram struct {
+	snapshot  programSnapshot
+	changedAt time.Time
+}
+
+// packageTracker 记录每个 AppID 已处理的包集合，以及发生变化、等待稳定的包集合
+type packageTracker struct {
+	settle    time.Duration
+	processed map[string]programSnapshot
+	pending   map[string]*pendingProgram
+}
+
+func newPackageTracker(settle time.Duration) *packageTracker {
+	return &packageTracker{
+		settle:    settle,
+		processed: make(map[string]programSnapshot),
+		pending:   make(map[string]*pendingProgram),
+	}
+}
+
+// baseline 把当前包集合视为已处理，启动时不带 -initial 使用
+func (t *packageTracker) baseline(snapshots map[string]programSnapshot) {
+	for appID, snapshot := range snapshots {
+		t.processed[appID] = snapshot
+	}
+}
+
+// observe 比对最新快照，返回包集合发生变化的 AppID（按字典序）；变化会重新开始计时
+func (t *packageTracker) observe(snapshots map[string]programSnapshot, now time.Time) []string {
+	var changed []string
+	for appID, snapshot := range snapshots {
+		if processed, ok := t.processed[appID]; ok && processed.Fingerprint == snapshot.Fingerprint {
+			delete(t.pending, appID)
+			continue
+		}
+		if pending, ok := t.pending[appID]; ok && pending.snapshot.Fingerprint == snapshot.Fingerprint {
+			continue
+		}
+		t.pending[appID] = &pendingProgram{snapshot: snapshot, changedAt: now}
+		changed = append(changed, appID)
+	}
+	for appID := range t.pending {
+		if _, ok := snapshots[appID]; !ok {
+			delete(t.pending, appID)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// due 取出已稳定 settle 时长的包集合，按变化时间先后排序
+func (t *packageTracker) due(now time.Time) []programSnapshot {
+	var ready []*pendingProgram
+	for appID, pending := range t.pending {
+		if now.Sub(pending.changedAt) >= t.settle {
+			ready = append(ready, pending)
+			delete(t.pending, appID)
+		}
+	}
+	sort.Slice(ready, func(i, j int) bool {
+		if ready[i].changedAt.Equal(ready[j].changedAt) {
+			return ready[i].snapshot.Program.AppID < ready[j].snapshot.Program.AppID
+		}
+		return ready[i].changedAt.Before(ready[j].changedAt)
+	})
+
+	result := make([]programSnapshot, 0, len(ready))
+	for _, pending := range ready {
+		result = append(result, pending.snapshot)
+	}
+	return result
+}
+
+// markProcessed 记录已处理的包集合；处理失败时也会记录，直到包集合再次变化才重试
+func (t *packageTracker) markProcessed(snapshot programSnapshot) {
+	t.processed[snapshot.Program.AppID] = snapshot
+}
+
+// diff 返回相对上次处理新增与消失的包文件
+func (t *packageTracker) diff(snapshot programSnapshot) ([]string, []string) {
+	previous := make(map[string]struct{})
+	if processed, ok := t.processed[snapshot.Program.AppID]; ok {
+		for _, file := range processed.Program.Files {
+			previous[file] = struct{}{}
+		}
+	}
+	current := make(map[string]struct{}, len(snapshot.Program.Files))
+	var added, removed []string
+	for _, file := range snapshot.Program.Files {
+		current[file] = struct{}{}
+		if _, ok := previous[file]; !ok {
+			added = append(added, file)
+		}
+	}
+	for file := range previous {
+		if _, ok := current[file]; !ok {
+			removed = append(removed, file)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+// snapshotPrograms 每个 AppID 只取最近更新的版本（扫描结果已按更新时间倒序）
+func snapshotPrograms(programs []locator.MiniProgramInfo) map[string]programSnapshot {
+	snapshots := make(map[string]programSnapshot, len(programs))
+	for _, program := range programs {
+		if _, ok := snapshots[program.AppID]; ok {
+			continue
+		}
+		files := append([]string(nil), program.Files...)
+		sort.Strings(files)
+		program.Files = files
+		snapshots[program.AppID] = programSnapshot{Program: program, Fingerprint: fingerprintFiles(files)}
+	}
+	return snapshots
+}
+
+func fingerprintFiles(files []string) string {
+	var builder strings.Builder
+	for _, file := range files {
+		builder.WriteString(file)
+		if info, err := os.Stat(file); err == nil {
+			fmt.Fprintf(&builder, "|%d|%d", info.Size(), info.ModTime().UnixNano())
+		}
+		builder.WriteByte('\n')
+	}
+	return builder.String()
+}
+
+// daemonEventLog 追加写入 JSON Lines 事件日志
+type daemonEventLog struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func openDaemonEventLog(path string) (*daemonEventLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("创建事件日志目录失败: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("打开事件日志失败: %w", err)
+	}
+	return &daemonEventLog{file: file}, nil
+}
+
+func (l *daemonEventLog) write(event DaemonEvent) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.file.Write(append(data, '\n')); err != nil {
+		ui.Warning("写入事件日志失败: %v", err)
+	}
+}
+
+func (l *daemonEventLog) Close() error {
+	return l.file.Close()
+}
+
+// ResolveDaemonLogPath 返回事件日志路径：显式指定优先，其次 <outputRoot>，最后 ~/.gwxapkg
+func ResolveDaemonLogPath(path, outputRoot string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+	if outputRoot != "" {
+		return filepath.Join(outputRoot, DaemonEventLogName), nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("获取用户目录失败: %w", err)
+	}
+	return filepath.Join(homeDir, ".gwxapkg", DaemonEventLogName), nil
+}
+
+type daemonRunner struct {
+	options DaemonOptions
+	watcher *fsnotify.Watcher
+	watched map[string]struct{}
+	tracker *packageTracker
+	log     *daemonEventLog
+
+	// 以下字段只在监听循环中访问；处理在 worker goroutine 中进行，完成后通过 done 回报 AppID
+	queue   []programSnapshot
+	running map[string]struct{}
+	done    chan string
+	workers sync.WaitGroup
+}
+
+// RunDaemon 监听所有缓存目录，某个 AppID 的包集合变化并稳定 Settle 时长后，
+// 对其最新版本重新运行完整流水线，把新分包合并进已有输出并刷新分包完整性报告。
+// 收到 Ctrl+C 或 SIGTERM 后返回。
+func RunDaemon(options DaemonOptions) error {
+	if options.Settle <= 0 {
+		options.Settle = 10 * time.Second
+	}
+	if options.Rescan <= 0 {
+		options.Rescan = time.Minute
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = 1
+	}
+
+	logPath, err := ResolveDaemonLogPath(options.LogPath, options.OutputRoot)
+	if err != nil {
+		return err
+	}
+	eventLog, err := openDaemonEventLog(logPath)
+	if err != nil {
+		return err
+	}
+	defer eventLog.Close()
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("创建目录监听失败: %w", err)
+	}
+	defer watcher.Close()
+
+	d := newDaemonRunner(options, eventLog)
+	d.watcher = watcher
+
+	if err := d.refreshWatches(); err != nil {
+		return err
+	}
+	if len(d.watched) == 0 {
+		ui.Warning("当前没有可监听的缓存目录，将每 %s 重新检查一次", options.Rescan)
+	}
+
+	snapshots, err := d.scan()
+	if err != nil {
+		return err
+	}
+	if !options.Initial {
+		d.tracker.baseline(snapshots)
+	}
+	d.log.write(DaemonEvent{Event: DaemonEventStart, Path: logPath, Message: fmt.Sprintf("已缓存 %d 个小程序，稳定等待 %s", len(snapshots), options.Settle)})
+	ui.Success("daemon 已启动: 监听 %d 个目录，已缓存 %d 个小程序", len(d.watched), len(snapshots))
+	ui.Info("   - 包集合稳定 %s 后自动处理，按 Ctrl+C 退出", options.Settle)
+	ui.Info("   - 事件日志: %s", logPath)
+	d.observe(snapshots)
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	dirty := false
+	lastRescan := time.Now()
+	for {
+		select {
+		case <-sigCh:
+			if len(d.running) > 0 {
+				ui.Info("等待 %d 个处理中的小程序完成...", len(d.running))
+			}
+			d.workers.Wait()
+			d.log.write(DaemonEvent{Event: DaemonEventStop})
+			ui.Info("daemon 已退出")
+			return nil
+		case appID := <-d.done:
+			delete(d.running, appID)
+			d.dispatch()
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if d.handleFSEvent(event) {
+				dirty = true
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			d.log.write(DaemonEvent{Event: DaemonEventError, Error: err.Error()})
+			ui.Warning("目录监听出错: %v", err)
+		case now := <-ticker.C:
+			if now.Sub(lastRescan) >= options.Rescan {
+				lastRescan = now
+				if err := d.refreshWatches(); err != nil {
+					ui.Warning("%v", err)
+				}
+				dirty = true
+			}
+			if dirty {
+				dirty = false
+				if snapshots, err := d.scan(); err != nil {
+					d.log.write(DaemonEvent{Event: DaemonEventError, Error: err.Error()})
+					ui.Warning("扫描失败: %v", err)
+				} else {
+					d.observe(snapshots)
+				}
+			}
+			for _, snapshot := range d.tracker.due(time.Now()) {
+				d.enqueue(snapshot)
+			}
+		}
+	}
+}
+
+func newDaemonRunner(options DaemonOptions, eventLog *daemonEventLog) *daemonRunner {
+	return &daemonRunner{
+		options: options,
+		watched: make(map[string]struct{}),
+		tracker: newPackageTracker(options.Settle),
+		log:     eventLog,
+		running: make(map[string]struct{}),
+		// 每个运行中的 worker 只回报一次，缓冲区不小于并发数即可保证 worker 不会阻塞
+		done: make(chan string, options.Concurrency),
+	}
+}
+
+// enqueue 把稳定后的包集合排入处理队列；同一 AppID 仍在排队时用最新的包集合替换
+func (d *daemonRunner) enqueue(snapshot programSnapshot) {
+	d.tracker.markProcessed(snapshot)
+	replaced := false
+	for i := range d.queue {
+		if d.queue[i].Program.AppID == snapshot.Program.AppID {
+			d.queue[i] = snapshot
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		d.queue = append(d.queue, snapshot)
+	}
+	d.dispatch()
+}
+
+// dispatch 在并发数以内把排队的包集合交给 worker 处理；正在处理的 AppID 留在队列中等待上一次完成，
+// 避免两次处理同时写入同一输出目录。监听循环不等待处理结果，事件与稳定计时不受长时间处理的影响。
+func (d *daemonRunner) dispatch() {
+	for i := 0; i < len(d.queue) && len(d.running) < d.options.Concurrency; {
+		snapshot := d.queue[i]
+		appID := snapshot.Program.AppID
+		if _, ok := d.running[appID]; ok {
+			i++
+			continue
+		}
+		d.queue = append(d.queue[:i], d.queue[i+1:]...)
+		d.running[appID] = struct{}{}
+		d.workers.Add(1)
+		go func() {
+			defer d.workers.Done()
+			d.process(snapshot)
+			d.done <- appID
+		}()
+	}
+}
+
+// handleFSEvent 新建目录时补充监听；返回事件是否可能改变包集合
+func (d *daemonRunner) handleFSEvent(event fsnotify.Event) bool {
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			d.watchTree(event.Name, watchDepth)
+			return true
+		}
+	}
+	if strings.EqualFold(filepath.Ext(event.Name), ".wxapkg") {
+		return true
+	}
+	return event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename)
+}
+
+// refreshWatches 重新收集基础路径，为新出现的目录补充监听
+func (d *daemonRunner) refreshWatches() error {
+	basePaths, err := locator.BasePaths(d.options.Scan)
+	if err != nil {
+		return fmt.Errorf("收集缓存目录失败: %w", err)
+	}
+	for _, basePath := range basePaths {
+		if _, ok := d.watched[basePath]; ok {
+			continue
+		}
+		d.watchTree(basePath, watchDepth)
+		d.log.write(DaemonEvent{Event: DaemonEventWatch, Path: basePath})
+	}
+	return nil
+}
+
+// watchTree 监听 root 及其下 depth 层子目录；fsnotify 不支持递归监听
+func (d *daemonRunner) watchTree(root string, depth int) {
+	root = filepath.Clean(root)
+	baseDepth := strings.Count(root, string(filepath.Separator))
+	_ = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.IsDir() {
+			return nil
+		}
+		if strings.Count(path, string(filepath.Separator))-baseDepth > depth {
+			return filepath.SkipDir
+		}
+		if _, ok := d.watched[path]; ok {
+			return nil
+		}
+		if err := d.watcher.Add(path); err != nil {
+			d.log.write(DaemonEvent{Event: DaemonEventError, Path: path, Error: err.Error()})
+			return nil
+		}
+		d.watched[path] = struct{}{}
+		return nil
+	})
+}
+
+func (d *daemonRunner) scan() (map[string]programSnapshot, error) {
+	report, err := locator.ScanWithOptions(d.options.Scan)
+	if err != nil {
+		return nil, err
+	}
+	if cache := d.options.Scan.NameCache; cache != nil {
+		if err := cache.Save(); err != nil {
+			ui.Warning("保存名称库失败: %v", err)
+		}
+	}
+	return snapshotPrograms(report.Programs), nil
+}
+
+func (d *daemonRunner) observe(snapshots map[string]programSnapshot) {
+	for _, appID := range d.tracker.observe(snapshots, time.Now()) {
+		snapshot := snapshots[appID]
+		added, removed := d.tracker.diff(snapshot)
+		d.log.write(DaemonEvent{
+			Event:   DaemonEventChange,
+			AppID:   appID,
+			AppName: snapshot.Program.AppName,
+			Version: snapshot.Program.Version,
+			Path:    snapshot.Program.Path,
+			Added:   added,
+			Removed: removed,
+		})
+		ui.Info("[%s] 包集合变化（新增 %d，移除 %d），等待稳定 %s", appID, len(added), len(removed), d.options.Settle)
+	}
+}
+
+func (d *daemonRunner) outputDir(program locator.MiniProgramInfo) string {
+	if d.options.OutputRoot != "" {
+		return filepath.Join(d.options.OutputRoot, program.AppID)
+	}
+	return internalcmd.DetermineOutputDir(program.Path, program.AppID)
+}
+
+// process 对稳定后的包集合重新运行流水线；结果覆盖写入同一输出目录，新分包随之合并。
+// 由 dispatch 在 worker goroutine 中调用，只能访问 options 与并发安全的事件日志
+func (d *daemonRunner) process(snapshot programSnapshot) {
+	program := snapshot.Program
+	outputDir := d.outputDir(program)
+
+	d.log.write(DaemonEvent{
+		Event:     DaemonEventProcess,
+		AppID:     program.AppID,
+		AppName:   program.AppName,
+		Version:   program.Version,
+		Files:     program.Files,
+		OutputDir: outputDir,
+	})
+	ui.PrintDivider()
+	if program.AppName != "" {
+		ui.Info("[%s] 开始处理 %s（版本 %s，%d 个包）", program.AppID, program.AppName, program.Version, len(program.Files))
+	} else {
+		ui.Info("[%s] 开始处理版本 %s（%d 个包）", program.AppID, program.Version, len(program.Files))
+	}
+
+	if d.options.Archive {
+		if version, created, err := internalcmd.ArchiveVersion(outputDir, program.AppID, program.Version, program.Path, program.Files); err != nil {
+			ui.Warning("[%s] 归档版本失败: %v", program.AppID, err)
+		} else if created {
+			ui.Success("[%s] 已归档新版本: %s", program.AppID, version.ID)
+		}
+	}
+
+	options := d.options.Pipeline
+	options.AppID = program.AppID
+	options.Input = program.Input()
+	options.OutputDir = outputDir
+	options.Observer = &batchObserver{appID: program.AppID}
+
+	started := time.Now()
+	result, err := wxapkg.Run(options)
+	event := DaemonEvent{
+		Event:      DaemonEventDone,
+		AppID:      program.AppID,
+		AppName:    program.AppName,
+		Version:    program.Version,
+		OutputDir:  outputDir,
+		DurationMS: time.Since(started).Milliseconds(),
+	}
+	if err != nil {
+		event.Event = DaemonEventError
+		event.Error = err.Error()
+		d.log.write(event)
+		ui.Error("[%s] %v", program.AppID, err)
+		return
+	}
+
+	event.Skipped = result.SkippedFiles
+	if report := result.Completeness; report != nil {
+		event.Completeness = report.Status
+		event.MissingSubpackages = report.MissingSubpackages
+	}
+	if report := result.Scan; report != nil {
+		event.Endpoints = len(report.APIEndpoints)
+		event.HighRisk = report.Summary.HighRisk
+	}
+	if manifest := result.Routes; manifest != nil {
+		event.Pages = manifest.Summary.TotalPages
+	}
+	if len(result.PackageErrors) > 0 {
+		event.Message = fmt.Sprintf("%d 个包处理失败", len(result.PackageErrors))
+	}
+	d.log.write(event)
+
+	printBatchSummary(result)
+	if report := result.Completeness; report != nil && len(report.MissingSubpackages) > 0 {
+		ui.Warning("[%s] 仍缺失 %d 个分包，在微信中打开对应页面后会自动合并", program.AppID, len(report.MissingSubpackages))
+	}
+}
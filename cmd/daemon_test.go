@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/25smoking/Gwxapkg/internal/locator"
+	"github.com/25smoking/Gwxapkg/pkg/wxapkg"
+)
+
+func writeDaemonTestPackage(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	writer := wxapkg.NewWriter()
+	for name, content := range files {
+		if err := writer.AddBytes(name, []byte(content)); err != nil {
+			t.Fatalf("添加文件失败: %v", err)
+		}
+	}
+	var data bytes.Buffer
+	if _, err := writer.WriteTo(&data); err != nil {
+		t.Fatalf("写出 wxapkg 失败: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("创建目录失败: %v", err)
+	}
+	if err := os.WriteFile(path, data.Bytes(), 0644); err != nil {
+		t.Fatalf("写入测试包失败: %v", err)
+	}
+}
+
+func TestPackageTrackerWaitsUntilPackageSetSettles(t *testing.T) {
+	dir := t.TempDir()
+	mainPkg := filepath.Join(dir, "__APP__.wxapkg")
+	subPkg := filepath.Join(dir, "_sub_.wxapkg")
+	os.WriteFile(mainPkg, []byte("main"), 0644)
+	program := locator.MiniProgramInfo{AppID: "wx00000000000000e1", Version: "3", Path: dir, Files: []string{mainPkg}}
+
+	now := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+	tracker := newPackageTracker(10 * time.Second)
+	tracker.baseline(snapshotPrograms([]locator.MiniProgramInfo{program}))
+	if changed := tracker.observe(snapshotPrograms([]locator.MiniProgramInfo{program}), now); len(changed) != 0 {
+		t.Fatalf("未变化的包集合不应触发: %v", changed)
+	}
+
+	os.WriteFile(subPkg, []byte("sub"), 0644)
+	program.Files = []string{subPkg, mainPkg}
+	snapshot := snapshotPrograms([]locator.MiniProgramInfo{program})
+	if changed := tracker.observe(snapshot, now); len(changed) != 1 {
+		t.Fatalf("新增分包应触发变化: %v", changed)
+	}
+	added, removed := tracker.diff(snapshot[program.AppID])
+	if len(added) != 1 || added[0] != subPkg || len(removed) != 0 {
+		t.Fatalf("新增/移除文件不正确: %v %v", added, removed)
+	}
+
+	// 分包仍在写入：大小变化会重新计时
+	os.WriteFile(subPkg, []byte("sub-grown"), 0644)
+	if changed := tracker.observe(snapshotPrograms([]locator.MiniProgramInfo{program}), now.Add(8*time.Second)); len(changed) != 1 {
+		t.Fatalf("文件大小变化应重新计时: %v", changed)
+	}
+	if ready := tracker.due(now.Add(12 * time.Second)); len(ready) != 0 {
+		t.Fatalf("未稳定足够时长不应处理: %d", len(ready))
+	}
+	ready := tracker.due(now.Add(18 * time.Second))
+	if len(ready) != 1 || len(ready[0].Program.Files) != 2 {
+		t.Fatalf("稳定后应处理一次: %#v", ready)
+	}
+	tracker.markProcessed(ready[0])
+	if changed := tracker.observe(snapshotPrograms([]locator.MiniProgramInfo{program}), now.Add(20*time.Second)); len(changed) != 0 {
+		t.Fatalf("处理后包集合未变化不应再次触发: %v", changed)
+	}
+}
+
+func TestDaemonProcessWritesCompletenessAndEventLog(t *testing.T) {
+	appID := "wx00000000000000e2"
+	cacheDir := filepath.Join(t.TempDir(), appID, "7")
+	mainPkg := filepath.Join(cacheDir, "__APP__.wxapkg")
+	writeDaemonTestPackage(t, mainPkg, map[string]string{
+		"/app.json":               `{"pages":["pages/index/index"],"subPackages":[{"root":"pkgA/","pages":["pages/detail"]}]}`,
+		"/pages/index/index.js":   "Page({})",
+		"/pages/index/index.wxml": "<view />",
+	})
+
+	root := t.TempDir()
+	logPath := filepath.Join(root, DaemonEventLogName)
+	eventLog, err := openDaemonEventLog(logPath)
+	if err != nil {
+		t.Fatalf("打开事件日志失败: %v", err)
+	}
+	defer eventLog.Close()
+
+	pipeline := wxapkg.DefaultOptions("", "")
+	pipeline.Sensitive = false
+	pipeline.Rewrite.ASTRename.Mode = wxapkg.ASTRenameOff
+	d := &daemonRunner{
+		options: DaemonOptions{OutputRoot: root, Pipeline: pipeline},
+		tracker: newPackageTracker(time.Second),
+		log:     eventLog,
+	}
+	program := locator.MiniProgramInfo{AppID: appID, Version: "7", Path: cacheDir, Files: []string{mainPkg}}
+	d.process(snapshotPrograms([]locator.MiniProgramInfo{program})[appID])
+
+	file, err := os.Open(logPath)
+	if err != nil {
+		t.Fatalf("读取事件日志失败: %v", err)
+	}
+	defer file.Close()
+	var events []DaemonEvent
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event DaemonEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("事件日志不是 JSON Lines: %v", err)
+		}
+		events = append(events, event)
+	}
+	if len(events) != 2 || events[0].Event != DaemonEventProcess || events[1].Event != DaemonEventDone {
+		t.Fatalf("事件序列不正确: %#v", events)
+	}
+	done := events[1]
+	if done.OutputDir != filepath.Join(root, appID) || done.Completeness == "" || len(done.MissingSubpackages) != 1 {
+		t.Fatalf("done 事件应包含输出目录与完整性状态: %#v", done)
+	}
+	if _, err := os.Stat(filepath.Join(root, appID, ".gwxapkg", "package_completeness.json")); err != nil {
+		t.Fatalf("应写出分包完整性报告: %v", err)
+	}
+}
+
+func TestDaemonDispatchBoundsWorkersAndSerializesSameAppID(t *testing.T) {
+	root := t.TempDir()
+	eventLog, err := openDaemonEventLog(filepath.Join(root, DaemonEventLogName))
+	if err != nil {
+		t.Fatalf("打开事件日志失败: %v", err)
+	}
+	defer eventLog.Close()
+
+	var programs []locator.MiniProgramInfo
+	for _, appID := range []string{"wx00000000000000e3", "wx00000000000000e4"} {
+		cacheDir := filepath.Join(t.TempDir(), appID, "1")
+		mainPkg := filepath.Join(cacheDir, "__APP__.wxapkg")
+		writeDaemonTestPackage(t, mainPkg, map[string]string{
+			"/app.json":               `{"pages":["pages/index/index"]}`,
+			"/pages/index/index.js":   "Page({})",
+			"/pages/index/index.wxml": "<view />",
+		})
+		programs = append(programs, locator.MiniProgramInfo{AppID: appID, Version: "1", Path: cacheDir, Files: []string{mainPkg}})
+	}
+	snapshots := snapshotPrograms(programs)
+	first, second := snapshots[programs[0].AppID], snapshots[programs[1].AppID]
+	firstAgain := first
+	firstAgain.Fingerprint += "changed"
+
+	pipeline := wxapkg.DefaultOptions("", "")
+	pipeline.Sensitive = false
+	pipeline.Rewrite.ASTRename.Mode = wxapkg.ASTRenameOff
+	d := newDaemonRunner(DaemonOptions{OutputRoot: root, Pipeline: pipeline, Concurrency: 1}, eventLog)
+
+	d.enqueue(first)
+	d.enqueue(second)
+	d.enqueue(firstAgain)
+	if len(d.running) != 1 || len(d.queue) != 2 {
+		t.Fatalf("并发数为 1 时只应启动一个 worker: running=%v queue=%d", d.running, len(d.queue))
+	}
+
+	// 同一 AppID 等上一次完成后才再次处理，其间先处理其他 AppID
+	var order []string
+	for len(order) < 3 {
+		appID := <-d.done
+		order = append(order, appID)
+		delete(d.running, appID)
+		d.dispatch()
+		if len(d.running) > 1 {
+			t.Fatalf("运行中的 worker 超出并发数: %v", d.running)
+		}
+	}
+	d.workers.Wait()
+	want := []string{first.Program.AppID, second.Program.AppID, first.Program.AppID}
+	if strings.Join(order, ",") != strings.Join(want, ",") {
+		t.Fatalf("处理顺序不正确: %v，期望 %v", order, want)
+	}
+}
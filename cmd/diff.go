@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	internalcmd "github.com/25smoking/Gwxapkg/internal/cmd"
+	"github.com/25smoking/Gwxapkg/internal/reporter"
+	"github.com/25smoking/Gwxapkg/internal/ui"
+	"github.com/25smoking/Gwxapkg/internal/versiondiff"
+	"github.com/25smoking/Gwxapkg/internal/versionstore"
+	"github.com/25smoking/Gwxapkg/pkg/wxapkg"
+)
+
+// DiffOptions diff 子命令参数。Old / New 可以是已解包输出目录、wxapkg 文件或包目录，
+// 也可以是 StoreDir 版本库中的版本选择器（如 latest、latest~1、版本 ID 前缀）。
+type DiffOptions struct {
+	AppID     string
+	Old       string
+	New       string
+	OldLabel  string
+	NewLabel  string
+	OutputDir string
+	StoreDir  string // 版本库所在的输出目录，为空时不按版本解析
+}
+
+// ExecuteDiff 对比两个版本并输出 JSON / Markdown / HTML 报告
+func ExecuteDiff(options DiffOptions) *versiondiff.Report {
+	oldInput, oldLabel, err := resolveDiffVersion(options.Old, options.OldLabel, options.StoreDir)
+	if err != nil {
+		ui.Error("旧版本: %v", err)
+		return nil
+	}
+	newInput, newLabel, err := resolveDiffVersion(options.New, options.NewLabel, options.StoreDir)
+	if err != nil {
+		ui.Error("新版本: %v", err)
+		return nil
+	}
+
+	oldSnapshot, cleanupOld, err := loadDiffSide(oldInput, oldLabel, options.AppID, "旧版本")
+	if err != nil {
+		ui.Error("%v", err)
+		return nil
+	}
+	defer cleanupOld()
+
+	newSnapshot, cleanupNew, err := loadDiffSide(newInput, newLabel, options.AppID, "新版本")
+	if err != nil {
+		ui.Error("%v", err)
+		return nil
+	}
+	defer cleanupNew()
+
+	report := versiondiff.Compare(oldSnapshot, newSnapshot)
+	if report.AppID == "" {
+		report.AppID = options.AppID
+	}
+
+	outputDir := options.OutputDir
+	if outputDir == "" {
+		switch {
+		case isUnpackedDir(newInput):
+			outputDir = filepath.Join(newInput, ".gwxapkg")
+		case options.StoreDir != "":
+			outputDir = filepath.Join(options.StoreDir, ".gwxapkg")
+		default:
+			outputDir = "."
+		}
+	}
+	artifacts, err := reporter.NewDiffReporter().Generate(report, outputDir)
+	if err != nil {
+		ui.Error("写入差异报告失败: %v", err)
+		return report
+	}
+
+	summary := report.Summary
+	for _, note := range report.Notes {
+		ui.Warning("%s", note)
+	}
+	ui.Info("   - 页面: +%d -%d ~%d", summary.AddedPages, summary.RemovedPages, summary.ChangedPages)
+	ui.Info("   - 接口: +%d -%d ~%d", summary.AddedEndpoints, summary.RemovedEndpoints, summary.ChangedEndpoints)
+	ui.Info("   - 敏感信息: +%d（高风险 %d） -%d", summary.AddedFindings, summary.AddedHighRisk, summary.RemovedFindings)
+	ui.Info("   - 跳转边: +%d -%d | 组件依赖变化页面: %d", summary.AddedEdges, summary.RemovedEdges, summary.ChangedComponents)
+	ui.Success("差异报告 JSON: %s", artifacts.JSONPath)
+	ui.Success("差异报告 Markdown: %s", artifacts.MarkdownPath)
+	ui.Success("差异报告 HTML: %s", artifacts.HTMLPath)
+	return report
+}
+
+// resolveDiffVersion 输入不是已存在的路径时，按版本选择器在版本库中查找对应的包目录
+func resolveDiffVersion(input, label, storeDir string) (string, string, error) {
+	if _, err := os.Stat(input); err == nil || storeDir == "" || input == "" {
+		return input, label, nil
+	}
+	version, err := versionstore.Open(storeDir).Resolve(input)
+	if err != nil {
+		return "", "", err
+	}
+	if label == "" {
+		label = version.ID
+	}
+	ui.Info("版本 %s -> %s", input, version.ID)
+	return version.PackageDir(), label, nil
+}
+
+// loadDiffSide 已解包目录直接读取产物；wxapkg 输入先在临时目录跑一遍完整流水线
+func loadDiffSide(input, label, appID, title string) (*versiondiff.Snapshot, func(), error) {
+	noop := func() {}
+	if input == "" {
+		return nil, noop, fmt.Errorf("请指定%s路径", title)
+	}
+	if label == "" {
+		label = filepath.Base(filepath.Clean(input))
+	}
+
+	if isUnpackedDir(input) {
+		snapshot, err := versiondiff.Load(input, label)
+		if err != nil {
+			return nil, noop, fmt.Errorf("读取%s失败: %w", title, err)
+		}
+		return snapshot, noop, nil
+	}
+
+	if appID == "" {
+		return nil, noop, fmt.Errorf("%s %s 不是已解包目录，解包 wxapkg 需要 -id", title, input)
+	}
+	if len(internalcmd.ParseInput(input, ".wxapkg")) == 0 {
+		return nil, noop, fmt.Errorf("%s %s 既不是已解包目录，也不包含 wxapkg 文件", title, input)
+	}
+
+	tempDir, err := os.MkdirTemp("", "gwxapkg-diff-")
+	if err != nil {
+		return nil, noop, fmt.Errorf("创建临时目录失败: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(tempDir) }
+
+	ui.Info("解包%s: %s", title, input)
+	runOptions := wxapkg.DefaultOptions(appID, input)
+	runOptions.OutputDir = tempDir
+	runOptions.Observer = &batchObserver{appID: label}
+	if _, err := wxapkg.Run(runOptions); err != nil {
+		cleanup()
+		return nil, noop, fmt.Errorf("解包%s失败: %w", title, err)
+	}
+
+	snapshot, err := versiondiff.Load(tempDir, label)
+	if err != nil {
+		cleanup()
+		return nil, noop, fmt.Errorf("读取%s失败: %w", title, err)
+	}
+	snapshot.Dir = input
+	return snapshot, cleanup, nil
+}
+
+// isUnpackedDir 目录中存在还原后的配置或本工具生成的产物时视为已解包输出
+func isUnpackedDir(dir string) bool {
+	for _, name := range []string{"route_manifest.json", "app.json", "app-config.json", ".gwxapkg"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -1,261 +1,206 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 	"sync"
-	"sync/atomic"
 
-	"github.com/25smoking/Gwxapkg/internal/analyzer"
-	. "github.com/25smoking/Gwxapkg/internal/cmd"
-	. "github.com/25smoking/Gwxapkg/internal/config"
-	"github.com/25smoking/Gwxapkg/internal/key"
-	packmeta "github.com/25smoking/Gwxapkg/internal/pack"
+	internalcmd "github.com/25smoking/Gwxapkg/internal/cmd"
 	"github.com/25smoking/Gwxapkg/internal/packagecheck"
-	"github.com/25smoking/Gwxapkg/internal/reporter"
-	"github.com/25smoking/Gwxapkg/internal/restore"
 	"github.com/25smoking/Gwxapkg/internal/semantic"
 	"github.com/25smoking/Gwxapkg/internal/ui"
-	"github.com/25smoking/Gwxapkg/internal/util"
+	"github.com/25smoking/Gwxapkg/pkg/wxapkg"
+	"github.com/schollz/progressbar/v3"
 )
 
+// Execute 兼容旧版位置参数调用方式，新代码请直接构造 wxapkg.Options。
 func Execute(appID, input, outputDir, fileExt string, restoreDir bool, pretty bool, noClean bool, save bool, sensitive bool, postman bool, workspace bool) *packagecheck.Report {
-	return ExecuteWithOptions(appID, input, outputDir, fileExt, restoreDir, pretty, noClean, save, sensitive, postman, workspace, semantic.DefaultRewriteOptions())
+	return ExecuteWithOptions(wxapkg.Options{
+		AppID:     appID,
+		Input:     input,
+		OutputDir: outputDir,
+		FileExt:   fileExt,
+		Restore:   restoreDir,
+		Pretty:    pretty,
+		NoClean:   noClean,
+		Save:      save,
+		Sensitive: sensitive,
+		Postman:   postman,
+		Workspace: workspace,
+		Rewrite:   semantic.DefaultRewriteOptions(),
+	})
 }
 
-func ExecuteWithOptions(appID, input, outputDir, fileExt string, restoreDir bool, pretty bool, noClean bool, save bool, sensitive bool, postman bool, workspace bool, rewriteOptions semantic.RewriteOptions) *packagecheck.Report {
-	// 确定输出目录
-	if outputDir == "" {
-		outputDir = DetermineOutputDir(input, appID)
+// ExecuteWithOptions 运行 wxapkg.Run 并把进度与结果输出到终端
+func ExecuteWithOptions(options wxapkg.Options) *packagecheck.Report {
+	observer := &cliObserver{}
+	options.Observer = observer
+	if options.Restore {
+		printASTRenameNotice(options.Rewrite.ASTRename)
 	}
-	expandedOutputDir, err := util.ExpandHomePath(outputDir)
-	if err != nil {
-		ui.Warning("展开输出目录失败，继续使用原路径: %v", err)
-	} else {
-		outputDir = expandedOutputDir
-	}
-
-	// 存储配置
-	configManager := NewSharedConfigManager()
-	configManager.Set("appID", appID)
-	configManager.Set("input", input)
-	configManager.Set("outputDir", outputDir)
-	configManager.Set("fileExt", fileExt)
-	configManager.Set("restoreDir", restoreDir)
-	configManager.Set("pretty", pretty)
-	configManager.Set("noClean", noClean)
-	configManager.Set("save", save)
-	configManager.Set("sensitive", sensitive)
-	configManager.Set("postman", postman)
-	configManager.Set("workspace", workspace)
 
-	inputFiles := ParseInput(input, fileExt)
-
-	if len(inputFiles) == 0 {
+	result, err := wxapkg.Run(options)
+	if errors.Is(err, wxapkg.ErrNoInput) {
 		ui.Warning("未找到任何文件")
 		return nil
 	}
-
-	// 如果需要敏感扫描或 Postman 导出，初始化规则与收集器
-	if sensitive || postman {
-		if err := key.InitRules(); err != nil {
-			ui.Warning("初始化扫描规则失败: %v", err)
-			sensitive = false
-			postman = false
-		} else {
-			key.InitCollector(appID)
-		}
+	if err != nil {
+		ui.Error("%v", err)
+		return nil
 	}
 
-	// 显示步骤信息
-	ui.Step(1, 2, "解包 wxapkg 文件...")
+	printResult(result, options.Sensitive, options.Triage)
+	return result.Completeness
+}
 
-	// 创建进度条
-	bar := ui.NewProgressBar(len(inputFiles), "解包中")
+// cliObserver 把流水线事件渲染为终端进度条与提示
+type cliObserver struct {
+	mu     sync.Mutex
+	bar    *progressbar.ProgressBar
+	errors []error
+}
 
-	var wg sync.WaitGroup
-	var errCount int32
-	errChan := make(chan error, len(inputFiles))
+func (o *cliObserver) Start(appID string, inputFiles []string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.errors = nil
+	o.bar = ui.NewProgressBar(len(inputFiles), "解包中")
+}
 
-	for _, inputFile := range inputFiles {
-		wg.Add(1)
-		go func(file string) {
-			defer wg.Done()
-			err := ProcessFile(file, outputDir, appID, save, workspace)
-			if err != nil {
-				atomic.AddInt32(&errCount, 1)
-				errChan <- err
-			}
-			bar.Add(1)
-		}(inputFile)
+func (o *cliObserver) Stage(step, total int, title string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	// 解包阶段结束后统一输出失败信息，避免打断进度条
+	if step > 1 && o.bar != nil {
+		o.bar = nil
+		for _, err := range o.errors {
+			ui.Error("%v", err)
+		}
+		if len(o.errors) > 0 {
+			ui.Warning("解包完成，%d 个文件处理失败", len(o.errors))
+		}
 	}
-	wg.Wait()
-	close(errChan)
+	ui.Step(step, total, "%s", title)
+}
 
-	for err := range errChan {
-		ui.Error("%v", err)
+func (o *cliObserver) PackageDone(file string, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if err != nil {
+		o.errors = append(o.errors, err)
 	}
+	if o.bar != nil {
+		o.bar.Add(1)
+	}
+}
 
-	// 显示解包结果
-	if errCount > 0 {
-		ui.Warning("解包完成，%d 个文件处理失败", errCount)
+func (o *cliObserver) Warning(message string) {
+	ui.Warning("%s", message)
+}
+
+func printResult(result *wxapkg.Result, sensitive bool, triage *wxapkg.Triage) {
+	artifacts := result.Artifacts
+	if result.Incremental {
+		ui.Info("增量处理: 重新解包 %d 个包，跳过 %d 个未变化的包（-full 可全部重新处理）",
+			len(result.InputFiles)-len(result.SkippedFiles), len(result.SkippedFiles))
 	}
 
-	// 为保留原始包内容的场景生成 manifest，方便后续精确回包
-	if workspace || !restoreDir || noClean {
-		if err := packmeta.WritePackageManifest(outputDir, appID, GetWxapkgManager()); err != nil {
-			ui.Warning("写入回包 manifest 失败: %v", err)
+	if report := result.Semantic; report != nil {
+		if artifacts.SemanticModuleMap != "" {
+			ui.Success("源码语义映射: %s", artifacts.SemanticModuleMap)
+			ui.Info("   - 语义重命名: %d | require 重写: %d | SourceMap 源码: %d",
+				report.RenamedCount,
+				report.RewrittenRequireCount,
+				report.SourceMapRecovered,
+			)
+		}
+		if artifacts.APIMap != "" {
+			ui.Success("API 地图: %s", artifacts.APIMap)
+			ui.Info("   - API 函数: %d | 细拆模块: %d",
+				report.APIEndpointCount,
+				report.APISplitCount,
+			)
+			ui.Success("API 调用链: %s", artifacts.APICallChain)
+			ui.Success("API 伪代码: %s", artifacts.APIPseudo)
+		}
+		if artifacts.CryptoMap != "" {
+			ui.Success("加解密地图: %s", artifacts.CryptoMap)
+			ui.Info("   - 加解密调用: %d | 签名函数: %d",
+				report.CryptoUsageCount,
+				report.CryptoSignerCount,
+			)
+		}
+		if artifacts.ASTRenameMap != "" {
+			ui.Success("AST 重命名报告: %s", artifacts.ASTRenameMap)
+			ui.Info("   - AST 重命名: %d | 文件数: %d",
+				report.ASTRenamedCount,
+				report.ASTRenamedFiles,
+			)
 		}
 	}
 
-	// 还原工程目录结构
-	ui.Step(2, 2, "还原工程结构...")
-	restore.ProjectStructure(outputDir, restoreDir)
-
-	if restoreDir {
-		printASTRenameNotice(rewriteOptions.ASTRename)
-		semanticReport, err := semantic.RewriteProjectWithOptions(outputDir, rewriteOptions)
-		if err != nil {
-			ui.Warning("源码级语义反混淆失败: %v", err)
-		} else {
-			if collector := key.GetCollector(); collector != nil {
-				collector.RewriteFilePaths(semanticReport.PathMap)
-			}
-			if semanticReport.RenamedCount > 0 || semanticReport.SourceMapRecovered > 0 {
-				ui.Success("源码语义映射: %s", filepath.Join(outputDir, ".gwxapkg", "semantic_module_map.json"))
-				ui.Info("   - 语义重命名: %d | require 重写: %d | SourceMap 源码: %d",
-					semanticReport.RenamedCount,
-					semanticReport.RewrittenRequireCount,
-					semanticReport.SourceMapRecovered,
-				)
-			}
-			if semanticReport.APIEndpointCount > 0 {
-				ui.Success("API 地图: %s", filepath.Join(outputDir, ".gwxapkg", "api_map.md"))
-				ui.Info("   - API 函数: %d | 细拆模块: %d",
-					semanticReport.APIEndpointCount,
-					semanticReport.APISplitCount,
-				)
-				ui.Success("API 调用链: %s", filepath.Join(outputDir, ".gwxapkg", "api_call_chain.md"))
-				ui.Success("API 伪代码: %s", filepath.Join(outputDir, ".gwxapkg", "api_pseudo.md"))
-			}
-			if semanticReport.ASTRenamedCount > 0 {
-				ui.Success("AST 重命名报告: %s", filepath.Join(outputDir, ".gwxapkg", "ast_rename_map.json"))
-				ui.Info("   - AST 重命名: %d | 文件数: %d",
-					semanticReport.ASTRenamedCount,
-					semanticReport.ASTRenamedFiles,
-				)
-			}
-		}
+	if artifacts.RequestTrace != "" {
+		ui.Success("请求追踪: %s", artifacts.RequestTrace)
 	}
 
-	var completenessReport *packagecheck.Report
-	if restoreDir {
-		report, err := packagecheck.AnalyzeAndWrite(outputDir, appID, inputFiles)
-		if err != nil {
-			ui.Warning("分包完整性检测失败: %v", err)
-		} else if report != nil && report.Status != packagecheck.StatusUnknown {
-			completenessReport = report
-			printPackageCompleteness(report, outputDir)
-		}
+	if result.Completeness != nil {
+		printPackageCompleteness(result.Completeness, result.OutputDir)
 	}
 
 	// 输出结果目录
 	fmt.Println()
-	ui.Success("输出目录: %s", filepath.Clean(outputDir))
-
-	collector := key.GetCollector()
-	if collector != nil {
-		collector.SetTotalFiles(len(inputFiles))
-		report := collector.GenerateReport()
+	ui.Success("输出目录: %s", filepath.Clean(result.OutputDir))
 
-		if len(report.APIEndpoints) > 0 {
-			apiEndpointMapReporter := reporter.NewAPIEndpointMapReporter()
-			artifacts, err := apiEndpointMapReporter.Generate(report, outputDir, outputDir)
-			if err != nil {
-				ui.Warning("生成通用 API Endpoint 地图失败: %v", err)
-			} else {
-				ui.Success("通用 API Endpoint 地图: %s", artifacts.MarkdownPath)
-				ui.Info("   - 通用 Endpoint: %d", len(report.APIEndpoints))
-			}
+	if report := result.Scan; report != nil {
+		if artifacts.APIEndpointMap != "" {
+			ui.Success("通用 API Endpoint 地图: %s", artifacts.APIEndpointMap)
+			ui.Info("   - 通用 Endpoint: %d", len(report.APIEndpoints))
 		}
-
-		if sensitive {
-			jsonReporter := reporter.NewJSONReporter()
-			jsonPath := filepath.Join(outputDir, "sensitive_report.json")
-			if err := jsonReporter.Generate(report, jsonPath); err != nil {
-				ui.Warning("生成 JSON 报告失败: %v", err)
-			} else {
-				ui.Success("JSON 报告: %s", jsonPath)
-			}
-
-			excelReporter := reporter.NewExcelReporter()
-			excelPath := filepath.Join(outputDir, "sensitive_report.xlsx")
-			if err := excelReporter.Generate(report, excelPath); err != nil {
-				ui.Warning("生成 Excel 报告失败: %v", err)
-			} else {
-				ui.Success("Excel 报告: %s", excelPath)
-			}
-
-			htmlReporter := reporter.NewHTMLReporter()
-			htmlPath := filepath.Join(outputDir, "sensitive_report.html")
-			if err := htmlReporter.Generate(report, htmlPath); err != nil {
-				ui.Warning("生成 HTML 报告失败: %v", err)
-			} else {
-				ui.Success("HTML 报告: %s", htmlPath)
-			}
+		if artifacts.SensitiveJSON != "" {
+			ui.Success("JSON 报告: %s", artifacts.SensitiveJSON)
 		}
-
-		if postman {
-			postmanReporter := reporter.NewPostmanReporter()
-			postmanPath := filepath.Join(outputDir, "api_collection.postman_collection.json")
-			if err := postmanReporter.Generate(report, postmanPath); err != nil {
-				ui.Warning("生成 Postman Collection 失败: %v", err)
-			} else {
-				ui.Success("Postman Collection: %s", postmanPath)
-			}
+		if artifacts.SensitiveExcel != "" {
+			ui.Success("Excel 报告: %s", artifacts.SensitiveExcel)
 		}
-
-		if sensitive || postman {
-			ui.Info("   - 接口数: %d", len(report.APIEndpoints))
-			ui.Info("   - 混淆文件: %d", len(report.ObfuscatedFiles))
+		if artifacts.SensitiveHTML != "" {
+			ui.Success("HTML 报告: %s", artifacts.SensitiveHTML)
+		}
+		if artifacts.SensitiveSARIF != "" {
+			ui.Success("SARIF 报告: %s", artifacts.SensitiveSARIF)
+		}
+		if artifacts.Postman != "" {
+			ui.Success("Postman Collection: %s", artifacts.Postman)
 		}
+		if artifacts.OpenAPI != "" {
+			ui.Success("OpenAPI: %s", artifacts.OpenAPI)
+		}
+
+		ui.Info("   - 接口数: %d", len(report.APIEndpoints))
+		ui.Info("   - 混淆文件: %d", len(report.ObfuscatedFiles))
 		if sensitive {
 			ui.Info("   - 总匹配数: %d", report.Summary.TotalMatches)
 			ui.Info("   - 去重后: %d", report.Summary.UniqueMatches)
 			ui.Info("   - 高风险: %d | 中风险: %d | 低风险: %d",
 				report.Summary.HighRisk, report.Summary.MediumRisk, report.Summary.LowRisk)
+			internalcmd.PrintVerificationSummary(report)
+			internalcmd.PrintTriageSummary(report, triage)
 		}
-
-		key.ResetCollector()
 	}
 
-	if restoreDir {
-		routeManifest, routeErr := analyzer.AnalyzeMiniProgram(outputDir, appID)
-		if routeErr != nil {
-			ui.Warning("生成页面与路由地图失败: %v", routeErr)
-			return completenessReport
-		}
-
-		routeReporter := reporter.NewRouteReporter()
-		artifacts, err := routeReporter.Generate(routeManifest, outputDir)
-		if err != nil {
-			ui.Warning("写入页面与路由地图失败: %v", err)
-			return completenessReport
-		}
-
-		ui.Success("页面路由清单: %s", artifacts.ManifestPath)
-		ui.Success("页面路由说明: %s", artifacts.MarkdownPath)
-		ui.Success("页面路由图: %s", artifacts.MermaidPath)
+	if manifest := result.Routes; manifest != nil {
+		ui.Success("页面路由清单: %s", artifacts.RouteManifest)
+		ui.Success("页面路由说明: %s", artifacts.RouteMarkdown)
+		ui.Success("页面路由图: %s", artifacts.RouteMermaid)
 		ui.Info("   - 页面数: %d | 跳转边: %d | 调用链边: %d | 共享助手: %d | TabBar: %d",
-			routeManifest.Summary.TotalPages,
-			routeManifest.Summary.NavigationEdgeCount,
-			routeManifest.Summary.CallChainEdgeCount,
-			routeManifest.Summary.SharedRouterHelperCount,
-			routeManifest.Summary.TabBarPages,
+			manifest.Summary.TotalPages,
+			manifest.Summary.NavigationEdgeCount,
+			manifest.Summary.CallChainEdgeCount,
+			manifest.Summary.SharedRouterHelperCount,
+			manifest.Summary.TabBarPages,
 		)
 	}
-
-	return completenessReport
 }
 
 func printASTRenameNotice(options semantic.ASTRenameOptions) {
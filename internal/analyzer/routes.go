@@ -8,6 +8,7 @@ import (
 	"path"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -75,10 +76,38 @@ var (
 
 // AnalyzeMiniProgram 分析已解包目录中的页面与路由关系。
 func AnalyzeMiniProgram(rootDir, appID string) (*RouteManifest, error) {
+	return analyzeMiniProgram(rootDir, appID, nil, nil)
+}
+
+// AnalyzeMiniProgramIncremental 增量分析页面路由：页面文件、依赖模块与跳转调用链都不在 affected 中的页面
+// 直接沿用 previous 中的分析结果，其余页面重新分析。affected 为相对 rootDir 的 / 分隔路径；
+// 全局配置文件变化或 previous 为空时退回全量分析。
+func AnalyzeMiniProgramIncremental(rootDir, appID string, previous *RouteManifest, affected map[string]bool) (*RouteManifest, error) {
+	if previous == nil || affected[previous.ConfigSource] {
+		return AnalyzeMiniProgram(rootDir, appID)
+	}
+	return analyzeMiniProgram(rootDir, appID, previous, affected)
+}
+
+// LoadRouteManifest 读取 rootDir 下的 route_manifest.json
+func LoadRouteManifest(rootDir string) (*RouteManifest, error) {
+	data, err := os.ReadFile(filepath.Join(rootDir, "route_manifest.json"))
+	if err != nil {
+		return nil, fmt.Errorf("读取 route_manifest.json 失败: %w", err)
+	}
+	var manifest RouteManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("解析 route_manifest.json 失败: %w", err)
+	}
+	return &manifest, nil
+}
+
+func analyzeMiniProgram(rootDir, appID string, previous *RouteManifest, affected map[string]bool) (*RouteManifest, error) {
 	app, configSource, err := loadAppConfig(rootDir)
 	if err != nil {
 		return nil, err
 	}
+	reusable := reusablePages(previous, affected)
 
 	entryPage := normalizeRoute(app.EntryPagePath)
 	if entryPage == "" && len(app.Pages) > 0 {
@@ -160,6 +189,15 @@ func AnalyzeMiniProgram(rootDir, appID string) (*RouteManifest, error) {
 		node := pageIndex[route]
 		node.Files = detectPageFiles(rootDir, route)
 		analyzerCtx.markPageScript(node.Files.JS)
+		if cached, ok := reusable[route]; ok && cached.node.Files == node.Files {
+			node.Title = cached.node.Title
+			node.UsingComponents = cached.node.UsingComponents
+			node.Dependencies = cached.node.Dependencies
+			node.APIUsage = cached.node.APIUsage
+			manifest.NavigationEdges = append(manifest.NavigationEdges, cached.edges...)
+			manifest.Pages = append(manifest.Pages, *node)
+			continue
+		}
 
 		title, components := parsePageMetadata(rootDir, route, node.Files.JSON)
 		if title == "" {
@@ -191,6 +229,42 @@ func AnalyzeMiniProgram(rootDir, appID string) (*RouteManifest, error) {
 	return manifest, nil
 }
 
+type reusablePage struct {
+	node  PageNode
+	edges []NavigationEdge
+}
+
+// reusablePages 返回上次分析中没有任何相关文件受影响的页面
+func reusablePages(previous *RouteManifest, affected map[string]bool) map[string]reusablePage {
+	if previous == nil {
+		return nil
+	}
+	edgesByPage := make(map[string][]NavigationEdge)
+	for _, edge := range previous.NavigationEdges {
+		edgesByPage[edge.SourcePage] = append(edgesByPage[edge.SourcePage], edge)
+	}
+
+	pages := make(map[string]reusablePage, len(previous.Pages))
+	for _, page := range previous.Pages {
+		related := []string{page.Files.JS, page.Files.WXML, page.Files.WXSS, page.Files.JSON}
+		related = append(related, page.Dependencies...)
+		for _, usage := range page.APIUsage {
+			related = append(related, usage.FilePath)
+		}
+		for _, edge := range edgesByPage[page.Route] {
+			related = append(related, edge.SourceFile)
+			for _, step := range edge.CallChain {
+				related = append(related, step.FilePath)
+			}
+		}
+		if slices.ContainsFunc(related, func(relPath string) bool { return affected[relPath] }) {
+			continue
+		}
+		pages[page.Route] = reusablePage{node: page, edges: edgesByPage[page.Route]}
+	}
+	return pages
+}
+
 func loadAppConfig(rootDir string) (*appConfig, string, error) {
 	candidates := []string{
 		filepath.Join(rootDir, "app.json"),
@@ -1400,11 +1474,17 @@ func shouldIgnoreGeneratedArtifact(relPath string) bool {
 	}
 
 	name := path.Base(relPath)
+	if strings.HasSuffix(name, ".postman_environment.json") {
+		return true
+	}
 	switch name {
 	case "sensitive_report.html",
 		"sensitive_report.xlsx",
 		"sensitive_report.json",
+		"sensitive_baseline.json",
 		"api_collection.postman_collection.json",
+		"request_trace.postman_collection.json",
+		"openapi.yaml",
 		"route_manifest.json",
 		"route_map.md",
 		"route_map.mmd":
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/25smoking/Gwxapkg/internal/restore"
+	"github.com/25smoking/Gwxapkg/internal/session"
 	"github.com/25smoking/Gwxapkg/internal/util"
 
 	. "github.com/25smoking/Gwxapkg/internal/config"
@@ -106,11 +107,13 @@ func isGoBuildTempDir(dir, tempDir string) bool {
 	return strings.Contains(cleanDir, "go-build")
 }
 
-// ProcessFile 合并目录
-func ProcessFile(inputFile, outputDir, appID string, save bool, workspace bool) error {
+// ProcessFile 合并目录，包信息登记到 sess.Manager，扫描结果写入 sess.Collector
+func ProcessFile(sess *session.Session, inputFile string, save bool, workspace bool) error {
 	// log.Printf("开始处理文件: %s\n", inputFile)
 
-	manager := GetWxapkgManager()
+	appID := sess.AppID
+	outputDir := sess.OutputDir
+	manager := sess.Manager
 
 	// 初始化 WxapkgInfo
 	info := &WxapkgInfo{
@@ -122,11 +125,12 @@ func ProcessFile(inputFile, outputDir, appID string, save bool, workspace bool)
 	// 确定解密后的文件路径
 	decryptedFilePath := filepath.Join(outputDir, filepath.Base(inputFile))
 
-	// 解密
-	decryptedData, err := decrypt.DecryptWxapkg(inputFile, appID)
+	// 解密（按需读取，避免整包载入内存）
+	decrypted, err := decrypt.Open(inputFile, appID)
 	if err != nil {
 		return fmt.Errorf("解密失败: %v", err)
 	}
+	defer decrypted.Close()
 
 	// 保存解密后的文件
 	err = os.MkdirAll(outputDir, 0755)
@@ -136,7 +140,7 @@ func ProcessFile(inputFile, outputDir, appID string, save bool, workspace bool)
 
 	// 是否保存解密后的文件
 	if save {
-		err = os.WriteFile(decryptedFilePath, decryptedData, 0755)
+		err = saveDecryptedFile(decryptedFilePath, decrypted)
 		if err != nil {
 			return fmt.Errorf("保存解密文件失败: %v", err)
 		}
@@ -158,7 +162,16 @@ func ProcessFile(inputFile, outputDir, appID string, save bool, workspace bool)
 	// 包文件列表
 	var filelist []string
 
-	filelist, err = unpack.UnpackWxapkg(decryptedData, inputFile, tempDir)
+	filelist, err = unpack.UnpackWxapkgFrom(decrypted, decrypted.Size(), inputFile, tempDir, unpack.Options{
+		Pretty:    sess.Pretty,
+		Collector: sess.Collector,
+		Rules:     sess.Rules,
+		Warn: func(err error) {
+			if sess.Warn != nil {
+				sess.Warn("%v", err)
+			}
+		},
+	})
 	if err != nil {
 		return err
 	}
@@ -202,6 +215,21 @@ func ProcessFile(inputFile, outputDir, appID string, save bool, workspace bool)
 	return nil
 }
 
+// saveDecryptedFile 流式写出解密后的完整包
+func saveDecryptedFile(path string, decrypted *decrypt.File) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriterSize(file, 256*1024)
+	if _, err := io.Copy(writer, io.NewSectionReader(decrypted, 0, decrypted.Size())); err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
 func copyDir(srcDir, dstDir string) error {
 	return filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
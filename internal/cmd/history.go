@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/25smoking/Gwxapkg/internal/ui"
+	"github.com/25smoking/Gwxapkg/internal/util"
+	"github.com/25smoking/Gwxapkg/internal/versionstore"
+)
+
+// ArchiveVersion 把一组包文件归档到 outputDir 的版本库，内容未变化时只刷新最近出现时间
+func ArchiveVersion(outputDir, appID, locatorVersion, sourcePath string, files []string) (*versionstore.Version, bool, error) {
+	wccVersion := ""
+	for _, file := range files {
+		inspection, err := InspectPackage(file, appID, nil)
+		if err == nil && inspection.WccVersion != "" {
+			wccVersion = inspection.WccVersion
+			break
+		}
+	}
+
+	return versionstore.Open(outputDir).Add(versionstore.AddInput{
+		AppID:          appID,
+		LocatorVersion: locatorVersion,
+		WccVersion:     wccVersion,
+		SourcePath:     sourcePath,
+		Files:          files,
+	})
+}
+
+// History 列出 outputDir 版本库中的全部版本
+func History(outputDir, format string) {
+	store := versionstore.Open(outputDir)
+	versions, err := store.List()
+	if err != nil {
+		ui.Error("%v", err)
+		return
+	}
+
+	if format == "json" {
+		if versions == nil {
+			versions = []*versionstore.Version{}
+		}
+		data, err := json.MarshalIndent(versions, "", "  ")
+		if err != nil {
+			ui.Error("序列化失败: %v", err)
+			return
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	if len(versions) == 0 {
+		ui.Warning("版本库为空: %s", store.Root)
+		ui.Info("运行 scan / all / daemon 时加上 -archive 才会归档当前缓存的包文件")
+		return
+	}
+
+	ui.Success("共 %d 个版本: %s", len(versions), store.Root)
+	fmt.Println()
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "#\tID\tWCC\tPACKAGES\tSIZE\tFIRST SEEN\tLAST SEEN")
+	for _, version := range versions {
+		wccVersion := version.WccVersion
+		if wccVersion == "" {
+			wccVersion = "-"
+		}
+		fmt.Fprintf(writer, "%d\t%s\t%s\t%d\t%s\t%s\t%s\n",
+			version.Sequence,
+			version.ID,
+			wccVersion,
+			len(version.Packages),
+			util.HumanReadableSize(uint64(version.TotalSize)),
+			version.FirstSeen,
+			version.LastSeen,
+		)
+	}
+	writer.Flush()
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/25smoking/Gwxapkg/internal/decrypt"
+	"github.com/25smoking/Gwxapkg/internal/ui"
+	"github.com/25smoking/Gwxapkg/internal/unpack"
+	"github.com/25smoking/Gwxapkg/internal/util"
+)
+
+// InspectOptions inspect 子命令参数
+type InspectOptions struct {
+	AppID      string
+	Input      string
+	Format     string   // table / json
+	Patterns   []string // 包内路径 glob，为空时列出全部
+	ExtractDir string   // 不为空时只写出匹配的条目
+	Raw        bool     // 提取时原样写出，不做格式化
+}
+
+// InspectPackage 打开单个 wxapkg，只解析头部与索引
+func InspectPackage(inputFile, appID string, patterns []string) (*unpack.Inspection, error) {
+	decrypted, err := decrypt.Open(inputFile, appID)
+	if err != nil {
+		return nil, fmt.Errorf("解密失败: %v", err)
+	}
+	defer decrypted.Close()
+
+	reader, err := unpack.NewReader(decrypted, decrypted.Size(), inputFile)
+	if err != nil {
+		return nil, err
+	}
+	inspection, err := unpack.Inspect(reader, patterns)
+	if err != nil {
+		return nil, err
+	}
+	inspection.Encrypted = decrypted.Encrypted()
+	return inspection, nil
+}
+
+// ExtractPackageEntries 从单个 wxapkg 中只写出匹配 patterns 的条目
+func ExtractPackageEntries(inputFile, appID, outputDir string, patterns []string, raw bool) ([]string, error) {
+	decrypted, err := decrypt.Open(inputFile, appID)
+	if err != nil {
+		return nil, fmt.Errorf("解密失败: %v", err)
+	}
+	defer decrypted.Close()
+
+	reader, err := unpack.NewReader(decrypted, decrypted.Size(), inputFile)
+	if err != nil {
+		return nil, err
+	}
+	options := unpack.DefaultOptions()
+	options.Raw = raw
+	return unpack.ExtractEntries(reader, outputDir, patterns, options)
+}
+
+// Inspect 列出 wxapkg 索引，或在指定 ExtractDir 时只提取选中的条目
+func Inspect(options InspectOptions) {
+	inputFiles := ParseInput(options.Input, ".wxapkg")
+	if len(inputFiles) == 0 {
+		ui.Error("未找到任何 wxapkg 文件: %s", options.Input)
+		return
+	}
+
+	if options.ExtractDir != "" {
+		extractEntries(inputFiles, options)
+		return
+	}
+
+	var inspections []*unpack.Inspection
+	for _, inputFile := range inputFiles {
+		inspection, err := InspectPackage(inputFile, options.AppID, options.Patterns)
+		if err != nil {
+			ui.Error("%s: %v", inputFile, err)
+			continue
+		}
+		inspections = append(inspections, inspection)
+	}
+
+	if strings.EqualFold(options.Format, "json") {
+		data, err := json.MarshalIndent(inspections, "", "  ")
+		if err != nil {
+			ui.Error("序列化失败: %v", err)
+			return
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	for _, inspection := range inspections {
+		printInspection(inspection, len(options.Patterns) > 0)
+	}
+}
+
+func extractEntries(inputFiles []string, options InspectOptions) {
+	total := 0
+	for _, inputFile := range inputFiles {
+		names, err := ExtractPackageEntries(inputFile, options.AppID, options.ExtractDir, options.Patterns, options.Raw)
+		if err != nil {
+			ui.Error("%s: %v", inputFile, err)
+			continue
+		}
+		total += len(names)
+		for _, name := range names {
+			ui.Info("   - %s", name)
+		}
+	}
+	ui.Success("已提取 %d 个文件到: %s", total, options.ExtractDir)
+}
+
+func printInspection(inspection *unpack.Inspection, filtered bool) {
+	ui.PrintDivider()
+	ui.Success("%s", inspection.SourcePath)
+
+	encrypted := "否"
+	if inspection.Encrypted {
+		encrypted = "是 (V1MMWX)"
+	}
+	ui.Info("   - 加密: %s | 明文大小: %s", encrypted, util.HumanReadableSize(uint64(inspection.Size)))
+	ui.Info("   - info1: %d | 索引段: %d | 数据段: %d | 文件数: %d",
+		inspection.Header.Info1,
+		inspection.Header.IndexInfoLength,
+		inspection.Header.BodyInfoLength,
+		inspection.Header.FileCount,
+	)
+	wccVersion := inspection.WccVersion
+	if wccVersion == "" {
+		wccVersion = "未知"
+	}
+	ui.Info("   - 类型: %s | 根目录: %s | wcc 版本: %s", inspection.Type, inspection.Root, wccVersion)
+	if len(inspection.Subpackages) > 0 {
+		ui.Info("   - 声明分包: %s", strings.Join(inspection.Subpackages, ", "))
+	}
+	if filtered {
+		ui.Info("   - 匹配条目: %d/%d", len(inspection.Entries), inspection.TotalEntries)
+	}
+	fmt.Println()
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "OFFSET\tSIZE\tNAME\tSUBPACKAGE")
+	for _, entry := range inspection.Entries {
+		name := entry.Name
+		// 重名或非规范路径在解包时会被改写，这里一并展示实际落盘位置
+		if entry.OutputPath != strings.TrimLeft(entry.Name, "/") {
+			name += " -> " + entry.OutputPath
+		}
+		fmt.Fprintf(writer, "%d\t%d\t%s\t%s\n", entry.Offset, entry.Size, name, entry.Subpackage)
+	}
+	writer.Flush()
+}
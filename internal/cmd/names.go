@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/25smoking/Gwxapkg/internal/locator"
+	"github.com/25smoking/Gwxapkg/internal/ui"
+)
+
+// NamesOptions names 子命令参数
+type NamesOptions struct {
+	// DB 名称库路径，为空时使用 ~/.gwxapkg/app_names.json
+	DB string
+	// Import 非空时先导入该 CSV（appid,name[,source[,fetched_at]]）
+	Import string
+	// Export 非空时把名称库导出为 CSV，可在其他机器上 -import
+	Export string
+}
+
+// ResolveNameDB 返回名称库路径，path 为空时使用默认位置
+func ResolveNameDB(path string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+	return locator.DefaultNameCachePath()
+}
+
+// Names 导入、导出或列出本地 AppID → 名称库
+func Names(options NamesOptions) {
+	path, err := ResolveNameDB(options.DB)
+	if err != nil {
+		ui.Error("%v", err)
+		return
+	}
+	cache, err := locator.LoadNameCache(path)
+	if err != nil {
+		ui.Error("%v", err)
+		return
+	}
+
+	if options.Import != "" {
+		file, err := os.Open(options.Import)
+		if err != nil {
+			ui.Error("打开 CSV 失败: %v", err)
+			return
+		}
+		count, err := cache.ImportCSV(file)
+		file.Close()
+		if err != nil {
+			ui.Error("%v", err)
+			return
+		}
+		if err := cache.Save(); err != nil {
+			ui.Error("%v", err)
+			return
+		}
+		ui.Success("已导入 %d 条名称到 %s", count, path)
+	}
+
+	if options.Export != "" {
+		if err := exportNames(cache.Entries(), options.Export); err != nil {
+			ui.Error("%v", err)
+			return
+		}
+		ui.Success("已导出 %d 条名称: %s", len(cache.Entries()), options.Export)
+	}
+
+	if options.Import != "" || options.Export != "" {
+		return
+	}
+
+	entries := cache.Entries()
+	if len(entries) == 0 {
+		ui.Warning("名称库为空: %s", path)
+		ui.Info("在线扫描查到的名称会自动写入，也可以用 names -import=<CSV> 导入")
+		return
+	}
+	ui.Success("共 %d 条名称: %s", len(entries), path)
+	fmt.Println()
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "APPID\tNAME\tSOURCE\tFETCHED AT")
+	for _, entry := range entries {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", entry.AppID, entry.Name, entry.Source, entry.FetchedAt.Format("2006-01-02 15:04:05"))
+	}
+	writer.Flush()
+}
+
+func exportNames(entries []locator.NameEntry, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("创建 CSV 失败: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Write([]string{"appid", "name", "source", "fetched_at"})
+	for _, entry := range entries {
+		writer.Write([]string{entry.AppID, entry.Name, entry.Source, entry.FetchedAt.Format(time.RFC3339)})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("写入 CSV 失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/25smoking/Gwxapkg/internal/proxy"
+	"github.com/25smoking/Gwxapkg/internal/ui"
+)
+
+// ProxyOptions proxy 子命令参数
+type ProxyOptions struct {
+	Dir    string
+	Listen string
+	CADir  string
+	// Insecure 不校验上游证书，便于对接自签名的测试后端
+	Insecure bool
+	// LogPath 非空时把每条请求以 JSON Lines 追加写入该文件
+	LogPath string
+}
+
+// Proxy 启动中间人代理，把经过的请求关联到已解包目录的源码 API 并实时输出
+func Proxy(options ProxyOptions) {
+	annotator, err := proxy.NewAnnotator(options.Dir)
+	if err != nil {
+		ui.Error("加载 API 地图失败（请先对该目录执行 semantic）: %v", err)
+		return
+	}
+	ca, err := proxy.LoadOrCreateCA(options.CADir)
+	if err != nil {
+		ui.Error("加载根证书失败: %v", err)
+		return
+	}
+
+	var logFile *os.File
+	if options.LogPath != "" {
+		logFile, err = os.OpenFile(options.LogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			ui.Error("打开日志文件失败: %v", err)
+			return
+		}
+		defer logFile.Close()
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	if options.Insecure {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	var mu sync.Mutex
+	server := &proxy.Server{
+		CA:        ca,
+		Annotator: annotator,
+		Transport: transport,
+		OnLog: func(entry proxy.Entry) {
+			mu.Lock()
+			defer mu.Unlock()
+			printProxyEntry(entry)
+			if logFile != nil {
+				if data, err := json.Marshal(entry); err == nil {
+					logFile.Write(append(data, '\n'))
+				}
+			}
+		},
+	}
+
+	ui.Success("代理已启动: http://%s", options.Listen)
+	ui.Info("根证书: %s （也可经代理访问 http://%s/%s 下载）", filepath.Join(options.CADir, proxy.CACertFileName), options.Listen, proxy.CACertFileName)
+	if err := http.ListenAndServe(options.Listen, server); err != nil {
+		ui.Error("代理退出: %v", err)
+	}
+}
+
+func printProxyEntry(entry proxy.Entry) {
+	line := entry.Method + " " + entry.URL
+	if entry.Error != "" {
+		ui.Warning("%s -> %s", line, entry.Error)
+		return
+	}
+	annotation := entry.Annotation
+	if annotation == nil {
+		ui.Info("%s [%d]", line, entry.Status)
+		return
+	}
+	ui.Success("%s [%d] -> %s %s (%s)", line, entry.Status, annotation.Function, annotation.Source(), annotation.Confidence)
+	if len(annotation.Routes) > 0 {
+		ui.Info("   页面: %s", strings.Join(annotation.Routes, ", "))
+	}
+}
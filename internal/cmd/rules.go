@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/25smoking/Gwxapkg/internal/key"
+	"github.com/25smoking/Gwxapkg/internal/ui"
+)
+
+// TestRules 校验规则包及其内嵌样例；paths 为空时校验当前生效的基础规则与 config/rules.d。
+// 全部通过时返回 true。
+func TestRules(paths []string) bool {
+	var packs []*key.Rules
+	if len(paths) == 0 {
+		loaded, err := key.LoadRuleSet()
+		if err != nil {
+			ui.Error("%v", err)
+			return false
+		}
+		packs = loaded
+	}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			ui.Error("规则包不存在: %s", path)
+			return false
+		}
+		if info.IsDir() {
+			loaded, err := key.ReadRulePacks(path)
+			if err != nil {
+				ui.Error("%v", err)
+				return false
+			}
+			if len(loaded) == 0 {
+				ui.Warning("目录下没有规则包: %s", path)
+			}
+			packs = append(packs, loaded...)
+			continue
+		}
+		pack, err := key.ReadRulePack(path)
+		if err != nil {
+			ui.Error("%v", err)
+			return false
+		}
+		packs = append(packs, pack)
+	}
+
+	passed := true
+	totalRules, totalSamples, totalFailures := 0, 0, 0
+	for _, pack := range packs {
+		result := key.ValidateRulePack(pack)
+		totalRules += result.Rules
+		totalSamples += result.Samples
+		totalFailures += len(result.Failures)
+
+		name := result.Name
+		if name == "" {
+			name = result.Source
+		}
+		if len(result.Failures) == 0 {
+			ui.Success("%s: %d 条规则，%d 个样例通过 (%s)", name, result.Rules, result.Samples, result.Source)
+			continue
+		}
+
+		passed = false
+		ui.Error("%s: %d 项失败 (%s)", name, len(result.Failures), result.Source)
+		for _, failure := range result.Failures {
+			if failure.Sample == "" {
+				ui.Info("   - [%s] %s", failure.RuleID, failure.Reason)
+			} else {
+				ui.Info("   - [%s] %s: %q", failure.RuleID, failure.Reason, failure.Sample)
+			}
+		}
+	}
+
+	ui.PrintDivider()
+	if passed {
+		ui.Success("规则校验通过: %d 个规则包，%d 条规则，%d 个样例", len(packs), totalRules, totalSamples)
+	} else {
+		ui.Error("规则校验失败: %d 项", totalFailures)
+	}
+	return passed
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/25smoking/Gwxapkg/internal/enum"
+	"github.com/25smoking/Gwxapkg/internal/locator"
+)
+
+// ScanPackage scan -json 中单个包文件的检测结果
+type ScanPackage struct {
+	Path       string          `json:"path"`
+	Size       int64           `json:"size"`
+	Type       enum.WxapkgType `json:"type,omitempty"`
+	Encrypted  bool            `json:"encrypted"`
+	WccVersion string          `json:"wcc_version,omitempty"`
+	Error      string          `json:"error,omitempty"`
+}
+
+// ScanProgram scan -json 输出的单个小程序版本；Index 与交互列表及 -select 的编号一致
+type ScanProgram struct {
+	Index int `json:"index"`
+	locator.MiniProgramInfo
+	TotalSize int64         `json:"total_size"`
+	Packages  []ScanPackage `json:"packages"`
+}
+
+// DescribePrograms 逐个解析包头与索引，补充大小、类型与加密状态；indices 为 programs 中的下标
+func DescribePrograms(programs []locator.MiniProgramInfo, indices []int) []ScanProgram {
+	result := make([]ScanProgram, 0, len(indices))
+	for _, index := range indices {
+		program := programs[index]
+		described := ScanProgram{Index: index + 1, MiniProgramInfo: program, Packages: make([]ScanPackage, 0, len(program.Files))}
+		for _, file := range program.Files {
+			pkg := describePackage(file, program.AppID)
+			described.TotalSize += pkg.Size
+			described.Packages = append(described.Packages, pkg)
+		}
+		result = append(result, described)
+	}
+	return result
+}
+
+func describePackage(file, appID string) ScanPackage {
+	pkg := ScanPackage{Path: file}
+	if info, err := os.Stat(file); err == nil {
+		pkg.Size = info.Size()
+	}
+	inspection, err := InspectPackage(file, appID, nil)
+	if err != nil {
+		pkg.Error = err.Error()
+		return pkg
+	}
+	pkg.Type = inspection.Type
+	pkg.Encrypted = inspection.Encrypted
+	pkg.WccVersion = inspection.WccVersion
+	return pkg
+}
+
+// PrintProgramsJSON 把扫描结果以 JSON 数组写到标准输出
+func PrintProgramsJSON(programs []ScanProgram) error {
+	data, err := json.MarshalIndent(programs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化失败: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
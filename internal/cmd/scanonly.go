@@ -13,11 +13,12 @@ import (
 	"github.com/25smoking/Gwxapkg/internal/packagecheck"
 	"github.com/25smoking/Gwxapkg/internal/reporter"
 	"github.com/25smoking/Gwxapkg/internal/scanner"
+	"github.com/25smoking/Gwxapkg/internal/semantic"
 	"github.com/25smoking/Gwxapkg/internal/ui"
 )
 
-// ScanOnly 对已解包目录执行独立敏感信息扫描，生成报告
-func ScanOnly(dir string, appID string, format string, outputDir string, postman bool) {
+// ScanOnly 对已解包目录执行独立敏感信息扫描，生成报告；triage 为空时所有发现都视为新发现
+func ScanOnly(dir string, appID string, format string, outputDir string, postman, openAPI bool, triage *scanner.Triage) {
 	if _, err := os.Stat(dir); err != nil {
 		ui.Error("目录不存在: %s", dir)
 		return
@@ -29,18 +30,19 @@ func ScanOnly(dir string, appID string, format string, outputDir string, postman
 	}
 
 	ui.Info("初始化扫描规则...")
-	if err := key.InitRules(); err != nil {
+	rules, err := key.LoadRules()
+	if err != nil {
 		ui.Error("初始化规则失败: %v", err)
 		return
 	}
-	key.InitCollector(appID)
+	collector := scanner.NewCollector(appID)
+	collector.SetTriage(triage)
 
 	// 遍历目录，扫描所有文本文件
 	ui.Step(1, 2, "扫描目录: %s", dir)
-	collector := key.GetCollector()
 
 	var fileCount int
-	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return nil
 		}
@@ -81,7 +83,7 @@ func ScanOnly(dir string, appID string, format string, outputDir string, postman
 			}
 		}
 
-		_ = scanner.ScanFile(relPath, content, collector)
+		_ = scanner.ScanFile(relPath, content, rules, collector)
 		return nil
 	})
 	if err != nil {
@@ -97,7 +99,6 @@ func ScanOnly(dir string, appID string, format string, outputDir string, postman
 	}
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
 		ui.Error("创建报告输出目录失败: %v", err)
-		key.ResetCollector()
 		return
 	}
 
@@ -136,14 +137,11 @@ func ScanOnly(dir string, appID string, format string, outputDir string, postman
 		}
 	}
 
-	// 生成报告（支持 json / excel / html / both）
-	format = strings.ToLower(format)
-	if format == "" {
-		format = "both"
-	}
+	// 生成报告（支持 json / excel / html / sarif / both，可逗号分隔组合）
+	formats := ParseReportFormats(format)
 
 	generated := 0
-	if format == "json" || format == "both" {
+	if formats["json"] {
 		path := filepath.Join(outputDir, "sensitive_report.json")
 		jr := reporter.NewJSONReporter()
 		if err := jr.Generate(report, path); err != nil {
@@ -153,7 +151,7 @@ func ScanOnly(dir string, appID string, format string, outputDir string, postman
 			generated++
 		}
 	}
-	if format == "excel" || format == "both" {
+	if formats["excel"] {
 		path := filepath.Join(outputDir, "sensitive_report.xlsx")
 		er := reporter.NewExcelReporter()
 		if err := er.Generate(report, path); err != nil {
@@ -163,7 +161,7 @@ func ScanOnly(dir string, appID string, format string, outputDir string, postman
 			generated++
 		}
 	}
-	if format == "html" || format == "both" {
+	if formats["html"] {
 		path := filepath.Join(outputDir, "sensitive_report.html")
 		hr := reporter.NewHTMLReporter()
 		if err := hr.Generate(report, path); err != nil {
@@ -173,6 +171,16 @@ func ScanOnly(dir string, appID string, format string, outputDir string, postman
 			generated++
 		}
 	}
+	if formats["sarif"] {
+		path := filepath.Join(outputDir, "sensitive_report.sarif")
+		sr := reporter.NewSARIFReporter()
+		if err := sr.Generate(report, path); err != nil {
+			ui.Warning("生成 SARIF 报告失败: %v", err)
+		} else {
+			ui.Success("SARIF 报告: %s", path)
+			generated++
+		}
+	}
 
 	if postman {
 		path := filepath.Join(outputDir, "api_collection.postman_collection.json")
@@ -184,6 +192,16 @@ func ScanOnly(dir string, appID string, format string, outputDir string, postman
 		}
 	}
 
+	if openAPI {
+		apiMap, _ := semantic.LoadAPIMap(dir)
+		openAPIPath := filepath.Join(outputDir, reporter.OpenAPIFileName)
+		if err := reporter.NewOpenAPIReporter().Generate(report, apiMap, openAPIPath); err != nil {
+			ui.Warning("生成 OpenAPI 文档失败: %v", err)
+		} else {
+			ui.Success("OpenAPI: %s", openAPIPath)
+		}
+	}
+
 	routeManifest, routeErr := analyzer.AnalyzeMiniProgram(dir, appID)
 	if routeErr != nil {
 		ui.Warning("生成页面与路由地图失败: %v", routeErr)
@@ -206,10 +224,8 @@ func ScanOnly(dir string, appID string, format string, outputDir string, postman
 		}
 	}
 
-	key.ResetCollector()
-
-	if generated == 0 && !postman {
-		ui.Warning("未生成任何报告，请检查 -format 参数（json/excel/html/both）")
+	if generated == 0 && !postman && !openAPI {
+		ui.Warning("未生成任何报告，请检查 -format 参数（json/excel/html/sarif/both）")
 		return
 	}
 
@@ -221,6 +237,45 @@ func ScanOnly(dir string, appID string, format string, outputDir string, postman
 	ui.Info("   - 去重后:     %d", report.Summary.UniqueMatches)
 	ui.Info("   - 高风险: %d | 中风险: %d | 低风险: %d",
 		report.Summary.HighRisk, report.Summary.MediumRisk, report.Summary.LowRisk)
+	PrintVerificationSummary(report)
+	PrintTriageSummary(report, triage)
+}
+
+// PrintVerificationSummary 输出凭据离线校验的统计，没有可校验的凭据时不输出
+func PrintVerificationSummary(report *scanner.ScanReport) {
+	summary := report.Summary
+	if summary.Verified+summary.Expired+summary.Malformed == 0 {
+		return
+	}
+	ui.Info("   - 凭据校验: 结构有效 %d | 已过期 %d | 格式错误 %d", summary.Verified, summary.Expired, summary.Malformed)
+}
+
+// PrintTriageSummary 输出新发现/已知/已忽略的数量，并提醒已过期的忽略规则
+func PrintTriageSummary(report *scanner.ScanReport, triage *scanner.Triage) {
+	if triage == nil {
+		return
+	}
+	ui.Info("   - 新发现: %d | 已知: %d | 已忽略: %d",
+		report.Summary.NewCount, report.Summary.KnownCount, report.Summary.Suppressed)
+	for _, suppression := range triage.ExpiredSuppressions() {
+		ui.Warning("忽略规则已于 %s 过期，请复核: %s", suppression.Expires, suppression.Justification)
+	}
+}
+
+// ParseReportFormats 解析 -format，支持逗号分隔组合；both 表示 json + excel + html，all 额外包含 sarif
+func ParseReportFormats(format string) map[string]bool {
+	formats := make(map[string]bool)
+	for _, name := range strings.Split(strings.ToLower(format), ",") {
+		switch name = strings.TrimSpace(name); name {
+		case "", "both":
+			formats["json"], formats["excel"], formats["html"] = true, true, true
+		case "all":
+			formats["json"], formats["excel"], formats["html"], formats["sarif"] = true, true, true, true
+		default:
+			formats[name] = true
+		}
+	}
+	return formats
 }
 
 // isTextFile 判断是否为需要扫描的文本文件
@@ -240,11 +295,18 @@ func isTextFile(ext string) bool {
 
 func shouldSkipGeneratedArtifact(relPath string) bool {
 	name := filepath.Base(relPath)
+	if strings.HasSuffix(name, ".postman_environment.json") {
+		return true
+	}
 	switch name {
 	case "sensitive_report.html",
 		"sensitive_report.xlsx",
 		"sensitive_report.json",
+		"sensitive_report.sarif",
+		"sensitive_baseline.json",
 		"api_collection.postman_collection.json",
+		"request_trace.postman_collection.json",
+		"openapi.yaml",
 		"route_manifest.json",
 		"route_map.md",
 		"route_map.mmd":
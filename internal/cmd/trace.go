@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/25smoking/Gwxapkg/internal/reporter"
+	"github.com/25smoking/Gwxapkg/internal/scanner"
+	"github.com/25smoking/Gwxapkg/internal/semantic"
+	"github.com/25smoking/Gwxapkg/internal/ui"
+	"github.com/25smoking/Gwxapkg/internal/wxenv"
+)
+
+// TracePostmanFileName 由请求追踪单独导出的 Postman Collection 文件名
+const TracePostmanFileName = "request_trace.postman_collection.json"
+
+// Trace 在 wx 桩环境中运行已解包目录的页面，写出请求追踪并并入 api_map，可选导出 Postman Collection
+func Trace(dir string, options wxenv.TraceOptions, postman bool) {
+	trace, err := wxenv.TraceApp(dir, options)
+	if err != nil {
+		ui.Error("运行页面失败: %v", err)
+		return
+	}
+
+	for _, page := range trace.Pages {
+		if page.Error != "" {
+			ui.Warning("   %s: %s", page.Route, page.Error)
+			continue
+		}
+		ui.Info("   %s: %d 个请求", page.Route, page.RequestCount)
+	}
+	for _, message := range trace.Errors {
+		ui.Warning("   异常: %s", message)
+	}
+
+	tracePath := semantic.RequestTracePath(dir)
+	if err := wxenv.WriteTrace(tracePath, trace); err != nil {
+		ui.Error("写入请求追踪失败: %v", err)
+		return
+	}
+	ui.Success("请求追踪: %s （%d 个请求，%d 次缓存写入）", tracePath, len(trace.Requests), len(trace.StorageWrites))
+
+	if report, err := semantic.ApplyRequestTrace(dir, trace); err == nil {
+		observed := 0
+		for _, endpoint := range report.Endpoints {
+			observed += len(endpoint.Observed)
+		}
+		ui.Success("已并入 API 地图: %d 个请求对应到接口函数，%d 个未归属", observed, len(report.RuntimeRequests))
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		ui.Warning("并入 API 地图失败: %v", err)
+	}
+
+	if postman {
+		report := &scanner.ScanReport{
+			AppID:        filepath.Base(filepath.Clean(dir)),
+			APIEndpoints: trace.APIEndpoints(),
+		}
+		path := filepath.Join(dir, TracePostmanFileName)
+		if err := reporter.NewPostmanReporter().Generate(report, path); err != nil {
+			ui.Warning("生成 Postman Collection 失败: %v", err)
+		} else {
+			ui.Success("Postman Collection: %s", path)
+		}
+	}
+}
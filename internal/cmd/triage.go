@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"path/filepath"
+
+	"github.com/25smoking/Gwxapkg/internal/scanner"
+	"github.com/25smoking/Gwxapkg/internal/ui"
+	"github.com/25smoking/Gwxapkg/internal/util"
+)
+
+// LoadTriage 按 -baseline / -suppress / -show-all 加载分诊配置；都未指定时返回 nil
+func LoadTriage(baselinePath, suppressionPath string, showAll bool) (*scanner.Triage, bool) {
+	if baselinePath == "" && suppressionPath == "" {
+		return nil, true
+	}
+
+	paths := []*string{&baselinePath, &suppressionPath}
+	for _, path := range paths {
+		if *path == "" {
+			continue
+		}
+		if expanded, err := util.ExpandHomePath(*path); err == nil {
+			*path = expanded
+		}
+	}
+
+	triage, err := scanner.LoadTriage(baselinePath, suppressionPath, showAll)
+	if err != nil {
+		ui.Error("%v", err)
+		return nil, false
+	}
+	if triage.Baseline != nil {
+		ui.Info("已加载基线: %s（%d 条）", baselinePath, len(triage.Baseline.Findings))
+	}
+	if len(triage.Suppressions) > 0 {
+		ui.Info("已加载忽略规则: %s（%d 条）", suppressionPath, len(triage.Suppressions))
+	}
+	return triage, true
+}
+
+// WriteBaseline 由上一次的 sensitive_report.json 生成基线文件
+func WriteBaseline(reportPath, outputPath string) {
+	baseline, err := scanner.LoadBaseline(reportPath)
+	if err != nil {
+		ui.Error("%v", err)
+		return
+	}
+	if outputPath == "" {
+		outputPath = filepath.Join(filepath.Dir(reportPath), "sensitive_baseline.json")
+	}
+	if err := baseline.Write(outputPath); err != nil {
+		ui.Error("%v", err)
+		return
+	}
+	ui.Success("基线文件: %s（%d 条发现）", outputPath, len(baseline.Findings))
+}
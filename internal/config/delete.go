@@ -14,21 +14,14 @@ type FileDeletionManager struct {
 	ctx      context.Context
 }
 
-var deleteInstance *FileDeletionManager
-var deleteOnce sync.Once
-
-// NewFileDeletionManager 创建或获取一个单例的FileDeletionManager
+// NewFileDeletionManager 创建一个新的FileDeletionManager，每次运行各自持有一个实例
 func NewFileDeletionManager() *FileDeletionManager {
-	deleteOnce.Do(func() {
-		c := context.Background()
-		ctx, cancel := context.WithCancel(c)
-		deleteInstance = &FileDeletionManager{
-			files:    make(map[string]bool),
-			cancelFn: cancel,
-			ctx:      ctx,
-		}
-	})
-	return deleteInstance
+	ctx, cancel := context.WithCancel(context.Background())
+	return &FileDeletionManager{
+		files:    make(map[string]bool),
+		cancelFn: cancel,
+		ctx:      ctx,
+	}
 }
 
 // AddFile 添加文件路径到删除列表
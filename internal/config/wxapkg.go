@@ -38,17 +38,11 @@ type WxapkgManager struct {
 	Packages map[string]*WxapkgInfo
 }
 
-var managerInstance *WxapkgManager
-var wxapkgOnce sync.Once
-
-// GetWxapkgManager 获取单例的 WxapkgManager 实例
-func GetWxapkgManager() *WxapkgManager {
-	wxapkgOnce.Do(func() {
-		managerInstance = &WxapkgManager{
-			Packages: make(map[string]*WxapkgInfo),
-		}
-	})
-	return managerInstance
+// NewWxapkgManager 创建新的 WxapkgManager，每次运行各自持有一个实例
+func NewWxapkgManager() *WxapkgManager {
+	return &WxapkgManager{
+		Packages: make(map[string]*WxapkgInfo),
+	}
 }
 
 // AddPackage 添加包信息
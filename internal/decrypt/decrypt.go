@@ -1,13 +1,11 @@
 package decrypt
 
 import (
-	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/sha1"
-	"encoding/binary"
 	"fmt"
-	"os"
+	"io"
 
 	"golang.org/x/crypto/pbkdf2"
 )
@@ -19,57 +17,19 @@ const (
 	defaultXorKey = 0x66
 )
 
+// DecryptWxapkg 解密整个 wxapkg 并返回明文；大包请优先使用 Open 按需读取。
 func DecryptWxapkg(inputFile, appID string) ([]byte, error) {
-	ciphertext, err := os.ReadFile(inputFile)
+	file, err := Open(inputFile, appID)
 	if err != nil {
-		return nil, fmt.Errorf("读取文件失败: %v", err)
+		return nil, err
 	}
+	defer file.Close()
 
-	// 先检查是否已解密
-	reader := bytes.NewReader(ciphertext)
-	var firstMark byte
-	binary.Read(reader, binary.BigEndian, &firstMark)
-	var info1, indexInfoLength, bodyInfoLength uint32
-	binary.Read(reader, binary.BigEndian, &info1)
-	binary.Read(reader, binary.BigEndian, &indexInfoLength)
-	binary.Read(reader, binary.BigEndian, &bodyInfoLength)
-	var lastMark byte
-	binary.Read(reader, binary.BigEndian, &lastMark)
-	if firstMark == 0xBE && lastMark == 0xED {
-		// 已解密直接返回
-		return ciphertext, nil
+	data := make([]byte, file.Size())
+	if _, err := io.ReadFull(io.NewSectionReader(file, 0, file.Size()), data); err != nil {
+		return nil, fmt.Errorf("读取解密内容失败: %v", err)
 	}
-
-	if string(ciphertext[:len(fileHeader)]) != fileHeader {
-		return nil, fmt.Errorf("无效的文件格式")
-	}
-
-	key := pbkdf2.Key([]byte(appID), []byte(saltStr), 1000, 32, sha1.New)
-
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return nil, fmt.Errorf("创建AES密码块失败: %v", err)
-	}
-
-	iv := []byte(ivStr)
-	mode := cipher.NewCBCDecrypter(block, iv)
-	originData := make([]byte, 1024)
-	mode.CryptBlocks(originData, ciphertext[6:1024+6])
-
-	afData := make([]byte, len(ciphertext)-1024-6)
-	var xorKey byte
-	if len(appID) >= 2 {
-		xorKey = appID[len(appID)-2]
-	} else {
-		xorKey = defaultXorKey
-	}
-	for i, b := range ciphertext[1024+6:] {
-		afData[i] = b ^ xorKey
-	}
-
-	originData = append(originData[:1023], afData...)
-
-	return originData, nil
+	return data, nil
 }
 
 // EncryptWxapkg 将明文 wxapkg 重新加密为微信客户端可识别的格式
@@ -97,14 +57,12 @@ func EncryptWxapkg(data []byte, appID string) ([]byte, error) {
 	mode := cipher.NewCBCEncrypter(block, []byte(ivStr))
 	mode.CryptBlocks(encryptedPrefix, prefixPlain)
 
-	xorKey := byte(defaultXorKey)
-	if len(appID) >= 2 {
-		xorKey = appID[len(appID)-2]
-	}
+	xorKey := xorKeyForAppID(appID)
 
+	// 明文不足 1023 字节时没有 XOR 部分，不能直接切 data[1023:]
 	tail := make([]byte, max(len(data)-1023, 0))
-	for i, b := range data[1023:] {
-		tail[i] = b ^ xorKey
+	for i := range tail {
+		tail[i] = data[1023+i] ^ xorKey
 	}
 
 	encrypted := make([]byte, 0, len(fileHeader)+len(encryptedPrefix)+len(tail))
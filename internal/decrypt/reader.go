@@ -0,0 +1,169 @@
+package decrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	aesPrefixSize   = 1024
+	plainPrefixSize = aesPrefixSize - 1
+	plainHeaderSize = 14
+)
+
+// File 以 io.ReaderAt 形式提供解密后的 wxapkg 内容。
+// 加密包只在打开时解密 AES 前缀，其余部分在读取时按需做 XOR，不会把整个包读入内存。
+type File struct {
+	file      *os.File
+	src       io.ReaderAt
+	size      int64
+	encrypted bool
+	prefix    []byte
+	xorKey    byte
+}
+
+// Open 打开 wxapkg 文件并返回可按偏移读取明文的 File，调用方负责 Close。
+func Open(inputFile, appID string) (*File, error) {
+	f, err := os.Open(inputFile)
+	if err != nil {
+		return nil, fmt.Errorf("读取文件失败: %v", err)
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("读取文件信息失败: %v", err)
+	}
+
+	decrypted, err := NewReader(f, stat.Size(), appID)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	decrypted.file = f
+	return decrypted, nil
+}
+
+// NewReader 基于任意 io.ReaderAt 构造解密读取器；明文包会原样透传。
+func NewReader(src io.ReaderAt, size int64, appID string) (*File, error) {
+	encrypted, err := IsEncrypted(src, size)
+	if err != nil {
+		return nil, err
+	}
+	if !encrypted {
+		return &File{src: src, size: size}, nil
+	}
+
+	if size < int64(len(fileHeader)+aesPrefixSize) {
+		return nil, fmt.Errorf("加密文件长度不足: %d", size)
+	}
+
+	ciphertext := make([]byte, aesPrefixSize)
+	if _, err := src.ReadAt(ciphertext, int64(len(fileHeader))); err != nil {
+		return nil, fmt.Errorf("读取加密头失败: %v", err)
+	}
+
+	key := pbkdf2.Key([]byte(appID), []byte(saltStr), 1000, 32, sha1.New)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("创建AES密码块失败: %v", err)
+	}
+
+	prefix := make([]byte, aesPrefixSize)
+	cipher.NewCBCDecrypter(block, []byte(ivStr)).CryptBlocks(prefix, ciphertext)
+
+	return &File{
+		src:       src,
+		size:      plainPrefixSize + size - int64(len(fileHeader)+aesPrefixSize),
+		encrypted: true,
+		prefix:    prefix[:plainPrefixSize],
+		xorKey:    xorKeyForAppID(appID),
+	}, nil
+}
+
+// IsEncrypted 判断包是否为 V1MMWX 加密格式；既不是明文 wxapkg 也不是加密包时返回错误。
+func IsEncrypted(src io.ReaderAt, size int64) (bool, error) {
+	header := make([]byte, plainHeaderSize)
+	n, err := src.ReadAt(header, 0)
+	if err != nil && err != io.EOF {
+		return false, fmt.Errorf("读取文件头失败: %v", err)
+	}
+	header = header[:n]
+
+	if len(header) == plainHeaderSize && header[0] == 0xBE && header[plainHeaderSize-1] == 0xED {
+		return false, nil
+	}
+	if len(header) >= len(fileHeader) && string(header[:len(fileHeader)]) == fileHeader {
+		return true, nil
+	}
+	return false, fmt.Errorf("无效的文件格式")
+}
+
+// ReadAt 实现 io.ReaderAt，可被多个 goroutine 并发调用。
+func (f *File) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("无效的读取偏移: %d", off)
+	}
+	if off >= f.size {
+		return 0, io.EOF
+	}
+	if !f.encrypted {
+		return f.src.ReadAt(p, off)
+	}
+
+	want := len(p)
+	if remaining := f.size - off; int64(want) > remaining {
+		want = int(remaining)
+	}
+
+	n := 0
+	if off < plainPrefixSize {
+		n = copy(p[:want], f.prefix[off:])
+	}
+	if n < want {
+		tailOffset := off + int64(n) - plainPrefixSize
+		m, err := f.src.ReadAt(p[n:want], int64(len(fileHeader)+aesPrefixSize)+tailOffset)
+		for i := n; i < n+m; i++ {
+			p[i] ^= f.xorKey
+		}
+		n += m
+		if err != nil && err != io.EOF {
+			return n, err
+		}
+	}
+
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Size 返回解密后明文的长度。
+func (f *File) Size() int64 {
+	return f.size
+}
+
+// Encrypted 返回源文件是否为加密包。
+func (f *File) Encrypted() bool {
+	return f.encrypted
+}
+
+// Close 关闭通过 Open 打开的底层文件。
+func (f *File) Close() error {
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Close()
+}
+
+func xorKeyForAppID(appID string) byte {
+	if len(appID) >= 2 {
+		return appID[len(appID)-2]
+	}
+	return defaultXorKey
+}
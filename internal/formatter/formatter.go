@@ -16,6 +16,16 @@ type FileFormatter interface {
 	FormatFile(input []byte, filePath string) ([]byte, *DeobfuscationResult, error)
 }
 
+// Options 单次运行的格式化配置
+type Options struct {
+	Pretty bool
+}
+
+// Configurable 可以按运行配置生成独立实例的格式化器
+type Configurable interface {
+	WithOptions(options Options) Formatter
+}
+
 // 注册所有格式化器
 var formatters = map[string]Formatter{}
 
@@ -32,3 +42,15 @@ func GetFormatter(ext string) (Formatter, error) {
 	}
 	return formatter, nil
 }
+
+// GetFormatterWithOptions 返回按 options 配置的格式化器，不支持配置的格式化器原样返回
+func GetFormatterWithOptions(ext string, options Options) (Formatter, error) {
+	formatter, err := GetFormatter(ext)
+	if err != nil {
+		return nil, err
+	}
+	if configurable, ok := formatter.(Configurable); ok {
+		return configurable.WithOptions(options), nil
+	}
+	return formatter, nil
+}
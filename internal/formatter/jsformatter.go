@@ -3,16 +3,22 @@ package formatter
 import (
 	"bytes"
 
-	. "github.com/25smoking/Gwxapkg/internal/config"
 	"github.com/ditashi/jsbeautifier-go/jsbeautifier"
 )
 
 // JSFormatter 结构体，用于格式化 JavaScript 代码
-type JSFormatter struct{}
+type JSFormatter struct {
+	pretty bool
+}
 
-// NewJSFormatter 创建一个新的 JSFormatter 实例
+// NewJSFormatter 创建一个新的 JSFormatter 实例，默认美化输出
 func NewJSFormatter() *JSFormatter {
-	return &JSFormatter{}
+	return &JSFormatter{pretty: true}
+}
+
+// WithOptions 返回按运行配置决定是否美化的新实例
+func (f *JSFormatter) WithOptions(options Options) Formatter {
+	return &JSFormatter{pretty: options.Pretty}
 }
 
 // Format 方法用于格式化 JavaScript 代码
@@ -31,15 +37,7 @@ func (f *JSFormatter) FormatFile(input []byte, filePath string) ([]byte, *Deobfu
 	}
 
 	output := bytes.TrimSpace(result.Content)
-	configManager := NewSharedConfigManager()
-	pretty := true
-	if value, ok := configManager.Get("pretty"); ok {
-		if enabled, ok := value.(bool); ok {
-			pretty = enabled
-		}
-	}
-
-	if pretty {
+	if f.pretty {
 		code := string(output)
 		beautifiedCode, beautifyErr := jsbeautifier.Beautify(&code, jsbeautifier.DefaultOptions())
 		if beautifyErr == nil {
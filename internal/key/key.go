@@ -13,10 +13,33 @@ type Rule struct {
 	Id      string `yaml:"id"`
 	Enabled bool   `yaml:"enabled"`
 	Pattern string `yaml:"pattern"`
+
+	// 以下为可选元数据，未填写时回退到 scanner/rule_meta.go 的内置映射
+	Name        string     `yaml:"name,omitempty"`
+	Category    string     `yaml:"category,omitempty"`
+	Confidence  string     `yaml:"confidence,omitempty"` // high/medium/low
+	Severity    string     `yaml:"severity,omitempty"`   // critical/high/medium/low/info，未填写 confidence 时换算
+	Description string     `yaml:"description,omitempty"`
+	Remediation string     `yaml:"remediation,omitempty"`
+	Keywords    []string   `yaml:"keywords,omitempty"`  // 行内至少包含一个关键字（忽略大小写）才执行正则
+	Allowlist   []string   `yaml:"allowlist,omitempty"` // 命中内容匹配任一正则时视为误报
+	Tests       *RuleTests `yaml:"tests,omitempty"`
+}
+
+// RuleTests 规则内嵌的测试样例，由 rules test 校验
+type RuleTests struct {
+	Positive []string `yaml:"positive,omitempty"` // 每个样例都必须命中
+	Negative []string `yaml:"negative,omitempty"` // 每个样例都不能命中
 }
 
+// Rules 一个规则包
 type Rules struct {
-	Rules []Rule `yaml:"rules"`
+	Name        string `yaml:"name,omitempty"`
+	Description string `yaml:"description,omitempty"`
+	Rules       []Rule `yaml:"rules"`
+
+	// Source 规则包来源（文件路径或内置规则），不写入 YAML
+	Source string `yaml:"-"`
 }
 
 // defaultRulesYAML 内置去重后的默认规则集。
@@ -35,6 +58,11 @@ func resolveRuleFilePath() string {
 	return filepath.Join("config", "rule.yaml")
 }
 
+// ResolveRulePackDir 额外规则包目录，其中的 *.yaml / *.yml 会在基础规则之后加载
+func ResolveRulePackDir() string {
+	return filepath.Join("config", "rules.d")
+}
+
 func ReadRuleFile() (*Rules, error) {
 	configFile := resolveRuleFilePath()
 	file, err := os.ReadFile(configFile)
@@ -45,7 +73,15 @@ func ReadRuleFile() (*Rules, error) {
 		return nil, fmt.Errorf("error reading rule file: %v", err)
 	}
 
-	return parseRules(file)
+	rules, err := parseRules(file)
+	if err != nil {
+		return nil, err
+	}
+	rules.Source = configFile
+	if rules.Name == "" {
+		rules.Name = "rule"
+	}
+	return rules, nil
 }
 
 func parseRules(data []byte) (*Rules, error) {
@@ -60,7 +96,15 @@ func loadEmbeddedRules() (*Rules, error) {
 	if len(defaultRulesYAML) == 0 {
 		return nil, fmt.Errorf("embedded default rules are empty")
 	}
-	return parseRules(defaultRulesYAML)
+	rules, err := parseRules(defaultRulesYAML)
+	if err != nil {
+		return nil, err
+	}
+	rules.Source = "内置规则"
+	if rules.Name == "" {
+		rules.Name = "default"
+	}
+	return rules, nil
 }
 
 func CreateConfigFile() error {
@@ -12,11 +12,9 @@ import (
 )
 
 var (
-	rulesInstance   *Rules
-	once            sync.Once
-	jsonMutex       sync.Mutex
-	globalCollector *scanner.DataCollector
-	collectorMutex  sync.Mutex
+	rulesInstance *Rules
+	once          sync.Once
+	jsonMutex     sync.Mutex
 )
 
 func getRulesInstance() (*Rules, error) {
@@ -85,54 +83,27 @@ func appendToJSON(ruleId, matchedContent string) error {
 	return nil
 }
 
-// InitCollector 初始化全局收集器
-func InitCollector(appID string) {
-	collectorMutex.Lock()
-	defer collectorMutex.Unlock()
-	globalCollector = scanner.NewCollector(appID)
-}
-
-// GetCollector 获取全局收集器
-func GetCollector() *scanner.DataCollector {
-	collectorMutex.Lock()
-	defer collectorMutex.Unlock()
-	return globalCollector
-}
-
-// ResetCollector 重置收集器
-func ResetCollector() {
-	collectorMutex.Lock()
-	defer collectorMutex.Unlock()
-	globalCollector = nil
-}
-
-// InitRules 初始化规则（预编译）
-func InitRules() error {
-	rules, err := ReadRuleFile()
+// LoadRules 读取并预编译规则（基础规则与 config/rules.d 下的规则包），每次运行各自持有一份结果
+func LoadRules() ([]*scanner.CompiledRule, error) {
+	packs, err := LoadRuleSet()
 	if err != nil {
-		return fmt.Errorf("读取规则文件失败: %w", err)
+		return nil, fmt.Errorf("读取规则文件失败: %w", err)
 	}
 
 	compiledRules := make([]*scanner.CompiledRule, 0)
-	for _, rule := range rules.Rules {
+	for _, rule := range MergeRules(packs) {
 		if !rule.Enabled {
 			continue
 		}
 
-		pattern, e := regexp.Compile(rule.Pattern)
+		compiled, e := CompileRule(rule)
 		if e != nil {
 			fmt.Printf("警告: 规则 %s 编译失败: %v\n", rule.Id, e)
 			continue
 		}
 
-		compiledRules = append(compiledRules, &scanner.CompiledRule{
-			ID:         rule.Id,
-			Pattern:    pattern,
-			Category:   scanner.GetCategoryKey(rule.Id),
-			Confidence: scanner.GetConfidence(rule.Id),
-		})
+		compiledRules = append(compiledRules, compiled)
 	}
 
-	scanner.CompiledRules = compiledRules
-	return nil
+	return compiledRules, nil
 }
@@ -0,0 +1,245 @@
+package key
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/25smoking/Gwxapkg/internal/scanner"
+	"gopkg.in/yaml.v3"
+)
+
+// ReadRulePacks 读取 dir 下全部规则包（按文件名排序），目录不存在时返回空
+func ReadRulePacks(dir string) ([]*Rules, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取规则包目录失败: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+
+	packs := make([]*Rules, 0, len(names))
+	for _, name := range names {
+		pack, err := ReadRulePack(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		packs = append(packs, pack)
+	}
+	return packs, nil
+}
+
+// ReadRulePack 读取单个规则包文件
+func ReadRulePack(path string) (*Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取规则包失败: %w", err)
+	}
+	pack, err := parseRulePack(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	pack.Source = path
+	if pack.Name == "" {
+		pack.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return pack, nil
+}
+
+// parseRulePack 解析规则包，省略 enabled 的规则默认启用；
+// config/rule.yaml 仍沿用 parseRules，省略 enabled 时保持禁用以兼容旧配置
+func parseRulePack(data []byte) (*Rules, error) {
+	pack, err := parseRules(data)
+	if err != nil {
+		return nil, err
+	}
+	var flags struct {
+		Rules []struct {
+			Enabled *bool `yaml:"enabled"`
+		} `yaml:"rules"`
+	}
+	if err := yaml.Unmarshal(data, &flags); err != nil {
+		return nil, fmt.Errorf("error unmarshalling rule file: %v", err)
+	}
+	for i, rule := range flags.Rules {
+		if i < len(pack.Rules) && rule.Enabled == nil {
+			pack.Rules[i].Enabled = true
+		}
+	}
+	return pack, nil
+}
+
+// LoadRuleSet 返回基础规则（config/rule.yaml 或内置规则）与 config/rules.d 下的规则包
+func LoadRuleSet() ([]*Rules, error) {
+	base, err := ReadRuleFile()
+	if err != nil {
+		return nil, err
+	}
+	packs, err := ReadRulePacks(ResolveRulePackDir())
+	if err != nil {
+		return nil, err
+	}
+	return append([]*Rules{base}, packs...), nil
+}
+
+// MergeRules 按顺序合并规则包，后加载的同 id 规则覆盖先前的定义（可用于禁用或改写内置规则）
+func MergeRules(packs []*Rules) []Rule {
+	var merged []Rule
+	index := make(map[string]int)
+	for _, pack := range packs {
+		for _, rule := range pack.Rules {
+			if i, ok := index[rule.Id]; ok {
+				merged[i] = rule
+				continue
+			}
+			index[rule.Id] = len(merged)
+			merged = append(merged, rule)
+		}
+	}
+	return merged
+}
+
+// CompileRule 编译单条规则，未填写的分类与可信度回退到内置映射
+func CompileRule(rule Rule) (*scanner.CompiledRule, error) {
+	pattern, err := regexp.Compile(rule.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("pattern 编译失败: %w", err)
+	}
+
+	confidence, err := ruleConfidence(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	compiled := &scanner.CompiledRule{
+		ID:          rule.Id,
+		Name:        strings.TrimSpace(rule.Name),
+		Pattern:     pattern,
+		Category:    strings.TrimSpace(rule.Category),
+		Confidence:  confidence,
+		Description: strings.TrimSpace(rule.Description),
+		Remediation: strings.TrimSpace(rule.Remediation),
+	}
+	if compiled.Category == "" {
+		compiled.Category = scanner.GetCategoryKey(rule.Id)
+	}
+	for _, keyword := range rule.Keywords {
+		if keyword = strings.ToLower(strings.TrimSpace(keyword)); keyword != "" {
+			compiled.Keywords = append(compiled.Keywords, keyword)
+		}
+	}
+	for _, allow := range rule.Allowlist {
+		allowPattern, err := regexp.Compile(allow)
+		if err != nil {
+			return nil, fmt.Errorf("allowlist %q 编译失败: %w", allow, err)
+		}
+		compiled.Allowlist = append(compiled.Allowlist, allowPattern)
+	}
+	return compiled, nil
+}
+
+// ruleConfidence confidence 优先，其次由 severity 换算，都未填写时使用内置映射
+func ruleConfidence(rule Rule) (string, error) {
+	switch confidence := strings.ToLower(strings.TrimSpace(rule.Confidence)); confidence {
+	case "high", "medium", "low":
+		return confidence, nil
+	case "":
+	default:
+		return "", fmt.Errorf("confidence 只能是 high/medium/low: %s", rule.Confidence)
+	}
+
+	switch severity := strings.ToLower(strings.TrimSpace(rule.Severity)); severity {
+	case "critical", "high":
+		return "high", nil
+	case "medium":
+		return "medium", nil
+	case "low", "info":
+		return "low", nil
+	case "":
+		return scanner.GetConfidence(rule.Id), nil
+	default:
+		return "", fmt.Errorf("severity 只能是 critical/high/medium/low/info: %s", rule.Severity)
+	}
+}
+
+// RuleTestFailure 规则包校验失败项
+type RuleTestFailure struct {
+	RuleID string
+	Sample string
+	Reason string
+}
+
+// RulePackResult 单个规则包的校验结果
+type RulePackResult struct {
+	Name     string
+	Source   string
+	Rules    int
+	Samples  int
+	Failures []RuleTestFailure
+}
+
+// ValidateRulePack 校验规则包：id 唯一、正则可编译、元数据合法，并用内嵌样例验证命中行为。
+// 样例按行匹配，与扫描时的行为一致。
+func ValidateRulePack(pack *Rules) RulePackResult {
+	result := RulePackResult{Name: pack.Name, Source: pack.Source, Rules: len(pack.Rules)}
+	fail := func(ruleID, sample, format string, args ...interface{}) {
+		result.Failures = append(result.Failures, RuleTestFailure{RuleID: ruleID, Sample: sample, Reason: fmt.Sprintf(format, args...)})
+	}
+
+	seen := make(map[string]struct{}, len(pack.Rules))
+	for _, rule := range pack.Rules {
+		if strings.TrimSpace(rule.Id) == "" {
+			fail("", "", "缺少 id")
+			continue
+		}
+		if _, ok := seen[rule.Id]; ok {
+			fail(rule.Id, "", "id 重复")
+		}
+		seen[rule.Id] = struct{}{}
+
+		compiled, err := CompileRule(rule)
+		if err != nil {
+			fail(rule.Id, "", "%v", err)
+			continue
+		}
+		if rule.Tests == nil {
+			continue
+		}
+		for _, sample := range rule.Tests.Positive {
+			result.Samples++
+			if !sampleMatches(compiled, sample) {
+				fail(rule.Id, sample, "正例未命中")
+			}
+		}
+		for _, sample := range rule.Tests.Negative {
+			result.Samples++
+			if sampleMatches(compiled, sample) {
+				fail(rule.Id, sample, "反例被命中")
+			}
+		}
+	}
+	return result
+}
+
+func sampleMatches(rule *scanner.CompiledRule, sample string) bool {
+	for _, line := range strings.Split(sample, "\n") {
+		if len(rule.FindAll(line)) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package key
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const samplePack = `
+name: internal-demo
+rules:
+  - id: demo_internal_token
+    name: 内部网关 Token
+    category: token
+    severity: critical
+    description: 内部网关签发的长期 Token
+    remediation: 在网关后台吊销并改为短期 Token
+    keywords: [gw_tk_]
+    allowlist: ["gw_tk_0{8,}"]
+    pattern: "gw_tk_[0-9a-f]{8,}"
+    tests:
+      positive:
+        - "headers['X-Token'] = 'gw_tk_1a2b3c4d5e'"
+      negative:
+        - "gw_tk_00000000"
+        - "token = 'abcdef123456'"
+  - id: email
+    enabled: false
+    pattern: "x"
+`
+
+func writePack(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRulePackMetadataAndMerge(t *testing.T) {
+	dir := t.TempDir()
+	writePack(t, dir, "10-demo.yaml", samplePack)
+	writePack(t, dir, "README.md", "不是规则包")
+
+	packs, err := ReadRulePacks(dir)
+	if err != nil || len(packs) != 1 {
+		t.Fatalf("读取规则包失败: %v %+v", err, packs)
+	}
+	pack := packs[0]
+	if pack.Name != "internal-demo" || !pack.Rules[0].Enabled || pack.Rules[1].Enabled {
+		t.Fatalf("规则包解析错误（省略 enabled 时应默认启用）: %+v", pack)
+	}
+
+	compiled, err := CompileRule(pack.Rules[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if compiled.Category != "token" || compiled.Confidence != "high" || compiled.Name != "内部网关 Token" || compiled.Remediation == "" {
+		t.Fatalf("元数据未生效: %+v", compiled)
+	}
+	if got := compiled.FindAll("var a = 'gw_tk_00000000', b = 'gw_tk_deadbeef01'"); len(got) != 1 || got[0] != "gw_tk_deadbeef01" {
+		t.Fatalf("白名单未生效: %v", got)
+	}
+
+	base := &Rules{Rules: []Rule{{Id: "email", Enabled: true, Pattern: "@"}, {Id: "phone_cn", Enabled: true, Pattern: "1"}}}
+	merged := MergeRules([]*Rules{base, pack})
+	if len(merged) != 3 || merged[0].Id != "email" || merged[0].Enabled {
+		t.Fatalf("后加载的同 id 规则应覆盖内置规则: %+v", merged)
+	}
+}
+
+func TestRuleFileKeepsOmittedEnabledDisabled(t *testing.T) {
+	rules, err := parseRules([]byte(samplePack))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rules.Rules[0].Enabled {
+		t.Fatalf("config/rule.yaml 中省略 enabled 的规则应保持禁用: %+v", rules.Rules[0])
+	}
+}
+
+func TestValidateRulePack(t *testing.T) {
+	dir := t.TempDir()
+	pack, err := ReadRulePack(writePack(t, dir, "demo.yaml", samplePack))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := ValidateRulePack(pack); len(result.Failures) != 0 || result.Samples != 3 {
+		t.Fatalf("样例应全部通过: %+v", result)
+	}
+
+	broken := &Rules{Rules: []Rule{
+		{Id: "a", Pattern: "foo", Tests: &RuleTests{Positive: []string{"bar"}, Negative: []string{"foo"}}},
+		{Id: "a", Pattern: "("},
+		{Id: "b", Pattern: "x", Confidence: "urgent"},
+	}}
+	result := ValidateRulePack(broken)
+	if len(result.Failures) != 5 {
+		t.Fatalf("应报告正例、反例、重复 id、正则与 confidence 错误: %+v", result.Failures)
+	}
+}
+
+func TestEmbeddedRulesPassSamples(t *testing.T) {
+	rules, err := loadEmbeddedRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := ValidateRulePack(rules); len(result.Failures) != 0 {
+		t.Fatalf("内置规则校验失败: %+v", result.Failures)
+	}
+}
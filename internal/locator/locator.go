@@ -21,17 +21,35 @@ import (
 
 // MiniProgramInfo 存储小程序的基本信息
 type MiniProgramInfo struct {
-	AppID      string
-	AppName    string
-	Version    string
-	UpdateTime time.Time
-	Path       string
-	Files      []string
+	AppID      string    `json:"appid"`
+	AppName    string    `json:"app_name"`
+	Version    string    `json:"version"`
+	UpdateTime time.Time `json:"update_time"`
+	Path       string    `json:"path"`
+	Files      []string  `json:"files"`
+	// Shared 为 true 时 Path 是多个小程序共用的包目录（Android 平铺布局），解包应使用 Files
+	Shared bool `json:"shared,omitempty"`
+}
+
+// Input 返回解包时使用的输入：独立目录返回 Path，共用目录返回逗号分隔的 Files
+func (p MiniProgramInfo) Input() string {
+	if p.Shared {
+		return strings.Join(p.Files, ",")
+	}
+	return p.Path
 }
 
 // ScanOptions 控制扫描行为。
 type ScanOptions struct {
 	Verbose bool
+	// Root 非空时不扫描本机，改为在该目录（挂载的设备镜像或拷贝出的数据目录）中查找 Linux / Android / iOS 缓存
+	Root string
+	// Offline 为 true 时不访问任何在线名称数据源，只使用包内元数据与本地名称库
+	Offline bool
+	// NameCache 本地名称库，可为空；在线查到的名称会写回其中，由调用方负责 Save
+	NameCache *NameCache
+	// NameProviders 在线名称数据源，为 nil 时使用 DefaultNameProviders
+	NameProviders []NameProvider
 }
 
 // ScanDiagnostic 表示扫描候选路径与命中情况。
@@ -50,6 +68,7 @@ type ScanReport struct {
 type basePathCandidate struct {
 	Path   string
 	Source string
+	Layout string
 }
 
 var genericAppTitles = map[string]struct{}{
@@ -115,6 +134,9 @@ var preferredSourceNameFiles = []string{
 	"manifest.js",
 }
 
+// directPackageVersion 包直接位于 AppID 目录下、没有版本子目录时使用的版本名
+const directPackageVersion = "latest"
+
 const runtimeAssignedShopNamePlaceholder = "点餐模板(商户名运行时下发)"
 
 var genericCodeNames = map[string]struct{}{
@@ -536,30 +558,6 @@ func queryMeituanMiniProgramName(appID string) string {
 	return name
 }
 
-func lookupRemoteAppName(appID string) string {
-	if appID == "" {
-		return ""
-	}
-
-	if cached, ok := preciseAppNameCache.Load(appID); ok {
-		name, _ := cached.(string)
-		return name
-	}
-
-	for _, resolver := range []func(string) string{
-		queryWeChatMiniProgramName,
-		queryMeituanMiniProgramName,
-	} {
-		if name := sanitizeDisplayName(resolver(appID)); name != "" {
-			preciseAppNameCache.Store(appID, name)
-			return name
-		}
-	}
-
-	preciseAppNameCache.Store(appID, "")
-	return ""
-}
-
 func resolvePreciseAppName(appID, name string) string {
 	if name != runtimeAssignedShopNamePlaceholder {
 		return name
@@ -627,8 +625,8 @@ func tryReadSourceAppName(appPath string) string {
 	return ""
 }
 
-// tryReadAppName 尝试读取小程序应用名称
-func tryReadAppName(appPath, appID string, files []string) string {
+// tryReadAppName 尝试读取小程序应用名称，包内取不到时交给 names 补查
+func tryReadAppName(appPath, appID string, files []string, names *nameResolver) string {
 	if name := sanitizeDisplayName(tryReadLocalAppName(appPath, appID)); name != "" {
 		return name
 	}
@@ -657,13 +655,13 @@ func tryReadAppName(appPath, appID string, files []string) string {
 	}
 
 	if needsRuntimeLookup {
-		if name := lookupRemoteAppName(appID); name != "" {
+		if name := names.lookup(appID); name != "" {
 			return name
 		}
 		return ""
 	}
 
-	return lookupRemoteAppName(appID)
+	return names.lookup(appID)
 }
 
 // extractNameFromWxapkg 尝试在内存中快速解密并提取包内应用名
@@ -774,12 +772,59 @@ func Scan() ([]MiniProgramInfo, error) {
 
 // ScanWithOptions 按指定选项执行扫描，并返回可选诊断信息。
 func ScanWithOptions(opts ScanOptions) (*ScanReport, error) {
-	homeDir, err := userHomeDirFunc()
+	homeDir, collector, err := resolveBasePathCollector(opts)
 	if err != nil {
-		return nil, fmt.Errorf("获取用户目录失败: %w", err)
+		return nil, err
+	}
+	return scanWithBasePathCollector(homeDir, opts, collector)
+}
+
+// BasePaths 返回当前存在的候选缓存目录（已去重），供 daemon 等需要监听目录变化的调用方使用
+func BasePaths(opts ScanOptions) ([]string, error) {
+	homeDir, collector, err := resolveBasePathCollector(opts)
+	if err != nil {
+		return nil, err
+	}
+	candidates, _, err := collector(homeDir)
+	if err != nil {
+		return nil, err
 	}
 
-	return scanWithBasePathCollector(homeDir, opts, scanBasePathCollector)
+	paths := make([]string, 0, len(candidates))
+	seen := make(map[string]struct{}, len(candidates))
+	for _, candidate := range candidates {
+		cleanPath := filepath.Clean(candidate.Path)
+		if candidate.Path == "" {
+			continue
+		}
+		if _, ok := seen[cleanPath]; ok {
+			continue
+		}
+		seen[cleanPath] = struct{}{}
+		if info, err := os.Stat(cleanPath); err == nil && info.IsDir() {
+			paths = append(paths, cleanPath)
+		}
+	}
+	return paths, nil
+}
+
+func resolveBasePathCollector(opts ScanOptions) (string, func(string) ([]basePathCandidate, []ScanDiagnostic, error), error) {
+	if opts.Root != "" {
+		info, err := os.Stat(opts.Root)
+		if err != nil {
+			return "", nil, fmt.Errorf("读取镜像目录失败: %w", err)
+		}
+		if !info.IsDir() {
+			return "", nil, fmt.Errorf("镜像路径不是目录: %s", opts.Root)
+		}
+		return opts.Root, collectDumpBasePathCandidates, nil
+	}
+
+	homeDir, err := userHomeDirFunc()
+	if err != nil {
+		return "", nil, fmt.Errorf("获取用户目录失败: %w", err)
+	}
+	return homeDir, scanBasePathCollector, nil
 }
 
 func scanWithBasePathCollector(homeDir string, opts ScanOptions, collector func(string) ([]basePathCandidate, []ScanDiagnostic, error)) (*ScanReport, error) {
@@ -793,6 +838,7 @@ func scanWithBasePathCollector(homeDir string, opts ScanOptions, collector func(
 		report.Diagnostics = append(report.Diagnostics, diagnostics...)
 	}
 
+	names := newNameResolver(opts)
 	seen := make(map[string]struct{}, len(candidates))
 	checkedCount := 0
 
@@ -840,7 +886,11 @@ func scanWithBasePathCollector(homeDir string, opts ScanOptions, collector func(
 			continue
 		}
 
-		programs, appCount, scanErr := scanDirectory(cleanPath)
+		scan := scanDirectory
+		if candidate.Layout == layoutFlat {
+			scan = scanFlatDirectory
+		}
+		programs, appCount, scanErr := scan(cleanPath, names)
 		if scanErr != nil {
 			if opts.Verbose {
 				report.Diagnostics = append(report.Diagnostics, ScanDiagnostic{
@@ -924,8 +974,8 @@ func buildPathStatDiagnostic(path string, err error) []ScanDiagnostic {
 	}
 }
 
-func scanDirectory(basePath string) ([]MiniProgramInfo, int, error) {
-	// 结构: base_path/{AppID}/{Version}/__APP__.wxapkg
+func scanDirectory(basePath string, names *nameResolver) ([]MiniProgramInfo, int, error) {
+	// 结构: base_path/{AppID}/{Version}/__APP__.wxapkg，iOS 缓存为 base_path/{AppID}/*.wxapkg
 
 	entries, err := os.ReadDir(basePath)
 	if err != nil {
@@ -954,6 +1004,29 @@ func scanDirectory(basePath string) ([]MiniProgramInfo, int, error) {
 
 		appHasPackage := false
 
+		var directFiles []string
+		var directTime time.Time
+		for _, verEntry := range verEntries {
+			if verEntry.IsDir() || !strings.HasSuffix(verEntry.Name(), ".wxapkg") {
+				continue
+			}
+			directFiles = append(directFiles, filepath.Join(appPath, verEntry.Name()))
+			if info, err := verEntry.Info(); err == nil && info.ModTime().After(directTime) {
+				directTime = info.ModTime()
+			}
+		}
+		if len(directFiles) > 0 {
+			appHasPackage = true
+			results = append(results, MiniProgramInfo{
+				AppID:      appID,
+				AppName:    tryReadAppName(appPath, appID, directFiles, names),
+				Version:    directPackageVersion,
+				UpdateTime: directTime,
+				Path:       appPath,
+				Files:      directFiles,
+			})
+		}
+
 		for _, verEntry := range verEntries {
 			if !verEntry.IsDir() {
 				continue
@@ -990,7 +1063,7 @@ func scanDirectory(basePath string) ([]MiniProgramInfo, int, error) {
 				appHasPackage = true
 				results = append(results, MiniProgramInfo{
 					AppID:      appID,
-					AppName:    tryReadAppName(appPath, appID, wxapkgFiles),
+					AppName:    tryReadAppName(appPath, appID, wxapkgFiles, names),
 					Version:    version,
 					UpdateTime: latestTime,
 					Path:       verPath,
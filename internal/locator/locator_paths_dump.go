@@ -0,0 +1,203 @@
+package locator
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/25smoking/Gwxapkg/internal/decrypt"
+)
+
+const (
+	// layoutAppVersion 桌面端与 iOS 布局：<base>/<AppID>/<版本>/*.wxapkg，iOS 也可能直接是 <base>/<AppID>/*.wxapkg
+	layoutAppVersion = ""
+	// layoutFlat Android 布局：<base>/ 下平铺所有小程序的包，文件名为 _<AppID 哈希>_<版本>.wxapkg
+	layoutFlat = "flat"
+)
+
+// androidDataDirPatterns Android 微信数据目录，分别对应整机镜像、多用户、直接拷贝的 /data/data 与 com.tencent.mm 目录
+var androidDataDirPatterns = []string{
+	"data/data/com.tencent.mm",
+	"data/user/*/com.tencent.mm",
+	"com.tencent.mm",
+	"",
+}
+
+// iosContainerPatterns iOS 微信应用容器：整机文件系统、备份导出的 AppDomain 目录或直接拷贝的容器
+var iosContainerPatterns = []string{
+	"private/var/mobile/Containers/Data/Application/*",
+	"var/mobile/Containers/Data/Application/*",
+	"AppDomain-com.tencent.xin",
+	"",
+}
+
+var iosPackageDirPatterns = []string{
+	"Library/WechatPrivate/*/WeApp/LocalCache/release",
+	"Library/WechatPrivate/WeApp/LocalCache/release",
+	"Documents/*/WeApp/LocalCache/release",
+}
+
+var (
+	androidPackageNamePattern = regexp.MustCompile(`^_(-?\d+)_(\d+)`)
+	packageAppIDPattern       = regexp.MustCompile(`(?i)["']?appid["']?\s*[:=]\s*["'](wx[0-9a-f]{16})["']`)
+)
+
+// collectDumpBasePathCandidates 在挂载的设备镜像或拷贝目录中查找 Linux、Android 与 iOS 微信缓存
+func collectDumpBasePathCandidates(root string) ([]basePathCandidate, []ScanDiagnostic, error) {
+	candidates := make([]basePathCandidate, 0, 8)
+	diagnostics := make([]ScanDiagnostic, 0, 32)
+	add := func(matched []basePathCandidate, matchDiagnostics []ScanDiagnostic) {
+		candidates = append(candidates, matched...)
+		diagnostics = append(diagnostics, matchDiagnostics...)
+	}
+
+	for _, prefix := range androidDataDirPatterns {
+		add(globBasePathCandidates(filepath.Join(root, prefix, "MicroMsg/*/appbrand/pkg"), "Android appbrand 包目录", layoutFlat))
+	}
+	for _, container := range iosContainerPatterns {
+		for _, packageDir := range iosPackageDirPatterns {
+			add(globBasePathCandidates(filepath.Join(root, container, packageDir), "iOS WeApp 缓存目录", layoutAppVersion))
+		}
+	}
+
+	homes, err := filepath.Glob(filepath.Join(root, "home/*"))
+	if err != nil {
+		diagnostics = append(diagnostics, ScanDiagnostic{
+			Path:   filepath.Join(root, "home/*"),
+			Status: "glob-error",
+			Detail: fmt.Sprintf("展开 Linux 用户目录失败: %v", err),
+		})
+	}
+	for _, home := range append(homes, filepath.Join(root, "root"), root) {
+		add(collectLinuxBasePathCandidates(home))
+	}
+
+	return candidates, diagnostics, nil
+}
+
+// globBasePathCandidates 展开通配路径，每个匹配项都作为一个候选目录
+func globBasePathCandidates(pattern, detail, layout string) ([]basePathCandidate, []ScanDiagnostic) {
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, []ScanDiagnostic{{
+			Path:   pattern,
+			Status: "glob-error",
+			Detail: fmt.Sprintf("%s展开失败: %v", detail, err),
+		}}
+	}
+
+	candidates := make([]basePathCandidate, 0, len(matches))
+	diagnostics := make([]ScanDiagnostic, 0, len(matches)+1)
+	diagnostics = append(diagnostics, ScanDiagnostic{
+		Path:   pattern,
+		Status: "glob",
+		Detail: fmt.Sprintf("%s展开得到 %d 个候选路径", detail, len(matches)),
+	})
+	for _, match := range matches {
+		candidates = append(candidates, basePathCandidate{Path: match, Source: detail, Layout: layout})
+		diagnostics = append(diagnostics, ScanDiagnostic{
+			Path:   match,
+			Status: "candidate",
+			Detail: fmt.Sprintf("来自 %s 展开结果", detail),
+		})
+	}
+	return candidates, diagnostics
+}
+
+// scanFlatDirectory 扫描 Android 平铺包目录，按文件名中的 AppID 哈希与版本分组；
+// AppID 优先取包内配置中的 appid，取不到时以哈希前缀代替
+func scanFlatDirectory(basePath string, names *nameResolver) ([]MiniProgramInfo, int, error) {
+	type group struct {
+		hash    string
+		version string
+		files   []string
+		latest  time.Time
+	}
+	groups := make(map[string]*group)
+
+	err := filepath.WalkDir(basePath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".wxapkg") {
+			return nil
+		}
+
+		key := strings.TrimSuffix(d.Name(), ".wxapkg")
+		hash, version := key, ""
+		if match := androidPackageNamePattern.FindStringSubmatch(d.Name()); match != nil {
+			hash, version = "_"+match[1], match[2]
+			key = hash + "_" + version
+		}
+		current, ok := groups[key]
+		if !ok {
+			current = &group{hash: hash, version: version}
+			groups[key] = current
+		}
+		current.files = append(current.files, path)
+		if info, err := d.Info(); err == nil && info.ModTime().After(current.latest) {
+			current.latest = info.ModTime()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	results := make([]MiniProgramInfo, 0, len(keys))
+	for _, key := range keys {
+		current := groups[key]
+		sort.Strings(current.files)
+		appID := detectPackageAppID(current.files)
+		if appID == "" {
+			appID = current.hash
+		}
+		results = append(results, MiniProgramInfo{
+			AppID:      appID,
+			AppName:    tryReadPackageName(appID, current.files, names),
+			Version:    current.version,
+			UpdateTime: current.latest,
+			Path:       basePath,
+			Files:      current.files,
+			Shared:     true,
+		})
+	}
+	return results, len(results), nil
+}
+
+// detectPackageAppID 在明文包中查找 appid 配置；Android 缓存的包不加密
+func detectPackageAppID(files []string) string {
+	for _, file := range files {
+		data, err := decrypt.DecryptWxapkg(file, "")
+		if err != nil {
+			continue
+		}
+		if match := packageAppIDPattern.FindSubmatch(data); match != nil {
+			return strings.ToLower(string(match[1]))
+		}
+	}
+	return ""
+}
+
+// tryReadPackageName 只从包内元数据提取名称；哈希代替的 AppID 不做远程补查
+func tryReadPackageName(appID string, files []string, names *nameResolver) string {
+	for _, file := range files {
+		if name := sanitizeDisplayName(extractNameFromWxapkg(file, appID)); name != "" && name != runtimeAssignedShopNamePlaceholder {
+			return name
+		}
+	}
+	if strings.HasPrefix(appID, "wx") {
+		return names.lookup(appID)
+	}
+	return ""
+}
@@ -0,0 +1,121 @@
+package locator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/25smoking/Gwxapkg/internal/pack"
+)
+
+func writeTestPackage(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	var entries []pack.WxapkgFile
+	var offset uint32
+	for name, content := range files {
+		entries = append(entries, pack.WxapkgFile{
+			NameLen: uint32(len(name)),
+			Name:    name,
+			Offset:  offset,
+			Size:    uint32(len(content)),
+			Data:    []byte(content),
+		})
+		offset += uint32(len(content))
+	}
+	var buffer bytes.Buffer
+	if err := pack.WriteArchive(&buffer, entries); err != nil {
+		t.Fatalf("生成测试包失败: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("创建目录失败: %v", err)
+	}
+	if err := os.WriteFile(path, buffer.Bytes(), 0644); err != nil {
+		t.Fatalf("写入测试包失败: %v", err)
+	}
+}
+
+func TestScanWithRootFindsAndroidAndIOSPackages(t *testing.T) {
+	root := t.TempDir()
+	androidDir := filepath.Join(root, "data/data/com.tencent.mm/MicroMsg/0123abcd/appbrand/pkg")
+	writeTestPackage(t, filepath.Join(androidDir, "_-1605423452_34.wxapkg"), map[string]string{
+		"/app-config.json": `{"appName":"安卓测试商城","appid":"wx0123456789abcdef"}`,
+	})
+	writeTestPackage(t, filepath.Join(androidDir, "general/_-1605423452_34_1.wxapkg"), map[string]string{
+		"/sub/page.js": `Page({})`,
+	})
+	writeTestPackage(t, filepath.Join(androidDir, "_998877_7.wxapkg"), map[string]string{
+		"/app-config.json": `{"appName":"未知AppID小程序"}`,
+	})
+	iosDir := filepath.Join(root, "AppDomain-com.tencent.xin/Library/WechatPrivate/ffee00/WeApp/LocalCache/release/wxfedcba9876543210")
+	writeTestPackage(t, filepath.Join(iosDir, "12.wxapkg"), map[string]string{
+		"/app-config.json": `{"appName":"苹果测试商城"}`,
+	})
+
+	report, err := ScanWithOptions(ScanOptions{Verbose: true, Root: root, Offline: true})
+	if err != nil {
+		t.Fatalf("ScanWithOptions 返回错误: %v", err)
+	}
+
+	programs := make(map[string]MiniProgramInfo)
+	for _, program := range report.Programs {
+		programs[program.AppID] = program
+	}
+	if len(programs) != 3 {
+		t.Fatalf("应发现 3 个小程序，实际: %#v", report.Programs)
+	}
+
+	android := programs["wx0123456789abcdef"]
+	if android.AppName != "安卓测试商城" || android.Version != "34" || len(android.Files) != 2 || !android.Shared {
+		t.Fatalf("Android 包应按哈希与版本分组并识别 AppID: %#v", android)
+	}
+	if android.Input() != android.Files[0]+","+android.Files[1] {
+		t.Fatalf("共用目录的解包输入应为文件列表: %s", android.Input())
+	}
+	if unknown := programs["_998877"]; unknown.AppName != "未知AppID小程序" || unknown.Version != "7" {
+		t.Fatalf("取不到 AppID 时应以哈希代替: %#v", unknown)
+	}
+
+	ios := programs["wxfedcba9876543210"]
+	if ios.AppName != "苹果测试商城" || ios.Version != directPackageVersion || ios.Input() != iosDir {
+		t.Fatalf("iOS 缓存应直接以 AppID 目录为输入: %#v", ios)
+	}
+
+	hits := 0
+	for _, diagnostic := range report.Diagnostics {
+		if diagnostic.Status == "hit" {
+			hits++
+		}
+	}
+	if hits != 2 {
+		t.Fatalf("应有 2 个命中目录的诊断，实际: %#v", report.Diagnostics)
+	}
+
+	if _, err := ScanWithOptions(ScanOptions{Root: filepath.Join(root, "missing")}); err == nil {
+		t.Fatalf("镜像目录不存在时应返回错误")
+	}
+}
+
+func TestCollectLinuxBasePathCandidatesIncludesWinePrefixes(t *testing.T) {
+	home := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(home, ".deepinwine/Deepin-WeChat/drive_c/users/uos"), 0755); err != nil {
+		t.Fatalf("创建目录失败: %v", err)
+	}
+
+	candidates, _ := collectLinuxBasePathCandidates(home)
+	expected := map[string]bool{
+		filepath.Join(home, ".xwechat/radium/Applet/packages"):                                                                     false,
+		filepath.Join(home, ".deepinwine/Deepin-WeChat/drive_c/users/uos/Application Data/Tencent/xwechat/radium/Applet/packages"): false,
+		filepath.Join(home, ".deepinwine/Deepin-WeChat/drive_c/users/uos/Documents/WeChat Files/Applet"):                           false,
+	}
+	for _, candidate := range candidates {
+		if _, ok := expected[candidate.Path]; ok {
+			expected[candidate.Path] = true
+		}
+	}
+	for path, found := range expected {
+		if !found {
+			t.Fatalf("缺少候选路径 %s", path)
+		}
+	}
+}
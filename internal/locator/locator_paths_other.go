@@ -1,8 +1,69 @@
 package locator
 
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// wineUserDirPatterns Wine / 深度 Wine（UOS、Deepin、星火）前缀下的 Windows 用户目录
+var wineUserDirPatterns = []string{
+	".wine/drive_c/users/*",
+	".deepinwine/*/drive_c/users/*",
+	".local/share/wineprefixes/*/drive_c/users/*",
+}
+
 func collectOtherBasePathCandidates(homeDir string) ([]basePathCandidate, []ScanDiagnostic, error) {
-	return nil, []ScanDiagnostic{{
-		Status: "unsupported",
-		Detail: "当前平台未内置微信缓存扫描候选路径",
-	}}, nil
+	candidates, diagnostics := collectLinuxBasePathCandidates(homeDir)
+	return candidates, diagnostics, nil
+}
+
+// collectLinuxBasePathCandidates 收集 Linux 原生微信（含 Flatpak）以及 Wine / UOS 版微信的缓存目录
+func collectLinuxBasePathCandidates(homeDir string) ([]basePathCandidate, []ScanDiagnostic) {
+	candidates := make([]basePathCandidate, 0, 8)
+	diagnostics := make([]ScanDiagnostic, 0, 16)
+
+	for _, xwechatDir := range []string{
+		filepath.Join(homeDir, ".xwechat"),
+		filepath.Join(homeDir, ".var/app/com.tencent.WeChat/.xwechat"),
+	} {
+		path := filepath.Join(xwechatDir, "radium/Applet/packages")
+		candidates = append(candidates, basePathCandidate{Path: path, Source: "Linux xwechat 固定路径"})
+		diagnostics = append(diagnostics, ScanDiagnostic{Path: path, Status: "candidate", Detail: "Linux xwechat 固定路径"})
+
+		matched, matchDiagnostics := globBasePathCandidates(filepath.Join(xwechatDir, "radium/users/*/applet/packages"), "Linux xwechat 用户隔离目录", layoutAppVersion)
+		candidates = append(candidates, matched...)
+		diagnostics = append(diagnostics, matchDiagnostics...)
+	}
+
+	for _, pattern := range wineUserDirPatterns {
+		pattern = filepath.Join(homeDir, pattern)
+		userDirs, err := filepath.Glob(pattern)
+		if err != nil {
+			diagnostics = append(diagnostics, ScanDiagnostic{
+				Path:   pattern,
+				Status: "glob-error",
+				Detail: fmt.Sprintf("Wine 用户目录展开失败: %v", err),
+			})
+			continue
+		}
+		diagnostics = append(diagnostics, ScanDiagnostic{
+			Path:   pattern,
+			Status: "glob",
+			Detail: fmt.Sprintf("Wine 用户目录展开得到 %d 个候选路径", len(userDirs)),
+		})
+
+		// 新版 Wine 使用 AppData/Roaming，旧版与深度 Wine 使用 Application Data
+		for _, userDir := range userDirs {
+			for _, configDir := range []string{
+				filepath.Join(userDir, "AppData/Roaming"),
+				filepath.Join(userDir, "Application Data"),
+			} {
+				matched, matchDiagnostics, _ := collectWindowsBasePathCandidates(userDir, configDir)
+				candidates = append(candidates, matched...)
+				diagnostics = append(diagnostics, matchDiagnostics...)
+			}
+		}
+	}
+
+	return candidates, diagnostics
 }
@@ -0,0 +1,264 @@
+package locator
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NameCacheFileName 本地名称库默认文件名，位于 ~/.gwxapkg 下
+const NameCacheFileName = "app_names.json"
+
+// NameSourceImport 由 CSV 导入的名称来源标记
+const NameSourceImport = "import"
+
+// NameProvider 按 AppID 查询小程序名称的在线数据源。查询会把 AppID 发送给第三方，离线模式下不会调用。
+type NameProvider interface {
+	// Source 数据源标识，写入名称库的 source 字段
+	Source() string
+	// LookupName 查不到或出错时返回空字符串
+	LookupName(appID string) string
+}
+
+type funcNameProvider struct {
+	source string
+	lookup func(string) string
+}
+
+func (p funcNameProvider) Source() string {
+	return p.source
+}
+
+func (p funcNameProvider) LookupName(appID string) string {
+	return p.lookup(appID)
+}
+
+// DefaultNameProviders 内置在线数据源：微信公众平台认证信息页与美团点餐模板接口
+func DefaultNameProviders() []NameProvider {
+	return []NameProvider{
+		funcNameProvider{source: "mp.weixin.qq.com", lookup: queryWeChatMiniProgramName},
+		funcNameProvider{source: "pos.meituan.com", lookup: queryMeituanMiniProgramName},
+	}
+}
+
+// NameEntry 名称库中的一条记录
+type NameEntry struct {
+	AppID     string    `json:"appid"`
+	Name      string    `json:"name"`
+	Source    string    `json:"source"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// NameCache 本地 AppID → 名称库。扫描时先查库再查在线数据源，在线查到的结果写回库中，
+// 之后离线扫描即可复用；可并发读写。
+type NameCache struct {
+	path    string
+	mu      sync.Mutex
+	entries map[string]NameEntry
+	dirty   bool
+}
+
+// DefaultNameCachePath 返回 ~/.gwxapkg/app_names.json
+func DefaultNameCachePath() (string, error) {
+	homeDir, err := userHomeDirFunc()
+	if err != nil {
+		return "", fmt.Errorf("获取用户目录失败: %w", err)
+	}
+	return filepath.Join(homeDir, ".gwxapkg", NameCacheFileName), nil
+}
+
+// LoadNameCache 读取名称库，文件不存在时返回空库
+func LoadNameCache(path string) (*NameCache, error) {
+	cache := &NameCache{path: path, entries: make(map[string]NameEntry)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取名称库失败: %w", err)
+	}
+
+	var entries []NameEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("解析名称库失败: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.AppID != "" && entry.Name != "" {
+			cache.entries[entry.AppID] = entry
+		}
+	}
+	return cache, nil
+}
+
+// Path 名称库文件路径
+func (c *NameCache) Path() string {
+	return c.path
+}
+
+// Lookup 查询名称库
+func (c *NameCache) Lookup(appID string) (NameEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[appID]
+	return entry, ok
+}
+
+// Put 写入或覆盖一条记录，FetchedAt 为空时取当前时间
+func (c *NameCache) Put(entry NameEntry) {
+	entry.AppID = strings.TrimSpace(entry.AppID)
+	entry.Name = sanitizeDisplayName(entry.Name)
+	if entry.AppID == "" || entry.Name == "" {
+		return
+	}
+	if entry.FetchedAt.IsZero() {
+		entry.FetchedAt = time.Now()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[entry.AppID] = entry
+	c.dirty = true
+}
+
+// Entries 按 AppID 排序返回全部记录
+func (c *NameCache) Entries() []NameEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entries := make([]NameEntry, 0, len(c.entries))
+	for _, entry := range c.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].AppID < entries[j].AppID })
+	return entries
+}
+
+// Save 有改动时写回文件
+func (c *NameCache) Save() error {
+	c.mu.Lock()
+	dirty := c.dirty
+	c.mu.Unlock()
+	if !dirty {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(c.Entries(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化名称库失败: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("创建名称库目录失败: %w", err)
+	}
+	if err := os.WriteFile(c.path, data, 0644); err != nil {
+		return fmt.Errorf("写入名称库失败: %w", err)
+	}
+
+	c.mu.Lock()
+	c.dirty = false
+	c.mu.Unlock()
+	return nil
+}
+
+// ImportCSV 导入 appid,name[,source[,fetched_at]] 格式的 CSV，首行为表头时自动跳过；
+// 未填写 source 时记为 import，fetched_at 支持 RFC 3339 与 2006-01-02。返回导入条数。
+func (c *NameCache) ImportCSV(r io.Reader) (int, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	imported := 0
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return imported, fmt.Errorf("解析 CSV 失败: %w", err)
+		}
+		if len(record) < 2 {
+			return imported, fmt.Errorf("第 %d 行至少需要 appid 与 name 两列", line)
+		}
+		appID := strings.TrimPrefix(strings.TrimSpace(record[0]), "\ufeff")
+		if line == 1 && strings.EqualFold(appID, "appid") {
+			continue
+		}
+
+		entry := NameEntry{AppID: appID, Name: record[1], Source: NameSourceImport}
+		if len(record) > 2 && strings.TrimSpace(record[2]) != "" {
+			entry.Source = strings.TrimSpace(record[2])
+		}
+		if len(record) > 3 && strings.TrimSpace(record[3]) != "" {
+			fetchedAt, err := parseFetchedAt(strings.TrimSpace(record[3]))
+			if err != nil {
+				return imported, fmt.Errorf("第 %d 行 fetched_at 无效: %w", line, err)
+			}
+			entry.FetchedAt = fetchedAt
+		}
+		if entry.AppID == "" || sanitizeDisplayName(entry.Name) == "" {
+			continue
+		}
+		c.Put(entry)
+		imported++
+	}
+	return imported, nil
+}
+
+func parseFetchedAt(value string) (time.Time, error) {
+	if fetchedAt, err := time.Parse(time.RFC3339, value); err == nil {
+		return fetchedAt, nil
+	}
+	return time.ParseInLocation("2006-01-02", value, time.Local)
+}
+
+// nameResolver 包内元数据取不到名称时的补查：先查本地名称库，非离线模式再依次询问在线数据源
+type nameResolver struct {
+	offline   bool
+	cache     *NameCache
+	providers []NameProvider
+}
+
+func newNameResolver(opts ScanOptions) *nameResolver {
+	providers := opts.NameProviders
+	if providers == nil {
+		providers = DefaultNameProviders()
+	}
+	return &nameResolver{offline: opts.Offline, cache: opts.NameCache, providers: providers}
+}
+
+func (r *nameResolver) lookup(appID string) string {
+	if r == nil || appID == "" {
+		return ""
+	}
+	if r.cache != nil {
+		if entry, ok := r.cache.Lookup(appID); ok {
+			return entry.Name
+		}
+	}
+	if r.offline {
+		return ""
+	}
+
+	if cached, ok := preciseAppNameCache.Load(appID); ok {
+		name, _ := cached.(string)
+		return name
+	}
+	for _, provider := range r.providers {
+		if name := sanitizeDisplayName(provider.LookupName(appID)); name != "" {
+			preciseAppNameCache.Store(appID, name)
+			if r.cache != nil {
+				r.cache.Put(NameEntry{AppID: appID, Name: name, Source: provider.Source()})
+			}
+			return name
+		}
+	}
+	preciseAppNameCache.Store(appID, "")
+	return ""
+}
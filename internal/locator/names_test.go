@@ -0,0 +1,87 @@
+package locator
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type countingNameProvider struct {
+	names map[string]string
+	calls int
+}
+
+func (p *countingNameProvider) Source() string {
+	return "test-provider"
+}
+
+func (p *countingNameProvider) LookupName(appID string) string {
+	p.calls++
+	return p.names[appID]
+}
+
+func TestNameCacheImportsCSVAndRoundTrips(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "names", NameCacheFileName)
+	cache, err := LoadNameCache(path)
+	if err != nil {
+		t.Fatalf("LoadNameCache 返回错误: %v", err)
+	}
+
+	csvText := "\ufeffappid,name,source,fetched_at\n" +
+		"wx00000000000000a1,测试商城,\n" +
+		"wx00000000000000a2, 点餐小程序 ,crm,2025-03-01\n" +
+		"wx00000000000000a3,\n"
+	count, err := cache.ImportCSV(strings.NewReader(csvText))
+	if err != nil || count != 2 {
+		t.Fatalf("应导入 2 条，实际 %d, err=%v", count, err)
+	}
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save 返回错误: %v", err)
+	}
+
+	reloaded, err := LoadNameCache(path)
+	if err != nil {
+		t.Fatalf("重新加载失败: %v", err)
+	}
+	first, ok := reloaded.Lookup("wx00000000000000a1")
+	if !ok || first.Name != "测试商城" || first.Source != NameSourceImport || first.FetchedAt.IsZero() {
+		t.Fatalf("导入记录不正确: %#v", first)
+	}
+	second, _ := reloaded.Lookup("wx00000000000000a2")
+	if second.Name != "点餐小程序" || second.Source != "crm" || second.FetchedAt.Format("2006-01-02") != "2025-03-01" {
+		t.Fatalf("source 与 fetched_at 应保留: %#v", second)
+	}
+
+	if _, err := cache.ImportCSV(strings.NewReader("wx00000000000000a4\n")); err == nil {
+		t.Fatalf("缺少 name 列时应返回错误")
+	}
+}
+
+func TestNameResolverHonorsOfflineAndCachesProviderResults(t *testing.T) {
+	cache, err := LoadNameCache(filepath.Join(t.TempDir(), NameCacheFileName))
+	if err != nil {
+		t.Fatalf("LoadNameCache 返回错误: %v", err)
+	}
+	cache.Put(NameEntry{AppID: "wx00000000000000b1", Name: "本地库名称", Source: NameSourceImport})
+	provider := &countingNameProvider{names: map[string]string{"wx00000000000000b2": "在线名称"}}
+
+	offline := newNameResolver(ScanOptions{Offline: true, NameCache: cache, NameProviders: []NameProvider{provider}})
+	if name := offline.lookup("wx00000000000000b1"); name != "本地库名称" {
+		t.Fatalf("离线模式应使用本地名称库: %q", name)
+	}
+	if name := offline.lookup("wx00000000000000b2"); name != "" || provider.calls != 0 {
+		t.Fatalf("离线模式不应访问在线数据源: %q, calls=%d", name, provider.calls)
+	}
+
+	online := newNameResolver(ScanOptions{NameCache: cache, NameProviders: []NameProvider{provider}})
+	if name := online.lookup("wx00000000000000b2"); name != "在线名称" || provider.calls != 1 {
+		t.Fatalf("在线模式应查询数据源: %q, calls=%d", name, provider.calls)
+	}
+	entry, ok := cache.Lookup("wx00000000000000b2")
+	if !ok || entry.Source != "test-provider" || entry.FetchedAt.IsZero() {
+		t.Fatalf("在线查到的名称应写回名称库: %#v", entry)
+	}
+	if name := online.lookup("wx00000000000000b1"); name != "本地库名称" || provider.calls != 1 {
+		t.Fatalf("名称库命中时不应再查询数据源: %q, calls=%d", name, provider.calls)
+	}
+}
@@ -0,0 +1,141 @@
+package locator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ProgramFilter scan 结果的筛选条件，零值不过滤
+type ProgramFilter struct {
+	// Name 名称或 AppID 包含该子串（不区分大小写）
+	Name string
+	// Since 只保留更新时间不早于该时刻的版本
+	Since time.Time
+	// ID 只保留 AppID 匹配该正则的小程序
+	ID *regexp.Regexp
+}
+
+// NewProgramFilter 解析命令行筛选参数。since 支持 24h / 7d 这类相对时长，
+// 以及 2006-01-02、RFC 3339 格式的时间点；now 为相对时长的基准。
+func NewProgramFilter(name, since, idPattern string, now time.Time) (ProgramFilter, error) {
+	filter := ProgramFilter{Name: strings.TrimSpace(name)}
+	if idPattern != "" {
+		pattern, err := regexp.Compile(idPattern)
+		if err != nil {
+			return filter, fmt.Errorf("-id 正则无效: %w", err)
+		}
+		filter.ID = pattern
+	}
+	if since != "" {
+		sinceTime, err := parseSince(strings.TrimSpace(since), now)
+		if err != nil {
+			return filter, err
+		}
+		filter.Since = sinceTime
+	}
+	return filter, nil
+}
+
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if count, err := strconv.Atoi(days); err == nil && count >= 0 {
+			return now.AddDate(0, 0, -count), nil
+		}
+	}
+	if duration, err := time.ParseDuration(value); err == nil {
+		return now.Add(-duration), nil
+	}
+	if sinceTime, err := time.Parse(time.RFC3339, value); err == nil {
+		return sinceTime, nil
+	}
+	if sinceTime, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return sinceTime, nil
+	}
+	return time.Time{}, fmt.Errorf("-since 无效: %s（支持 24h、7d、2006-01-02 或 RFC 3339）", value)
+}
+
+// Match 判断单个版本是否满足全部条件
+func (f ProgramFilter) Match(program MiniProgramInfo) bool {
+	if f.Name != "" {
+		needle := strings.ToLower(f.Name)
+		if !strings.Contains(strings.ToLower(program.AppName), needle) && !strings.Contains(strings.ToLower(program.AppID), needle) {
+			return false
+		}
+	}
+	if !f.Since.IsZero() && program.UpdateTime.Before(f.Since) {
+		return false
+	}
+	if f.ID != nil && !f.ID.MatchString(program.AppID) {
+		return false
+	}
+	return true
+}
+
+// FilterPrograms 返回满足条件的版本，保持原有顺序
+func FilterPrograms(programs []MiniProgramInfo, filter ProgramFilter) []MiniProgramInfo {
+	result := make([]MiniProgramInfo, 0, len(programs))
+	for _, program := range programs {
+		if filter.Match(program) {
+			result = append(result, program)
+		}
+	}
+	return result
+}
+
+// SelectPrograms 解析非交互选择：all、从 1 开始的列表编号或 AppID，可用逗号组合。
+// all 与 AppID 对同一 AppID 只取排在最前（最近更新）的版本，避免多个版本写入同一输出目录；
+// 返回 programs 中的下标，按出现顺序去重。
+func SelectPrograms(programs []MiniProgramInfo, selector string) ([]int, error) {
+	var indices []int
+	seen := make(map[int]struct{})
+	add := func(index int) {
+		if _, ok := seen[index]; !ok {
+			seen[index] = struct{}{}
+			indices = append(indices, index)
+		}
+	}
+
+	for _, item := range strings.Split(selector, ",") {
+		item = strings.TrimSpace(item)
+		switch {
+		case item == "":
+			continue
+		case strings.EqualFold(item, "all"):
+			appIDs := make(map[string]struct{})
+			for index, program := range programs {
+				if _, ok := appIDs[program.AppID]; ok {
+					continue
+				}
+				appIDs[program.AppID] = struct{}{}
+				add(index)
+			}
+		default:
+			if number, err := strconv.Atoi(item); err == nil {
+				if number < 1 || number > len(programs) {
+					return nil, fmt.Errorf("编号 %d 超出范围 1-%d", number, len(programs))
+				}
+				add(number - 1)
+				continue
+			}
+			found := false
+			for index, program := range programs {
+				if program.AppID == item {
+					add(index)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("未找到 AppID: %s", item)
+			}
+		}
+	}
+
+	if len(indices) == 0 {
+		return nil, fmt.Errorf("没有选中任何小程序")
+	}
+	return indices, nil
+}
@@ -0,0 +1,62 @@
+package locator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFilterAndSelectPrograms(t *testing.T) {
+	now := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+	programs := []MiniProgramInfo{
+		{AppID: "wx00000000000000c1", AppName: "测试商城", Version: "12", UpdateTime: now.Add(-time.Hour)},
+		{AppID: "wx00000000000000c1", AppName: "测试商城", Version: "11", UpdateTime: now.AddDate(0, 0, -3)},
+		{AppID: "wx00000000000000d2", AppName: "点餐", Version: "5", UpdateTime: now.AddDate(0, 0, -10)},
+	}
+
+	filter, err := NewProgramFilter("商城", "7d", "", now)
+	if err != nil {
+		t.Fatalf("NewProgramFilter 返回错误: %v", err)
+	}
+	if filtered := FilterPrograms(programs, filter); len(filtered) != 2 || filtered[1].Version != "11" {
+		t.Fatalf("名称与时间筛选不正确: %#v", filtered)
+	}
+	filter, _ = NewProgramFilter("", "2025-06-01", `d2$`, now)
+	if filtered := FilterPrograms(programs, filter); len(filtered) != 0 {
+		t.Fatalf("-id 与 -since 应同时生效: %#v", filtered)
+	}
+	if _, err := NewProgramFilter("", "yesterday", "", now); err == nil {
+		t.Fatalf("无效的 -since 应返回错误")
+	}
+	if _, err := NewProgramFilter("", "", "wx(", now); err == nil {
+		t.Fatalf("无效的 -id 正则应返回错误")
+	}
+
+	cases := []struct {
+		selector string
+		expected []int
+	}{
+		{"all", []int{0, 2}},
+		{"2", []int{1}},
+		{"wx00000000000000c1", []int{0}},
+		{"3, wx00000000000000c1,3", []int{2, 0}},
+	}
+	for _, tc := range cases {
+		indices, err := SelectPrograms(programs, tc.selector)
+		if err != nil {
+			t.Fatalf("%s: 返回错误 %v", tc.selector, err)
+		}
+		if len(indices) != len(tc.expected) {
+			t.Fatalf("%s: 期望 %v，实际 %v", tc.selector, tc.expected, indices)
+		}
+		for i := range indices {
+			if indices[i] != tc.expected[i] {
+				t.Fatalf("%s: 期望 %v，实际 %v", tc.selector, tc.expected, indices)
+			}
+		}
+	}
+	for _, selector := range []string{"4", "0", "wx00000000000000ff", ""} {
+		if _, err := SelectPrograms(programs, selector); err == nil {
+			t.Fatalf("%q 应返回错误", selector)
+		}
+	}
+}
@@ -17,24 +17,51 @@ const (
 	manifestFileName = "manifest.json"
 )
 
-// PackageManifest 保存原始多包结构，供后续精确回包使用
+// PackageManifest 保存原始多包结构，供后续精确回包与增量处理使用
 type PackageManifest struct {
-	Version     int               `json:"version"`
-	AppID       string            `json:"app_id"`
-	GeneratedAt string            `json:"generated_at"`
-	Packages    []ManifestPackage `json:"packages"`
+	Version     int    `json:"version"`
+	AppID       string `json:"app_id"`
+	GeneratedAt string `json:"generated_at"`
+	// Settings 影响输出内容的处理参数摘要，与本次不一致时增量处理退回全量
+	Settings string `json:"settings,omitempty"`
+	// RawRemoved 为 true 时原始文件已在还原工程结构时清理，manifest 只用于增量处理，不能据此精确回包
+	RawRemoved bool              `json:"raw_removed,omitempty"`
+	Packages   []ManifestPackage `json:"packages"`
 }
 
 // ManifestPackage 描述单个原始 wxapkg 包
 type ManifestPackage struct {
-	Name       string   `json:"name"`
-	SourceRoot string   `json:"source_root,omitempty"`
-	Files      []string `json:"files"`
+	Name       string `json:"name"`
+	SourceRoot string `json:"source_root,omitempty"`
+	// SHA256 / Size 为原始包文件（解密前）的内容哈希与大小，增量处理据此判断包是否变化
+	SHA256 string   `json:"sha256,omitempty"`
+	Size   int64    `json:"size,omitempty"`
+	Files  []string `json:"files"`
 }
 
+// Package 按名称查找包，不存在时返回 nil
+func (m *PackageManifest) Package(name string) *ManifestPackage {
+	for i := range m.Packages {
+		if m.Packages[i].Name == name {
+			return &m.Packages[i]
+		}
+	}
+	return nil
+}
+
+// WritePackageManifest 根据本次解包登记的包生成并写出 manifest
 func WritePackageManifest(outputDir string, appID string, manager *config.WxapkgManager) error {
+	manifest, err := BuildPackageManifest(appID, manager)
+	if err != nil {
+		return err
+	}
+	return SavePackageManifest(outputDir, manifest)
+}
+
+// BuildPackageManifest 根据包管理器中登记的包生成 manifest，不写盘
+func BuildPackageManifest(appID string, manager *config.WxapkgManager) (*PackageManifest, error) {
 	if manager == nil {
-		return fmt.Errorf("包管理器为空")
+		return nil, fmt.Errorf("包管理器为空")
 	}
 
 	manifest := &PackageManifest{
@@ -59,8 +86,12 @@ func WritePackageManifest(outputDir string, appID string, manager *config.Wxapkg
 			Files:      files,
 		})
 	}
+	return manifest, nil
+}
 
-	if len(manifest.Packages) == 0 {
+// SavePackageManifest 写出 .gwxapkg/manifest.json；没有任何包时不写
+func SavePackageManifest(outputDir string, manifest *PackageManifest) error {
+	if manifest == nil || len(manifest.Packages) == 0 {
 		return nil
 	}
 
@@ -67,6 +67,7 @@ type WxapkgFile struct {
 	Offset  uint32
 	Size    uint32
 	Source  string
+	Data    []byte // 不为空时直接写入，不再读取 Source
 }
 
 // 打包文件到 wxapkg 格式
@@ -173,11 +174,6 @@ func buildFilesFromRelativePaths(inputDir string, relPaths []string) ([]WxapkgFi
 }
 
 func packFiles(files []WxapkgFile, outputFile string, appID string, raw bool) (string, error) {
-	var totalSize uint32
-	for _, file := range files {
-		totalSize += file.Size
-	}
-
 	// 创建输出文件
 	outFile, err := os.Create(outputFile)
 	if err != nil {
@@ -194,14 +190,58 @@ func packFiles(files []WxapkgFile, outputFile string, appID string, raw bool) (s
 		}
 	}(outFile)
 
+	if err := WriteArchive(outFile, files); err != nil {
+		return "", err
+	}
+
+	if err := outFile.Close(); err != nil {
+		return "", fmt.Errorf("关闭输出文件失败: %w", err)
+	}
+	closed = true
+
+	if raw {
+		log.Println("警告: 当前输出为未加密 wxapkg，仅适合工具链测试，微信客户端通常无法直接打开")
+		return outputFile, nil
+	}
+
+	if appID == "" {
+		log.Println("警告: 未提供 AppID，已输出未加密 wxapkg；如需在微信客户端中使用，请追加 -id=<AppID>")
+		return outputFile, nil
+	}
+
+	rawData, err := os.ReadFile(outputFile)
+	if err != nil {
+		return "", fmt.Errorf("读取未加密包失败: %w", err)
+	}
+
+	encryptedData, err := decrypt.EncryptWxapkg(rawData, appID)
+	if err != nil {
+		return "", fmt.Errorf("加密 wxapkg 失败: %w", err)
+	}
+
+	if err := os.WriteFile(outputFile, encryptedData, 0644); err != nil {
+		return "", fmt.Errorf("写入加密包失败: %w", err)
+	}
+
+	return outputFile, nil
+}
+
+// WriteArchive 按 wxapkg 明文格式写出文件头、索引段和数据段。
+// files 的 Offset 为相对数据段起点的偏移，写入索引时会自动加上头部与索引长度。
+func WriteArchive(w io.Writer, files []WxapkgFile) error {
+	var totalSize uint32
+	for _, file := range files {
+		totalSize += file.Size
+	}
+
 	// 写入文件头
-	if err := binary.Write(outFile, binary.BigEndian, byte(0xBE)); err != nil {
-		return "", fmt.Errorf("写入文件头标记失败: %w", err)
+	if err := binary.Write(w, binary.BigEndian, byte(0xBE)); err != nil {
+		return fmt.Errorf("写入文件头标记失败: %w", err)
 	}
 
 	info1 := uint32(0) // 示例值
-	if err := binary.Write(outFile, binary.BigEndian, info1); err != nil {
-		return "", fmt.Errorf("写入 info1 失败: %w", err)
+	if err := binary.Write(w, binary.BigEndian, info1); err != nil {
+		return fmt.Errorf("写入 info1 失败: %w", err)
 	}
 
 	// 计算索引段长度，包含每个文件的元数据长度和文件名长度
@@ -210,93 +250,72 @@ func packFiles(files []WxapkgFile, outputFile string, appID string, raw bool) (s
 		indexInfoLength += 4 + uint32(len(file.Name)) + 4 + 4 // NameLen + Name + Offset + Size
 	}
 
-	if err := binary.Write(outFile, binary.BigEndian, indexInfoLength); err != nil {
-		return "", fmt.Errorf("写入索引段长度失败: %w", err)
+	if err := binary.Write(w, binary.BigEndian, indexInfoLength); err != nil {
+		return fmt.Errorf("写入索引段长度失败: %w", err)
 	}
 
 	bodyInfoLength := totalSize
-	if err := binary.Write(outFile, binary.BigEndian, bodyInfoLength); err != nil {
-		return "", fmt.Errorf("写入数据段长度失败: %w", err)
+	if err := binary.Write(w, binary.BigEndian, bodyInfoLength); err != nil {
+		return fmt.Errorf("写入数据段长度失败: %w", err)
 	}
 
-	if err := binary.Write(outFile, binary.BigEndian, byte(0xED)); err != nil {
-		return "", fmt.Errorf("写入文件尾标记失败: %w", err)
+	if err := binary.Write(w, binary.BigEndian, byte(0xED)); err != nil {
+		return fmt.Errorf("写入文件尾标记失败: %w", err)
 	}
 
 	// 写入文件数量
 	fileCount := uint32(len(files))
-	if err := binary.Write(outFile, binary.BigEndian, fileCount); err != nil {
-		return "", fmt.Errorf("写入文件数量失败: %w", err)
+	if err := binary.Write(w, binary.BigEndian, fileCount); err != nil {
+		return fmt.Errorf("写入文件数量失败: %w", err)
 	}
 
 	// 写入索引段
 	for _, file := range files {
-		if err := binary.Write(outFile, binary.BigEndian, file.NameLen); err != nil {
-			return "", fmt.Errorf("写入文件名长度失败: %w", err)
+		if err := binary.Write(w, binary.BigEndian, file.NameLen); err != nil {
+			return fmt.Errorf("写入文件名长度失败: %w", err)
 		}
-		if _, err := outFile.Write([]byte(file.Name)); err != nil {
-			return "", fmt.Errorf("写入文件名失败: %w", err)
+		if _, err := w.Write([]byte(file.Name)); err != nil {
+			return fmt.Errorf("写入文件名失败: %w", err)
 		}
 		// 加上 18 字节文件头长度和索引段长度
-		if err := binary.Write(outFile, binary.BigEndian, file.Offset+indexInfoLength+18); err != nil {
-			return "", fmt.Errorf("写入文件偏移量失败: %w", err)
+		if err := binary.Write(w, binary.BigEndian, file.Offset+indexInfoLength+18); err != nil {
+			return fmt.Errorf("写入文件偏移量失败: %w", err)
 		}
-		if err := binary.Write(outFile, binary.BigEndian, file.Size); err != nil {
-			return "", fmt.Errorf("写入文件大小失败: %w", err)
+		if err := binary.Write(w, binary.BigEndian, file.Size); err != nil {
+			return fmt.Errorf("写入文件大小失败: %w", err)
 		}
 	}
 
 	// 写入数据段
 	for _, file := range files {
-		func() {
-			f, err := os.Open(file.Source)
-			if err != nil {
-				log.Printf("打开文件失败: %v\n", err)
-				return
+		if file.Data != nil {
+			if _, err := w.Write(file.Data); err != nil {
+				return fmt.Errorf("写入文件内容失败 %s: %w", file.Name, err)
 			}
-			defer func(f *os.File) {
-				err := f.Close()
-				if err != nil {
-					log.Printf("关闭文件失败: %v\n", err)
-				}
-			}(f)
-
-			if _, err = io.Copy(outFile, f); err != nil {
-				log.Printf("写入文件内容失败: %v\n", err)
-			}
-		}()
-	}
-
-	if err := outFile.Close(); err != nil {
-		return "", fmt.Errorf("关闭输出文件失败: %w", err)
-	}
-	closed = true
-
-	if raw {
-		log.Println("警告: 当前输出为未加密 wxapkg，仅适合工具链测试，微信客户端通常无法直接打开")
-		return outputFile, nil
-	}
-
-	if appID == "" {
-		log.Println("警告: 未提供 AppID，已输出未加密 wxapkg；如需在微信客户端中使用，请追加 -id=<AppID>")
-		return outputFile, nil
+			continue
+		}
+		if err := copySourceFile(w, file.Source); err != nil {
+			return fmt.Errorf("写入文件内容失败 %s: %w", file.Name, err)
+		}
 	}
 
-	rawData, err := os.ReadFile(outputFile)
-	if err != nil {
-		return "", fmt.Errorf("读取未加密包失败: %w", err)
-	}
+	return nil
+}
 
-	encryptedData, err := decrypt.EncryptWxapkg(rawData, appID)
+func copySourceFile(w io.Writer, source string) error {
+	f, err := os.Open(source)
 	if err != nil {
-		return "", fmt.Errorf("加密 wxapkg 失败: %w", err)
-	}
-
-	if err := os.WriteFile(outputFile, encryptedData, 0644); err != nil {
-		return "", fmt.Errorf("写入加密包失败: %w", err)
+		return err
 	}
+	defer func(f *os.File) {
+		err := f.Close()
+		if err != nil {
+			log.Printf("关闭文件失败: %v\n", err)
+		}
+	}(f)
 
-	return outputFile, nil
+	_, err = io.Copy(w, f)
+	return err
 }
 
 func repackWithManifest(inputDir string, outputPath string, appID string, raw bool) (bool, error) {
@@ -308,7 +327,8 @@ func repackWithManifest(inputDir string, outputPath string, appID string, raw bo
 		return false, err
 	}
 
-	if len(manifest.Packages) == 0 {
+	// 原始文件已在还原时清理的 manifest 只记录增量处理状态，按普通目录打包
+	if len(manifest.Packages) == 0 || manifest.RawRemoved {
 		return false, nil
 	}
 
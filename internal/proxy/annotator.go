@@ -0,0 +1,136 @@
+package proxy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/25smoking/Gwxapkg/internal/analyzer"
+	"github.com/25smoking/Gwxapkg/internal/semantic"
+)
+
+// Annotation 是一个请求关联到的源码位置
+type Annotation struct {
+	Function   string   `json:"function"`
+	File       string   `json:"file"`
+	Line       int      `json:"line,omitempty"`
+	Controller string   `json:"controller,omitempty"`
+	Method     string   `json:"method,omitempty"`
+	Confidence string   `json:"confidence"`
+	CallSites  []string `json:"call_sites,omitempty"`
+	Routes     []string `json:"routes,omitempty"`
+}
+
+// Source 返回 file:line 形式的源码位置
+func (a *Annotation) Source() string {
+	if a.Line > 0 {
+		return fmt.Sprintf("%s:%d", a.File, a.Line)
+	}
+	return a.File
+}
+
+// Annotator 基于已解包输出目录的 api_map 与 route_manifest 关联请求。
+type Annotator struct {
+	linker *semantic.APILinker
+	// routesByFile 记录 JS 文件被哪些页面直接或间接引用
+	routesByFile map[string][]string
+}
+
+// NewAnnotator 读取 outputDir 下的 .gwxapkg/api_map.json 与 route_manifest.json；
+// route_manifest.json 缺失时不输出页面路由
+func NewAnnotator(outputDir string) (*Annotator, error) {
+	linker, err := semantic.NewAPILinker(outputDir)
+	if err != nil {
+		return nil, err
+	}
+	annotator := &Annotator{linker: linker, routesByFile: make(map[string][]string)}
+
+	data, err := os.ReadFile(filepath.Join(outputDir, "route_manifest.json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return annotator, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取 route_manifest.json 失败: %w", err)
+	}
+	var manifest analyzer.RouteManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("解析 route_manifest.json 失败: %w", err)
+	}
+	for _, page := range manifest.Pages {
+		files := append([]string{page.Files.JS}, page.Dependencies...)
+		for _, file := range files {
+			if file == "" {
+				continue
+			}
+			file = filepath.ToSlash(file)
+			annotator.routesByFile[file] = append(annotator.routesByFile[file], page.Route)
+		}
+	}
+	return annotator, nil
+}
+
+// Annotate 返回请求得分最高的 high / medium 候选；无法关联时返回 nil
+func (a *Annotator) Annotate(request *http.Request, body []byte) *Annotation {
+	headers := make([][2]string, 0, len(request.Header)+1)
+	headers = append(headers, [2]string{"Host", request.Host})
+	for name, values := range request.Header {
+		for _, value := range values {
+			headers = append(headers, [2]string{name, value})
+		}
+	}
+	parsed := semantic.ParseBurpRequest(rawRequest(request.Method, request.URL.RequestURI(), headers, body))
+
+	matches := a.linker.Match(parsed)
+	if len(matches) == 0 || matches[0].Confidence == semantic.ASTConfidenceLow {
+		return nil
+	}
+	best := matches[0]
+	annotation := &Annotation{
+		Function:   best.FunctionName,
+		File:       best.FilePath,
+		Line:       best.LineNumber,
+		Controller: best.ControllerName,
+		Method:     best.MethodsName,
+		Confidence: best.Confidence,
+		CallSites:  best.CallSites,
+	}
+
+	routes := append([]string(nil), a.routesByFile[best.FilePath]...)
+	for _, site := range best.CallSites {
+		file, _, _ := strings.Cut(site, ":")
+		routes = append(routes, a.routesByFile[file]...)
+	}
+	annotation.Routes = uniqueSorted(routes)
+	return annotation
+}
+
+func rawRequest(method, target string, headers [][2]string, body []byte) string {
+	var builder strings.Builder
+	builder.WriteString(method + " " + target + " HTTP/1.1\n")
+	for _, header := range headers {
+		builder.WriteString(header[0] + ": " + header[1] + "\n")
+	}
+	builder.WriteString("\n")
+	builder.Write(body)
+	return builder.String()
+}
+
+func uniqueSorted(values []string) []string {
+	seen := make(map[string]struct{}, len(values))
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if _, ok := seen[value]; ok {
+			continue
+		}
+		seen[value] = struct{}{}
+		result = append(result, value)
+	}
+	sort.Strings(result)
+	return result
+}
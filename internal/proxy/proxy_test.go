@@ -0,0 +1,135 @@
+package proxy
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/25smoking/Gwxapkg/internal/analyzer"
+	"github.com/25smoking/Gwxapkg/internal/semantic"
+)
+
+func TestProxyAnnotatesResponsesWithSourceLocation(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "api", "cert.js"), `var request=require("../utils/request.js");
+
+exports.getECert=function(params){var requestData={userId:params.userId,controllerName:"CerInfo",methodsName:"GetECert"};return request.request({url:"",method:"POST",data:requestData})};`)
+	writeFile(t, filepath.Join(root, "utils", "request.js"), `exports.request=function(options){return wx.request(options)};`)
+	if _, err := semantic.BuildAPIMap(root, []string{"api/cert.js", "utils/request.js"}); err != nil {
+		t.Fatalf("BuildAPIMap 返回错误: %v", err)
+	}
+	manifest, _ := json.Marshal(analyzer.RouteManifest{Pages: []analyzer.PageNode{
+		{Route: "pages/cert/cert", Files: analyzer.PageFiles{JS: "pages/cert/cert.js"}, Dependencies: []string{"api/cert.js"}},
+	}})
+	writeFile(t, filepath.Join(root, "route_manifest.json"), string(manifest))
+
+	backend := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write([]byte(r.Method + " " + r.URL.Path + " " + string(body)))
+	}))
+	defer backend.Close()
+	plain := httptest.NewServer(backend.Config.Handler)
+	defer plain.Close()
+
+	ca, err := LoadOrCreateCA(filepath.Join(t.TempDir(), "ca"))
+	if err != nil {
+		t.Fatalf("LoadOrCreateCA 返回错误: %v", err)
+	}
+	annotator, err := NewAnnotator(root)
+	if err != nil {
+		t.Fatalf("NewAnnotator 返回错误: %v", err)
+	}
+	var entries []Entry
+	logged := make(chan Entry, 4)
+	proxy := httptest.NewServer(&Server{
+		CA:        ca,
+		Annotator: annotator,
+		Transport: backend.Client().Transport,
+		OnLog:     func(entry Entry) { logged <- entry },
+	})
+	defer proxy.Close()
+
+	proxyURL, _ := url.Parse(proxy.URL)
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Cert)
+	client := &http.Client{Transport: &http.Transport{
+		Proxy:           http.ProxyURL(proxyURL),
+		TLSClientConfig: &tls.Config{RootCAs: roots},
+	}}
+
+	payload := `{"controllerName":"CerInfo","methodsName":"GetECert","userId":"1"}`
+	for _, target := range []string{backend.URL, plain.URL} {
+		response, err := client.Post(target+"/gateway", "application/json", strings.NewReader(payload))
+		if err != nil {
+			t.Fatalf("通过代理请求 %s 失败: %v", target, err)
+		}
+		body, _ := io.ReadAll(response.Body)
+		response.Body.Close()
+		if string(body) != "POST /gateway "+payload {
+			t.Fatalf("上游收到的请求不正确: %s", body)
+		}
+		if got := response.Header.Get(HeaderFunction); got != "getECert" {
+			t.Fatalf("%s 应为 getECert，实际 %q", HeaderFunction, got)
+		}
+		if got := response.Header.Get(HeaderSource); got != "api/cert.js:3" {
+			t.Fatalf("%s 应为 api/cert.js:3，实际 %q", HeaderSource, got)
+		}
+		if got := response.Header.Get(HeaderRoute); got != "pages/cert/cert" {
+			t.Fatalf("%s 应为 pages/cert/cert，实际 %q", HeaderRoute, got)
+		}
+		entries = append(entries, <-logged)
+	}
+
+	response, err := client.Get(backend.URL + "/banner")
+	if err != nil {
+		t.Fatalf("通过代理请求失败: %v", err)
+	}
+	response.Body.Close()
+	if response.Header.Get(HeaderFunction) != "" || (<-logged).Annotation != nil {
+		t.Fatalf("无法关联的请求不应带注解")
+	}
+	if len(entries) != 2 || entries[0].Annotation == nil || entries[0].Status != http.StatusOK {
+		t.Fatalf("实时日志不正确: %#v", entries)
+	}
+}
+
+func TestLoadOrCreateCAReusesExistingCertificate(t *testing.T) {
+	dir := t.TempDir()
+	first, err := LoadOrCreateCA(dir)
+	if err != nil {
+		t.Fatalf("LoadOrCreateCA 返回错误: %v", err)
+	}
+	second, err := LoadOrCreateCA(dir)
+	if err != nil {
+		t.Fatalf("LoadOrCreateCA 返回错误: %v", err)
+	}
+	if !first.Cert.Equal(second.Cert) {
+		t.Fatalf("已有根证书应被复用")
+	}
+	leaf, err := second.CertFor("127.0.0.1")
+	if err != nil {
+		t.Fatalf("CertFor 返回错误: %v", err)
+	}
+	parsed, _ := x509.ParseCertificate(leaf.Certificate[0])
+	if len(parsed.IPAddresses) != 1 || parsed.CheckSignatureFrom(first.Cert) != nil {
+		t.Fatalf("叶子证书应包含 IP 并由根证书签发: %#v", parsed.IPAddresses)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,224 @@
+package proxy
+
+import (
+	"bytes"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 代理写入响应的注解头
+const (
+	HeaderFunction   = "X-Gwxapkg-Function"
+	HeaderSource     = "X-Gwxapkg-Source"
+	HeaderRoute      = "X-Gwxapkg-Route"
+	HeaderConfidence = "X-Gwxapkg-Confidence"
+)
+
+// maxAnnotateBody 超过该大小的请求体只转发、不参与关联
+const maxAnnotateBody = 4 << 20
+
+// hopHeaders 是逐跳首部，转发时需要移除
+var hopHeaders = []string{
+	"Connection", "Proxy-Connection", "Keep-Alive", "Proxy-Authenticate",
+	"Proxy-Authorization", "Te", "Trailer", "Transfer-Encoding", "Upgrade",
+}
+
+// Entry 是一条实时日志
+type Entry struct {
+	Time       time.Time   `json:"time"`
+	Method     string      `json:"method"`
+	URL        string      `json:"url"`
+	Status     int         `json:"status"`
+	Error      string      `json:"error,omitempty"`
+	Annotation *Annotation `json:"annotation,omitempty"`
+}
+
+// Server 是 HTTP(S) 中间人代理：CONNECT 隧道用 CA 签发的证书解密，
+// 转发请求后在响应中附加源码位置注解。
+type Server struct {
+	CA        *CA
+	Annotator *Annotator
+	// Transport 转发到上游使用的 RoundTripper，为空时使用 http.DefaultTransport
+	Transport http.RoundTripper
+	// OnLog 每个请求完成后回调，可为空
+	OnLog func(Entry)
+}
+
+// ServeHTTP 实现 http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodConnect:
+		s.handleConnect(w, r)
+	case r.URL.IsAbs():
+		s.forward(w, r, r.URL.Scheme)
+	case r.URL.Path == "/"+CACertFileName:
+		w.Header().Set("Content-Type", "application/x-x509-ca-cert")
+		w.Write(s.CA.CertPEM)
+	default:
+		http.Error(w, "Gwxapkg proxy: 请将本地址设置为 HTTP 代理，访问 /"+CACertFileName+" 下载根证书", http.StatusBadRequest)
+	}
+}
+
+func (s *Server) handleConnect(w http.ResponseWriter, r *http.Request) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "不支持 CONNECT", http.StatusInternalServerError)
+		return
+	}
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		return
+	}
+	if _, err := conn.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n")); err != nil {
+		conn.Close()
+		return
+	}
+
+	host := r.Host
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		host = name
+	}
+	tlsConn := tls.Server(conn, &tls.Config{
+		NextProtos: []string{"http/1.1"},
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			if hello.ServerName != "" {
+				return s.CA.CertFor(hello.ServerName)
+			}
+			return s.CA.CertFor(host)
+		},
+	})
+
+	authority := r.Host
+	handler := http.HandlerFunc(func(w http.ResponseWriter, inner *http.Request) {
+		inner.URL.Scheme = "https"
+		inner.URL.Host = authority
+		s.forward(w, inner, "https")
+	})
+	listener := newConnListener(tlsConn)
+	(&http.Server{Handler: handler, ReadHeaderTimeout: 30 * time.Second}).Serve(listener)
+}
+
+func (s *Server) forward(w http.ResponseWriter, r *http.Request, scheme string) {
+	entry := Entry{Time: time.Now(), Method: r.Method, URL: r.URL.String()}
+	defer func() {
+		if s.OnLog != nil {
+			s.OnLog(entry)
+		}
+	}()
+
+	var body []byte
+	if r.Body != nil {
+		data, err := io.ReadAll(io.LimitReader(r.Body, maxAnnotateBody+1))
+		if err != nil {
+			entry.Error = err.Error()
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		body = data
+	}
+
+	outbound := r.Clone(r.Context())
+	outbound.RequestURI = ""
+	outbound.URL.Scheme = scheme
+	if outbound.URL.Host == "" {
+		outbound.URL.Host = r.Host
+	}
+	outbound.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+	removeHopHeaders(outbound.Header)
+
+	if s.Annotator != nil && len(body) <= maxAnnotateBody {
+		entry.Annotation = s.Annotator.Annotate(r, body)
+	}
+
+	transport := s.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	response, err := transport.RoundTrip(outbound)
+	if err != nil {
+		entry.Error = err.Error()
+		entry.Status = http.StatusBadGateway
+		http.Error(w, "上游请求失败: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer response.Body.Close()
+
+	removeHopHeaders(response.Header)
+	for name, values := range response.Header {
+		for _, value := range values {
+			w.Header().Add(name, value)
+		}
+	}
+	if annotation := entry.Annotation; annotation != nil {
+		w.Header().Set(HeaderFunction, annotation.Function)
+		w.Header().Set(HeaderSource, annotation.Source())
+		w.Header().Set(HeaderConfidence, annotation.Confidence)
+		if len(annotation.Routes) > 0 {
+			w.Header().Set(HeaderRoute, strings.Join(annotation.Routes, ", "))
+		}
+	}
+	entry.Status = response.StatusCode
+	w.WriteHeader(response.StatusCode)
+	io.Copy(w, response.Body)
+}
+
+func removeHopHeaders(header http.Header) {
+	for _, name := range strings.Split(header.Get("Connection"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			header.Del(name)
+		}
+	}
+	for _, name := range hopHeaders {
+		header.Del(name)
+	}
+}
+
+// connListener 把单个已建立的连接包装成 net.Listener，供 http.Server 处理隧道内的请求
+type connListener struct {
+	conn   net.Conn
+	once   sync.Once
+	closed chan struct{}
+}
+
+func newConnListener(conn net.Conn) *connListener {
+	return &connListener{conn: &notifyConn{Conn: conn}, closed: make(chan struct{})}
+}
+
+func (l *connListener) Accept() (net.Conn, error) {
+	var conn net.Conn
+	l.once.Do(func() {
+		conn = l.conn
+		l.conn.(*notifyConn).onClose = func() { close(l.closed) }
+	})
+	if conn != nil {
+		return conn, nil
+	}
+	<-l.closed
+	return nil, net.ErrClosed
+}
+
+func (l *connListener) Close() error { return nil }
+
+func (l *connListener) Addr() net.Addr { return l.conn.LocalAddr() }
+
+// notifyConn 在连接关闭时通知 connListener 结束 Accept
+type notifyConn struct {
+	net.Conn
+	once    sync.Once
+	onClose func()
+}
+
+func (c *notifyConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(func() {
+		if c.onClose != nil {
+			c.onClose()
+		}
+	})
+	return err
+}
@@ -0,0 +1,297 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/25smoking/Gwxapkg/internal/versiondiff"
+)
+
+type DiffArtifacts struct {
+	JSONPath     string
+	MarkdownPath string
+	HTMLPath     string
+}
+
+// DiffReporter 负责输出两个版本之间的差异报告。
+type DiffReporter struct{}
+
+func NewDiffReporter() *DiffReporter {
+	return &DiffReporter{}
+}
+
+func (r *DiffReporter) Generate(report *versiondiff.Report, outputDir string) (*DiffArtifacts, error) {
+	if report == nil {
+		return nil, fmt.Errorf("diff 报告不能为空")
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("创建输出目录失败: %w", err)
+	}
+
+	artifacts := &DiffArtifacts{
+		JSONPath:     filepath.Join(outputDir, "version_diff.json"),
+		MarkdownPath: filepath.Join(outputDir, "version_diff.md"),
+		HTMLPath:     filepath.Join(outputDir, "version_diff.html"),
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("序列化 diff 报告失败: %w", err)
+	}
+	if err := os.WriteFile(artifacts.JSONPath, data, 0644); err != nil {
+		return nil, fmt.Errorf("写入 diff JSON 失败: %w", err)
+	}
+
+	if err := os.WriteFile(artifacts.MarkdownPath, []byte(buildDiffMarkdown(report)), 0644); err != nil {
+		return nil, fmt.Errorf("写入 diff markdown 失败: %w", err)
+	}
+
+	tmpl, err := template.New("diff").Funcs(template.FuncMap{
+		"join": strings.Join,
+	}).Parse(diffHTMLTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("解析模板失败: %w", err)
+	}
+	f, err := os.Create(artifacts.HTMLPath)
+	if err != nil {
+		return nil, fmt.Errorf("创建文件失败: %w", err)
+	}
+	defer f.Close()
+	if err := tmpl.Execute(f, report); err != nil {
+		return nil, fmt.Errorf("渲染模板失败: %w", err)
+	}
+
+	return artifacts, nil
+}
+
+func buildDiffMarkdown(report *versiondiff.Report) string {
+	var builder strings.Builder
+	summary := report.Summary
+
+	builder.WriteString("# 版本差异报告\n\n")
+	if report.AppID != "" {
+		builder.WriteString(fmt.Sprintf("- AppID: `%s`\n", report.AppID))
+	}
+	builder.WriteString(fmt.Sprintf("- 旧版本: `%s` (`%s`)\n", report.Old.Label, report.Old.Dir))
+	builder.WriteString(fmt.Sprintf("- 新版本: `%s` (`%s`)\n", report.New.Label, report.New.Dir))
+	builder.WriteString(fmt.Sprintf("- 生成时间: `%s`\n", report.GeneratedAt))
+	for _, note := range report.Notes {
+		builder.WriteString(fmt.Sprintf("- 注意: %s\n", note))
+	}
+
+	builder.WriteString("\n## 摘要\n\n")
+	builder.WriteString("| 项目 | 新增 | 删除 | 变更 |\n")
+	builder.WriteString("|------|------|------|------|\n")
+	builder.WriteString(fmt.Sprintf("| 页面 | %d | %d | %d |\n", summary.AddedPages, summary.RemovedPages, summary.ChangedPages))
+	builder.WriteString(fmt.Sprintf("| 接口 | %d | %d | %d |\n", summary.AddedEndpoints, summary.RemovedEndpoints, summary.ChangedEndpoints))
+	builder.WriteString(fmt.Sprintf("| 敏感信息 | %d (高风险 %d) | %d | - |\n", summary.AddedFindings, summary.AddedHighRisk, summary.RemovedFindings))
+	builder.WriteString(fmt.Sprintf("| 跳转边 | %d | %d | - |\n", summary.AddedEdges, summary.RemovedEdges))
+	builder.WriteString(fmt.Sprintf("| 组件依赖 | - | - | %d |\n", summary.ChangedComponents))
+
+	if !report.HasChanges() {
+		builder.WriteString("\n两个版本之间未发现差异。\n")
+		return builder.String()
+	}
+
+	if len(report.Pages.Added)+len(report.Pages.Removed)+len(report.Pages.Changed) > 0 {
+		builder.WriteString("\n## 页面\n\n")
+		for _, route := range report.Pages.Added {
+			builder.WriteString(fmt.Sprintf("- ➕ `%s`\n", route))
+		}
+		for _, route := range report.Pages.Removed {
+			builder.WriteString(fmt.Sprintf("- ➖ `%s`\n", route))
+		}
+		for _, change := range report.Pages.Changed {
+			builder.WriteString(fmt.Sprintf("- ✏️ `%s`: %s\n", change.Route, strings.Join(change.Fields, ", ")))
+		}
+	}
+
+	if len(report.APIEndpoints.Added)+len(report.APIEndpoints.Removed)+len(report.APIEndpoints.Changed) > 0 {
+		builder.WriteString("\n## 接口\n\n")
+		builder.WriteString("| 变化 | 接口 | 名称 | 文件 | 来源 |\n")
+		builder.WriteString("|------|------|------|------|------|\n")
+		for _, endpoint := range report.APIEndpoints.Added {
+			builder.WriteString(fmt.Sprintf("| 新增 | `%s` | %s | `%s` | %s |\n",
+				escapeTableCell(endpoint.Key), escapeTableCell(emptyAsDash(endpoint.Name)),
+				escapeTableCell(endpoint.FilePath), strings.Join(endpoint.Sources, ", ")))
+		}
+		for _, endpoint := range report.APIEndpoints.Removed {
+			builder.WriteString(fmt.Sprintf("| 删除 | `%s` | %s | `%s` | %s |\n",
+				escapeTableCell(endpoint.Key), escapeTableCell(emptyAsDash(endpoint.Name)),
+				escapeTableCell(endpoint.FilePath), strings.Join(endpoint.Sources, ", ")))
+		}
+		for _, change := range report.APIEndpoints.Changed {
+			builder.WriteString(fmt.Sprintf("| 参数变化 | `%s` | +%s / -%s | - | - |\n",
+				escapeTableCell(change.Key),
+				escapeTableCell(emptyAsDash(strings.Join(change.AddedParams, ","))),
+				escapeTableCell(emptyAsDash(strings.Join(change.RemovedParams, ",")))))
+		}
+	}
+
+	if len(report.Findings.Added) > 0 {
+		builder.WriteString("\n## 新增敏感信息\n\n")
+		builder.WriteString("| 风险 | 规则 | 内容 | 位置 |\n")
+		builder.WriteString("|------|------|------|------|\n")
+		for _, item := range report.Findings.Added {
+			builder.WriteString(fmt.Sprintf("| %s | %s | `%s` | `%s:%d` |\n",
+				item.Confidence, escapeTableCell(item.RuleName), escapeTableCell(item.Content),
+				escapeTableCell(item.FilePath), item.LineNumber))
+		}
+	}
+	if len(report.Findings.Removed) > 0 {
+		builder.WriteString("\n## 已消失的敏感信息\n\n")
+		for _, item := range report.Findings.Removed {
+			builder.WriteString(fmt.Sprintf("- %s: `%s`\n", escapeMarkdown(item.RuleName), escapeMarkdown(item.Content)))
+		}
+	}
+
+	if len(report.NavigationEdges.Added)+len(report.NavigationEdges.Removed) > 0 {
+		builder.WriteString("\n## 跳转边\n\n")
+		for _, edge := range report.NavigationEdges.Added {
+			builder.WriteString(fmt.Sprintf("- ➕ `%s` → `%s` (%s)\n", edge.SourcePage, edge.Target, edge.Method))
+		}
+		for _, edge := range report.NavigationEdges.Removed {
+			builder.WriteString(fmt.Sprintf("- ➖ `%s` → `%s` (%s)\n", edge.SourcePage, edge.Target, edge.Method))
+		}
+	}
+
+	if len(report.Components) > 0 {
+		builder.WriteString("\n## 组件与依赖\n")
+		for _, change := range report.Components {
+			builder.WriteString(fmt.Sprintf("\n### `%s`\n\n", change.Route))
+			writeDiffList(&builder, "新增组件", change.AddedComponents)
+			writeDiffList(&builder, "移除组件", change.RemovedComponents)
+			writeDiffList(&builder, "新增依赖", change.AddedDependencies)
+			writeDiffList(&builder, "移除依赖", change.RemovedDependencies)
+		}
+	}
+
+	return builder.String()
+}
+
+func writeDiffList(builder *strings.Builder, title string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, "`"+value+"`")
+	}
+	builder.WriteString(fmt.Sprintf("- %s: %s\n", title, strings.Join(quoted, ", ")))
+}
+
+const diffHTMLTemplate = `<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="UTF-8">
+<meta name="viewport" content="width=device-width,initial-scale=1">
+<title>Gwxapkg 版本差异 - {{.AppID}}</title>
+<style>
+*{margin:0;padding:0;box-sizing:border-box}
+body{font-family:-apple-system,BlinkMacSystemFont,"Segoe UI",Roboto,sans-serif;background:#0f1117;color:#e1e4e8;min-height:100vh}
+.header{background:linear-gradient(135deg,#1a1d27 0%,#12151e 100%);border-bottom:1px solid #21262d;padding:24px 32px}
+.header h1{font-size:22px;font-weight:700;color:#58a6ff;letter-spacing:.5px}
+.header .meta{margin-top:8px;font-size:13px;color:#8b949e;display:flex;gap:24px;flex-wrap:wrap}
+.container{max-width:1400px;margin:0 auto;padding:24px 32px}
+.stats{display:grid;grid-template-columns:repeat(auto-fit,minmax(160px,1fr));gap:16px;margin-bottom:28px}
+.stat-card{background:#161b22;border:1px solid #21262d;border-radius:10px;padding:18px 20px}
+.stat-card .val{font-size:26px;font-weight:700;line-height:1}
+.stat-card .lbl{font-size:12px;color:#8b949e;margin-top:6px}
+.add{color:#3fb950}
+.del{color:#f85149}
+.mod{color:#e3b341}
+h2{font-size:16px;margin:28px 0 12px;color:#e1e4e8}
+.table-wrap{overflow-x:auto;border:1px solid #21262d;border-radius:10px}
+table{width:100%;border-collapse:collapse;font-size:13px}
+thead th{background:#161b22;padding:11px 14px;text-align:left;font-weight:600;color:#8b949e;font-size:12px;border-bottom:1px solid #21262d}
+tbody tr{border-bottom:1px solid #0d1117}
+tbody tr:hover{background:#161b22}
+td{padding:10px 14px;vertical-align:top;word-break:break-all}
+td.mono{font-family:monospace;font-size:12px;color:#79c0ff}
+.note{border:1px solid rgba(227,179,65,.45);background:rgba(227,179,65,.08);border-radius:10px;padding:12px 16px;margin-bottom:16px;font-size:13px;color:#c9d1d9}
+.no-data{text-align:center;padding:48px;color:#484f58}
+.footer{text-align:center;padding:24px;color:#484f58;font-size:12px;border-top:1px solid #21262d;margin-top:24px}
+</style>
+</head>
+<body>
+<div class="header">
+  <h1>🔀 Gwxapkg 版本差异报告</h1>
+  <div class="meta">
+    {{if .AppID}}<span>📦 AppID: <b style="color:#e1e4e8">{{.AppID}}</b></span>{{end}}
+    <span>旧版本: <b style="color:#e1e4e8">{{.Old.Label}}</b></span>
+    <span>新版本: <b style="color:#e1e4e8">{{.New.Label}}</b></span>
+    <span>🕐 {{.GeneratedAt}}</span>
+  </div>
+</div>
+<div class="container">
+{{range .Notes}}<div class="note">⚠ {{.}}</div>{{end}}
+
+<div class="stats">
+  <div class="stat-card"><div class="val"><span class="add">+{{.Summary.AddedPages}}</span> <span class="del">-{{.Summary.RemovedPages}}</span> <span class="mod">~{{.Summary.ChangedPages}}</span></div><div class="lbl">页面</div></div>
+  <div class="stat-card"><div class="val"><span class="add">+{{.Summary.AddedEndpoints}}</span> <span class="del">-{{.Summary.RemovedEndpoints}}</span> <span class="mod">~{{.Summary.ChangedEndpoints}}</span></div><div class="lbl">接口</div></div>
+  <div class="stat-card"><div class="val"><span class="add">+{{.Summary.AddedFindings}}</span> <span class="del">-{{.Summary.RemovedFindings}}</span></div><div class="lbl">敏感信息（新增高风险 {{.Summary.AddedHighRisk}}）</div></div>
+  <div class="stat-card"><div class="val"><span class="add">+{{.Summary.AddedEdges}}</span> <span class="del">-{{.Summary.RemovedEdges}}</span></div><div class="lbl">跳转边</div></div>
+  <div class="stat-card"><div class="val"><span class="mod">~{{.Summary.ChangedComponents}}</span></div><div class="lbl">组件依赖变化页面</div></div>
+</div>
+
+{{if not .HasChanges}}<div class="no-data">两个版本之间未发现差异</div>{{end}}
+
+{{if or .Pages.Added .Pages.Removed .Pages.Changed}}
+<h2>页面</h2>
+<div class="table-wrap"><table>
+<thead><tr><th>变化</th><th>路由</th><th>变更字段</th></tr></thead>
+<tbody>
+{{range .Pages.Added}}<tr><td class="add">新增</td><td class="mono">{{.}}</td><td>-</td></tr>{{end}}
+{{range .Pages.Removed}}<tr><td class="del">删除</td><td class="mono">{{.}}</td><td>-</td></tr>{{end}}
+{{range .Pages.Changed}}<tr><td class="mod">变更</td><td class="mono">{{.Route}}</td><td>{{join .Fields ", "}}</td></tr>{{end}}
+</tbody></table></div>
+{{end}}
+
+{{if or .APIEndpoints.Added .APIEndpoints.Removed .APIEndpoints.Changed}}
+<h2>接口</h2>
+<div class="table-wrap"><table>
+<thead><tr><th>变化</th><th>接口</th><th>名称</th><th>文件</th><th>来源 / 参数</th></tr></thead>
+<tbody>
+{{range .APIEndpoints.Added}}<tr><td class="add">新增</td><td class="mono">{{.Key}}</td><td>{{.Name}}</td><td>{{.FilePath}}</td><td>{{join .Sources ", "}}</td></tr>{{end}}
+{{range .APIEndpoints.Removed}}<tr><td class="del">删除</td><td class="mono">{{.Key}}</td><td>{{.Name}}</td><td>{{.FilePath}}</td><td>{{join .Sources ", "}}</td></tr>{{end}}
+{{range .APIEndpoints.Changed}}<tr><td class="mod">参数变化</td><td class="mono">{{.Key}}</td><td>-</td><td>-</td><td><span class="add">+{{join .AddedParams ","}}</span> <span class="del">-{{join .RemovedParams ","}}</span></td></tr>{{end}}
+</tbody></table></div>
+{{end}}
+
+{{if or .Findings.Added .Findings.Removed}}
+<h2>敏感信息</h2>
+<div class="table-wrap"><table>
+<thead><tr><th>变化</th><th>风险</th><th>规则</th><th>内容</th><th>位置</th></tr></thead>
+<tbody>
+{{range .Findings.Added}}<tr><td class="add">新增</td><td>{{.Confidence}}</td><td>{{.RuleName}}</td><td class="mono">{{.Content}}</td><td>{{.FilePath}}:{{.LineNumber}}</td></tr>{{end}}
+{{range .Findings.Removed}}<tr><td class="del">消失</td><td>{{.Confidence}}</td><td>{{.RuleName}}</td><td class="mono">{{.Content}}</td><td>{{.FilePath}}:{{.LineNumber}}</td></tr>{{end}}
+</tbody></table></div>
+{{end}}
+
+{{if or .NavigationEdges.Added .NavigationEdges.Removed}}
+<h2>跳转边</h2>
+<div class="table-wrap"><table>
+<thead><tr><th>变化</th><th>源页面</th><th>目标</th><th>方式</th></tr></thead>
+<tbody>
+{{range .NavigationEdges.Added}}<tr><td class="add">新增</td><td class="mono">{{.SourcePage}}</td><td class="mono">{{.Target}}</td><td>{{.Method}}</td></tr>{{end}}
+{{range .NavigationEdges.Removed}}<tr><td class="del">删除</td><td class="mono">{{.SourcePage}}</td><td class="mono">{{.Target}}</td><td>{{.Method}}</td></tr>{{end}}
+</tbody></table></div>
+{{end}}
+
+{{if .Components}}
+<h2>组件与依赖</h2>
+<div class="table-wrap"><table>
+<thead><tr><th>页面</th><th>组件</th><th>依赖</th></tr></thead>
+<tbody>
+{{range .Components}}<tr><td class="mono">{{.Route}}</td><td><span class="add">{{join .AddedComponents ", "}}</span> <span class="del">{{join .RemovedComponents ", "}}</span></td><td><span class="add">{{join .AddedDependencies ", "}}</span> <span class="del">{{join .RemovedDependencies ", "}}</span></td></tr>{{end}}
+</tbody></table></div>
+{{end}}
+</div>
+<div class="footer">Generated by <b>Gwxapkg</b> · <a href="https://github.com/25smoking/Gwxapkg" style="color:#58a6ff;text-decoration:none">github.com/25smoking/Gwxapkg</a></div>
+</body>
+</html>
+`
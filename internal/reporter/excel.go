@@ -2,6 +2,7 @@ package reporter
 
 import (
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 	"unicode/utf8"
@@ -40,9 +41,15 @@ func (r *ExcelReporter) Generate(report *scanner.ScanReport, filename string) er
 	}
 	sort.Strings(categories)
 
+	decoded := make(map[string]string)
+	for _, item := range report.Items {
+		if summary := decodedSummary(item); summary != "" {
+			decoded[item.Category+":"+item.Content] = summary
+		}
+	}
 	for _, category := range categories {
 		data := report.Categories[category]
-		if err := r.createCategorySheet(category, data, usedSheetNames); err != nil {
+		if err := r.createCategorySheet(category, data, decoded, usedSheetNames); err != nil {
 			return fmt.Errorf("创建分类页 %s 失败: %w", category, err)
 		}
 	}
@@ -51,6 +58,12 @@ func (r *ExcelReporter) Generate(report *scanner.ScanReport, filename string) er
 		return fmt.Errorf("创建混淆文件页失败: %w", err)
 	}
 
+	if len(report.KnownItems)+len(report.SuppressedItems) > 0 {
+		if err := r.createTriageSheet(report, usedSheetNames); err != nil {
+			return fmt.Errorf("创建已知与已忽略页失败: %w", err)
+		}
+	}
+
 	// 3. 应用样式
 	r.applyStyles()
 
@@ -102,6 +115,12 @@ func (r *ExcelReporter) createOverviewSheet(report *scanner.ScanReport, usedShee
 	r.file.SetCellValue(sheet, "B12", report.Summary.LowRisk)
 	r.file.SetCellValue(sheet, "A13", "混淆文件数:")
 	r.file.SetCellValue(sheet, "B13", len(report.ObfuscatedFiles))
+	r.file.SetCellValue(sheet, "C8", "新发现:")
+	r.file.SetCellValue(sheet, "D8", report.Summary.NewCount)
+	r.file.SetCellValue(sheet, "C9", "已知:")
+	r.file.SetCellValue(sheet, "D9", report.Summary.KnownCount)
+	r.file.SetCellValue(sheet, "C10", "已忽略:")
+	r.file.SetCellValue(sheet, "D10", report.Summary.Suppressed)
 
 	// 分类统计表头
 	r.file.SetCellValue(sheet, "A15", "分类统计")
@@ -134,8 +153,8 @@ func (r *ExcelReporter) createOverviewSheet(report *scanner.ScanReport, usedShee
 	return nil
 }
 
-// createCategorySheet 创建分类页
-func (r *ExcelReporter) createCategorySheet(category string, data *scanner.CategoryData, usedSheetNames map[string]struct{}) error {
+// createCategorySheet 创建分类页，decoded 为 分类:内容 到解析摘要的映射
+func (r *ExcelReporter) createCategorySheet(category string, data *scanner.CategoryData, decoded map[string]string, usedSheetNames map[string]struct{}) error {
 	sheetName := safeExcelSheetName(data.Name, category, usedSheetNames)
 
 	_, err := r.file.NewSheet(sheetName)
@@ -144,7 +163,7 @@ func (r *ExcelReporter) createCategorySheet(category string, data *scanner.Categ
 	}
 
 	// 表头
-	headers := []string{"序号", "内容", "出现次数", "文件路径", "行号"}
+	headers := []string{"序号", "内容", "出现次数", "文件路径", "行号", "解析结果"}
 	for i, header := range headers {
 		cell := fmt.Sprintf("%s1", string(rune('A'+i)))
 		r.file.SetCellValue(sheetName, cell, header)
@@ -165,6 +184,9 @@ func (r *ExcelReporter) createCategorySheet(category string, data *scanner.Categ
 		r.file.SetCellValue(sheetName, fmt.Sprintf("A%d", row), idx)
 		r.file.SetCellValue(sheetName, fmt.Sprintf("B%d", row), content)
 		r.file.SetCellValue(sheetName, fmt.Sprintf("C%d", row), len(locations))
+		if summary := decoded[category+":"+content]; summary != "" {
+			r.file.SetCellValue(sheetName, fmt.Sprintf("F%d", row), summary)
+		}
 
 		if len(locations) > 0 {
 			r.file.SetCellValue(sheetName, fmt.Sprintf("D%d", row), locations[0].FilePath)
@@ -188,6 +210,7 @@ func (r *ExcelReporter) createCategorySheet(category string, data *scanner.Categ
 	r.file.SetColWidth(sheetName, "C", "C", 12)
 	r.file.SetColWidth(sheetName, "D", "D", 40)
 	r.file.SetColWidth(sheetName, "E", "E", 10)
+	r.file.SetColWidth(sheetName, "F", "F", 40)
 
 	return nil
 }
@@ -223,6 +246,44 @@ func (r *ExcelReporter) createObfuscatedSheet(report *scanner.ScanReport, usedSh
 	return nil
 }
 
+// createTriageSheet 已知与已忽略的发现不计入分类页，单独列出便于复核
+func (r *ExcelReporter) createTriageSheet(report *scanner.ScanReport, usedSheetNames map[string]struct{}) error {
+	sheetName := safeExcelSheetName("已知与已忽略", "已知与已忽略", usedSheetNames)
+	if _, err := r.file.NewSheet(sheetName); err != nil {
+		return err
+	}
+
+	headers := []string{"序号", "状态", "规则", "内容", "文件路径", "行号", "忽略理由"}
+	for i, header := range headers {
+		cell := fmt.Sprintf("%s1", string(rune('A'+i)))
+		r.file.SetCellValue(sheetName, cell, header)
+	}
+
+	items := append(slices.Clone(report.SuppressedItems), report.KnownItems...)
+	for index, item := range items {
+		row := index + 2
+		status := "已知"
+		if item.Status == scanner.StatusSuppressed {
+			status = "已忽略"
+		}
+		r.file.SetCellValue(sheetName, fmt.Sprintf("A%d", row), index+1)
+		r.file.SetCellValue(sheetName, fmt.Sprintf("B%d", row), status)
+		r.file.SetCellValue(sheetName, fmt.Sprintf("C%d", row), item.RuleID)
+		r.file.SetCellValue(sheetName, fmt.Sprintf("D%d", row), item.Content)
+		r.file.SetCellValue(sheetName, fmt.Sprintf("E%d", row), item.FilePath)
+		r.file.SetCellValue(sheetName, fmt.Sprintf("F%d", row), item.LineNumber)
+		r.file.SetCellValue(sheetName, fmt.Sprintf("G%d", row), item.Justification)
+	}
+
+	r.file.SetColWidth(sheetName, "A", "B", 8)
+	r.file.SetColWidth(sheetName, "C", "C", 20)
+	r.file.SetColWidth(sheetName, "D", "E", 40)
+	r.file.SetColWidth(sheetName, "F", "F", 8)
+	r.file.SetColWidth(sheetName, "G", "G", 40)
+
+	return nil
+}
+
 func safeExcelSheetName(name, fallback string, used map[string]struct{}) string {
 	name = strings.TrimSpace(name)
 	if name == "" {
@@ -331,7 +392,11 @@ func (r *ExcelReporter) applyStyles() {
 				r.file.SetCellStyle(sheet, "A1", "F1", headerStyle)
 				continue
 			}
-			r.file.SetCellStyle(sheet, "A1", "E1", headerStyle)
+			if sheet == "已知与已忽略" {
+				r.file.SetCellStyle(sheet, "A1", "G1", headerStyle)
+				continue
+			}
+			r.file.SetCellStyle(sheet, "A1", "F1", headerStyle)
 		}
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"html/template"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 
@@ -87,6 +88,7 @@ type HTMLData struct {
 	MediumItems     []HTMLItem
 	LowItems        []HTMLItem
 	ObfuscatedFiles []HTMLObfuscated
+	TriagedItems    []HTMLTriaged
 	PackageStatus   *HTMLPackageStatus
 }
 
@@ -105,6 +107,7 @@ type HTMLItem struct {
 	Context    string
 	Confidence string
 	Category   string
+	Decoded    string // JWT、密钥与证书的解析摘要
 }
 
 type HTMLObfuscated struct {
@@ -115,6 +118,17 @@ type HTMLObfuscated struct {
 	Tag        string
 }
 
+// HTMLTriaged 基线中已知或被忽略规则命中的发现，默认不出现在主列表中
+type HTMLTriaged struct {
+	Status        string
+	RuleID        string
+	Content       string
+	FilePath      string
+	LineNumber    int
+	Justification string
+	Decoded       string
+}
+
 type HTMLPackageStatus struct {
 	Status                  string
 	DeclaredSubpackageCount int
@@ -154,11 +168,15 @@ func buildHTMLData(report *scanner.ScanReport, filename string) HTMLData {
 	confidenceMap := make(map[string]string)
 	contentConfidenceMap := make(map[string]string)
 	contextMap := make(map[string]string)
+	decodedMap := make(map[string]string)
 	for _, item := range report.Items {
 		key := item.Category + ":" + item.Content
 		confidenceMap[key] = maxConfidence(confidenceMap[key], item.Confidence)
 		contentConfidenceMap[item.Content] = maxConfidence(contentConfidenceMap[item.Content], item.Confidence)
 		contextMap[key] = strings.TrimSpace(item.Context)
+		if summary := decodedSummary(item); summary != "" {
+			decodedMap[key] = summary
+		}
 	}
 
 	for _, k := range catKeys {
@@ -198,6 +216,7 @@ func buildHTMLData(report *scanner.ScanReport, filename string) HTMLData {
 				Context:    contextMap[k+":"+content],
 				Confidence: conf,
 				Category:   catData.Name,
+				Decoded:    decodedMap[k+":"+content],
 			}
 			cat.Items = append(cat.Items, item)
 			data.AllItems = append(data.AllItems, item)
@@ -228,9 +247,32 @@ func buildHTMLData(report *scanner.ScanReport, filename string) HTMLData {
 		})
 	}
 
+	for _, item := range append(slices.Clone(report.SuppressedItems), report.KnownItems...) {
+		status := "已知"
+		if item.Status == scanner.StatusSuppressed {
+			status = "已忽略"
+		}
+		data.TriagedItems = append(data.TriagedItems, HTMLTriaged{
+			Status:        status,
+			RuleID:        item.RuleID,
+			Content:       item.Content,
+			FilePath:      item.FilePath,
+			LineNumber:    item.LineNumber,
+			Justification: item.Justification,
+			Decoded:       decodedSummary(item),
+		})
+	}
+
 	return data
 }
 
+func decodedSummary(item scanner.SensitiveItem) string {
+	if item.Decoded == nil {
+		return ""
+	}
+	return item.Decoded.Summary
+}
+
 func loadHTMLPackageStatus(rootDir string) *HTMLPackageStatus {
 	report, err := packagecheck.ReadReport(rootDir)
 	if err != nil || report == nil || report.Status == packagecheck.StatusUnknown {
@@ -343,6 +385,7 @@ tbody tr:hover{background:#161b22}
 tbody tr:last-child{border-bottom:none}
 td{padding:10px 14px;vertical-align:top}
 td.content-cell{max-width:320px;word-break:break-all;font-family:monospace;font-size:12px;color:#79c0ff}
+td.content-cell .decoded{margin-top:4px;font-family:inherit;color:#d29922;font-size:11px}
 td.path-cell{max-width:260px;word-break:break-all;color:#8b949e;font-size:12px}
 td.ctx-cell{max-width:360px;word-break:break-all;color:#6e7681;font-size:11px;font-family:monospace}
 .risk-badge{display:inline-block;padding:2px 8px;border-radius:4px;font-size:11px;font-weight:600}
@@ -419,6 +462,9 @@ td.ctx-cell{max-width:360px;word-break:break-all;color:#6e7681;font-size:11px;fo
   <div class="tab" onclick="switchTab('risk-medium',this)">中风险<span class="badge">{{.MediumRisk}}</span></div>
   <div class="tab" onclick="switchTab('risk-low',this)">低风险<span class="badge">{{.LowRisk}}</span></div>
   <div class="tab" onclick="switchTab('obfuscated',this)">混淆文件<span class="badge">{{.ObfuscatedCount}}</span></div>
+  {{if .TriagedItems}}
+  <div class="tab" onclick="switchTab('triaged',this)">已知与已忽略<span class="badge">{{len .TriagedItems}}</span></div>
+  {{end}}
   {{range .Categories}}
   <div class="tab" onclick="switchTab('{{.Key}}',this)">{{.Name}}<span class="badge">{{.Count}}</span></div>
   {{end}}
@@ -436,7 +482,7 @@ td.ctx-cell{max-width:360px;word-break:break-all;color:#6e7681;font-size:11px;fo
     {{range $i,$item := .AllItems}}
     <tr>
       <td style="color:#484f58;white-space:nowrap">{{add $i 1}}</td>
-      <td class="content-cell">{{$item.Content}}</td>
+      <td class="content-cell">{{$item.Content}}{{if $item.Decoded}}<div class="decoded">{{$item.Decoded}}</div>{{end}}</td>
       <td style="white-space:nowrap;color:#8b949e">{{$item.Category}}</td>
       <td><span class="risk-badge {{riskClass $item.Confidence}}">{{riskLabel $item.Confidence}}</span></td>
       <td style="text-align:center;color:#8b949e">{{$item.Count}}</td>
@@ -462,7 +508,7 @@ td.ctx-cell{max-width:360px;word-break:break-all;color:#6e7681;font-size:11px;fo
     {{range $i,$item := .HighItems}}
     <tr>
       <td style="color:#484f58;white-space:nowrap">{{add $i 1}}</td>
-      <td class="content-cell">{{$item.Content}}</td>
+      <td class="content-cell">{{$item.Content}}{{if $item.Decoded}}<div class="decoded">{{$item.Decoded}}</div>{{end}}</td>
       <td style="white-space:nowrap;color:#8b949e">{{$item.Category}}</td>
       <td><span class="risk-badge {{riskClass $item.Confidence}}">{{riskLabel $item.Confidence}}</span></td>
       <td style="text-align:center;color:#8b949e">{{$item.Count}}</td>
@@ -488,7 +534,7 @@ td.ctx-cell{max-width:360px;word-break:break-all;color:#6e7681;font-size:11px;fo
     {{range $i,$item := .MediumItems}}
     <tr>
       <td style="color:#484f58;white-space:nowrap">{{add $i 1}}</td>
-      <td class="content-cell">{{$item.Content}}</td>
+      <td class="content-cell">{{$item.Content}}{{if $item.Decoded}}<div class="decoded">{{$item.Decoded}}</div>{{end}}</td>
       <td style="white-space:nowrap;color:#8b949e">{{$item.Category}}</td>
       <td><span class="risk-badge {{riskClass $item.Confidence}}">{{riskLabel $item.Confidence}}</span></td>
       <td style="text-align:center;color:#8b949e">{{$item.Count}}</td>
@@ -514,7 +560,7 @@ td.ctx-cell{max-width:360px;word-break:break-all;color:#6e7681;font-size:11px;fo
     {{range $i,$item := .LowItems}}
     <tr>
       <td style="color:#484f58;white-space:nowrap">{{add $i 1}}</td>
-      <td class="content-cell">{{$item.Content}}</td>
+      <td class="content-cell">{{$item.Content}}{{if $item.Decoded}}<div class="decoded">{{$item.Decoded}}</div>{{end}}</td>
       <td style="white-space:nowrap;color:#8b949e">{{$item.Category}}</td>
       <td><span class="risk-badge {{riskClass $item.Confidence}}">{{riskLabel $item.Confidence}}</span></td>
       <td style="text-align:center;color:#8b949e">{{$item.Count}}</td>
@@ -553,6 +599,29 @@ td.ctx-cell{max-width:360px;word-break:break-all;color:#6e7681;font-size:11px;fo
   {{end}}
 </div>
 
+{{if .TriagedItems}}
+<div class="panel" id="panel-triaged">
+  <div class="table-wrap">
+  <table id="tbl-triaged">
+    <thead><tr><th>#</th><th>状态</th><th>规则</th><th>内容</th><th>文件路径</th><th>行号</th><th>忽略理由</th></tr></thead>
+    <tbody>
+    {{range $i,$item := .TriagedItems}}
+    <tr>
+      <td style="color:#484f58;white-space:nowrap">{{add $i 1}}</td>
+      <td style="white-space:nowrap;color:#8b949e">{{$item.Status}}</td>
+      <td style="white-space:nowrap;color:#8b949e">{{$item.RuleID}}</td>
+      <td class="content-cell">{{$item.Content}}{{if $item.Decoded}}<div class="decoded">{{$item.Decoded}}</div>{{end}}</td>
+      <td class="path-cell">{{$item.FilePath}}</td>
+      <td style="text-align:center;color:#8b949e">{{$item.LineNumber}}</td>
+      <td class="ctx-cell">{{$item.Justification}}</td>
+    </tr>
+    {{end}}
+    </tbody>
+  </table>
+  </div>
+</div>
+{{end}}
+
 <!-- 分类面板 -->
 {{range .Categories}}
 <div class="panel" id="panel-{{.Key}}">
@@ -566,7 +635,7 @@ td.ctx-cell{max-width:360px;word-break:break-all;color:#6e7681;font-size:11px;fo
     {{range $i,$item := .Items}}
     <tr>
       <td style="color:#484f58;white-space:nowrap">{{add $i 1}}</td>
-      <td class="content-cell">{{$item.Content}}</td>
+      <td class="content-cell">{{$item.Content}}{{if $item.Decoded}}<div class="decoded">{{$item.Decoded}}</div>{{end}}</td>
       <td><span class="risk-badge {{riskClass $item.Confidence}}">{{riskLabel $item.Confidence}}</span></td>
       <td style="text-align:center;color:#8b949e">{{$item.Count}}</td>
       <td class="path-cell">{{$item.FilePath}}</td>
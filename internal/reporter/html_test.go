@@ -66,3 +66,37 @@ func TestHTMLReporterKeepsHighRiskVisible(t *testing.T) {
 		t.Fatalf("唯一高危样本不应被渲染成低危")
 	}
 }
+
+func TestHTMLReporterShowsDecodedSummary(t *testing.T) {
+	item := scanner.SensitiveItem{
+		RuleID:     "jwt_token_full",
+		Category:   "token",
+		Content:    "eyJhbGciOiJSUzI1NiJ9.eyJleHAiOjQwNzA5MDg4MDB9.c2ln",
+		FilePath:   "app-service.js",
+		LineNumber: 3,
+		Confidence: "medium",
+		Decoded:    &scanner.DecodedCredential{Kind: scanner.DecodedJWT, Summary: "RS256 JWT，2099-01-01 过期"},
+	}
+	report := &scanner.ScanReport{
+		AppID: "wx-test",
+		Categories: map[string]*scanner.CategoryData{
+			"token": {Name: "Token/令牌", Count: 1, UniqueCount: 1, Items: map[string][]scanner.LocationInfo{
+				item.Content: {{FilePath: item.FilePath, LineNumber: item.LineNumber}},
+			}},
+		},
+		Items:   []scanner.SensitiveItem{item},
+		Summary: scanner.ReportSummary{TotalMatches: 1, UniqueMatches: 1, MediumRisk: 1},
+	}
+
+	output := filepath.Join(t.TempDir(), "sensitive_report.html")
+	if err := NewHTMLReporter().Generate(report, output); err != nil {
+		t.Fatalf("Generate 返回错误: %v", err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("读取 HTML 失败: %v", err)
+	}
+	if !strings.Contains(string(data), `<div class="decoded">RS256 JWT，2099-01-01 过期</div>`) {
+		t.Fatalf("HTML 应展示解析摘要")
+	}
+}
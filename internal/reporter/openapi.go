@@ -0,0 +1,490 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/25smoking/Gwxapkg/internal/scanner"
+	"github.com/25smoking/Gwxapkg/internal/semantic"
+)
+
+// OpenAPIFileName OpenAPI 文档文件名，与 Postman Collection 一同写入输出目录
+const OpenAPIFileName = "openapi.yaml"
+
+var (
+	templateExprPattern = regexp.MustCompile(`\$\{([^}]*)\}`)
+	identifierPattern   = regexp.MustCompile(`[A-Za-z_$][\w$]*`)
+)
+
+// OpenAPIReporter OpenAPI 3 文档生成器
+type OpenAPIReporter struct{}
+
+// NewOpenAPIReporter 创建 OpenAPI 文档生成器
+func NewOpenAPIReporter() *OpenAPIReporter {
+	return &OpenAPIReporter{}
+}
+
+type openAPIDocument struct {
+	OpenAPI string                                  `yaml:"openapi"`
+	Info    openAPIInfo                             `yaml:"info"`
+	Servers []openAPIServer                         `yaml:"servers,omitempty"`
+	Tags    []openAPITag                            `yaml:"tags,omitempty"`
+	Paths   map[string]map[string]*openAPIOperation `yaml:"paths"`
+}
+
+type openAPIInfo struct {
+	Title       string `yaml:"title"`
+	Version     string `yaml:"version"`
+	Description string `yaml:"description,omitempty"`
+}
+
+type openAPIServer struct {
+	URL         string `yaml:"url"`
+	Description string `yaml:"description,omitempty"`
+}
+
+type openAPITag struct {
+	Name string `yaml:"name"`
+}
+
+type openAPIOperation struct {
+	OperationID string                     `yaml:"operationId"`
+	Summary     string                     `yaml:"summary,omitempty"`
+	Description string                     `yaml:"description,omitempty"`
+	Tags        []string                   `yaml:"tags,omitempty"`
+	Parameters  []openAPIParameter         `yaml:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody        `yaml:"requestBody,omitempty"`
+	Responses   map[string]openAPIResponse `yaml:"responses"`
+	Servers     []openAPIServer            `yaml:"servers,omitempty"`
+	Source      *openAPISource             `yaml:"x-source,omitempty"`
+}
+
+type openAPIParameter struct {
+	Name     string         `yaml:"name"`
+	In       string         `yaml:"in"`
+	Required bool           `yaml:"required,omitempty"`
+	Schema   *openAPISchema `yaml:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Content map[string]openAPIMediaType `yaml:"content"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `yaml:"schema"`
+}
+
+type openAPISchema struct {
+	Type       string                    `yaml:"type,omitempty"`
+	Properties map[string]*openAPISchema `yaml:"properties,omitempty"`
+	Required   []string                  `yaml:"required,omitempty"`
+	Enum       []string                  `yaml:"enum,omitempty"`
+	Example    interface{}               `yaml:"example,omitempty"`
+}
+
+type openAPIResponse struct {
+	Description string `yaml:"description"`
+}
+
+// openAPISource 指向生成该操作的源码位置
+type openAPISource struct {
+	File      string   `yaml:"file"`
+	Line      int      `yaml:"line,omitempty"`
+	Function  string   `yaml:"function,omitempty"`
+	Rule      string   `yaml:"rule,omitempty"`
+	CallSites []string `yaml:"callSites,omitempty"`
+}
+
+// openAPIBuilder 汇总 api_map 与扫描结果，保证 operationId 与 path+method 唯一
+type openAPIBuilder struct {
+	doc          *openAPIDocument
+	servers      map[string]struct{}
+	baseGroups   map[string]map[string]string
+	primaryBase  string
+	tags         map[string]struct{}
+	operationIDs map[string]int
+}
+
+// Generate 由 api_map（语义还原的 controller/method 接口）与扫描提取的请求生成 OpenAPI 3.0 文档，
+// apiMap 可为空；同一 method + path 以 api_map 为准。
+func (r *OpenAPIReporter) Generate(report *scanner.ScanReport, apiMap *semantic.APIMapReport, filename string) error {
+	if report == nil && apiMap == nil {
+		return fmt.Errorf("报告为空")
+	}
+
+	title := "Mini Program API"
+	if report != nil && report.AppID != "" {
+		title = report.AppID + " API"
+	}
+	builder := &openAPIBuilder{
+		doc: &openAPIDocument{
+			OpenAPI: "3.0.3",
+			Info: openAPIInfo{
+				Title:       title,
+				Version:     "1.0.0",
+				Description: "由 Gwxapkg 从小程序源码还原，x-source 指向生成该操作的源码位置",
+			},
+			Paths: make(map[string]map[string]*openAPIOperation),
+		},
+		servers:      make(map[string]struct{}),
+		tags:         make(map[string]struct{}),
+		operationIDs: make(map[string]int),
+	}
+	if apiMap != nil {
+		for _, endpoint := range apiMap.Endpoints {
+			builder.addAPIMapEndpoint(endpoint)
+		}
+	}
+	if report != nil {
+		builder.baseGroups = scanner.BaseURLGroups(report.BaseURLs)
+		builder.primaryBase = mostUsedBaseURL(report.APIEndpoints)
+		for _, endpoint := range report.APIEndpoints {
+			builder.addScanEndpoint(endpoint)
+		}
+	}
+
+	// 主基础地址的各环境排在最前，供文档工具切换；其余为绝对地址中出现过的主机
+	builder.doc.Servers = builder.groupServers(builder.primaryBase)
+	listed := make(map[string]struct{})
+	for _, server := range builder.doc.Servers {
+		listed[server.URL] = struct{}{}
+	}
+	hosts := make([]string, 0, len(builder.servers))
+	for server := range builder.servers {
+		if _, ok := listed[server]; !ok {
+			hosts = append(hosts, server)
+		}
+	}
+	sort.Strings(hosts)
+	for _, host := range hosts {
+		builder.doc.Servers = append(builder.doc.Servers, openAPIServer{URL: host})
+	}
+	for tag := range builder.tags {
+		builder.doc.Tags = append(builder.doc.Tags, openAPITag{Name: tag})
+	}
+	sort.Slice(builder.doc.Tags, func(i, j int) bool { return builder.doc.Tags[i].Name < builder.doc.Tags[j].Name })
+
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(builder.doc); err != nil {
+		return fmt.Errorf("序列化 OpenAPI 文档失败: %w", err)
+	}
+	data := buffer.Bytes()
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return fmt.Errorf("创建输出目录失败: %w", err)
+	}
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("写入 OpenAPI 文档失败: %w", err)
+	}
+	return nil
+}
+
+func (b *openAPIBuilder) addAPIMapEndpoint(endpoint semantic.APIEndpointEntry) {
+	rawURL, method := endpoint.URL, endpoint.HTTPMethod
+	observed := make(map[string]interface{})
+	for _, request := range endpoint.Observed {
+		if rawURL == "" {
+			rawURL = request.URL
+		}
+		if method == "" {
+			method = request.Method
+		}
+		if data, ok := request.Data.(map[string]interface{}); ok {
+			for key, value := range data {
+				if _, exists := observed[key]; !exists {
+					observed[key] = value
+				}
+			}
+		}
+	}
+	if method == "" {
+		method = "GET"
+	}
+
+	path, parameters := b.splitURL(rawURL)
+	description := ""
+	if path == "" {
+		// 网关类接口只靠 controllerName / methodsName 路由，源码与追踪都没有 URL 时用占位路径
+		path = "/" + strings.Trim(endpoint.ControllerName+"/"+endpoint.MethodsName, "/")
+		description = "源码未给出请求 URL，路径为 controllerName/methodsName 占位，实际请求发往网关地址"
+	}
+
+	schema := &openAPISchema{Type: "object", Properties: make(map[string]*openAPISchema)}
+	for name, value := range map[string]string{"controllerName": endpoint.ControllerName, "methodsName": endpoint.MethodsName} {
+		if value != "" {
+			schema.Properties[name] = &openAPISchema{Type: "string", Enum: []string{value}}
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	sort.Strings(schema.Required)
+	for _, field := range endpoint.ParamFields {
+		kind := endpoint.ParamTypes[field]
+		if kind == "" {
+			kind = jsonSchemaType(observed[field])
+		}
+		schema.Properties[field] = &openAPISchema{Type: kind, Example: observed[field]}
+	}
+
+	operationID := endpoint.FunctionName
+	if endpoint.ControllerName != "" && endpoint.MethodsName != "" {
+		operationID = endpoint.ControllerName + "_" + endpoint.MethodsName
+	}
+	operation := &openAPIOperation{
+		OperationID: b.uniqueOperationID(operationID),
+		Summary:     endpoint.FunctionName,
+		Description: description,
+		Parameters:  parameters,
+		Responses:   defaultOpenAPIResponses(),
+		Source: &openAPISource{
+			File:      endpoint.FilePath,
+			Line:      endpoint.LineNumber,
+			Function:  endpoint.FunctionName,
+			CallSites: openAPICallSites(endpoint.CallSites),
+		},
+	}
+	if endpoint.ControllerName != "" {
+		operation.Tags = []string{endpoint.ControllerName}
+		b.tags[endpoint.ControllerName] = struct{}{}
+	}
+	if strings.EqualFold(method, "GET") || strings.EqualFold(method, "DELETE") {
+		operation.Parameters = append(operation.Parameters, schemaToQuery(schema)...)
+	} else {
+		operation.RequestBody = &openAPIRequestBody{Content: map[string]openAPIMediaType{"application/json": {Schema: schema}}}
+	}
+	b.addOperation(path, method, operation)
+}
+
+func (b *openAPIBuilder) addScanEndpoint(endpoint scanner.APIEndpoint) {
+	rawURL := endpoint.RawURL
+	if endpoint.BaseURLName != "" {
+		rawURL = scanner.StripBaseURLPrefix(rawURL)
+	}
+	path, parameters := b.splitURL(rawURL)
+	if path == "" {
+		return
+	}
+	method := strings.ToUpper(endpoint.Method)
+	description := ""
+	if method == "" || method == "UNKNOWN" {
+		method = "GET"
+		description = "源码中未识别到请求方法，默认按 GET 输出"
+	}
+	// 同一 method + path 已由 api_map 描述时不再重复
+	if _, exists := b.doc.Paths[path][strings.ToLower(method)]; exists {
+		return
+	}
+
+	operation := &openAPIOperation{
+		OperationID: b.uniqueOperationID(operationIDFromName(endpoint.Name)),
+		Summary:     endpoint.Name,
+		Description: description,
+		Parameters:  parameters,
+		Responses:   defaultOpenAPIResponses(),
+		Source:      &openAPISource{File: endpoint.FilePath, Line: endpoint.LineNumber, Rule: endpoint.SourceRule},
+	}
+	if endpoint.BaseURLName != "" && endpoint.BaseURLName != b.primaryBase {
+		operation.Servers = b.groupServers(endpoint.BaseURLName)
+	}
+	if endpoint.Body != "" {
+		operation.RequestBody = &openAPIRequestBody{Content: map[string]openAPIMediaType{
+			"application/json": {Schema: schemaFromJSON(endpoint.Body)},
+		}}
+	}
+	b.addOperation(path, method, operation)
+}
+
+// groupServers 把一组基础地址按环境展开为 servers，默认环境在前
+func (b *openAPIBuilder) groupServers(name string) []openAPIServer {
+	envs := b.baseGroups[name]
+	if len(envs) == 0 {
+		return nil
+	}
+	preferred := scanner.PreferredEnvironment(envs)
+	names := make([]string, 0, len(envs))
+	for env := range envs {
+		if env != preferred {
+			names = append(names, env)
+		}
+	}
+	sort.Strings(names)
+	servers := make([]openAPIServer, 0, len(envs))
+	for _, env := range append([]string{preferred}, names...) {
+		servers = append(servers, openAPIServer{URL: envs[env], Description: env})
+	}
+	return servers
+}
+
+// mostUsedBaseURL 返回被最多接口引用的基础地址名，作为文档级 servers
+func mostUsedBaseURL(endpoints []scanner.APIEndpoint) string {
+	counts := make(map[string]int)
+	for _, endpoint := range endpoints {
+		if endpoint.BaseURLName != "" {
+			counts[endpoint.BaseURLName]++
+		}
+	}
+	best := ""
+	for name, count := range counts {
+		if count > counts[best] || count == counts[best] && name < best {
+			best = name
+		}
+	}
+	return best
+}
+
+// splitURL 拆出路径与查询参数，绝对地址的 scheme://host 记为 server；模板表达式 ${id} 转为路径参数 {id}
+func (b *openAPIBuilder) splitURL(rawURL string) (string, []openAPIParameter) {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return "", nil
+	}
+
+	parameters := make([]openAPIParameter, 0)
+	seen := make(map[string]struct{})
+	rawURL = templateExprPattern.ReplaceAllStringFunc(rawURL, func(expr string) string {
+		identifiers := identifierPattern.FindAllString(expr, -1)
+		if len(identifiers) == 0 {
+			return ""
+		}
+		name := identifiers[len(identifiers)-1]
+		if _, ok := seen[name]; !ok {
+			seen[name] = struct{}{}
+			parameters = append(parameters, openAPIParameter{Name: name, In: "path", Required: true, Schema: &openAPISchema{Type: "string"}})
+		}
+		return "{" + name + "}"
+	})
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", nil
+	}
+	if (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != "" {
+		b.servers[parsed.Scheme+"://"+parsed.Host] = struct{}{}
+	}
+	path, _ := url.PathUnescape(parsed.EscapedPath())
+	if path == "" && parsed.Host == "" {
+		return "", nil
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	keys := make([]string, 0)
+	query := parsed.Query()
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		parameters = append(parameters, openAPIParameter{Name: key, In: "query", Schema: &openAPISchema{Type: "string", Example: query.Get(key)}})
+	}
+	return path, parameters
+}
+
+// addOperation 写入 paths；网关类接口常共用同一地址，冲突时在路径后追加 #operationId 区分
+func (b *openAPIBuilder) addOperation(path, method string, operation *openAPIOperation) {
+	method = strings.ToLower(method)
+	if _, exists := b.doc.Paths[path][method]; exists {
+		path += "#" + operation.OperationID
+	}
+	if b.doc.Paths[path] == nil {
+		b.doc.Paths[path] = make(map[string]*openAPIOperation)
+	}
+	b.doc.Paths[path][method] = operation
+}
+
+func (b *openAPIBuilder) uniqueOperationID(id string) string {
+	if id == "" {
+		id = "operation"
+	}
+	b.operationIDs[id]++
+	if count := b.operationIDs[id]; count > 1 {
+		return fmt.Sprintf("%s_%d", id, count)
+	}
+	return id
+}
+
+// operationIDFromName 把 "GET /api/user/info" 转为 get_api_user_info
+func operationIDFromName(name string) string {
+	parts := identifierPattern.FindAllString(strings.ReplaceAll(name, "$", ""), -1)
+	return strings.ToLower(strings.Join(parts, "_"))
+}
+
+func schemaToQuery(schema *openAPISchema) []openAPIParameter {
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	required := make(map[string]bool, len(schema.Required))
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+	parameters := make([]openAPIParameter, 0, len(names))
+	for _, name := range names {
+		parameters = append(parameters, openAPIParameter{Name: name, In: "query", Required: required[name], Schema: schema.Properties[name]})
+	}
+	return parameters
+}
+
+// schemaFromJSON 由运行时追踪到的 JSON 请求体推断 schema
+func schemaFromJSON(body string) *openAPISchema {
+	var value interface{}
+	if err := json.Unmarshal([]byte(body), &value); err != nil {
+		return &openAPISchema{Type: "string", Example: body}
+	}
+	return schemaFromValue(value)
+}
+
+func schemaFromValue(value interface{}) *openAPISchema {
+	schema := &openAPISchema{Type: jsonSchemaType(value)}
+	if object, ok := value.(map[string]interface{}); ok {
+		schema.Properties = make(map[string]*openAPISchema, len(object))
+		for key, item := range object {
+			schema.Properties[key] = schemaFromValue(item)
+		}
+	} else if schema.Type != "array" {
+		schema.Example = value
+	}
+	return schema
+}
+
+func jsonSchemaType(value interface{}) string {
+	switch typed := value.(type) {
+	case bool:
+		return "boolean"
+	case int, int64:
+		return "integer"
+	case float64:
+		if typed == float64(int64(typed)) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return "string"
+}
+
+func openAPICallSites(callSites []semantic.APICallSite) []string {
+	results := make([]string, 0, len(callSites))
+	for _, call := range callSites {
+		results = append(results, fmt.Sprintf("%s:%d", call.FilePath, call.LineNumber))
+	}
+	return results
+}
+
+func defaultOpenAPIResponses() map[string]openAPIResponse {
+	return map[string]openAPIResponse{"200": {Description: "OK"}}
+}
@@ -0,0 +1,90 @@
+package reporter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/25smoking/Gwxapkg/internal/scanner"
+	"github.com/25smoking/Gwxapkg/internal/semantic"
+	"github.com/25smoking/Gwxapkg/internal/wxenv"
+)
+
+func TestOpenAPIReporterMergesAPIMapAndScanEndpoints(t *testing.T) {
+	apiMap := &semantic.APIMapReport{Endpoints: []semantic.APIEndpointEntry{
+		{
+			FunctionName: "getECert", ControllerName: "CerInfo", MethodsName: "GetECert", HTTPMethod: "POST",
+			FilePath: "api/cert.js", LineNumber: 3, ParamFields: []string{"page", "userId"},
+			ParamTypes: map[string]string{"page": "integer"},
+			CallSites:  []semantic.APICallSite{{FilePath: "pages/cert/cert.js", LineNumber: 12}},
+			Observed:   []wxenv.Request{{URL: "https://api.shop-wx.com.cn/gateway", Method: "POST", Data: map[string]interface{}{"userId": "1"}}},
+		},
+		{
+			FunctionName: "getUser", ControllerName: "User", MethodsName: "GetUser", HTTPMethod: "POST",
+			URL: "https://api.shop-wx.com.cn/gateway", FilePath: "api/user.js", LineNumber: 5,
+		},
+		{FunctionName: "listNews", ControllerName: "News", MethodsName: "List", FilePath: "api/news.js", ParamFields: []string{"size"}},
+	}}
+	report := &scanner.ScanReport{AppID: "wx-test", APIEndpoints: []scanner.APIEndpoint{
+		{Name: "GET /v1/goods/${item.id}?from=home", Method: "GET", RawURL: "https://mall.shop-wx.com.cn/v1/goods/${item.id}?from=home", FilePath: "pages/goods.js", LineNumber: 8, SourceRule: "url-field"},
+		{Name: "POST /gateway", Method: "POST", RawURL: "https://api.shop-wx.com.cn/gateway", FilePath: "utils/request.js", LineNumber: 2},
+		{Name: "UNKNOWN /api/banner", Method: "UNKNOWN", RawURL: "/api/banner", FilePath: "app.js", LineNumber: 1, Body: `{"pos":1}`},
+	}}
+
+	path := filepath.Join(t.TempDir(), OpenAPIFileName)
+	if err := NewOpenAPIReporter().Generate(report, apiMap, path); err != nil {
+		t.Fatalf("Generate 返回错误: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("读取 openapi.yaml 失败: %v", err)
+	}
+	var doc openAPIDocument
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("openapi.yaml 无法解析: %v", err)
+	}
+
+	if doc.OpenAPI != "3.0.3" || len(doc.Servers) != 2 || doc.Servers[0].URL != "https://api.shop-wx.com.cn" {
+		t.Fatalf("servers 不正确: %#v", doc.Servers)
+	}
+	cert := doc.Paths["/gateway"]["post"]
+	if cert == nil || cert.OperationID != "CerInfo_GetECert" || cert.Tags[0] != "CerInfo" {
+		t.Fatalf("getECert 应使用追踪到的地址: %#v", doc.Paths)
+	}
+	if cert.Source.File != "api/cert.js" || cert.Source.Line != 3 || cert.Source.CallSites[0] != "pages/cert/cert.js:12" {
+		t.Fatalf("x-source 不正确: %#v", cert.Source)
+	}
+	schema := cert.RequestBody.Content["application/json"].Schema
+	if schema.Properties["page"].Type != "integer" || schema.Properties["userId"].Example != "1" || schema.Properties["methodsName"].Enum[0] != "GetECert" {
+		t.Fatalf("请求体 schema 不正确: %#v", schema.Properties)
+	}
+	if user := doc.Paths["/gateway#User_GetUser"]["post"]; user == nil {
+		t.Fatalf("共用网关地址的接口应以 #operationId 区分: %v", mapKeys(doc.Paths))
+	}
+	news := doc.Paths["/News/List"]["get"]
+	if news == nil || len(news.Parameters) != 3 || news.Description == "" {
+		t.Fatalf("无 URL 的 GET 接口应使用占位路径与查询参数: %#v", news)
+	}
+
+	goods := doc.Paths["/v1/goods/{id}"]["get"]
+	if goods == nil || goods.Parameters[0].In != "path" || goods.Parameters[1].Name != "from" || goods.Source.Rule != "url-field" {
+		t.Fatalf("模板路径应转为路径参数: %#v", goods)
+	}
+	banner := doc.Paths["/api/banner"]["get"]
+	if banner == nil || banner.RequestBody.Content["application/json"].Schema.Properties["pos"].Type != "integer" {
+		t.Fatalf("未知方法按 GET 输出，追踪到的请求体应推断类型: %#v", banner)
+	}
+	if len(doc.Paths["/gateway"]) != 1 {
+		t.Fatalf("扫描结果与 api_map 重复的 method + path 不应再输出: %#v", doc.Paths["/gateway"])
+	}
+}
+
+func mapKeys(paths map[string]map[string]*openAPIOperation) []string {
+	keys := make([]string, 0, len(paths))
+	for key := range paths {
+		keys = append(keys, key)
+	}
+	return keys
+}
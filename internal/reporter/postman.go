@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/25smoking/Gwxapkg/internal/scanner"
 )
@@ -18,8 +20,21 @@ func NewPostmanReporter() *PostmanReporter {
 }
 
 type postmanCollection struct {
-	Info postmanInfo   `json:"info"`
-	Item []postmanItem `json:"item"`
+	Info     postmanInfo       `json:"info"`
+	Item     []postmanItem     `json:"item"`
+	Variable []postmanVariable `json:"variable,omitempty"`
+}
+
+type postmanVariable struct {
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+	Enabled bool   `json:"enabled,omitempty"`
+}
+
+type postmanEnvironment struct {
+	Name   string            `json:"name"`
+	Values []postmanVariable `json:"values"`
+	Scope  string            `json:"_postman_variable_scope"`
 }
 
 type postmanInfo struct {
@@ -48,9 +63,24 @@ type postmanScript struct {
 type postmanRequest struct {
 	Method string            `json:"method"`
 	Header []postmanHeader   `json:"header"`
+	Body   *postmanBody      `json:"body,omitempty"`
 	URL    postmanRequestURL `json:"url"`
 }
 
+type postmanBody struct {
+	Mode    string              `json:"mode"`
+	Raw     string              `json:"raw"`
+	Options *postmanBodyOptions `json:"options,omitempty"`
+}
+
+type postmanBodyOptions struct {
+	Raw postmanRawOptions `json:"raw"`
+}
+
+type postmanRawOptions struct {
+	Language string `json:"language"`
+}
+
 type postmanHeader struct {
 	Key   string `json:"key"`
 	Value string `json:"value"`
@@ -68,7 +98,8 @@ type postmanQuery struct {
 	Value string `json:"value"`
 }
 
-// Generate 生成 Postman Collection v2.1
+// Generate 生成 Postman Collection v2.1。相对地址使用 {{baseUrl}} 形式的集合变量，
+// 默认值取生产环境；基础地址区分多个环境时，同目录额外写出每个环境的 Postman Environment。
 func (r *PostmanReporter) Generate(report *scanner.ScanReport, filename string) error {
 	if report == nil {
 		return fmt.Errorf("报告为空")
@@ -82,8 +113,22 @@ func (r *PostmanReporter) Generate(report *scanner.ScanReport, filename string)
 		Item: make([]postmanItem, 0, len(report.APIEndpoints)),
 	}
 
+	groups := scanner.BaseURLGroups(report.BaseURLs)
+	used := make(map[string]map[string]string)
 	for _, endpoint := range report.APIEndpoints {
 		collection.Item = append(collection.Item, buildPostmanItem(endpoint))
+		if envs, ok := groups[endpoint.BaseURLName]; ok {
+			used[endpoint.BaseURLName] = envs
+		}
+	}
+	names := make([]string, 0, len(used))
+	for name := range used {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		envs := used[name]
+		collection.Variable = append(collection.Variable, postmanVariable{Key: name, Value: envs[scanner.PreferredEnvironment(envs)]})
 	}
 
 	data, err := json.MarshalIndent(collection, "", "  ")
@@ -99,6 +144,41 @@ func (r *PostmanReporter) Generate(report *scanner.ScanReport, filename string)
 		return fmt.Errorf("写入 Postman Collection 失败: %w", err)
 	}
 
+	return writePostmanEnvironments(report.AppID, names, used, filename)
+}
+
+// writePostmanEnvironments 为每个环境写出 <集合名>.<环境>.postman_environment.json；
+// 某个变量缺少该环境时沿用其默认值
+func writePostmanEnvironments(appID string, names []string, used map[string]map[string]string, filename string) error {
+	envSet := make(map[string]struct{})
+	for _, envs := range used {
+		for env := range envs {
+			envSet[env] = struct{}{}
+		}
+	}
+	if len(envSet) < 2 {
+		return nil
+	}
+
+	base := strings.TrimSuffix(filename, ".postman_collection.json")
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+	for env := range envSet {
+		environment := postmanEnvironment{Name: appID + " - " + env, Scope: "environment"}
+		for _, name := range names {
+			value, ok := used[name][env]
+			if !ok {
+				value = used[name][scanner.PreferredEnvironment(used[name])]
+			}
+			environment.Values = append(environment.Values, postmanVariable{Key: name, Value: value, Enabled: true})
+		}
+		data, err := json.MarshalIndent(environment, "", "  ")
+		if err != nil {
+			return fmt.Errorf("序列化 Postman Environment 失败: %w", err)
+		}
+		if err := os.WriteFile(base+"."+env+".postman_environment.json", data, 0644); err != nil {
+			return fmt.Errorf("写入 Postman Environment 失败: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -106,12 +186,39 @@ func buildPostmanItem(endpoint scanner.APIEndpoint) postmanItem {
 	requestURL := postmanRequestURL{
 		Raw: endpoint.RawURL,
 	}
+	if endpoint.BaseURLName != "" {
+		path := strings.TrimLeft(scanner.StripBaseURLPrefix(endpoint.RawURL), "/")
+		variable := "{{" + endpoint.BaseURLName + "}}"
+		requestURL.Raw = variable + "/" + path
+		requestURL.Host = []string{variable}
+		if route, _, _ := strings.Cut(path, "?"); route != "" {
+			requestURL.Path = strings.Split(route, "/")
+		}
+	}
+
+	headers := make([]postmanHeader, 0, len(endpoint.Headers))
+	for key, value := range endpoint.Headers {
+		headers = append(headers, postmanHeader{Key: key, Value: value})
+	}
+	sort.Slice(headers, func(i, j int) bool {
+		return headers[i].Key < headers[j].Key
+	})
+
+	var body *postmanBody
+	if endpoint.Body != "" {
+		body = &postmanBody{
+			Mode:    "raw",
+			Raw:     endpoint.Body,
+			Options: &postmanBodyOptions{Raw: postmanRawOptions{Language: "json"}},
+		}
+	}
 
 	return postmanItem{
 		Name: endpoint.Name,
 		Request: postmanRequest{
 			Method: endpoint.Method,
-			Header: []postmanHeader{},
+			Header: headers,
+			Body:   body,
 			URL:    requestURL,
 		},
 	}
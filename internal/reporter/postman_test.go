@@ -0,0 +1,66 @@
+package reporter
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/25smoking/Gwxapkg/internal/scanner"
+)
+
+func TestPostmanReporterWritesBaseURLVariablesPerEnvironment(t *testing.T) {
+	report := &scanner.ScanReport{
+		AppID: "wx-test",
+		BaseURLs: []scanner.BaseURL{
+			{Name: "apiBase", Environment: "develop", URL: "https://dev-api.shop-wx.com.cn"},
+			{Name: "apiBase", Environment: "release", URL: "https://api.shop-wx.com.cn"},
+			{Name: "cdnHost", Environment: scanner.DefaultEnvironment, URL: "https://cdn.shop-wx.com.cn"},
+		},
+		APIEndpoints: []scanner.APIEndpoint{
+			{Name: "GET /user/info", Method: "GET", RawURL: "/user/info?id=1", BaseURLName: "apiBase", FilePath: "api/user.js"},
+			{Name: "GET /a.png", Method: "GET", RawURL: "${cdnHost}/a.png", BaseURLName: "cdnHost", FilePath: "api/cdn.js"},
+		},
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "api_collection.postman_collection.json")
+	if err := NewPostmanReporter().Generate(report, path); err != nil {
+		t.Fatalf("Generate 返回错误: %v", err)
+	}
+
+	var collection postmanCollection
+	readPostmanJSON(t, path, &collection)
+	if len(collection.Variable) != 2 || collection.Variable[0].Value != "https://api.shop-wx.com.cn" {
+		t.Fatalf("集合变量应默认使用生产环境: %#v", collection.Variable)
+	}
+	var requestURL postmanRequestURL
+	for _, item := range collection.Item {
+		if len(item.Request.URL.Host) > 0 && item.Request.URL.Host[0] == "{{apiBase}}" {
+			requestURL = item.Request.URL
+		}
+	}
+	if requestURL.Raw != "{{apiBase}}/user/info?id=1" || len(requestURL.Path) != 2 {
+		t.Fatalf("相对地址应使用基础地址变量: %#v", requestURL)
+	}
+
+	var develop postmanEnvironment
+	readPostmanJSON(t, filepath.Join(dir, "api_collection.develop.postman_environment.json"), &develop)
+	if len(develop.Values) != 2 || develop.Values[0].Value != "https://dev-api.shop-wx.com.cn" || develop.Values[1].Value != "https://cdn.shop-wx.com.cn" {
+		t.Fatalf("develop 环境变量不正确: %#v", develop.Values)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "api_collection.release.postman_environment.json")); err != nil {
+		t.Fatalf("应为 release 环境写出 Environment: %v", err)
+	}
+}
+
+func readPostmanJSON(t *testing.T, path string, target interface{}) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("读取 %s 失败: %v", path, err)
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		t.Fatalf("%s 无法解析: %v", path, err)
+	}
+}
@@ -0,0 +1,320 @@
+package reporter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/25smoking/Gwxapkg/internal/scanner"
+)
+
+const (
+	sarifVersion        = "2.1.0"
+	sarifSchema         = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifInformationURI = "https://github.com/25smoking/Gwxapkg"
+	sarifSourceRoot     = "SRCROOT"
+	sarifFingerprintKey = "gwxapkgFinding/v1"
+
+	// obfuscatedRuleID 混淆文件不对应扫描规则，统一挂在该虚拟规则下
+	obfuscatedRuleID = "gwxapkg/obfuscated-file"
+)
+
+// SARIFReporter 生成 SARIF 2.1.0 格式的扫描报告，供 CI 与代码扫描平台导入。
+type SARIFReporter struct{}
+
+// NewSARIFReporter 创建 SARIF 报告生成器。
+func NewSARIFReporter() *SARIFReporter {
+	return &SARIFReporter{}
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool       sarifTool              `json:"tool"`
+	Results    []sarifResult          `json:"results"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string                 `json:"id"`
+	Name                 string                 `json:"name,omitempty"`
+	ShortDescription     sarifMessage           `json:"shortDescription"`
+	FullDescription      *sarifMessage          `json:"fullDescription,omitempty"`
+	Help                 *sarifMessage          `json:"help,omitempty"`
+	DefaultConfiguration sarifRuleConfig        `json:"defaultConfiguration"`
+	Properties           map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifRuleConfig struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations,omitempty"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+	Properties          map[string]string `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactID `json:"artifactLocation"`
+	Region           *sarifRegion    `json:"region,omitempty"`
+}
+
+type sarifArtifactID struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine int           `json:"startLine"`
+	Snippet   *sarifMessage `json:"snippet,omitempty"`
+}
+
+// Generate 将敏感信息扫描报告写出为 SARIF。
+func (r *SARIFReporter) Generate(report *scanner.ScanReport, filename string) error {
+	if report == nil {
+		return fmt.Errorf("报告为空")
+	}
+	data, err := json.MarshalIndent(buildSARIFLog(report), "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化 SARIF 报告失败: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return fmt.Errorf("创建输出目录失败: %w", err)
+	}
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("写入 SARIF 报告失败: %w", err)
+	}
+	return nil
+}
+
+func buildSARIFLog(report *scanner.ScanReport) *sarifLog {
+	var rules []sarifRule
+	ruleIndex := make(map[string]int)
+	indexOf := func(ruleID string, build func() sarifRule) int {
+		if index, ok := ruleIndex[ruleID]; ok {
+			return index
+		}
+		ruleIndex[ruleID] = len(rules)
+		rules = append(rules, build())
+		return len(rules) - 1
+	}
+
+	results := make([]sarifResult, 0, len(report.Items)+len(report.ObfuscatedFiles))
+	for _, item := range report.Items {
+		ruleID := strings.TrimSpace(item.RuleID)
+		if ruleID == "" {
+			ruleID = "unknown"
+		}
+		confidence := item.Confidence
+		if confidence == "" {
+			confidence = scanner.GetConfidence(ruleID)
+		}
+		index := indexOf(ruleID, func() sarifRule { return buildSARIFRule(ruleID, item) })
+
+		ruleName := item.RuleName
+		if ruleName == "" {
+			ruleName = scanner.GetRuleName(ruleID)
+		}
+		results = append(results, sarifResult{
+			RuleID:    ruleID,
+			RuleIndex: index,
+			Level:     sarifLevel(confidence),
+			Message:   sarifMessage{Text: fmt.Sprintf("%s: %s", ruleName, maskSARIFContent(item.Content))},
+			Locations: sarifLocations(item.FilePath, item.LineNumber, maskSARIFSnippet(item.Context, item.Content)),
+			PartialFingerprints: map[string]string{
+				sarifFingerprintKey: sarifFingerprint(ruleID, item.FilePath, item.Content),
+			},
+			Properties: sarifItemProperties(confidence, item),
+		})
+	}
+
+	for _, file := range report.ObfuscatedFiles {
+		index := indexOf(obfuscatedRuleID, func() sarifRule {
+			return sarifRule{
+				ID:                   obfuscatedRuleID,
+				Name:                 "ObfuscatedFile",
+				ShortDescription:     sarifMessage{Text: "疑似混淆的 JavaScript 文件"},
+				DefaultConfiguration: sarifRuleConfig{Level: "note"},
+				Properties:           map[string]interface{}{"category": "obfuscation"},
+			}
+		})
+		message := fmt.Sprintf("疑似混淆文件（评分 %d，状态 %s）", file.Score, file.Status)
+		if len(file.Techniques) > 0 {
+			message += ": " + strings.Join(file.Techniques, ", ")
+		}
+		results = append(results, sarifResult{
+			RuleID:    obfuscatedRuleID,
+			RuleIndex: index,
+			Level:     "note",
+			Message:   sarifMessage{Text: message},
+			Locations: sarifLocations(file.FilePath, 0, ""),
+			PartialFingerprints: map[string]string{
+				sarifFingerprintKey: sarifFingerprint(obfuscatedRuleID, file.FilePath, ""),
+			},
+		})
+	}
+
+	if rules == nil {
+		rules = []sarifRule{}
+	}
+	return &sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "Gwxapkg",
+				InformationURI: sarifInformationURI,
+				Rules:          rules,
+			}},
+			Results: results,
+			Properties: map[string]interface{}{
+				"appId":        report.AppID,
+				"scanTime":     report.ScanTime,
+				"totalFiles":   report.TotalFiles,
+				"apiEndpoints": len(report.APIEndpoints),
+			},
+		}},
+	}
+}
+
+func sarifItemProperties(confidence string, item scanner.SensitiveItem) map[string]string {
+	properties := map[string]string{
+		"confidence": confidence,
+		"content":    maskSARIFContent(item.Content),
+	}
+	properties["contentHash"] = item.ContentHash
+	if item.ContentHash == "" {
+		properties["contentHash"] = scanner.ContentHash(item.Content)
+	}
+	if item.Verification != "" {
+		properties["verification"] = item.Verification
+		properties["verificationDetail"] = item.VerificationDetail
+	}
+	return properties
+}
+
+func buildSARIFRule(ruleID string, item scanner.SensitiveItem) sarifRule {
+	category := item.Category
+	if category == "" {
+		category = scanner.GetCategoryKey(ruleID)
+	}
+	name := item.RuleName
+	if name == "" {
+		name = scanner.GetRuleName(ruleID)
+	}
+	confidence := item.Confidence
+	if confidence == "" {
+		confidence = scanner.GetConfidence(ruleID)
+	}
+	rule := sarifRule{
+		ID:                   ruleID,
+		Name:                 name,
+		ShortDescription:     sarifMessage{Text: fmt.Sprintf("%s（%s）", name, scanner.GetCategoryName(category))},
+		DefaultConfiguration: sarifRuleConfig{Level: sarifLevel(confidence)},
+		Properties: map[string]interface{}{
+			"category":      category,
+			"category_name": scanner.GetCategoryName(category),
+			"confidence":    confidence,
+			"tags":          []string{"security", category},
+		},
+	}
+	// 规则包提供的描述与修复建议
+	if item.Description != "" {
+		rule.FullDescription = &sarifMessage{Text: item.Description}
+	}
+	if item.Remediation != "" {
+		rule.Help = &sarifMessage{Text: item.Remediation}
+	}
+	return rule
+}
+
+// sarifLevel 把规则可信度映射为 SARIF 级别
+func sarifLevel(confidence string) string {
+	switch confidence {
+	case "high":
+		return "error"
+	case "medium":
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// sarifLocations SARIF 要求 startLine 从 1 开始，行号未知时只给出文件
+func sarifLocations(filePath string, line int, snippet string) []sarifLocation {
+	if filePath == "" {
+		return nil
+	}
+	location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactID{
+			URI:       strings.TrimPrefix(filepath.ToSlash(filePath), "/"),
+			URIBaseID: sarifSourceRoot,
+		},
+	}}
+	if line > 0 {
+		region := &sarifRegion{StartLine: line}
+		if snippet = strings.TrimSpace(snippet); snippet != "" {
+			region.Snippet = &sarifMessage{Text: snippet}
+		}
+		location.PhysicalLocation.Region = region
+	}
+	return []sarifLocation{location}
+}
+
+// maskSARIFContent SARIF 会被上传到代码扫描平台或作为 CI 产物保留，只保留首尾少量字符
+func maskSARIFContent(content string) string {
+	runes := []rune(content)
+	keep := 4
+	if len(runes) <= 12 {
+		keep = len(runes) / 4
+	}
+	if keep == 0 {
+		return strings.Repeat("*", len(runes))
+	}
+	return string(runes[:keep]) + strings.Repeat("*", len(runes)-2*keep) + string(runes[len(runes)-keep:])
+}
+
+// maskSARIFSnippet 把上下文中出现的原始值替换为脱敏后的值
+func maskSARIFSnippet(snippet, content string) string {
+	if content == "" {
+		return snippet
+	}
+	return strings.ReplaceAll(snippet, content, maskSARIFContent(content))
+}
+
+// sarifFingerprint 不含行号，代码格式化导致行号漂移时同一发现仍能被平台识别为同一条
+func sarifFingerprint(ruleID, filePath, content string) string {
+	sum := sha256.Sum256([]byte(ruleID + "\x00" + filepath.ToSlash(filePath) + "\x00" + content))
+	return hex.EncodeToString(sum[:])
+}
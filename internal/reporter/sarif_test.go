@@ -0,0 +1,79 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/25smoking/Gwxapkg/internal/scanner"
+)
+
+func TestSARIFReporterMapsFindingsAndObfuscatedFiles(t *testing.T) {
+	report := &scanner.ScanReport{
+		AppID:    "wx-test",
+		ScanTime: "2026-05-15 12:00:00",
+		Items: []scanner.SensitiveItem{
+			{RuleID: "aliyun_ak", RuleName: "阿里云 AccessKey", Category: "cloud", Content: "LTAI5tAbCdEfGhIjKlMnOpQr", FilePath: "utils/config.js", LineNumber: 3, Context: "var ak = 'LTAI5tAbCdEfGhIjKlMnOpQr'", Confidence: "high"},
+			{RuleID: "aliyun_ak", RuleName: "阿里云 AccessKey", Category: "cloud", Content: "LTAI1111", FilePath: "app.js", LineNumber: 9, Confidence: "high"},
+			{RuleID: "phone_cn", Category: "contact", Content: "13800000000", FilePath: "pages/a.js"},
+		},
+		ObfuscatedFiles: []scanner.ObfuscatedFile{
+			{FilePath: "vendor.js", Score: 80, Status: "obfuscated", Techniques: []string{"string-array"}},
+		},
+	}
+
+	output := filepath.Join(t.TempDir(), "sensitive_report.sarif")
+	if err := NewSARIFReporter().Generate(report, output); err != nil {
+		t.Fatalf("Generate 返回错误: %v", err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("读取 SARIF 报告失败: %v", err)
+	}
+
+	// SARIF 会被上传到代码扫描平台，不能包含原始凭据
+	if bytes.Contains(data, []byte("LTAI5tAbCdEfGhIjKlMnOpQr")) || bytes.Contains(data, []byte("13800000000")) {
+		t.Fatalf("SARIF 报告不应包含原始命中内容: %s", data)
+	}
+
+	var decoded sarifLog
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("生成内容不是合法 JSON: %v", err)
+	}
+	if decoded.Version != "2.1.0" || len(decoded.Runs) != 1 {
+		t.Fatalf("SARIF 版本或 run 数量错误: %+v", decoded)
+	}
+
+	run := decoded.Runs[0]
+	if len(run.Tool.Driver.Rules) != 3 {
+		t.Fatalf("同一规则应只声明一次: %+v", run.Tool.Driver.Rules)
+	}
+	if len(run.Results) != 4 {
+		t.Fatalf("结果数量错误: %d", len(run.Results))
+	}
+
+	first := run.Results[0]
+	if first.Level != "error" || first.RuleIndex != 0 || run.Results[1].RuleIndex != 0 {
+		t.Fatalf("高可信度发现映射错误: %+v", first)
+	}
+	region := first.Locations[0].PhysicalLocation.Region
+	if first.Locations[0].PhysicalLocation.ArtifactLocation.URI != "utils/config.js" || region == nil || region.StartLine != 3 {
+		t.Fatalf("物理位置错误: %+v", first.Locations)
+	}
+	if first.Message.Text != "阿里云 AccessKey: LTAI****************OpQr" || region.Snippet.Text != "var ak = 'LTAI****************OpQr'" {
+		t.Fatalf("命中内容应保留首尾字符脱敏: %q %q", first.Message.Text, region.Snippet.Text)
+	}
+	if first.PartialFingerprints[sarifFingerprintKey] == run.Results[1].PartialFingerprints[sarifFingerprintKey] {
+		t.Fatalf("不同发现的指纹不应相同")
+	}
+
+	// 行号未知时不能输出 startLine=0
+	if run.Results[2].Locations[0].PhysicalLocation.Region != nil || run.Results[2].Level != "note" {
+		t.Fatalf("缺少行号的发现映射错误: %+v", run.Results[2])
+	}
+	if run.Results[3].RuleID != obfuscatedRuleID || run.Results[3].Level != "note" {
+		t.Fatalf("混淆文件应作为 note 输出: %+v", run.Results[3])
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/25smoking/Gwxapkg/internal/config"
 	"github.com/25smoking/Gwxapkg/internal/enum"
+	"github.com/25smoking/Gwxapkg/internal/session"
 )
 
 type WxapkgDecompiler struct {
@@ -78,15 +79,8 @@ func isPlugin(wxapkg *config.WxapkgInfo) bool {
 	return isAppPlugin(wxapkg) || isGamePlugin(wxapkg)
 }
 
-// OutputDir 输出目录
-var OutputDir string
-
-func (d *WxapkgDecompiler) Decompile(outputDir string) {
-	// 设置输出目录
-	OutputDir = outputDir
-
-	wxapkgManager := config.GetWxapkgManager()
-	for _, wxapkg := range wxapkgManager.Packages {
+func (d *WxapkgDecompiler) Decompile(sess *session.Session) {
+	for _, wxapkg := range sess.Manager.Packages {
 		// log.Println(wxapkg.WxapkgType)
 		switch wxapkg.WxapkgType {
 		case enum.App_V1, enum.App_V4:
@@ -94,25 +88,25 @@ func (d *WxapkgDecompiler) Decompile(outputDir string) {
 				ViewSource:   filepath.Join(wxapkg.SourcePath, enum.PageFrameHtml),
 				SetAppConfig: true,
 			}
-			setApp(wxapkg)
+			setApp(wxapkg, sess)
 		case enum.App_V2, enum.App_V3:
 			wxapkg.Option = &config.WxapkgOption{
 				SetAppConfig: true,
 			}
-			setApp(wxapkg)
+			setApp(wxapkg, sess)
 		case enum.APP_SUBPACKAGE_V1, enum.APP_SUBPACKAGE_V2:
 			wxapkg.Option = &config.WxapkgOption{
 				ViewSource:   filepath.Join(wxapkg.SourcePath, enum.Page_Frame),
 				SetAppConfig: false,
 			}
-			setApp(wxapkg)
+			setApp(wxapkg, sess)
 		case enum.APP_PLUGIN_V1:
 			wxapkg.Option = &config.WxapkgOption{
 				ViewSource:    filepath.Join(wxapkg.SourcePath, enum.PageFrame),
 				ServiceSource: filepath.Join(wxapkg.SourcePath, enum.AppService),
 				SetAppConfig:  false,
 			}
-			setApp(wxapkg)
+			setApp(wxapkg, sess)
 		case enum.GAME:
 		case enum.GAME_SUBPACKAGE:
 		case enum.GAME_PLUGIN:
@@ -120,7 +114,7 @@ func (d *WxapkgDecompiler) Decompile(outputDir string) {
 	}
 }
 
-func setApp(wxapkg *config.WxapkgInfo) {
+func setApp(wxapkg *config.WxapkgInfo, sess *session.Session) {
 	// 如果未解压，则不进行解析
 	if !wxapkg.IsExtracted {
 		return
@@ -150,22 +144,20 @@ func setApp(wxapkg *config.WxapkgInfo) {
 		wxapkg.Parsers = append(wxapkg.Parsers, &unpack.ConfigParser{})
 	}
 
-	wxapkg.Parsers = append(wxapkg.Parsers, &unpack.JavaScriptParser{OutputDir: OutputDir})
-	wxapkg.Parsers = append(wxapkg.Parsers, &unpack.XssParser{OutputDir: OutputDir})
+	outputDir := sess.OutputDir
+	wxapkg.Parsers = append(wxapkg.Parsers, &unpack.JavaScriptParser{OutputDir: outputDir})
+	wxapkg.Parsers = append(wxapkg.Parsers, &unpack.XssParser{OutputDir: outputDir, Deletions: sess.Deletions})
 	if isParserV1(wxapkg) {
-		wxapkg.Parsers = append(wxapkg.Parsers, &unpack.XmlParser{OutputDir: OutputDir, Version: "v1"})
+		wxapkg.Parsers = append(wxapkg.Parsers, &unpack.XmlParser{OutputDir: outputDir, Version: "v1"})
 	} else if isParserV2(wxapkg) {
-		wxapkg.Parsers = append(wxapkg.Parsers, &unpack.XmlParser{OutputDir: OutputDir, Version: "v2"})
+		wxapkg.Parsers = append(wxapkg.Parsers, &unpack.XmlParser{OutputDir: outputDir, Version: "v2"})
 	}
 
 	// 清除无用文件
-	cleanApp(wxapkg.SourcePath)
+	cleanApp(wxapkg.SourcePath, sess.Deletions)
 }
 
-func cleanApp(path string) {
-	// 创建文件删除管理器
-	manager := config.NewFileDeletionManager()
-
+func cleanApp(path string, manager *config.FileDeletionManager) {
 	// 删除相关的JS文件, unlinks
 	unlinks := []string{
 		//".appservice.js",
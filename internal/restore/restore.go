@@ -9,6 +9,7 @@ import (
 	"github.com/25smoking/Gwxapkg/internal/enum"
 
 	"github.com/25smoking/Gwxapkg/internal/config"
+	"github.com/25smoking/Gwxapkg/internal/session"
 	"github.com/25smoking/Gwxapkg/internal/unpack"
 )
 
@@ -17,7 +18,11 @@ func fixSubpackageDir(wxapkg *config.WxapkgInfo, outputDir string) string {
 	var e struct {
 		SubPackages []unpack.SubPackage `json:"subPackages"`
 	}
-	content, _ := os.ReadFile(filepath.Join(outputDir, enum.App_Config))
+	content, err := os.ReadFile(filepath.Join(outputDir, enum.App_Config))
+	if err != nil {
+		// 增量处理只解新分包时，主包的 app-config.json 已在上次还原后清理，改读还原出的 app.json
+		content, _ = os.ReadFile(filepath.Join(outputDir, enum.AppJson))
+	}
 	_ = json.Unmarshal(content, &e)
 
 	for _, subPackage := range e.SubPackages {
@@ -33,42 +38,32 @@ func fixSubpackageDir(wxapkg *config.WxapkgInfo, outputDir string) string {
 	return ""
 }
 
-// ProjectStructure 是否还原工程目录结构
-func ProjectStructure(outputDir string, restoreDir bool) {
+// ProjectStructure 是否还原工程目录结构，只处理 sess.Manager 中登记的包
+func ProjectStructure(sess *session.Session, restoreDir bool) {
 	if !restoreDir {
 		return
 	}
 
-	configManager := config.NewSharedConfigManager()
-
-	// 创建文件删除管理器
-	manager := config.NewFileDeletionManager()
-
 	defer func() {
-		if noClean, ok := configManager.Get("noClean"); ok {
-			if !noClean.(bool) {
-				// 执行删除文件操作
-				manager.DeleteFiles()
-			}
+		if !sess.NoClean {
+			// 执行删除文件操作
+			sess.Deletions.DeleteFiles()
 		}
 	}()
 
-	// 包管理器
-	wxakpgManager := config.GetWxapkgManager()
-
 	// 修正子包目录
-	for _, wxapkg := range wxakpgManager.Packages {
+	for _, wxapkg := range sess.Manager.Packages {
 		if IsSubpackage(wxapkg) {
-			wxapkg.SourcePath = fixSubpackageDir(wxapkg, outputDir)
+			wxapkg.SourcePath = fixSubpackageDir(wxapkg, sess.OutputDir)
 		}
 	}
 
 	// 反编译
 	decompiler := new(WxapkgDecompiler)
 	// 执行反编译操作
-	decompiler.Decompile(outputDir)
+	decompiler.Decompile(sess)
 
 	// 创建命令执行器, 执行解析器
-	executor := NewCommandExecutor(wxakpgManager)
+	executor := NewCommandExecutor(sess.Manager)
 	executor.ExecuteAll()
 }
@@ -18,6 +18,7 @@ var (
 	versionPathPattern = regexp.MustCompile(`^v\d+/`)
 	axiosMethodPattern = regexp.MustCompile(`(?is)\b(?:axios|\$http|this\.\$http)\.(get|post|put|delete|patch|head|options)\b`)
 	fetchCallPattern   = regexp.MustCompile(`(?is)\bfetch\s*\(`)
+	baseTemplatePrefix = regexp.MustCompile(`^\$\{[^}]+\}/`)
 )
 
 var apiExtractors = []apiRegexExtractor{
@@ -88,6 +89,21 @@ var apiExtractors = []apiRegexExtractor{
 			return buildEndpoint(filePath, text, match[0], match[1], method, rawURL, "generic-method-call"), true
 		},
 	},
+	{
+		// url: baseUrl + "/user/info" 记为 ${baseUrl}/user/info，生成报告时按基础地址补全
+		sourceRule: "url-concat",
+		pattern:    regexp.MustCompile(`(?is)\burl\s*:\s*([A-Za-z_$][\w$.]*)\s*\+\s*["'` + "`" + `](/[^"'` + "`" + `]*)["'` + "`" + `]`),
+		handler: func(filePath, text string, match []int) (APIEndpoint, bool) {
+			groups := extractGroups(text, match)
+			if len(groups) < 3 {
+				return APIEndpoint{}, false
+			}
+			rawURL := "${" + groups[1] + "}" + strings.TrimSpace(groups[2])
+			context := collectContextWindow(text, match[0], match[1])
+			method := inferMethodFromContext(context)
+			return buildEndpoint(filePath, text, match[0], match[1], method, rawURL, "url-concat"), true
+		},
+	},
 	{
 		sourceRule: "url-field",
 		pattern:    regexp.MustCompile(`(?is)\burl\s*:\s*["'` + "`" + `]((?:https?://|/|(?:api|v\d+)/)[^"'` + "`" + `]*)["'` + "`" + `]`),
@@ -153,6 +169,11 @@ func buildEndpoint(filePath, text string, start, end int, method, rawURL, source
 	}
 }
 
+// APIName 返回接口的展示名称（方法 + 路径），与静态提取的命名保持一致
+func APIName(method, rawURL string) string {
+	return buildAPIName(method, rawURL)
+}
+
 func buildAPIName(method, rawURL string) string {
 	display := rawURL
 	if parsed, err := url.Parse(rawURL); err == nil && parsed.Path != "" {
@@ -198,7 +219,7 @@ func isLikelyAPIURL(rawURL string) bool {
 		return true
 	}
 
-	if strings.HasPrefix(value, "/") {
+	if strings.HasPrefix(value, "/") || baseTemplatePrefix.MatchString(value) {
 		return true
 	}
 
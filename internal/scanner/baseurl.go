@@ -0,0 +1,574 @@
+package scanner
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultEnvironment 未区分环境的基础地址使用的环境名
+const DefaultEnvironment = "default"
+
+// maxBaseURLExpression 超过该长度的赋值表达式不做解析，避免在压缩代码中扫描整段语句
+const maxBaseURLExpression = 512
+
+var (
+	baseURLNamePattern = regexp.MustCompile(`(?i)^(?:[a-z0-9]+_)*(?:base_?(?:url|uri|api|path|host|domain)?|api_?(?:url|uri|host|base|root|server|domain|prefix|path)?|host(?:_?url|name)?|domain(?:_?url)?|server(?:_?url|_?host|_?address)?|root_?url|request_?(?:url|host|base)|http_?(?:url|host))$`)
+	assignmentPattern  = regexp.MustCompile(`["']?([A-Za-z_$][\w$]*)["']?\s*(?::|=)`)
+	envKeyPattern      = regexp.MustCompile(`(?i)["']?\b(development|develop|dev|testing|test|sit|uat|preview|pre|staging|stage|production|prod|release|online|local|trial)\b["']?\s*:`)
+	envKeywordPattern  = regexp.MustCompile(`(?i)(development|develop|dev|debug|testing|test|sit|uat|preview|pre|staging|stage|production|prod|release|online|local|trial)`)
+	envContainerName   = regexp.MustCompile(`(?i)(url|uri|host|api|server|domain|base|env|config)`)
+	urlConstantPattern = regexp.MustCompile(`["']?([A-Za-z_$][\w$]*)["']?\s*(?::|=)\s*["'` + "`" + `]((?:https?:)?//[^"'` + "`" + `\s]+|/[^"'` + "`" + `\s]*)["'` + "`" + `]`)
+	identifierPattern  = regexp.MustCompile(`[A-Za-z_$][\w$]*`)
+	memberRefPattern   = regexp.MustCompile(`^[A-Za-z_$][\w$]*(?:\.[A-Za-z_$][\w$]*)*$`)
+	leadingTemplate    = regexp.MustCompile(`^\$\{([^}]*)\}`)
+	templateRefPattern = regexp.MustCompile(`\$\{([^}]*)\}`)
+)
+
+// baseURLDefinition 是一次基础地址赋值，Expression 尚未解析
+type baseURLDefinition struct {
+	Name        string
+	Environment string
+	Expression  string
+	FilePath    string
+	LineNumber  int
+}
+
+// baseURLSource 单个文件中的基础地址赋值与可用于拼接的地址常量
+type baseURLSource struct {
+	definitions []baseURLDefinition
+	constants   map[string]string
+}
+
+// extractBaseURLSource 提取 baseUrl / HOST 等赋值、按 dev / test / prod 区分的环境表，以及 URL / 路径字符串常量
+func extractBaseURLSource(filePath, text string) baseURLSource {
+	source := baseURLSource{constants: make(map[string]string)}
+	for _, match := range urlConstantPattern.FindAllStringSubmatch(text, -1) {
+		if _, exists := source.constants[match[1]]; !exists {
+			source.constants[match[1]] = match[2]
+		}
+	}
+
+	add := func(name, env, expression string, offset int) {
+		expression = strings.TrimSpace(expression)
+		if expression == "" || len(expression) > maxBaseURLExpression {
+			return
+		}
+		source.definitions = append(source.definitions, baseURLDefinition{
+			Name:        name,
+			Environment: strings.ToLower(env),
+			Expression:  expression,
+			FilePath:    filePath,
+			LineNumber:  lineNumberAtOffset(text, offset),
+		})
+	}
+
+	for _, match := range assignmentPattern.FindAllStringSubmatchIndex(text, -1) {
+		name := text[match[2]:match[3]]
+		if isBaseURLName(name) && isAssignment(text, match[0], match[1]) {
+			add(name, "", scanExpression(text, match[1]), match[0])
+		}
+	}
+
+	for _, match := range envKeyPattern.FindAllStringSubmatchIndex(text, -1) {
+		env := text[match[2]:match[3]]
+		valueStart := match[1]
+		for valueStart < len(text) && (text[valueStart] == ' ' || text[valueStart] == '\t') {
+			valueStart++
+		}
+		if valueStart < len(text) && text[valueStart] == '{' {
+			end := matchingBrace(text, valueStart)
+			if end < 0 {
+				continue
+			}
+			for _, inner := range assignmentPattern.FindAllStringSubmatchIndex(text[valueStart:end], -1) {
+				start, stop := valueStart+inner[0], valueStart+inner[1]
+				name := text[valueStart+inner[2] : valueStart+inner[3]]
+				if isBaseURLName(name) && isAssignment(text, start, stop) {
+					add(name, env, scanExpression(text[:end], stop), start)
+				}
+			}
+			continue
+		}
+		add(envContainer(text, match[0]), env, scanExpression(text, valueStart), match[0])
+	}
+	return source
+}
+
+// isBaseURLName 判断变量名是否像基础地址：baseUrl、API_HOST，或 uploadHost 这类驼峰前缀
+func isBaseURLName(name string) bool {
+	if baseURLNamePattern.MatchString(name) {
+		return true
+	}
+	for i := 1; i < len(name); i++ {
+		if name[i] >= 'A' && name[i] <= 'Z' && name[i-1] >= 'a' && name[i-1] <= 'z' && baseURLNamePattern.MatchString(name[i:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAssignment 排除 ==、=>、三元表达式等看似赋值的位置
+func isAssignment(text string, start, end int) bool {
+	operator := text[end-1]
+	if operator == '=' && end < len(text) && (text[end] == '=' || text[end] == '>') {
+		return false
+	}
+	if operator == '=' && start > 0 && strings.ContainsRune("=!<>+-*/%&|^", rune(text[start-1])) {
+		return false
+	}
+
+	prefix := strings.TrimRight(text[max(0, start-16):start], " \t")
+	if prefix == "" {
+		return true
+	}
+	switch prefix[len(prefix)-1] {
+	case '{', ',', ';', '(', '\n', '\r':
+		return true
+	case '.':
+		return operator == '='
+	}
+	for _, keyword := range []string{"var", "let", "const"} {
+		if strings.HasSuffix(prefix, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// envContainer 返回环境表所在对象的变量名；变量名看不出用途时按 baseUrl 处理
+func envContainer(text string, offset int) string {
+	depth := 0
+	for i := offset - 1; i >= 0; i-- {
+		switch text[i] {
+		case '}':
+			depth++
+		case '{':
+			if depth > 0 {
+				depth--
+				continue
+			}
+			before := strings.TrimRight(text[:i], " \t\r\n")
+			before = strings.TrimRight(strings.TrimSuffix(strings.TrimSuffix(before, "="), ":"), " \t\r\n")
+			start := len(before)
+			for start > 0 && isIdentifierByte(before[start-1]) {
+				start--
+			}
+			if name := before[start:]; name != "" && envContainerName.MatchString(name) {
+				return name
+			}
+			return "baseUrl"
+		}
+	}
+	return "baseUrl"
+}
+
+func isIdentifierByte(ch byte) bool {
+	return ch == '_' || ch == '$' || ch >= '0' && ch <= '9' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z'
+}
+
+// scanExpression 截取从 start 开始、到顶层 , ; 换行或外层闭括号为止的表达式
+func scanExpression(text string, start int) string {
+	depth := 0
+	var quote byte
+	for i := start; i < len(text) && i-start <= maxBaseURLExpression; i++ {
+		ch := text[i]
+		if quote != 0 {
+			if ch == '\\' {
+				i++
+			} else if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch ch {
+		case '"', '\'', '`':
+			quote = ch
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			if depth == 0 {
+				return text[start:i]
+			}
+			depth--
+		case ',', ';', '\n':
+			if depth == 0 {
+				return text[start:i]
+			}
+		}
+	}
+	return text[start:min(len(text), start+maxBaseURLExpression+1)]
+}
+
+func matchingBrace(text string, open int) int {
+	depth := 0
+	var quote byte
+	for i := open; i < len(text); i++ {
+		ch := text[i]
+		if quote != 0 {
+			if ch == '\\' {
+				i++
+			} else if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch ch {
+		case '"', '\'', '`':
+			quote = ch
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitTopLevel 按不在括号与字符串内的分隔符切分
+func splitTopLevel(text string, separator byte) []string {
+	parts := make([]string, 0, 2)
+	depth, last := 0, 0
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		ch := text[i]
+		if quote != 0 {
+			if ch == '\\' {
+				i++
+			} else if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch ch {
+		case '"', '\'', '`':
+			quote = ch
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case separator:
+			if depth == 0 {
+				parts = append(parts, text[last:i])
+				last = i + 1
+			}
+		}
+	}
+	return append(parts, text[last:])
+}
+
+// splitTernary 拆分顶层的 cond ? yes : no
+func splitTernary(expression string) (string, string, string, bool) {
+	question, nested := -1, 0
+	depth := 0
+	var quote byte
+	for i := 0; i < len(expression); i++ {
+		ch := expression[i]
+		if quote != 0 {
+			if ch == '\\' {
+				i++
+			} else if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch ch {
+		case '"', '\'', '`':
+			quote = ch
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case '?':
+			if depth != 0 || i+1 < len(expression) && (expression[i+1] == '.' || expression[i+1] == '?') || i > 0 && expression[i-1] == '?' {
+				continue
+			}
+			if question < 0 {
+				question = i
+			} else {
+				nested++
+			}
+		case ':':
+			if depth != 0 || question < 0 {
+				continue
+			}
+			if nested > 0 {
+				nested--
+				continue
+			}
+			return expression[:question], expression[question+1 : i], expression[i+1:], true
+		}
+	}
+	return "", "", "", false
+}
+
+// resolveURLExpression 解析字符串字面量、模板字符串与 + 拼接，标识符从 constants 中取值
+func resolveURLExpression(expression string, constants map[string]string) (string, bool) {
+	expression = strings.TrimSpace(expression)
+	for strings.HasPrefix(expression, "(") && strings.HasSuffix(expression, ")") {
+		expression = strings.TrimSpace(expression[1 : len(expression)-1])
+	}
+
+	var builder strings.Builder
+	for _, part := range splitTopLevel(expression, '+') {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return "", false
+		}
+		switch quote := part[0]; {
+		case quote == '"' || quote == '\'' || quote == '`':
+			if len(part) < 2 || part[len(part)-1] != quote {
+				return "", false
+			}
+			value := part[1 : len(part)-1]
+			if quote == '`' {
+				resolved := true
+				value = templateRefPattern.ReplaceAllStringFunc(value, func(ref string) string {
+					constant, ok := lookupConstant(ref[2:len(ref)-1], constants)
+					resolved = resolved && ok
+					return constant
+				})
+				if !resolved {
+					return "", false
+				}
+			}
+			builder.WriteString(value)
+		default:
+			value, ok := lookupConstant(part, constants)
+			if !ok {
+				return "", false
+			}
+			builder.WriteString(value)
+		}
+	}
+	return builder.String(), true
+}
+
+func lookupConstant(ref string, constants map[string]string) (string, bool) {
+	ref = strings.TrimSpace(ref)
+	if !memberRefPattern.MatchString(ref) {
+		return "", false
+	}
+	value, ok := constants[ref[strings.LastIndex(ref, ".")+1:]]
+	return value, ok
+}
+
+func isAbsoluteURL(value string) bool {
+	for _, scheme := range []string{"https://", "http://"} {
+		if strings.HasPrefix(value, scheme) && len(value) > len(scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// expandTernaries 把 isDev ? a : b 形式的赋值拆成两个环境；条件里看不出环境时只保留第一个分支
+func expandTernaries(definitions []baseURLDefinition) []baseURLDefinition {
+	results := make([]baseURLDefinition, 0, len(definitions))
+	for _, definition := range definitions {
+		cond, yes, no, ok := splitTernary(definition.Expression)
+		if !ok || definition.Environment != "" {
+			results = append(results, definition)
+			continue
+		}
+		first := strings.ToLower(envKeywordPattern.FindString(cond))
+		if first == "" {
+			definition.Expression = yes
+			results = append(results, definition)
+			continue
+		}
+		if first == "debug" {
+			first = "dev"
+		}
+		second := "prod"
+		if isProductionEnvironment(first) {
+			second = "dev"
+		}
+		if strings.HasPrefix(strings.TrimSpace(cond), "!") || strings.Contains(cond, "!=") {
+			first, second = second, first
+		}
+		yesDefinition, noDefinition := definition, definition
+		yesDefinition.Environment, yesDefinition.Expression = first, yes
+		noDefinition.Environment, noDefinition.Expression = second, no
+		results = append(results, yesDefinition, noDefinition)
+	}
+	return results
+}
+
+func isProductionEnvironment(env string) bool {
+	switch strings.ToLower(env) {
+	case "prod", "production", "release", "online":
+		return true
+	}
+	return false
+}
+
+// resolveBaseURLs 解析全部赋值，得到各变量在各环境下的完整基础地址
+func resolveBaseURLs(definitions []baseURLDefinition, constants map[string]string) []BaseURL {
+	definitions = expandTernaries(definitions)
+	known := make(map[string]string, len(constants))
+	for name, value := range constants {
+		known[name] = value
+	}
+
+	resolved := make([]BaseURL, 0)
+	done := make([]bool, len(definitions))
+	// 基础地址之间可能互相拼接（API = HOST + "/api"），多轮解析直到没有新结果
+	for pass := 0; pass < 3; pass++ {
+		progress := false
+		for i, definition := range definitions {
+			if done[i] {
+				continue
+			}
+			value, ok := resolveURLExpression(definition.Expression, known)
+			if !ok || !isAbsoluteURL(value) {
+				continue
+			}
+			done[i], progress = true, true
+			if _, exists := known[definition.Name]; !exists && definition.Environment == "" {
+				known[definition.Name] = value
+			}
+			resolved = append(resolved, BaseURL{
+				Name:        definition.Name,
+				Environment: definition.Environment,
+				URL:         value,
+				FilePath:    definition.FilePath,
+				LineNumber:  definition.LineNumber,
+			})
+		}
+		if !progress {
+			break
+		}
+	}
+
+	// 环境表内的赋值同时会被当作无环境赋值提取，有环境的版本优先
+	withEnv := make(map[string]struct{})
+	for _, baseURL := range resolved {
+		if baseURL.Environment != "" {
+			withEnv[baseURL.Name+"|"+baseURL.URL] = struct{}{}
+		}
+	}
+	seen := make(map[string]struct{})
+	results := make([]BaseURL, 0, len(resolved))
+	for _, baseURL := range resolved {
+		if baseURL.Environment == "" {
+			if _, ok := withEnv[baseURL.Name+"|"+baseURL.URL]; ok {
+				continue
+			}
+			baseURL.Environment = DefaultEnvironment
+		}
+		key := baseURL.Name + "|" + baseURL.Environment
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		results = append(results, baseURL)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Name != results[j].Name {
+			return results[i].Name < results[j].Name
+		}
+		return results[i].Environment < results[j].Environment
+	})
+	return results
+}
+
+// BaseURLGroups 按变量名汇总基础地址：name -> environment -> URL
+func BaseURLGroups(baseURLs []BaseURL) map[string]map[string]string {
+	groups := make(map[string]map[string]string)
+	for _, baseURL := range baseURLs {
+		if groups[baseURL.Name] == nil {
+			groups[baseURL.Name] = make(map[string]string)
+		}
+		groups[baseURL.Name][baseURL.Environment] = baseURL.URL
+	}
+	return groups
+}
+
+// PreferredEnvironment 选出导出时默认使用的环境：生产环境优先，其次 default
+func PreferredEnvironment(envs map[string]string) string {
+	names := make([]string, 0, len(envs))
+	for env := range envs {
+		names = append(names, env)
+	}
+	sort.Strings(names)
+	for _, env := range names {
+		if isProductionEnvironment(env) {
+			return env
+		}
+	}
+	if _, ok := envs[DefaultEnvironment]; ok {
+		return DefaultEnvironment
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	return names[0]
+}
+
+// primaryBaseURL 选出相对地址默认拼接的基础地址：环境最多者优先，名称含 base / api 者次之
+func primaryBaseURL(groups map[string]map[string]string) string {
+	best, bestScore := "", -1
+	for name, envs := range groups {
+		score := len(envs) * 10
+		lower := strings.ToLower(name)
+		if strings.Contains(lower, "base") || strings.Contains(lower, "api") {
+			score += 5
+		}
+		if score > bestScore || score == bestScore && name < best {
+			best, bestScore = name, score
+		}
+	}
+	return best
+}
+
+// applyBaseURLs 为相对地址与 ${baseUrl}/path 形式的地址补全各环境下的完整 URL
+func applyBaseURLs(endpoints []APIEndpoint, baseURLs []BaseURL) {
+	groups := BaseURLGroups(baseURLs)
+	if len(groups) == 0 {
+		return
+	}
+	byLowerName := make(map[string]string, len(groups))
+	for name := range groups {
+		byLowerName[strings.ToLower(name)] = name
+	}
+	primary := primaryBaseURL(groups)
+
+	for i := range endpoints {
+		endpoint := &endpoints[i]
+		if isAbsoluteURL(endpoint.RawURL) || strings.HasPrefix(endpoint.RawURL, "//") {
+			continue
+		}
+		name := primary
+		if match := leadingTemplate.FindStringSubmatch(endpoint.RawURL); match != nil {
+			identifiers := identifierPattern.FindAllString(match[1], -1)
+			if len(identifiers) > 0 {
+				if known, ok := byLowerName[strings.ToLower(identifiers[len(identifiers)-1])]; ok {
+					name = known
+				}
+			}
+		}
+		path := StripBaseURLPrefix(endpoint.RawURL)
+		endpoint.BaseURLName = name
+		endpoint.ResolvedURLs = make(map[string]string, len(groups[name]))
+		for env, base := range groups[name] {
+			endpoint.ResolvedURLs[env] = JoinBaseURL(base, path)
+		}
+	}
+}
+
+// StripBaseURLPrefix 去掉地址开头的 ${baseUrl} 模板表达式，返回相对路径
+func StripBaseURLPrefix(rawURL string) string {
+	return leadingTemplate.ReplaceAllString(rawURL, "")
+}
+
+// JoinBaseURL 拼接基础地址与相对路径，处理重复或缺失的斜杠
+func JoinBaseURL(base, path string) string {
+	if path == "" || strings.HasPrefix(path, "?") {
+		return base + path
+	}
+	return strings.TrimRight(base, "/") + "/" + strings.TrimLeft(path, "/")
+}
@@ -0,0 +1,71 @@
+package scanner
+
+import "testing"
+
+func TestGenerateReportResolvesBaseURLsPerEnvironment(t *testing.T) {
+	files := map[string]string{
+		"config/env.js": `var HOST = "https://api.shop-wx.com.cn";
+var envVersion = wx.getAccountInfoSync().miniProgram.envVersion;
+var ENV_HOSTS = {
+  develop: "https://dev-api.shop-wx.com.cn",
+  trial: "https://test-api.shop-wx.com.cn",
+  release: HOST
+};
+module.exports = {apiBase: ENV_HOSTS[envVersion]};`,
+		"config/upload.js": `var isDev = false;
+var uploadHost = isDev ? "http://127.0.0.1:8080/upload" : HOST + "/upload";`,
+		"config/cdn.js": `export default {dev: {baseURL: "http://localhost:9000"}, prod: {baseURL: ` + "`${HOST}/v2`" + `}};`,
+		"api/user.js": `function getUser(){return request({url:"/user/info",method:"GET"})}
+function upload(){return wx.request({url: config.uploadHost + "/avatar", method:"POST"})}
+function list(){return wx.request({url:` + "`${baseURL}/goods/list`" + `,method:"GET"})}
+function cdn(){return wx.request({url:"https://cdn.shop-wx.com.cn/a.png"})}`,
+	}
+
+	collector := NewCollector("wx-test")
+	for _, name := range []string{"config/env.js", "config/upload.js", "config/cdn.js", "api/user.js"} {
+		if err := ScanFile(name, []byte(files[name]), nil, collector); err != nil {
+			t.Fatalf("ScanFile 返回错误: %v", err)
+		}
+	}
+	report := collector.GenerateReport()
+
+	groups := BaseURLGroups(report.BaseURLs)
+	expectedGroups := map[string]map[string]string{
+		"ENV_HOSTS":  {"develop": "https://dev-api.shop-wx.com.cn", "trial": "https://test-api.shop-wx.com.cn", "release": "https://api.shop-wx.com.cn"},
+		"HOST":       {DefaultEnvironment: "https://api.shop-wx.com.cn"},
+		"uploadHost": {"dev": "http://127.0.0.1:8080/upload", "prod": "https://api.shop-wx.com.cn/upload"},
+		"baseURL":    {"dev": "http://localhost:9000", "prod": "https://api.shop-wx.com.cn/v2"},
+	}
+	if len(groups) != len(expectedGroups) {
+		t.Fatalf("基础地址分组不正确: %#v", groups)
+	}
+	for name, envs := range expectedGroups {
+		for env, url := range envs {
+			if groups[name][env] != url {
+				t.Fatalf("%s[%s] 应为 %s，实际 %#v", name, env, url, groups[name])
+			}
+		}
+	}
+
+	resolved := make(map[string]APIEndpoint)
+	for _, endpoint := range report.APIEndpoints {
+		resolved[endpoint.RawURL] = endpoint
+	}
+	user := resolved["/user/info"]
+	if user.BaseURLName != "ENV_HOSTS" || user.ResolvedURLs["trial"] != "https://test-api.shop-wx.com.cn/user/info" || len(user.ResolvedURLs) != 3 {
+		t.Fatalf("相对地址应按环境最多的基础地址补全: %#v", user)
+	}
+	upload := resolved["${config.uploadHost}/avatar"]
+	if upload.BaseURLName != "uploadHost" || upload.ResolvedURLs["prod"] != "https://api.shop-wx.com.cn/upload/avatar" || upload.Method != "POST" {
+		t.Fatalf("拼接地址应使用引用的基础地址: %#v", upload)
+	}
+	if list := resolved["${baseURL}/goods/list"]; list.ResolvedURLs["dev"] != "http://localhost:9000/goods/list" {
+		t.Fatalf("模板字符串地址应使用引用的基础地址: %#v", list)
+	}
+	if cdn := resolved["https://cdn.shop-wx.com.cn/a.png"]; cdn.BaseURLName != "" || cdn.ResolvedURLs != nil {
+		t.Fatalf("绝对地址不应补全: %#v", cdn)
+	}
+	if PreferredEnvironment(groups["ENV_HOSTS"]) != "release" || PreferredEnvironment(groups["HOST"]) != DefaultEnvironment {
+		t.Fatalf("默认环境应优先生产环境")
+	}
+}
@@ -3,6 +3,7 @@ package scanner
 import (
 	"fmt"
 	"slices"
+	"strconv"
 	"sync"
 	"time"
 )
@@ -14,12 +15,17 @@ type DataCollector struct {
 	dedup           map[string]*DedupInfo
 	categories      map[string]*CategoryData
 	apiEndpoints    []APIEndpoint
-	apiDedup        map[string]struct{}
+	apiDedup        map[string]int
+	baseURLDefs     []baseURLDefinition
+	urlConstants    map[string]string
 	obfuscatedFiles []ObfuscatedFile
 	obfuscatedIndex map[string]int
 	appID           string
 	totalFiles      int
 	filter          *SensitiveFilter
+	triage          *Triage
+	verifiers       []Verifier
+	annotated       int // items 中已完成解析与校验的数量
 }
 
 // NewCollector 创建收集器
@@ -29,11 +35,13 @@ func NewCollector(appID string) *DataCollector {
 		dedup:           make(map[string]*DedupInfo),
 		categories:      make(map[string]*CategoryData),
 		apiEndpoints:    make([]APIEndpoint, 0),
-		apiDedup:        make(map[string]struct{}),
+		apiDedup:        make(map[string]int),
+		urlConstants:    make(map[string]string),
 		obfuscatedFiles: make([]ObfuscatedFile, 0),
 		obfuscatedIndex: make(map[string]int),
 		appID:           appID,
 		filter:          NewFilter(),
+		verifiers:       DefaultVerifiers(),
 	}
 }
 
@@ -70,7 +78,7 @@ func (c *DataCollector) Add(item SensitiveItem) {
 		c.items = append(c.items, item)
 
 		// 添加到分类
-		c.addToCategory(item)
+		addToCategory(c.categories, item)
 	}
 }
 
@@ -88,14 +96,39 @@ func (c *DataCollector) AddAPIEndpoint(endpoint APIEndpoint) {
 	defer c.mu.Unlock()
 
 	key := fmt.Sprintf("%s:%s", endpoint.Method, endpoint.RawURL)
-	if _, exists := c.apiDedup[key]; exists {
+	if idx, exists := c.apiDedup[key]; exists {
+		// 运行时追踪得到的请求头与请求体补充到静态提取的同一接口上
+		current := &c.apiEndpoints[idx]
+		if len(current.Headers) == 0 {
+			current.Headers = endpoint.Headers
+		}
+		if current.Body == "" {
+			current.Body = endpoint.Body
+		}
 		return
 	}
 
-	c.apiDedup[key] = struct{}{}
+	c.apiDedup[key] = len(c.apiEndpoints)
 	c.apiEndpoints = append(c.apiEndpoints, endpoint)
 }
 
+// addBaseURLSource 记录文件中的基础地址赋值与地址常量，生成报告时统一解析
+func (c *DataCollector) addBaseURLSource(source baseURLSource) {
+	if len(source.definitions) == 0 && len(source.constants) == 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.baseURLDefs = append(c.baseURLDefs, source.definitions...)
+	for name, value := range source.constants {
+		if _, exists := c.urlConstants[name]; !exists {
+			c.urlConstants[name] = value
+		}
+	}
+}
+
 // AddObfuscatedFile 添加混淆文件信息
 func (c *DataCollector) AddObfuscatedFile(file ObfuscatedFile) {
 	if file.FilePath == "" {
@@ -122,20 +155,20 @@ func (c *DataCollector) AddObfuscatedFile(file ObfuscatedFile) {
 }
 
 // addToCategory 添加到分类
-func (c *DataCollector) addToCategory(item SensitiveItem) {
+func addToCategory(categories map[string]*CategoryData, item SensitiveItem) {
 	category := item.Category
 	if category == "" {
 		category = GetCategoryKey(item.RuleID)
 	}
 
-	if c.categories[category] == nil {
-		c.categories[category] = &CategoryData{
+	if categories[category] == nil {
+		categories[category] = &CategoryData{
 			Name:  GetCategoryName(category),
 			Items: make(map[string][]LocationInfo),
 		}
 	}
 
-	cat := c.categories[category]
+	cat := categories[category]
 	cat.Count++
 
 	if cat.Items[item.Content] == nil {
@@ -148,6 +181,20 @@ func (c *DataCollector) addToCategory(item SensitiveItem) {
 	})
 }
 
+// SetTriage 设置基线与忽略规则，GenerateReport 据此区分新发现
+func (c *DataCollector) SetTriage(triage *Triage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.triage = triage
+}
+
+// SetVerifiers 替换凭据校验器，传入 nil 关闭校验
+func (c *DataCollector) SetVerifiers(verifiers []Verifier) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.verifiers = verifiers
+}
+
 // SetTotalFiles 设置总文件数
 func (c *DataCollector) SetTotalFiles(count int) {
 	c.totalFiles = count
@@ -189,6 +236,9 @@ func (c *DataCollector) RewriteFilePaths(pathMap map[string]string) {
 	for i := range c.apiEndpoints {
 		c.apiEndpoints[i].FilePath = rename(c.apiEndpoints[i].FilePath)
 	}
+	for i := range c.baseURLDefs {
+		c.baseURLDefs[i].FilePath = rename(c.baseURLDefs[i].FilePath)
+	}
 
 	c.obfuscatedIndex = make(map[string]int, len(c.obfuscatedFiles))
 	for i := range c.obfuscatedFiles {
@@ -197,14 +247,86 @@ func (c *DataCollector) RewriteFilePaths(pathMap map[string]string) {
 	}
 }
 
-// GenerateReport 生成报告
+// MergeReport 把上次生成的报告合并进当前收集器，用于增量处理时保留未变化文件的结果。
+// keep 返回 false 的文件路径（已重新扫描或已被替换的文件）对应的位置会被丢弃；
+// 发现、接口、基础地址与混淆信息按位置重新登记，生成报告时重新分级与校验。
+func (c *DataCollector) MergeReport(previous *ScanReport, keep func(path string) bool) {
+	if previous == nil {
+		return
+	}
+	if keep == nil {
+		keep = func(string) bool { return true }
+	}
+
+	for _, item := range previous.Items {
+		category := item.Category
+		if category == "" {
+			category = GetCategoryKey(item.RuleID)
+		}
+		locations := []LocationInfo{{FilePath: item.FilePath, LineNumber: item.LineNumber}}
+		if data := previous.Categories[category]; data != nil && len(data.Items[item.Content]) > 0 {
+			locations = data.Items[item.Content]
+		}
+		c.mergeItem(item, locations, keep)
+	}
+	// 已知与已忽略的发现不进入分类统计，只能按首个位置恢复
+	for _, items := range [][]SensitiveItem{previous.KnownItems, previous.SuppressedItems} {
+		for _, item := range items {
+			c.mergeItem(item, []LocationInfo{{FilePath: item.FilePath, LineNumber: item.LineNumber}}, keep)
+		}
+	}
+
+	for _, endpoint := range previous.APIEndpoints {
+		if keep(endpoint.FilePath) {
+			endpoint.BaseURLName = ""
+			endpoint.ResolvedURLs = nil
+			c.AddAPIEndpoint(endpoint)
+		}
+	}
+
+	var definitions []baseURLDefinition
+	for _, baseURL := range previous.BaseURLs {
+		if keep(baseURL.FilePath) {
+			definitions = append(definitions, baseURLDefinition{
+				Name:        baseURL.Name,
+				Environment: baseURL.Environment,
+				Expression:  strconv.Quote(baseURL.URL),
+				FilePath:    baseURL.FilePath,
+				LineNumber:  baseURL.LineNumber,
+			})
+		}
+	}
+	c.addBaseURLSource(baseURLSource{definitions: definitions})
+
+	for _, file := range previous.ObfuscatedFiles {
+		if keep(file.FilePath) {
+			c.AddObfuscatedFile(file)
+		}
+	}
+}
+
+func (c *DataCollector) mergeItem(item SensitiveItem, locations []LocationInfo, keep func(string) bool) {
+	// 分级与校验结果在生成报告时重新计算
+	item.Status = ""
+	item.Justification = ""
+	item.ContentHash = ""
+	for _, location := range locations {
+		if !keep(location.FilePath) {
+			continue
+		}
+		item.FilePath = location.FilePath
+		item.LineNumber = location.LineNumber
+		c.Add(item)
+	}
+}
+
+// GenerateReport 生成报告。Items 默认只包含新发现，已知与已忽略的发现分别放在
+// KnownItems / SuppressedItems 中；分类与风险统计只针对 Items。
 func (c *DataCollector) GenerateReport() *ScanReport {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	summary := c.generateSummary()
-
-	return &ScanReport{
+	report := &ScanReport{
 		AppID:           c.appID,
 		ScanTime:        time.Now().Format("2006-01-02 15:04:05"),
 		TotalFiles:      c.totalFiles,
@@ -212,29 +334,89 @@ func (c *DataCollector) GenerateReport() *ScanReport {
 		Items:           c.items,
 		APIEndpoints:    slices.Clone(c.apiEndpoints),
 		ObfuscatedFiles: cloneObfuscatedFiles(c.obfuscatedFiles),
-		Summary:         summary,
+		BaseURLs:        resolveBaseURLs(c.baseURLDefs, c.urlConstants),
+	}
+	applyBaseURLs(report.APIEndpoints, report.BaseURLs)
+
+	// 每个去重后的发现只解析与校验一次，在线校验器不会被重复调用
+	for ; c.annotated < len(c.items); c.annotated++ {
+		item := &c.items[c.annotated]
+		item.Decoded = DecodeCredential(item.Content)
+		if item.Decoded != nil && item.Decoded.Kind == DecodedPrivateKey {
+			// 通用 Base64 规则命中的内容解析出私钥时按高风险处理
+			item.Confidence = "high"
+		}
+		VerifyItem(item, c.verifiers)
 	}
+
+	visible := c.dedup
+	if c.triage != nil {
+		report.Items = make([]SensitiveItem, 0, len(c.items))
+		report.Categories = make(map[string]*CategoryData)
+		visible = make(map[string]*DedupInfo, len(c.dedup))
+	}
+	for i := range c.items {
+		item := c.items[i]
+		key := fmt.Sprintf("%s:%s", item.RuleID, item.Content)
+		item.ContentHash = ContentHash(item.Content)
+		item.Status = StatusNew
+		if c.triage == nil {
+			c.items[i] = item
+			continue
+		}
+
+		var paths []string
+		for _, location := range c.dedup[key].Locations {
+			paths = append(paths, location.FilePath)
+		}
+		item.Status, item.Justification = c.triage.Classify(item, paths)
+		switch {
+		case item.Status == StatusKnown && !c.triage.ShowAll:
+			report.KnownItems = append(report.KnownItems, item)
+		case item.Status == StatusSuppressed && !c.triage.ShowAll:
+			report.SuppressedItems = append(report.SuppressedItems, item)
+		default:
+			report.Items = append(report.Items, item)
+			visible[key] = c.dedup[key]
+			addToCategory(report.Categories, item)
+		}
+	}
+
+	report.Summary = generateSummary(report.Items, visible, report.Categories)
+	report.Summary.KnownCount = len(report.KnownItems)
+	report.Summary.Suppressed = len(report.SuppressedItems)
+	for _, item := range report.Items {
+		switch item.Status {
+		case StatusKnown:
+			report.Summary.KnownCount++
+		case StatusSuppressed:
+			report.Summary.Suppressed++
+		default:
+			report.Summary.NewCount++
+		}
+	}
+	return report
 }
 
 // generateSummary 生成摘要
-func (c *DataCollector) generateSummary() ReportSummary {
+func generateSummary(items []SensitiveItem, dedup map[string]*DedupInfo, categories map[string]*CategoryData) ReportSummary {
 	summary := ReportSummary{
 		TotalMatches:  0,
-		UniqueMatches: len(c.dedup),
+		UniqueMatches: len(dedup),
 		CategoryStats: make(map[string]int),
 	}
 
 	// 统计总匹配数和分类
-	for _, dedup := range c.dedup {
-		summary.TotalMatches += dedup.Count
+	for _, info := range dedup {
+		summary.TotalMatches += info.Count
 	}
 
-	for category, data := range c.categories {
+	for category, data := range categories {
 		summary.CategoryStats[category] = data.UniqueCount
 	}
 
 	// 统计风险等级
-	for _, item := range c.items {
+	for _, item := range items {
 		switch item.Confidence {
 		case "high":
 			summary.HighRisk++
@@ -243,6 +425,14 @@ func (c *DataCollector) generateSummary() ReportSummary {
 		default:
 			summary.LowRisk++
 		}
+		switch item.Verification {
+		case VerificationValid:
+			summary.Verified++
+		case VerificationExpired:
+			summary.Expired++
+		case VerificationMalformed:
+			summary.Malformed++
+		}
 	}
 
 	return summary
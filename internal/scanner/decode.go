@@ -0,0 +1,443 @@
+package scanner
+
+import (
+	"bytes"
+	"crypto/dsa"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// 解析结果类型
+const (
+	DecodedJWT         = "jwt"
+	DecodedPrivateKey  = "private_key"
+	DecodedPublicKey   = "public_key"
+	DecodedCertificate = "certificate"
+)
+
+// DecodedCredential 从 JWT、PEM/DER 密钥或 X.509 证书中解析出的可读信息
+type DecodedCredential struct {
+	Kind    string            `json:"kind"`
+	Summary string            `json:"summary"` // 报告中展示的一句话描述，如 "RS256 JWT，2027-01-01 过期"
+	Fields  map[string]string `json:"fields,omitempty"`
+
+	NotAfter time.Time `json:"-"` // JWT exp 或证书有效期，零值表示未设置
+}
+
+const decodedDateLayout = "2006-01-02"
+
+var (
+	jwtPattern       = regexp.MustCompile(`eyJ[A-Za-z0-9_\-+/]+={0,2}\.eyJ[A-Za-z0-9_\-+/]+={0,2}\.[A-Za-z0-9_\-+/]*={0,2}`)
+	pemPattern       = regexp.MustCompile(`-----\s*BEGIN ([A-Z0-9 ]+?)\s*-----([\s\S]*?)-----\s*END [A-Z0-9 ]+?\s*-----`)
+	derBase64Pattern = regexp.MustCompile(`M[A-Za-z0-9+/]{60,}={0,2}`)
+	ecPointPattern   = regexp.MustCompile(`^04[0-9a-fA-F]{128}$`)
+
+	// SM2 曲线 OID 1.2.156.10197.1.301 的 DER 编码，标准库不支持该曲线，只能按 OID 识别
+	sm2CurveOID = []byte{0x06, 0x08, 0x2a, 0x81, 0x1c, 0xcf, 0x55, 0x01, 0x82, 0x2d}
+	sm2Params   = &elliptic.CurveParams{
+		Name:    "SM2",
+		BitSize: 256,
+		P:       hexBigInt("FFFFFFFEFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF00000000FFFFFFFFFFFFFFFF"),
+		B:       hexBigInt("28E9FA9E9D9F5E344D5A9E4BCF6509A7F39789F515AB8F92DDBCBD414D940E93"),
+	}
+)
+
+// DecodeCredential 从命中内容中解析 JWT、PEM/DER 密钥与 X.509 证书，无法识别时返回 nil
+func DecodeCredential(content string) *DecodedCredential {
+	if strings.Contains(content, "eyJ") {
+		if token := jwtPattern.FindString(content); token != "" {
+			if decoded := decodeJWT(token); decoded != nil {
+				return decoded
+			}
+		}
+	}
+
+	// 小程序代码里的 PEM 多为一行带 \n 转义的字符串
+	normalized := strings.NewReplacer(`\r`, "", `\n`, "\n").Replace(content)
+	if match := pemPattern.FindStringSubmatch(normalized); match != nil {
+		return decodePEM(match[1], match[0], match[2])
+	}
+
+	value := credentialValue(content)
+	if ecPointPattern.MatchString(value) {
+		return decodeECPoint(value)
+	}
+	if der := derBase64Pattern.FindString(value); der != "" {
+		if data, err := base64.StdEncoding.DecodeString(padBase64(der)); err == nil {
+			return decodeDER("", data)
+		}
+	}
+	return nil
+}
+
+// parseJWT 解码 JWT 的头部与载荷，不校验签名
+func parseJWT(token string) (header, claims map[string]interface{}, err error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, nil, fmt.Errorf("JWT 应由三段组成")
+	}
+	if err := decodeJWTSegment(parts[0], &header); err != nil {
+		return nil, nil, fmt.Errorf("JWT 头部无法解码")
+	}
+	if err := decodeJWTSegment(parts[1], &claims); err != nil {
+		return nil, nil, fmt.Errorf("JWT 载荷无法解码")
+	}
+	return header, claims, nil
+}
+
+func decodeJWTSegment(segment string, target interface{}) error {
+	segment = strings.TrimRight(segment, "=")
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		// 部分实现误用标准 Base64 字母表
+		if data, err = base64.RawStdEncoding.DecodeString(segment); err != nil {
+			return err
+		}
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(target)
+}
+
+func decodeJWT(token string) *DecodedCredential {
+	header, claims, err := parseJWT(token)
+	if err != nil {
+		return nil
+	}
+	alg, _ := header["alg"].(string)
+	if alg == "" {
+		return nil
+	}
+
+	decoded := &DecodedCredential{Kind: DecodedJWT, Fields: map[string]string{"alg": alg}}
+	for _, name := range []string{"typ", "kid"} {
+		if value, ok := header[name].(string); ok && value != "" {
+			decoded.Fields[name] = value
+		}
+	}
+	for _, name := range []string{"iss", "sub", "aud"} {
+		if value := claimString(claims[name]); value != "" {
+			decoded.Fields[name] = value
+		}
+	}
+	for _, name := range []string{"iat", "nbf", "exp"} {
+		if at, ok := claimTime(claims[name]); ok {
+			decoded.Fields[name] = at.Format("2006-01-02 15:04:05")
+			if name == "exp" {
+				decoded.NotAfter = at
+			}
+		}
+	}
+
+	parts := []string{alg + " JWT"}
+	if strings.EqualFold(alg, "none") {
+		parts[0] += "（未签名）"
+	}
+	if iss := decoded.Fields["iss"]; iss != "" {
+		parts = append(parts, "iss="+iss)
+	}
+	parts = append(parts, expiryText(decoded.NotAfter, "未设置过期时间"))
+	decoded.Summary = strings.Join(parts, "，")
+	return decoded
+}
+
+func claimString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case []interface{}:
+		var values []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return strings.Join(values, ",")
+	}
+	return ""
+}
+
+func claimTime(value interface{}) (time.Time, bool) {
+	number, ok := value.(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+	seconds, err := number.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(seconds), 0), true
+}
+
+func decodePEM(blockType, block, body string) *DecodedCredential {
+	if strings.Contains(body, "Proc-Type:") && strings.Contains(body, "ENCRYPTED") {
+		return &DecodedCredential{
+			Kind:    DecodedPrivateKey,
+			Summary: "已加密的 PEM 私钥（" + blockType + "）",
+			Fields:  map[string]string{"pem_type": blockType, "encrypted": "true"},
+		}
+	}
+	if blockType == "OPENSSH PRIVATE KEY" {
+		key, err := ssh.ParseRawPrivateKey([]byte(block))
+		if _, missing := err.(*ssh.PassphraseMissingError); missing {
+			return &DecodedCredential{
+				Kind:    DecodedPrivateKey,
+				Summary: "已加密的 OpenSSH 私钥",
+				Fields:  map[string]string{"format": "OpenSSH", "encrypted": "true"},
+			}
+		}
+		if err != nil {
+			return nil
+		}
+		return keyCredential(DecodedPrivateKey, key, "OpenSSH")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(padBase64(strings.Join(strings.Fields(body), "")))
+	if err != nil {
+		return nil
+	}
+	decoded := decodeDER(blockType, data)
+	if decoded != nil {
+		decoded.Fields["pem_type"] = blockType
+	}
+	return decoded
+}
+
+// decodeDER 按 PEM 类型（为空时依次尝试）解析证书、私钥或公钥
+func decodeDER(blockType string, der []byte) *DecodedCredential {
+	anyType := blockType == ""
+	if anyType || strings.Contains(blockType, "CERTIFICATE") {
+		if cert, err := x509.ParseCertificate(der); err == nil {
+			return certificateCredential(cert)
+		}
+	}
+	if anyType || strings.Contains(blockType, "PRIVATE") {
+		if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+			return keyCredential(DecodedPrivateKey, key, "PKCS#8")
+		}
+		if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+			return keyCredential(DecodedPrivateKey, key, "PKCS#1")
+		}
+		if key, err := x509.ParseECPrivateKey(der); err == nil {
+			return keyCredential(DecodedPrivateKey, key, "SEC1")
+		}
+	}
+	if anyType || strings.Contains(blockType, "PUBLIC") {
+		if key, err := x509.ParsePKIXPublicKey(der); err == nil {
+			return keyCredential(DecodedPublicKey, key, "PKIX")
+		}
+		if key, err := x509.ParsePKCS1PublicKey(der); err == nil {
+			return keyCredential(DecodedPublicKey, key, "PKCS#1")
+		}
+	}
+	if bytes.Contains(der, sm2CurveOID) {
+		return sm2Credential(blockType, der)
+	}
+	return nil
+}
+
+func certificateCredential(cert *x509.Certificate) *DecodedCredential {
+	algorithm, bits := keyAlgorithm(cert.PublicKey)
+	decoded := &DecodedCredential{
+		Kind: DecodedCertificate,
+		Fields: map[string]string{
+			"subject":             cert.Subject.String(),
+			"issuer":              cert.Issuer.String(),
+			"serial":              cert.SerialNumber.String(),
+			"not_before":          cert.NotBefore.Format(decodedDateLayout),
+			"not_after":           cert.NotAfter.Format(decodedDateLayout),
+			"key_algorithm":       algorithm,
+			"signature_algorithm": cert.SignatureAlgorithm.String(),
+		},
+		NotAfter: cert.NotAfter,
+	}
+	if bits > 0 {
+		decoded.Fields["key_bits"] = fmt.Sprint(bits)
+	}
+	if len(cert.DNSNames) > 0 {
+		decoded.Fields["dns_names"] = strings.Join(cert.DNSNames, ",")
+	}
+
+	name := cert.Subject.CommonName
+	if name == "" {
+		name = cert.Subject.String()
+	}
+	validity := "有效期至 " + cert.NotAfter.Format(decodedDateLayout)
+	if cert.NotAfter.Before(time.Now()) {
+		validity = expiryText(cert.NotAfter, "")
+	}
+	decoded.Summary = fmt.Sprintf("X.509 证书 CN=%s，%s，%s", name, keyLabel(algorithm, bits), validity)
+	return decoded
+}
+
+func keyCredential(kind string, key interface{}, format string) *DecodedCredential {
+	algorithm, bits := keyAlgorithm(key)
+	decoded := &DecodedCredential{
+		Kind:   kind,
+		Fields: map[string]string{"algorithm": algorithm, "format": format},
+	}
+	if bits > 0 {
+		decoded.Fields["bits"] = fmt.Sprint(bits)
+	}
+	label := keyLabel(algorithm, bits)
+	if !strings.HasSuffix(label, "位") {
+		label += " "
+	}
+	if kind == DecodedPublicKey {
+		decoded.Summary = label + "公钥"
+	} else {
+		decoded.Summary = label + "私钥"
+	}
+	return decoded
+}
+
+// keyAlgorithm 返回密钥算法与位数，ECDSA 的算法名包含曲线
+func keyAlgorithm(key interface{}) (string, int) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return "RSA", k.N.BitLen()
+	case *rsa.PublicKey:
+		return "RSA", k.N.BitLen()
+	case *ecdsa.PrivateKey:
+		return "ECDSA " + k.Curve.Params().Name, k.Curve.Params().BitSize
+	case *ecdsa.PublicKey:
+		return "ECDSA " + k.Curve.Params().Name, k.Curve.Params().BitSize
+	case ed25519.PrivateKey, *ed25519.PrivateKey, ed25519.PublicKey:
+		return "Ed25519", 256
+	case *ecdh.PrivateKey, *ecdh.PublicKey:
+		return "X25519", 256
+	case *dsa.PrivateKey:
+		return "DSA", k.P.BitLen()
+	case *dsa.PublicKey:
+		return "DSA", k.P.BitLen()
+	}
+	return fmt.Sprintf("%T", key), 0
+}
+
+func keyLabel(algorithm string, bits int) string {
+	if bits > 0 && (algorithm == "RSA" || algorithm == "DSA") {
+		return fmt.Sprintf("%s %d 位", algorithm, bits)
+	}
+	return algorithm
+}
+
+// sm2Credential 标准库无法解析 SM2 密钥，按 ASN.1 结构判断是私钥、公钥还是证书
+func sm2Credential(blockType string, der []byte) *DecodedCredential {
+	kind := ""
+	switch {
+	case strings.Contains(blockType, "CERTIFICATE"):
+		kind = DecodedCertificate
+	case strings.Contains(blockType, "PRIVATE"):
+		kind = DecodedPrivateKey
+	case strings.Contains(blockType, "PUBLIC"):
+		kind = DecodedPublicKey
+	default:
+		var outer asn1.RawValue
+		if _, err := asn1.Unmarshal(der, &outer); err != nil {
+			return nil
+		}
+		var first asn1.RawValue
+		if _, err := asn1.Unmarshal(outer.Bytes, &first); err != nil {
+			return nil
+		}
+		var inner asn1.RawValue
+		_, innerErr := asn1.Unmarshal(first.Bytes, &inner)
+		switch {
+		case first.Tag == asn1.TagInteger:
+			kind = DecodedPrivateKey
+		case first.Tag == asn1.TagSequence && innerErr == nil && inner.Tag == asn1.TagOID:
+			kind = DecodedPublicKey
+		default:
+			kind = DecodedCertificate
+		}
+	}
+
+	summary := map[string]string{
+		DecodedPrivateKey:  "SM2 私钥",
+		DecodedPublicKey:   "SM2 公钥",
+		DecodedCertificate: "SM2 证书（国密证书暂不解析主体与有效期）",
+	}[kind]
+	return &DecodedCredential{
+		Kind:    kind,
+		Summary: summary,
+		Fields:  map[string]string{"algorithm": "SM2", "bits": "256"},
+	}
+}
+
+// decodeECPoint 识别 04 开头的十六进制未压缩公钥（sm-crypto 等库的默认格式）
+func decodeECPoint(value string) *DecodedCredential {
+	data, err := hex.DecodeString(value)
+	if err != nil {
+		return nil
+	}
+	x := new(big.Int).SetBytes(data[1:33])
+	y := new(big.Int).SetBytes(data[33:])
+	for _, params := range []*elliptic.CurveParams{sm2Params, elliptic.P256().Params()} {
+		if onCurve(params, x, y) {
+			return &DecodedCredential{
+				Kind:    DecodedPublicKey,
+				Summary: params.Name + " 公钥（十六进制未压缩点）",
+				Fields:  map[string]string{"algorithm": params.Name, "bits": "256", "format": "uncompressed point"},
+			}
+		}
+	}
+	return nil
+}
+
+// onCurve 判断点是否在 a = -3 的短 Weierstrass 曲线上（SM2 与 P-256 均满足）
+func onCurve(params *elliptic.CurveParams, x, y *big.Int) bool {
+	if x.Cmp(params.P) >= 0 || y.Cmp(params.P) >= 0 {
+		return false
+	}
+	left := new(big.Int).Mul(y, y)
+	left.Mod(left, params.P)
+
+	right := new(big.Int).Mul(x, x)
+	right.Mul(right, x)
+	threeX := new(big.Int).Lsh(x, 1)
+	threeX.Add(threeX, x)
+	right.Sub(right, threeX)
+	right.Add(right, params.B)
+	right.Mod(right, params.P)
+	return left.Cmp(right) == 0
+}
+
+func expiryText(notAfter time.Time, unset string) string {
+	switch {
+	case notAfter.IsZero():
+		return unset
+	case notAfter.Before(time.Now()):
+		return "已于 " + notAfter.Format(decodedDateLayout) + " 过期"
+	}
+	return notAfter.Format(decodedDateLayout) + " 过期"
+}
+
+func padBase64(value string) string {
+	value = strings.TrimRight(value, "=")
+	if rem := len(value) % 4; rem != 0 {
+		value += strings.Repeat("=", 4-rem)
+	}
+	return value
+}
+
+func hexBigInt(value string) *big.Int {
+	n, _ := new(big.Int).SetString(value, 16)
+	return n
+}
@@ -0,0 +1,115 @@
+package scanner
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeJWTSummary(t *testing.T) {
+	encode := base64.RawURLEncoding.EncodeToString
+	exp := time.Date(2099, 1, 1, 0, 0, 0, 0, time.Local)
+	token := encode([]byte(`{"alg":"RS256","kid":"k1"}`)) + "." +
+		encode([]byte(fmt.Sprintf(`{"iss":"auth.shop-wx.com.cn","sub":"10086","exp":%d}`, exp.Unix()))) + ".c2ln"
+
+	decoded := DecodeCredential(`Authorization: "Bearer ` + token + `"`)
+	if decoded == nil || decoded.Kind != DecodedJWT {
+		t.Fatalf("JWT 未解析: %+v", decoded)
+	}
+	if decoded.Summary != "RS256 JWT，iss=auth.shop-wx.com.cn，2099-01-01 过期" {
+		t.Fatalf("摘要错误: %s", decoded.Summary)
+	}
+	if decoded.Fields["sub"] != "10086" || decoded.Fields["kid"] != "k1" {
+		t.Fatalf("字段缺失: %+v", decoded.Fields)
+	}
+}
+
+func TestDecodeKeysAndCertificates(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(7),
+		Subject:      pkix.Name{CommonName: "api.shop-wx.com.cn"},
+		NotBefore:    time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:     time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &rsaKey.PublicKey, rsaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecDER, err := x509.MarshalPKCS8PrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	escape := func(block *pem.Block) string {
+		return strings.ReplaceAll(string(pem.EncodeToMemory(block)), "\n", `\n`)
+	}
+	point, err := ecKey.PublicKey.ECDH()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name, content, kind, summary string
+	}{
+		{"证书", escape(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}), DecodedCertificate, "X.509 证书 CN=api.shop-wx.com.cn，RSA 1024 位，已于 2021-01-01 过期"},
+		{"裸 DER 公钥", `"` + base64.StdEncoding.EncodeToString(pubDER) + `"`, DecodedPublicKey, "RSA 1024 位公钥"},
+		{"PKCS#8 私钥", escape(&pem.Block{Type: "PRIVATE KEY", Bytes: ecDER}), DecodedPrivateKey, "ECDSA P-256 私钥"},
+		{"十六进制公钥", `'` + hex.EncodeToString(point.Bytes()) + `'`, DecodedPublicKey, "P-256 公钥（十六进制未压缩点）"},
+		{"SM2 公钥", `"MFkwEwYHKoZIzj0CAQYIKoEcz1UBgi0DQgAEz0MKt4i7d3DqCn2jKdyX0MgtCS4bD0eaurxP1yFt0qLWp1cbP7rQKfR1Jd9H8a4cDy6X0wzBzgBphi8zbQ2nzw=="`, DecodedPublicKey, "SM2 公钥"},
+	}
+	for _, tc := range cases {
+		decoded := DecodeCredential(tc.content)
+		if decoded == nil || decoded.Kind != tc.kind || decoded.Summary != tc.summary {
+			t.Fatalf("%s 解析错误: %+v", tc.name, decoded)
+		}
+	}
+
+	if decoded := DecodeCredential(`"MIIBnotreallyaDERpayloadbutlongenoughtomatchthebase64patternokay"`); decoded != nil {
+		t.Fatalf("无法解析的 Base64 不应返回结果: %+v", decoded)
+	}
+}
+
+func TestCollectorAnnotatesDecodedFindings(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := `"` + base64.StdEncoding.EncodeToString(der) + `"`
+
+	collector := NewCollector("wx-test")
+	collector.Add(SensitiveItem{RuleID: "der_key_base64", Category: "private_key", Content: content, Confidence: "medium"})
+	report := collector.GenerateReport()
+	if len(report.Items) != 1 {
+		t.Fatalf("应保留一条发现: %+v", report.Items)
+	}
+	item := report.Items[0]
+	if item.Decoded == nil || item.Decoded.Summary != "ECDSA P-256 私钥" || item.Confidence != "high" {
+		t.Fatalf("解析出私钥后应记录摘要并提升为高风险: %+v", item)
+	}
+}
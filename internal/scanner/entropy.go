@@ -0,0 +1,480 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja/ast"
+	"github.com/dop251/goja/file"
+	"github.com/dop251/goja/parser"
+)
+
+// GenericSecretRuleID 熵检测发现的规则 ID，可在忽略规则中按此 ID 批量忽略
+const GenericSecretRuleID = "generic_secret"
+
+const (
+	genericSecretMinLength = 16
+	genericSecretMaxLength = 256
+	// genericSecretThreshold 低于该分数不报告；没有命中键名时最高只有 60 分，因此必须结合上下文
+	genericSecretThreshold = 65
+	// maxGenericSecretFileBytes 超大文件（通常是第三方库）跳过 AST 解析
+	maxGenericSecretFileBytes = 2 * 1024 * 1024
+	genericSecretContextWidth = 160
+)
+
+const gojaASTPackagePath = "github.com/dop251/goja/ast"
+
+var (
+	// strongSecretKeys 键名命中时几乎可以确定是凭据
+	strongSecretKeys = []string{
+		"secret", "password", "passwd", "pwd", "privatekey", "private_key", "accesskey", "access_key",
+		"apikey", "api_key", "token", "credential", "signkey", "sign_key", "signsecret", "encryptkey", "aeskey", "aes_key",
+	}
+	// weakSecretKeys 键名命中时只作为辅助证据
+	weakSecretKeys = []string{"key", "sign", "salt", "auth", "cipher", "nonce", "iv"}
+	// nonSecretKeySuffixes 键名以这些结尾时描述的是凭据的元信息，而不是凭据本身
+	nonSecretKeySuffixes = []string{
+		"url", "uri", "path", "name", "type", "label", "title", "text", "desc", "msg", "message",
+		"placeholder", "field", "header", "expire", "expires", "expiretime", "time", "length", "len", "count", "id",
+	}
+
+	secretKeyNormalizer = regexp.MustCompile(`[^a-z0-9_]+`)
+	camelBoundary       = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+)
+
+// genericCandidate 一个 key = "value" 形式的候选值
+type genericCandidate struct {
+	key    string
+	value  string
+	offset int // 值在源码中的字节偏移，-1 表示未知
+}
+
+// GenericSecretFinding 熵检测的打分明细
+type GenericSecretFinding struct {
+	Key      string
+	Value    string
+	Entropy  float64
+	Charset  string
+	KeyScore int
+	Score    int
+	Reasons  []string
+}
+
+// DetectGenericSecrets 从 JS / JSON 的赋值语义中找出疑似通用密钥：
+// 键名（secret、appSecret、accessKey 等）+ Shannon 熵 + 字符集共同打分，每条结果附带可解释的评分依据。
+func DetectGenericSecrets(filePath string, content []byte) []SensitiveItem {
+	if len(content) == 0 || len(content) > maxGenericSecretFileBytes {
+		return nil
+	}
+
+	var candidates []genericCandidate
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".js", ".mjs", ".cjs":
+		candidates = collectJSCandidates(string(content))
+	case ".json":
+		candidates = collectJSONCandidates(content)
+	default:
+		return nil
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	lines := newLineIndex(content)
+	timestamp := time.Now().Format("2006-01-02 15:04:05")
+	var items []SensitiveItem
+	for _, candidate := range candidates {
+		finding, ok := ScoreGenericSecret(candidate.key, candidate.value)
+		if !ok {
+			continue
+		}
+		offset := candidate.offset
+		if offset < 0 {
+			offset = strings.Index(string(content), candidate.value)
+		}
+		lineNumber, context := lines.locate(offset, len(candidate.value))
+
+		confidence := "medium"
+		if finding.Score >= 80 {
+			confidence = "high"
+		}
+		items = append(items, SensitiveItem{
+			RuleID:      GenericSecretRuleID,
+			RuleName:    GetRuleName(GenericSecretRuleID),
+			Category:    GetCategoryKey(GenericSecretRuleID),
+			Content:     candidate.value,
+			FilePath:    filePath,
+			LineNumber:  lineNumber,
+			Context:     context,
+			Confidence:  confidence,
+			Description: fmt.Sprintf("评分 %d：%s", finding.Score, strings.Join(finding.Reasons, "；")),
+			Timestamp:   timestamp,
+		})
+	}
+	return items
+}
+
+// ScoreGenericSecret 对单个 key/value 打分，返回是否达到报告阈值。
+// 满分 100：键名最高 40，熵最高 40，字符集最高 20。
+func ScoreGenericSecret(key, value string) (GenericSecretFinding, bool) {
+	finding := GenericSecretFinding{Key: key, Value: value}
+	if len(value) < genericSecretMinLength || len(value) > genericSecretMaxLength {
+		return finding, false
+	}
+	if strings.ContainsAny(value, " \t\r\n") || strings.Contains(value, "://") {
+		return finding, false
+	}
+
+	finding.Charset = classifyCharset(value)
+	if finding.Charset == "" {
+		return finding, false
+	}
+
+	finding.KeyScore = secretKeyScore(key)
+	switch finding.KeyScore {
+	case 40:
+		finding.Reasons = append(finding.Reasons, fmt.Sprintf("键名 %s 指向凭据", key))
+	case 20:
+		finding.Reasons = append(finding.Reasons, fmt.Sprintf("键名 %s 可能指向凭据", key))
+	}
+
+	finding.Entropy = ShannonEntropy(value)
+	entropyScore := entropyScore(finding.Entropy, finding.Charset)
+	finding.Reasons = append(finding.Reasons, fmt.Sprintf("熵 %.2f bit/字符", finding.Entropy))
+
+	charsetScore := 0
+	switch finding.Charset {
+	case "alnum-mixed", "base64":
+		charsetScore = 20
+	case "hex":
+		charsetScore = 15
+	case "alnum":
+		charsetScore = 10
+	}
+	finding.Reasons = append(finding.Reasons, fmt.Sprintf("字符集 %s，长度 %d", finding.Charset, len(value)))
+
+	finding.Score = finding.KeyScore + entropyScore + charsetScore
+	return finding, finding.KeyScore > 0 && entropyScore > 0 && finding.Score >= genericSecretThreshold
+}
+
+// ShannonEntropy 计算字符串的 Shannon 熵（bit/字符）
+func ShannonEntropy(value string) float64 {
+	if value == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	total := 0
+	for _, ch := range value {
+		counts[ch]++
+		total++
+	}
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// entropyScore 不同字符集的熵上限不同：hex 最高 4 bit，base64 最高 6 bit
+func entropyScore(entropy float64, charset string) int {
+	floor, ceiling := 3.5, 5.0
+	if charset == "hex" {
+		floor, ceiling = 3.0, 4.0
+	}
+	if entropy < floor {
+		return 0
+	}
+	score := 20 + int((entropy-floor)/(ceiling-floor)*20)
+	return min(score, 40)
+}
+
+// classifyCharset 返回 hex / alnum / alnum-mixed / base64；不像密钥的字符集返回空字符串
+func classifyCharset(value string) string {
+	var lower, upper, digit, symbol, other bool
+	for _, ch := range value {
+		switch {
+		case ch >= 'a' && ch <= 'z':
+			lower = true
+		case ch >= 'A' && ch <= 'Z':
+			upper = true
+		case ch >= '0' && ch <= '9':
+			digit = true
+		case ch == '+' || ch == '/' || ch == '=' || ch == '-' || ch == '_':
+			symbol = true
+		default:
+			other = true
+		}
+	}
+	switch {
+	case other || !digit:
+		// 含其它字符的是文本，不含数字的通常是标识符或单词
+		return ""
+	case !symbol && isHexString(value):
+		return "hex"
+	case symbol:
+		return "base64"
+	case lower && upper:
+		return "alnum-mixed"
+	default:
+		return "alnum"
+	}
+}
+
+func isHexString(value string) bool {
+	for _, ch := range value {
+		if !(ch >= '0' && ch <= '9') && !(ch >= 'a' && ch <= 'f') && !(ch >= 'A' && ch <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// secretKeyScore 把 appSecret、APP_SECRET、app-secret 统一成 app_secret 后匹配
+func secretKeyScore(key string) int {
+	normalized := strings.ToLower(camelBoundary.ReplaceAllString(strings.TrimSpace(key), "${1}_${2}"))
+	normalized = strings.Trim(secretKeyNormalizer.ReplaceAllString(normalized, "_"), "_")
+	// 单独的 key 多是列表渲染或缓存键名，不作为证据
+	if normalized == "" || normalized == "key" {
+		return 0
+	}
+	compact := strings.ReplaceAll(normalized, "_", "")
+	for _, suffix := range nonSecretKeySuffixes {
+		if strings.HasSuffix(normalized, "_"+suffix) || normalized == suffix {
+			return 0
+		}
+	}
+	for _, fragment := range strongSecretKeys {
+		if strings.Contains(normalized, fragment) || strings.Contains(compact, strings.ReplaceAll(fragment, "_", "")) {
+			return 40
+		}
+	}
+	for _, part := range strings.Split(normalized, "_") {
+		for _, fragment := range weakSecretKeys {
+			if part == fragment {
+				return 20
+			}
+		}
+	}
+	return 0
+}
+
+// collectJSCandidates 遍历 AST 收集 var x = "..."、a.b = "..."、{key: "..."} 三类赋值
+func collectJSCandidates(source string) []genericCandidate {
+	program, err := parser.ParseFile(nil, "", source, parser.IgnoreRegExpErrors, parser.WithDisableSourceMaps)
+	if err != nil || program == nil {
+		return nil
+	}
+
+	var candidates []genericCandidate
+	// Program 的 DeclarationList 会再次引用 var 声明，按字面量位置去重
+	seen := make(map[file.Idx]struct{})
+	add := func(key string, value ast.Expression) {
+		literal, ok := value.(*ast.StringLiteral)
+		if !ok || literal == nil || key == "" {
+			return
+		}
+		if _, ok := seen[literal.Idx]; ok {
+			return
+		}
+		seen[literal.Idx] = struct{}{}
+		candidates = append(candidates, genericCandidate{
+			key:    key,
+			value:  literal.Value.String(),
+			offset: int(literal.Idx), // Idx 从 1 开始且指向引号，恰好是值的首字节偏移
+		})
+	}
+
+	walkASTNode(program, func(node ast.Node) {
+		switch n := node.(type) {
+		case *ast.Binding:
+			if identifier, ok := n.Target.(*ast.Identifier); ok && identifier != nil {
+				add(identifier.Name.String(), n.Initializer)
+			}
+		case *ast.AssignExpression:
+			add(expressionKeyName(n.Left), n.Right)
+		case *ast.PropertyKeyed:
+			if !n.Computed {
+				add(expressionKeyName(n.Key), n.Value)
+			}
+		}
+	})
+	return candidates
+}
+
+func expressionKeyName(expression ast.Expression) string {
+	switch node := expression.(type) {
+	case *ast.Identifier:
+		if node != nil {
+			return node.Name.String()
+		}
+	case *ast.StringLiteral:
+		if node != nil {
+			return node.Value.String()
+		}
+	case *ast.DotExpression:
+		if node != nil {
+			return node.Identifier.Name.String()
+		}
+	case *ast.BracketExpression:
+		if node != nil {
+			return expressionKeyName(node.Member)
+		}
+	}
+	return ""
+}
+
+// collectJSONCandidates JSON 配置中的 "appSecret": "..." 同样按键名打分
+func collectJSONCandidates(content []byte) []genericCandidate {
+	var root interface{}
+	if err := json.Unmarshal(content, &root); err != nil {
+		return nil
+	}
+	var candidates []genericCandidate
+	var walk func(key string, value interface{})
+	walk = func(key string, value interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			keys := make([]string, 0, len(v))
+			for k := range v {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				walk(k, v[k])
+			}
+		case []interface{}:
+			for _, item := range v {
+				walk(key, item)
+			}
+		case string:
+			if key != "" {
+				candidates = append(candidates, genericCandidate{key: key, value: v, offset: -1})
+			}
+		}
+	}
+	walk("", root)
+	return candidates
+}
+
+// lineIndex 把字节偏移换算为行号，并截取命中附近的上下文（压缩代码的单行可能很长）
+type lineIndex struct {
+	content []byte
+	starts  []int
+}
+
+func newLineIndex(content []byte) *lineIndex {
+	starts := []int{0}
+	for i, ch := range content {
+		if ch == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return &lineIndex{content: content, starts: starts}
+}
+
+func (l *lineIndex) locate(offset, length int) (int, string) {
+	if offset < 0 || offset >= len(l.content) {
+		return 0, ""
+	}
+	line := sort.Search(len(l.starts), func(i int) bool { return l.starts[i] > offset })
+	start := l.starts[line-1]
+	end := len(l.content)
+	if line < len(l.starts) {
+		end = l.starts[line] - 1
+	}
+
+	from := max(start, offset-genericSecretContextWidth/2)
+	to := min(end, offset+length+genericSecretContextWidth/2)
+	return line, strings.TrimSpace(string(l.content[from:to]))
+}
+
+func walkASTNode(node ast.Node, fn func(ast.Node)) {
+	if isNilASTNode(node) {
+		return
+	}
+
+	fn(node)
+	walkASTStructFields(reflect.ValueOf(node), fn)
+}
+
+func isNilASTNode(node ast.Node) bool {
+	if node == nil {
+		return true
+	}
+	value := reflect.ValueOf(node)
+	switch value.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Pointer, reflect.Slice:
+		return value.IsNil()
+	default:
+		return false
+	}
+}
+
+func walkASTStructFields(value reflect.Value, fn func(ast.Node)) {
+	if !value.IsValid() {
+		return
+	}
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return
+		}
+		// 只向下遍历 goja AST 自身的结构，避免进入 file.File 等辅助对象
+		if value.Elem().Kind() == reflect.Struct && value.Elem().Type().PkgPath() != gojaASTPackagePath {
+			return
+		}
+		walkASTStructFields(value.Elem(), fn)
+		return
+	}
+
+	switch value.Kind() {
+	case reflect.Interface:
+		if value.IsNil() {
+			return
+		}
+		elem := value.Elem()
+		if elem.CanInterface() {
+			if node, ok := elem.Interface().(ast.Node); ok {
+				walkASTNode(node, fn)
+				return
+			}
+		}
+		if elem.Kind() == reflect.Struct && elem.Type().PkgPath() != gojaASTPackagePath {
+			return
+		}
+		walkASTStructFields(elem, fn)
+	case reflect.Struct:
+		if value.Type().PkgPath() != "" && value.Type().PkgPath() != gojaASTPackagePath {
+			return
+		}
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Field(i)
+			if !field.CanInterface() {
+				continue
+			}
+			if node, ok := field.Interface().(ast.Node); ok {
+				walkASTNode(node, fn)
+				continue
+			}
+			walkASTStructFields(field, fn)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			item := value.Index(i)
+			if item.CanInterface() {
+				if node, ok := item.Interface().(ast.Node); ok {
+					walkASTNode(node, fn)
+					continue
+				}
+			}
+			walkASTStructFields(item, fn)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package scanner
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/dop251/goja/ast"
+)
+
+func TestDetectGenericSecretsInMinifiedJS(t *testing.T) {
+	source := `var a=1;` + "\n" +
+		`var e={appSecret:"Zq8Lr2Vx9Ta4Kb7Wm1Np6Yc3",appId:"wx8f2c1a9b7d3e4f50",title:"Hello World 2024"};` +
+		`t.accessKey="3f9a1c7e5b2d8046ac1e9f7b3d5a2c8e";var secretUrl="Zq8Lr2Vx9Ta4Kb7Wm1Np6Yc3aa";` +
+		`var list=[{key:"a1b2c3d4e5f6a7b8"}];var token="xxxxxxxxxxxxxxxxxxxx";`
+
+	items := DetectGenericSecrets("app-service.js", []byte(source))
+	got := make(map[string]SensitiveItem)
+	for _, item := range items {
+		got[item.Content] = item
+	}
+	if len(items) != 2 {
+		t.Fatalf("应只检出 appSecret 与 accessKey: %+v", items)
+	}
+
+	secret, ok := got["Zq8Lr2Vx9Ta4Kb7Wm1Np6Yc3"]
+	if !ok || secret.RuleID != GenericSecretRuleID || secret.Category != "generic_secret" || secret.LineNumber != 2 {
+		t.Fatalf("appSecret 结果错误: %+v", secret)
+	}
+	if !strings.Contains(secret.Description, "appSecret") || !strings.Contains(secret.Description, "熵") {
+		t.Fatalf("缺少评分依据: %s", secret.Description)
+	}
+	if !strings.Contains(secret.Context, "appSecret") || len(secret.Context) > genericSecretContextWidth+len(secret.Content)+2 {
+		t.Fatalf("上下文应截取命中附近: %q", secret.Context)
+	}
+	if _, ok := got["3f9a1c7e5b2d8046ac1e9f7b3d5a2c8e"]; !ok {
+		t.Fatalf("成员赋值 t.accessKey 未检出: %+v", items)
+	}
+}
+
+func TestDetectGenericSecretsInJSON(t *testing.T) {
+	content := []byte("{\n  \"cloud\": {\n    \"client_secret\": \"gH4kP9sQ2vX7zB1nM6tR3wY8\"\n  }\n}")
+	items := DetectGenericSecrets("config.json", content)
+	if len(items) != 1 || items[0].LineNumber != 3 {
+		t.Fatalf("JSON 配置结果错误: %+v", items)
+	}
+}
+
+func TestScoreGenericSecret(t *testing.T) {
+	cases := []struct {
+		key, value string
+		want       bool
+	}{
+		{"password", "Tr0ub4dor&3", false},                // 太短且含其它字符
+		{"apiKey", "aaaaaaaaaaaaaaaa1111", false},         // 熵过低
+		{"userName", "Zq8Lr2Vx9Ta4Kb7Wm1Np6Yc3", false},   // 键名不指向凭据
+		{"private_key", "MIIBVgIBADANBgkqhkiG9w0B", true}, // base64
+		{"signKey", "9c4e1f7a3b8d2065", true},             // hex
+		{"aesIv", "k3J9xQ2mV7pL4tR8", true},               // 弱键名 + 高熵
+	}
+	for _, tc := range cases {
+		finding, ok := ScoreGenericSecret(tc.key, tc.value)
+		if ok != tc.want {
+			t.Fatalf("%s=%s: got %v (score %d, entropy %.2f, charset %s)", tc.key, tc.value, ok, finding.Score, finding.Entropy, finding.Charset)
+		}
+	}
+}
+
+func TestScanFileSkipsGenericSecretCoveredByRule(t *testing.T) {
+	rule := &CompiledRule{ID: "custom_api_token", Pattern: regexp.MustCompile(`tk_[A-Za-z0-9]{20}`), Category: "token", Confidence: "high"}
+	collector := NewCollector("wx-test")
+	source := []byte(`var apiToken="tk_Zq8Lr2Vx9Ta4Kb7Wm1Np";`)
+	if err := ScanFile("a.js", source, []*CompiledRule{rule}, collector); err != nil {
+		t.Fatal(err)
+	}
+	report := collector.GenerateReport()
+	if len(report.Items) != 1 || report.Items[0].RuleID != "custom_api_token" {
+		t.Fatalf("正则已命中的值不应再由熵检测重复报告: %+v", report.Items)
+	}
+}
+
+func TestWalkASTNodeSkipsTypedNilNode(t *testing.T) {
+	var identifier *ast.Identifier
+	called := false
+
+	walkASTNode(identifier, func(node ast.Node) {
+		called = true
+	})
+
+	if called {
+		t.Fatal("walkASTNode 不应访问 typed nil AST 节点")
+	}
+}
@@ -50,19 +50,20 @@ var (
 	}
 
 	credentialCategories = map[string]bool{
-		"password":      true,
-		"api_key":       true,
-		"secret":        true,
-		"token":         true,
-		"private_key":   true,
-		"cloud":         true,
-		"payment":       true,
-		"messaging":     true,
-		"devops":        true,
-		"observability": true,
-		"security":      true,
-		"saas":          true,
-		"wechat":        true,
+		"password":       true,
+		"api_key":        true,
+		"secret":         true,
+		"token":          true,
+		"private_key":    true,
+		"cloud":          true,
+		"payment":        true,
+		"messaging":      true,
+		"devops":         true,
+		"observability":  true,
+		"security":       true,
+		"saas":           true,
+		"wechat":         true,
+		"generic_secret": true,
 	}
 
 	placeholderValues = map[string]bool{
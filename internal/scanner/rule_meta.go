@@ -8,6 +8,8 @@ import (
 var nonAlnumPattern = regexp.MustCompile(`[^a-z0-9]+`)
 
 var exactCategoryMap = map[string]string{
+	"generic_secret": "generic_secret",
+
 	"path":         "path",
 	"url":          "url",
 	"api_endpoint": "url",
@@ -108,6 +110,9 @@ var exactCategoryMap = map[string]string{
 	"private_key_pkcs8":   "private_key",
 	"pgp_private_key":     "private_key",
 	"certificate":         "private_key",
+	"public_key_pem":      "private_key",
+	"der_key_base64":      "private_key",
+	"sm2_public_key_hex":  "private_key",
 
 	"aws_access_key_id":     "cloud",
 	"aws_secret_access_key": "cloud",
@@ -145,6 +150,7 @@ var exactCategoryMap = map[string]string{
 	"razorpay_key":      "payment",
 	"alipay_key":        "payment",
 	"wechatpay_key":     "payment",
+	"wechatpay_mch_key": "payment",
 	"shopify_token":     "payment",
 
 	"slack_token":             "messaging",
@@ -219,30 +225,32 @@ var exactCategoryMap = map[string]string{
 }
 
 var categoryNames = map[string]string{
-	"path":          "路径",
-	"url":           "URL/API",
-	"domain":        "域名",
-	"contact":       "联系信息",
-	"network":       "网络标识",
-	"database":      "数据库与连接",
-	"password":      "密码",
-	"api_key":       "API 密钥",
-	"secret":        "Secret/密钥",
-	"token":         "Token/令牌",
-	"private_key":   "私钥与证书",
-	"artifact":      "编码与指纹",
-	"cloud":         "云平台",
-	"payment":       "支付与电商",
-	"messaging":     "通知与协作",
-	"devops":        "开发与交付",
-	"observability": "监控与告警",
-	"security":      "安全平台",
-	"saas":          "第三方 SaaS",
-	"wechat":        "微信生态",
-	"other":         "其他",
+	"path":           "路径",
+	"url":            "URL/API",
+	"domain":         "域名",
+	"contact":        "联系信息",
+	"network":        "网络标识",
+	"database":       "数据库与连接",
+	"password":       "密码",
+	"api_key":        "API 密钥",
+	"secret":         "Secret/密钥",
+	"token":          "Token/令牌",
+	"private_key":    "私钥与证书",
+	"artifact":       "编码与指纹",
+	"cloud":          "云平台",
+	"payment":        "支付与电商",
+	"messaging":      "通知与协作",
+	"devops":         "开发与交付",
+	"observability":  "监控与告警",
+	"security":       "安全平台",
+	"saas":           "第三方 SaaS",
+	"wechat":         "微信生态",
+	"generic_secret": "通用密钥（熵检测）",
+	"other":          "其他",
 }
 
 var ruleNames = map[string]string{
+	"generic_secret":       "疑似通用密钥",
 	"email":                "邮箱",
 	"phone_cn":             "手机号",
 	"id_card_cn":           "身份证",
@@ -262,8 +270,13 @@ var ruleNames = map[string]string{
 	"private_key_rsa":      "RSA 私钥",
 	"private_key_dsa":      "DSA 私钥",
 	"private_key_ec":       "EC 私钥",
+	"certificate":          "X.509 证书",
+	"public_key_pem":       "PEM 公钥",
+	"der_key_base64":       "Base64 DER 密钥/证书",
+	"sm2_public_key_hex":   "SM2 公钥（十六进制）",
 	"wechat_appid":         "微信 AppID",
 	"wechat_secret":        "微信 Secret",
+	"wechatpay_mch_key":    "微信支付商户密钥",
 	"jdbc_mysql":           "MySQL 连接",
 	"mongodb_connection":   "MongoDB 连接",
 	"redis_connection":     "Redis 连接",
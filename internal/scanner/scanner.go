@@ -9,17 +9,63 @@ import (
 
 // CompiledRule 编译后的规则
 type CompiledRule struct {
-	ID         string
-	Pattern    *regexp.Regexp
-	Category   string
-	Confidence string
+	ID          string
+	Name        string
+	Pattern     *regexp.Regexp
+	Category    string
+	Confidence  string
+	Description string
+	Remediation string
+	// Keywords 预过滤关键字（小写），行内不含任一关键字时跳过正则匹配；为空时不过滤
+	Keywords []string
+	// Allowlist 命中内容匹配任一模式时视为误报
+	Allowlist []*regexp.Regexp
 }
 
-// CompiledRules 全局编译后的规则 (由 key 包设置)
-var CompiledRules []*CompiledRule
+// FindAll 返回 line 中该规则的全部命中，已应用关键字预过滤与白名单
+func (r *CompiledRule) FindAll(line string) []string {
+	if len(r.Keywords) > 0 {
+		lower := strings.ToLower(line)
+		found := false
+		for _, keyword := range r.Keywords {
+			if strings.Contains(lower, keyword) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil
+		}
+	}
+
+	var matches []string
+	for _, match := range r.Pattern.FindAllString(line, -1) {
+		if strings.TrimSpace(match) == "" || r.allowed(match) {
+			continue
+		}
+		matches = append(matches, match)
+	}
+	return matches
+}
+
+func (r *CompiledRule) allowed(match string) bool {
+	for _, pattern := range r.Allowlist {
+		if pattern.MatchString(match) {
+			return true
+		}
+	}
+	return false
+}
 
-// ScanFile 扫描单个文件
-func ScanFile(filePath string, content []byte, collector *DataCollector) error {
+func (r *CompiledRule) displayName() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return GetRuleName(r.ID)
+}
+
+// ScanFile 使用 rules 扫描单个文件，结果写入 collector
+func ScanFile(filePath string, content []byte, rules []*CompiledRule, collector *DataCollector) error {
 	// 转换为字符串
 	text := string(content)
 
@@ -27,6 +73,7 @@ func ScanFile(filePath string, content []byte, collector *DataCollector) error {
 		for _, endpoint := range ExtractAPIEndpoints(filePath, content) {
 			collector.AddAPIEndpoint(endpoint)
 		}
+		collector.addBaseURLSource(extractBaseURLSource(filePath, text))
 	}
 
 	// 按行扫描以获取行号
@@ -36,6 +83,8 @@ func ScanFile(filePath string, content []byte, collector *DataCollector) error {
 	buf := make([]byte, maxScanTokenSize)
 	scanner.Buffer(buf, maxScanTokenSize)
 	lineNumber := 1
+	// 凭据类规则已命中的内容，熵检测不再重复报告
+	var credentialMatches []string
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -45,31 +94,49 @@ func ScanFile(filePath string, content []byte, collector *DataCollector) error {
 		}
 
 		// 使用所有规则扫描这一行
-		for _, rule := range CompiledRules {
-			matches := rule.Pattern.FindAllString(line, -1)
-			for _, match := range matches {
-				if strings.TrimSpace(match) == "" {
-					continue
-				}
-
+		for _, rule := range rules {
+			for _, match := range rule.FindAll(line) {
 				item := SensitiveItem{
-					RuleID:     rule.ID,
-					RuleName:   GetRuleName(rule.ID),
-					Category:   rule.Category,
-					Content:    match,
-					FilePath:   filePath,
-					LineNumber: lineNumber,
-					Context:    line,
-					Confidence: rule.Confidence,
-					Timestamp:  time.Now().Format("2006-01-02 15:04:05"),
+					RuleID:      rule.ID,
+					RuleName:    rule.displayName(),
+					Category:    rule.Category,
+					Content:     match,
+					FilePath:    filePath,
+					LineNumber:  lineNumber,
+					Context:     line,
+					Confidence:  rule.Confidence,
+					Description: rule.Description,
+					Remediation: rule.Remediation,
+					Timestamp:   time.Now().Format("2006-01-02 15:04:05"),
 				}
 
 				collector.Add(item)
+				if credentialCategories[rule.Category] {
+					credentialMatches = append(credentialMatches, match)
+				}
 			}
 		}
 
 		lineNumber++
 	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
 
-	return scanner.Err()
+	// 正则之外再按赋值语义 + 熵检测通用密钥
+	for _, item := range DetectGenericSecrets(filePath, content) {
+		if !containsMatch(credentialMatches, item.Content) {
+			collector.Add(item)
+		}
+	}
+	return nil
+}
+
+func containsMatch(matches []string, value string) bool {
+	for _, match := range matches {
+		if strings.Contains(match, value) {
+			return true
+		}
+	}
+	return false
 }
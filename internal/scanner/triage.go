@@ -0,0 +1,297 @@
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/25smoking/Gwxapkg/internal/util"
+	"gopkg.in/yaml.v3"
+)
+
+// 发现状态
+const (
+	StatusNew        = "new"        // 基线中没有的新发现
+	StatusKnown      = "known"      // 基线中已存在
+	StatusSuppressed = "suppressed" // 命中未过期的忽略规则
+)
+
+const (
+	baselineVersion   = 1
+	suppressionLayout = "2006-01-02"
+)
+
+// ContentHash 计算命中内容的 SHA-256，基线与忽略规则只保存哈希，不落盘明文
+func ContentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// BaselineFinding 基线中的单条发现。文件路径会随混淆哈希变化，因此只按规则与内容识别。
+type BaselineFinding struct {
+	RuleID      string `json:"rule_id"`
+	ContentHash string `json:"content_hash"`
+}
+
+// Baseline 某次扫描结果的快照，再次扫描时其中的发现标记为 known
+type Baseline struct {
+	Version     int               `json:"version"`
+	AppID       string            `json:"app_id,omitempty"`
+	GeneratedAt string            `json:"generated_at"`
+	Findings    []BaselineFinding `json:"findings"`
+}
+
+// NewBaseline 由扫描报告生成基线，已知与已忽略的发现一并计入
+func NewBaseline(report *ScanReport) *Baseline {
+	baseline := &Baseline{
+		Version:     baselineVersion,
+		AppID:       report.AppID,
+		GeneratedAt: time.Now().Format(time.RFC3339),
+		Findings:    make([]BaselineFinding, 0, len(report.Items)),
+	}
+	seen := make(map[string]struct{})
+	for _, items := range [][]SensitiveItem{report.Items, report.KnownItems, report.SuppressedItems} {
+		for _, item := range items {
+			finding := BaselineFinding{RuleID: item.RuleID, ContentHash: ContentHash(item.Content)}
+			key := finding.RuleID + ":" + finding.ContentHash
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			baseline.Findings = append(baseline.Findings, finding)
+		}
+	}
+	return baseline
+}
+
+// LoadBaseline 读取基线文件；也可以直接传入上一次的 sensitive_report.json
+func LoadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取基线失败: %w", err)
+	}
+
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("解析基线失败: %w", err)
+	}
+	if _, ok := probe["findings"]; !ok {
+		var report ScanReport
+		if err := json.Unmarshal(data, &report); err != nil {
+			return nil, fmt.Errorf("解析扫描报告失败: %w", err)
+		}
+		return NewBaseline(&report), nil
+	}
+
+	var baseline Baseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("解析基线失败: %w", err)
+	}
+	return &baseline, nil
+}
+
+// Write 写出基线文件
+func (b *Baseline) Write(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化基线失败: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建输出目录失败: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("写入基线失败: %w", err)
+	}
+	return nil
+}
+
+// Contains 判断发现是否已在基线中
+func (b *Baseline) Contains(item SensitiveItem) bool {
+	return slices.Contains(b.Findings, BaselineFinding{RuleID: item.RuleID, ContentHash: ContentHash(item.Content)})
+}
+
+// Suppression 单条忽略规则。RuleID、ContentHash、Path 至少填写一项，填写的条件需同时满足。
+type Suppression struct {
+	RuleID        string `yaml:"rule_id" json:"rule_id,omitempty"`
+	ContentHash   string `yaml:"content_hash" json:"content_hash,omitempty"`
+	Path          string `yaml:"path" json:"path,omitempty"`       // glob，所有出现位置都匹配时才忽略
+	Expires       string `yaml:"expires" json:"expires,omitempty"` // YYYY-MM-DD，到期日当天仍有效
+	Justification string `yaml:"justification" json:"justification"`
+}
+
+type suppressionFile struct {
+	Suppressions []Suppression `yaml:"suppressions"`
+}
+
+// LoadSuppressions 读取 YAML（或 JSON）格式的忽略文件
+func LoadSuppressions(path string) ([]Suppression, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取忽略文件失败: %w", err)
+	}
+	var file suppressionFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("解析忽略文件失败: %w", err)
+	}
+	for i := range file.Suppressions {
+		if err := file.Suppressions[i].compile(); err != nil {
+			return nil, fmt.Errorf("忽略规则 #%d: %w", i+1, err)
+		}
+	}
+	return file.Suppressions, nil
+}
+
+func (s *Suppression) compile() error {
+	s.RuleID = strings.TrimSpace(s.RuleID)
+	s.ContentHash = strings.ToLower(strings.TrimSpace(s.ContentHash))
+	s.Path = strings.TrimSpace(s.Path)
+	if s.RuleID == "" && s.ContentHash == "" && s.Path == "" {
+		return fmt.Errorf("rule_id、content_hash、path 至少需要填写一项")
+	}
+	if strings.TrimSpace(s.Justification) == "" {
+		return fmt.Errorf("缺少 justification")
+	}
+	if s.Expires != "" {
+		if _, err := time.ParseInLocation(suppressionLayout, s.Expires, time.Local); err != nil {
+			return fmt.Errorf("expires 格式应为 YYYY-MM-DD: %s", s.Expires)
+		}
+	}
+	return nil
+}
+
+// Expired 忽略规则是否已过期；expires 无法解析时视为已过期，避免误忽略
+func (s Suppression) Expired(now time.Time) bool {
+	if s.Expires == "" {
+		return false
+	}
+	expires, err := time.ParseInLocation(suppressionLayout, s.Expires, time.Local)
+	if err != nil {
+		return true
+	}
+	return !now.Before(expires.AddDate(0, 0, 1))
+}
+
+func (s Suppression) matches(item SensitiveItem, contentHash string, paths []string, matcher *util.GlobMatcher) bool {
+	if s.RuleID != "" && s.RuleID != "*" && s.RuleID != item.RuleID {
+		return false
+	}
+	if s.ContentHash != "" && !strings.EqualFold(s.ContentHash, contentHash) {
+		return false
+	}
+	if matcher != nil {
+		if len(paths) == 0 {
+			return false
+		}
+		for _, path := range paths {
+			if !matcher.Match(path) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Triage 控制 GenerateReport 如何区分新发现、已知发现与已忽略发现。
+// 首次分类时才编译规则，之后只读，可在多个并发运行间共享。
+type Triage struct {
+	Baseline     *Baseline
+	Suppressions []Suppression
+	// ShowAll 为 true 时已知与已忽略的发现也保留在 Items 中，默认只保留新发现
+	ShowAll bool
+	// Now 判断忽略规则是否过期的时间，零值使用当前时间
+	Now time.Time
+
+	once     sync.Once
+	known    map[BaselineFinding]struct{}
+	active   []Suppression
+	matchers []*util.GlobMatcher
+}
+
+func (t *Triage) prepare() {
+	t.once.Do(func() {
+		if t.Baseline != nil {
+			t.known = make(map[BaselineFinding]struct{}, len(t.Baseline.Findings))
+			for _, finding := range t.Baseline.Findings {
+				t.known[finding] = struct{}{}
+			}
+		}
+		now := t.Now
+		if now.IsZero() {
+			now = time.Now()
+		}
+		for _, suppression := range t.Suppressions {
+			if suppression.Expired(now) {
+				continue
+			}
+			var matcher *util.GlobMatcher
+			if suppression.Path != "" {
+				matcher = util.NewGlobMatcher([]string{suppression.Path})
+			}
+			t.active = append(t.active, suppression)
+			t.matchers = append(t.matchers, matcher)
+		}
+	})
+}
+
+// LoadTriage 按路径加载基线与忽略文件，路径为空的部分跳过
+func LoadTriage(baselinePath, suppressionPath string, showAll bool) (*Triage, error) {
+	triage := &Triage{ShowAll: showAll}
+	if baselinePath != "" {
+		baseline, err := LoadBaseline(baselinePath)
+		if err != nil {
+			return nil, err
+		}
+		triage.Baseline = baseline
+	}
+	if suppressionPath != "" {
+		suppressions, err := LoadSuppressions(suppressionPath)
+		if err != nil {
+			return nil, err
+		}
+		triage.Suppressions = suppressions
+	}
+	return triage, nil
+}
+
+// Classify 返回发现的状态；被忽略时一并返回理由
+func (t *Triage) Classify(item SensitiveItem, paths []string) (string, string) {
+	if t == nil {
+		return StatusNew, ""
+	}
+	t.prepare()
+	contentHash := ContentHash(item.Content)
+	for i, suppression := range t.active {
+		if suppression.matches(item, contentHash, paths, t.matchers[i]) {
+			return StatusSuppressed, suppression.Justification
+		}
+	}
+	if _, ok := t.known[BaselineFinding{RuleID: item.RuleID, ContentHash: contentHash}]; ok {
+		return StatusKnown, ""
+	}
+	return StatusNew, ""
+}
+
+// ExpiredSuppressions 列出已过期的忽略规则，便于提醒复核
+func (t *Triage) ExpiredSuppressions() []Suppression {
+	if t == nil {
+		return nil
+	}
+	now := t.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	var expired []Suppression
+	for _, suppression := range t.Suppressions {
+		if suppression.Expired(now) {
+			expired = append(expired, suppression)
+		}
+	}
+	return expired
+}
@@ -0,0 +1,140 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGenerateReportPartitionsByTriage(t *testing.T) {
+	collector := NewCollector("wx-test")
+	collector.Add(SensitiveItem{RuleID: "phone", Category: "contact", Content: "13912345678", FilePath: "pages/a.js", LineNumber: 1, Confidence: "medium"})
+	collector.Add(SensitiveItem{RuleID: "phone", Category: "contact", Content: "13912345678", FilePath: "pages/b.js", LineNumber: 2, Confidence: "medium"})
+	collector.Add(SensitiveItem{RuleID: "phone", Category: "contact", Content: "13587654321", FilePath: "vendor/sdk.js", LineNumber: 3, Confidence: "medium"})
+	collector.Add(SensitiveItem{RuleID: "email", Category: "contact", Content: "ops@shop-wx.com.cn", FilePath: "app.js", LineNumber: 4, Confidence: "low"})
+	collector.Add(SensitiveItem{RuleID: "email", Category: "contact", Content: "dev@shop-wx.com.cn", FilePath: "app.js", LineNumber: 5, Confidence: "low"})
+
+	baseline := &Baseline{Findings: []BaselineFinding{{RuleID: "email", ContentHash: ContentHash("ops@shop-wx.com.cn")}}}
+	triage := &Triage{
+		Baseline: baseline,
+		Suppressions: []Suppression{
+			// 只在 vendor 下出现时忽略；13912345678 在 pages 下出现，不应被忽略
+			{RuleID: "phone", Path: "vendor/**", Justification: "第三方 SDK 示例号码"},
+			{ContentHash: ContentHash("dev@shop-wx.com.cn"), Expires: "2026-01-31", Justification: "已过期"},
+		},
+		Now: time.Date(2026, 2, 1, 0, 0, 0, 0, time.Local),
+	}
+	collector.SetTriage(triage)
+
+	report := collector.GenerateReport()
+	if len(report.Items) != 2 || len(report.KnownItems) != 1 || len(report.SuppressedItems) != 1 {
+		t.Fatalf("分组错误: items=%d known=%d suppressed=%d", len(report.Items), len(report.KnownItems), len(report.SuppressedItems))
+	}
+	if report.SuppressedItems[0].Content != "13587654321" || report.SuppressedItems[0].Justification != "第三方 SDK 示例号码" {
+		t.Fatalf("忽略项错误: %+v", report.SuppressedItems[0])
+	}
+	if report.KnownItems[0].Status != StatusKnown {
+		t.Fatalf("已知项状态错误: %+v", report.KnownItems[0])
+	}
+	for _, item := range report.Items {
+		if item.Status != StatusNew || item.ContentHash == "" {
+			t.Fatalf("新发现缺少状态或哈希: %+v", item)
+		}
+	}
+
+	summary := report.Summary
+	if summary.NewCount != 2 || summary.KnownCount != 1 || summary.Suppressed != 1 {
+		t.Fatalf("摘要计数错误: %+v", summary)
+	}
+	// 分类与风险统计只覆盖新发现
+	if summary.UniqueMatches != 2 || summary.TotalMatches != 3 || summary.MediumRisk != 1 || summary.LowRisk != 1 {
+		t.Fatalf("摘要统计错误: %+v", summary)
+	}
+	if report.Categories["contact"].UniqueCount != 2 {
+		t.Fatalf("分类统计错误: %+v", report.Categories["contact"])
+	}
+
+	expired := triage.ExpiredSuppressions()
+	if len(expired) != 1 || expired[0].Justification != "已过期" {
+		t.Fatalf("过期规则错误: %+v", expired)
+	}
+}
+
+func TestGenerateReportShowAllKeepsTriagedItems(t *testing.T) {
+	collector := NewCollector("wx-test")
+	collector.Add(SensitiveItem{RuleID: "email", Category: "contact", Content: "ops@shop-wx.com.cn", FilePath: "app.js"})
+	collector.SetTriage(&Triage{
+		Baseline: &Baseline{Findings: []BaselineFinding{{RuleID: "email", ContentHash: ContentHash("ops@shop-wx.com.cn")}}},
+		ShowAll:  true,
+	})
+
+	report := collector.GenerateReport()
+	if len(report.Items) != 1 || report.Items[0].Status != StatusKnown || len(report.KnownItems) != 0 {
+		t.Fatalf("show-all 应保留已知项: %+v", report)
+	}
+	if report.Summary.KnownCount != 1 || report.Summary.NewCount != 0 {
+		t.Fatalf("摘要计数错误: %+v", report.Summary)
+	}
+}
+
+func TestLoadBaselineAcceptsScanReport(t *testing.T) {
+	dir := t.TempDir()
+	reportPath := filepath.Join(dir, "sensitive_report.json")
+	data := `{"app_id":"wx-test","items":[{"rule_id":"email","content":"ops@shop-wx.com.cn"}],"known_items":[{"rule_id":"phone","content":"13912345678"}]}`
+	if err := os.WriteFile(reportPath, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	baseline, err := LoadBaseline(reportPath)
+	if err != nil {
+		t.Fatalf("读取报告失败: %v", err)
+	}
+	if len(baseline.Findings) != 2 || !baseline.Contains(SensitiveItem{RuleID: "phone", Content: "13912345678"}) {
+		t.Fatalf("基线内容错误: %+v", baseline.Findings)
+	}
+
+	// 写出后再读回应得到相同结果
+	baselinePath := filepath.Join(dir, "baseline.json")
+	if err := baseline.Write(baselinePath); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := LoadBaseline(baselinePath)
+	if err != nil || len(reloaded.Findings) != 2 {
+		t.Fatalf("基线读回错误: %+v %v", reloaded, err)
+	}
+}
+
+func TestLoadSuppressionsValidatesEntries(t *testing.T) {
+	dir := t.TempDir()
+	cases := map[string]string{
+		"missing-criteria": "suppressions:\n  - justification: 无条件\n",
+		"missing-reason":   "suppressions:\n  - rule_id: phone\n",
+		"bad-date":         "suppressions:\n  - rule_id: phone\n    expires: 2026/01/01\n    justification: 日期格式错误\n",
+	}
+	for name, content := range cases {
+		path := filepath.Join(dir, name+".yaml")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadSuppressions(path); err == nil {
+			t.Fatalf("%s: 应返回错误", name)
+		}
+	}
+
+	path := filepath.Join(dir, "ok.yaml")
+	content := "suppressions:\n  - rule_id: phone\n    path: \"vendor/**\"\n    expires: 2026-12-31\n    justification: 第三方 SDK\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	suppressions, err := LoadSuppressions(path)
+	if err != nil || len(suppressions) != 1 {
+		t.Fatalf("读取忽略文件失败: %+v %v", suppressions, err)
+	}
+	if suppressions[0].Expired(time.Date(2026, 12, 31, 23, 0, 0, 0, time.Local)) {
+		t.Fatalf("到期日当天仍应有效")
+	}
+	if !suppressions[0].Expired(time.Date(2027, 1, 1, 0, 0, 0, 0, time.Local)) {
+		t.Fatalf("到期日次日应过期")
+	}
+}
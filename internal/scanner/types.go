@@ -11,6 +11,19 @@ type SensitiveItem struct {
 	Context    string `json:"context"`    // 完整行内容
 	Confidence string `json:"confidence"` // high/medium/low
 	Timestamp  string `json:"timestamp"`
+
+	Description string `json:"description,omitempty"` // 规则描述，来自规则包
+	Remediation string `json:"remediation,omitempty"` // 修复建议，来自规则包
+
+	ContentHash   string `json:"content_hash,omitempty"`
+	Status        string `json:"status,omitempty"`        // new/known/suppressed
+	Justification string `json:"justification,omitempty"` // 被忽略时的理由
+
+	Verification       string `json:"verification,omitempty"`        // structurally_valid/expired/malformed
+	VerificationDetail string `json:"verification_detail,omitempty"` // 校验依据
+	VerifiedBy         string `json:"verified_by,omitempty"`         // 给出结论的校验器
+
+	Decoded *DecodedCredential `json:"decoded,omitempty"` // JWT、密钥与证书的解析结果
 }
 
 // APIEndpoint 提取到的接口信息
@@ -22,6 +35,23 @@ type APIEndpoint struct {
 	LineNumber int    `json:"line_number"`
 	SourceRule string `json:"source_rule"`
 	Context    string `json:"context"`
+
+	// Headers 与 Body 仅在运行时追踪等能还原完整请求的来源中填写
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+
+	// BaseURLName 为相对地址拼接的基础地址变量名，ResolvedURLs 为各环境下的完整地址
+	BaseURLName  string            `json:"base_url_name,omitempty"`
+	ResolvedURLs map[string]string `json:"resolved_urls,omitempty"`
+}
+
+// BaseURL 源码中定义的接口基础地址；Environment 为空表示未区分环境
+type BaseURL struct {
+	Name        string `json:"name"`
+	Environment string `json:"environment,omitempty"`
+	URL         string `json:"url"`
+	FilePath    string `json:"file_path"`
+	LineNumber  int    `json:"line_number"`
 }
 
 // ObfuscatedFile 混淆文件信息
@@ -54,7 +84,10 @@ type ScanReport struct {
 	TotalFiles      int                      `json:"total_files"`
 	Categories      map[string]*CategoryData `json:"categories"`
 	Items           []SensitiveItem          `json:"items"`
+	KnownItems      []SensitiveItem          `json:"known_items,omitempty"`
+	SuppressedItems []SensitiveItem          `json:"suppressed_items,omitempty"`
 	APIEndpoints    []APIEndpoint            `json:"api_endpoints"`
+	BaseURLs        []BaseURL                `json:"base_urls,omitempty"`
 	ObfuscatedFiles []ObfuscatedFile         `json:"obfuscated_files"`
 	Summary         ReportSummary            `json:"summary"`
 }
@@ -66,6 +99,12 @@ type ReportSummary struct {
 	HighRisk      int            `json:"high_risk"`
 	MediumRisk    int            `json:"medium_risk"`
 	LowRisk       int            `json:"low_risk"`
+	NewCount      int            `json:"new_count"`
+	KnownCount    int            `json:"known_count"`
+	Suppressed    int            `json:"suppressed_count"`
+	Verified      int            `json:"verified_count"`
+	Expired       int            `json:"expired_count"`
+	Malformed     int            `json:"malformed_count"`
 	CategoryStats map[string]int `json:"category_stats"`
 }
 
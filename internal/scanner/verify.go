@@ -0,0 +1,222 @@
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// 凭据校验结论
+const (
+	VerificationValid     = "structurally_valid" // 结构有效，可能仍可使用
+	VerificationExpired   = "expired"            // 结构有效但已过期
+	VerificationMalformed = "malformed"          // 结构不合法，大概率是误报或被截断
+)
+
+// Verification 校验器对单条发现给出的结论
+type Verification struct {
+	Status string
+	Detail string
+}
+
+// Verifier 凭据校验器。内置校验器全部离线运行，只检查格式、编码与有效期；
+// 需要在线确认时可以实现该接口（例如请求本地测试桩服务），通过 DataCollector.SetVerifiers 显式传入。
+type Verifier interface {
+	// Name 校验器名称，写入报告的 verified_by 字段
+	Name() string
+	// Verify 返回 false 表示该校验器不处理这条发现
+	Verify(item SensitiveItem) (Verification, bool)
+}
+
+// DefaultVerifiers 返回内置离线校验器的副本，调用方可以在末尾追加在线校验器
+func DefaultVerifiers() []Verifier {
+	return append([]Verifier(nil), offlineVerifiers...)
+}
+
+// VerifyItem 依次执行校验器并把结论写入 item；某个校验器判定过期或格式错误后不再继续，
+// 因此排在离线校验之后的在线校验不会浪费在格式错误的凭据上
+func VerifyItem(item *SensitiveItem, verifiers []Verifier) {
+	for _, verifier := range verifiers {
+		result, ok := verifier.Verify(*item)
+		if !ok || result.Status == "" {
+			continue
+		}
+		item.Verification = result.Status
+		item.VerificationDetail = result.Detail
+		item.VerifiedBy = verifier.Name()
+		if result.Status != VerificationValid {
+			return
+		}
+	}
+}
+
+// offlineVerifier 按规则 ID 分派的离线结构校验
+type offlineVerifier struct {
+	name    string
+	ruleIDs map[string]struct{}
+	check   func(value string) Verification
+}
+
+func newOfflineVerifier(name string, check func(value string) Verification, ruleIDs ...string) *offlineVerifier {
+	verifier := &offlineVerifier{name: name, ruleIDs: make(map[string]struct{}, len(ruleIDs)), check: check}
+	for _, id := range ruleIDs {
+		verifier.ruleIDs[id] = struct{}{}
+	}
+	return verifier
+}
+
+func (v *offlineVerifier) Name() string {
+	return v.name
+}
+
+func (v *offlineVerifier) Verify(item SensitiveItem) (Verification, bool) {
+	if _, ok := v.ruleIDs[normalizeRuleID(item.RuleID)]; !ok {
+		return Verification{}, false
+	}
+	return v.check(credentialValue(item.Content)), true
+}
+
+var offlineVerifiers = []Verifier{
+	newOfflineVerifier("aliyun-accesskey", verifyAliyunAccessKeyID, "aliyun_access_key"),
+	newOfflineVerifier("tencent-secretid", verifyTencentSecretID, "tencent_secret_id"),
+	newOfflineVerifier("tencent-secretkey", verifyAlnumKey("SecretKey", 32), "tencent_secret_key"),
+	newOfflineVerifier("jwt", verifyJWT, "jwt_token_full"),
+	newOfflineVerifier("x509-certificate", verifyCertificate, "certificate"),
+	newOfflineVerifier("pem-private-key", verifyPEMPrivateKey,
+		"private_key_rsa", "private_key_dsa", "private_key_ec", "private_key_openssh", "private_key_pkcs8"),
+	newOfflineVerifier("wechat-appsecret", verifyWechatAppSecret, "wechat_secret"),
+	newOfflineVerifier("wechatpay-mch-key", verifyAlnumKey("商户密钥", 32), "wechatpay_key", "wechatpay_mch_key"),
+}
+
+var (
+	quotedValuePattern = regexp.MustCompile("[\"'`]([^\"'`]*)[\"'`]\\s*$")
+	alnumPattern       = regexp.MustCompile(`^[A-Za-z0-9]+$`)
+	lowerHexPattern    = regexp.MustCompile(`^[0-9a-f]+$`)
+	aliyunKeyIDPattern = regexp.MustCompile(`LTAI[A-Za-z0-9]*`)
+	tencentIDPattern   = regexp.MustCompile(`AKID[A-Za-z0-9]*`)
+)
+
+// credentialValue 从命中内容中取出凭据本身：形如 key: "value" 的命中取最后一对引号内的值
+func credentialValue(content string) string {
+	content = strings.TrimSpace(content)
+	if match := quotedValuePattern.FindStringSubmatch(content); match != nil && match[1] != "" {
+		return match[1]
+	}
+	return strings.Trim(content, "\"'`")
+}
+
+// verifyAliyunAccessKeyID 阿里云 AccessKey ID 为 LTAI 前缀加 12 位（旧版）或 20 位（新版）字母数字。
+// 阿里云未公开校验位算法，这里只能检查长度与字符集。
+func verifyAliyunAccessKeyID(value string) Verification {
+	id := aliyunKeyIDPattern.FindString(value)
+	switch {
+	case id == "":
+		return Verification{Status: VerificationMalformed, Detail: "缺少 LTAI 前缀"}
+	case len(id) != 16 && len(id) != 24:
+		return Verification{Status: VerificationMalformed, Detail: fmt.Sprintf("长度 %d，应为 16 或 24", len(id))}
+	}
+	return Verification{Status: VerificationValid, Detail: fmt.Sprintf("AccessKey ID 格式正确（%d 位）", len(id))}
+}
+
+// verifyTencentSecretID 腾讯云 SecretId 为 AKID 前缀加 32 位字母数字
+func verifyTencentSecretID(value string) Verification {
+	id := tencentIDPattern.FindString(value)
+	switch {
+	case id == "":
+		return Verification{Status: VerificationMalformed, Detail: "缺少 AKID 前缀"}
+	case len(id) != 36:
+		return Verification{Status: VerificationMalformed, Detail: fmt.Sprintf("长度 %d，应为 36", len(id))}
+	}
+	return Verification{Status: VerificationValid, Detail: "SecretId 格式正确"}
+}
+
+// verifyAlnumKey 校验固定长度的字母数字密钥（腾讯云 SecretKey、微信支付 API 密钥）
+func verifyAlnumKey(label string, length int) func(string) Verification {
+	return func(value string) Verification {
+		switch {
+		case len(value) != length:
+			return Verification{Status: VerificationMalformed, Detail: fmt.Sprintf("%s 长度 %d，应为 %d", label, len(value), length)}
+		case !alnumPattern.MatchString(value):
+			return Verification{Status: VerificationMalformed, Detail: label + " 含有字母数字以外的字符"}
+		case isLowEntropyValue(value):
+			return Verification{Status: VerificationMalformed, Detail: label + " 字符重复度过高，疑似占位值"}
+		}
+		return Verification{Status: VerificationValid, Detail: fmt.Sprintf("%s 格式正确", label)}
+	}
+}
+
+// verifyWechatAppSecret 小程序 AppSecret 为 32 位小写十六进制
+func verifyWechatAppSecret(value string) Verification {
+	switch {
+	case len(value) != 32:
+		return Verification{Status: VerificationMalformed, Detail: fmt.Sprintf("AppSecret 长度 %d，应为 32", len(value))}
+	case !lowerHexPattern.MatchString(value):
+		return Verification{Status: VerificationMalformed, Detail: "AppSecret 应为小写十六进制"}
+	case isLowEntropyValue(value):
+		return Verification{Status: VerificationMalformed, Detail: "AppSecret 字符重复度过高，疑似占位值"}
+	}
+	return Verification{Status: VerificationValid, Detail: "AppSecret 格式正确"}
+}
+
+// verifyJWT 解码头部与载荷，检查 alg 与 exp
+func verifyJWT(value string) Verification {
+	header, claims, err := parseJWT(value)
+	if err != nil {
+		return Verification{Status: VerificationMalformed, Detail: err.Error()}
+	}
+	if alg, _ := header["alg"].(string); alg == "" {
+		return Verification{Status: VerificationMalformed, Detail: "JWT 头部缺少 alg"}
+	}
+	if _, ok := claims["exp"]; ok {
+		if _, valid := claimTime(claims["exp"]); !valid {
+			return Verification{Status: VerificationMalformed, Detail: "exp 不是数字"}
+		}
+	}
+	return expiryVerification(decodeJWT(value))
+}
+
+// verifyCertificate X.509 证书可解析且在有效期内
+func verifyCertificate(value string) Verification {
+	decoded := DecodeCredential(value)
+	if decoded == nil || decoded.Kind != DecodedCertificate {
+		return Verification{Status: VerificationMalformed, Detail: "证书无法解析"}
+	}
+	return expiryVerification(decoded)
+}
+
+func expiryVerification(decoded *DecodedCredential) Verification {
+	if !decoded.NotAfter.IsZero() && decoded.NotAfter.Before(time.Now()) {
+		return Verification{Status: VerificationExpired, Detail: decoded.Summary}
+	}
+	return Verification{Status: VerificationValid, Detail: decoded.Summary}
+}
+
+// verifyPEMPrivateKey 解析 PEM 私钥；JS 字符串中的 \n 转义会先还原为换行
+func verifyPEMPrivateKey(value string) Verification {
+	normalized := strings.NewReplacer(`\r`, "", `\n`, "\n").Replace(value)
+	_, err := ssh.ParseRawPrivateKey([]byte(normalized))
+	var missing *ssh.PassphraseMissingError
+	switch {
+	case err == nil:
+		return Verification{Status: VerificationValid, Detail: "私钥可以解析"}
+	case errors.As(err, &missing) || strings.Contains(normalized, "ENCRYPTED"):
+		return Verification{Status: VerificationValid, Detail: "私钥已加密，结构完整"}
+	}
+	return Verification{Status: VerificationMalformed, Detail: "私钥无法解析: " + err.Error()}
+}
+
+// isLowEntropyValue 同一字符占比超过一半视为占位值
+func isLowEntropyValue(value string) bool {
+	counts := make(map[rune]int)
+	for _, r := range value {
+		counts[r]++
+		if counts[r]*2 > len(value) {
+			return true
+		}
+	}
+	return false
+}
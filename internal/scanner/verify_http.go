@@ -0,0 +1,79 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// HTTPVerifier 把发现提交给外部校验服务（通常是本地测试桩），由服务返回结论。
+// 请求体为 {"rule_id","content","value"}，响应体为 {"status","detail"}；
+// 非 200 响应或 status 为空时视为不处理。
+type HTTPVerifier struct {
+	Endpoint string
+	RuleIDs  []string // 为空时提交全部发现
+	Client   *http.Client
+}
+
+// NewHTTPVerifier 创建在线校验器，默认超时 5 秒
+func NewHTTPVerifier(endpoint string, ruleIDs ...string) *HTTPVerifier {
+	return &HTTPVerifier{
+		Endpoint: endpoint,
+		RuleIDs:  ruleIDs,
+		Client:   &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (v *HTTPVerifier) Name() string {
+	return "http:" + v.Endpoint
+}
+
+func (v *HTTPVerifier) Verify(item SensitiveItem) (Verification, bool) {
+	if !v.accepts(item.RuleID) {
+		return Verification{}, false
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"rule_id": item.RuleID,
+		"content": item.Content,
+		"value":   credentialValue(item.Content),
+	})
+	if err != nil {
+		return Verification{}, false
+	}
+	client := v.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Post(v.Endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return Verification{}, false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Verification{}, false
+	}
+
+	var result struct {
+		Status string `json:"status"`
+		Detail string `json:"detail"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil || result.Status == "" {
+		return Verification{}, false
+	}
+	return Verification{Status: result.Status, Detail: result.Detail}, true
+}
+
+func (v *HTTPVerifier) accepts(ruleID string) bool {
+	if len(v.RuleIDs) == 0 {
+		return true
+	}
+	normalized := normalizeRuleID(ruleID)
+	for _, id := range v.RuleIDs {
+		if normalizeRuleID(id) == normalized {
+			return true
+		}
+	}
+	return false
+}
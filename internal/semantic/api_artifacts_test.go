@@ -57,3 +57,17 @@ exports.getECert=function(params){var requestData={userId:params.userId,controll
 	assertExists(t, filepath.Join(root, ".gwxapkg/burp_api_link.json"))
 	assertExists(t, filepath.Join(root, ".gwxapkg/burp_api_link.md"))
 }
+
+func TestExtractParamTypesInfersLiteralTypes(t *testing.T) {
+	block := `exports.list=function(params){var requestData={page:1,size:params.size,rate:.5,all:!0,ids:[],extra:{a:1},keyword:"",token:String(params.token),controllerName:"News",methodsName:"List"};return request.request({data:requestData})}`
+	types := extractParamTypes(block)
+	expected := map[string]string{"page": "integer", "rate": "number", "all": "boolean", "ids": "array", "extra": "object", "keyword": "string", "token": "string"}
+	if len(types) != len(expected) {
+		t.Fatalf("参数类型数量不正确: %#v", types)
+	}
+	for key, kind := range expected {
+		if types[key] != kind {
+			t.Fatalf("%s 应推断为 %s，实际 %#v", key, kind, types)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package semantic
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	apiCoverageJSONFileName = "api_coverage.json"
+	apiCoverageMDFileName   = "api_coverage.md"
+)
+
+// coverageRoutingParams 是网关用来路由的字段，不参与参数差异比较
+var coverageRoutingParams = map[string]struct{}{
+	"controllerName": {}, "controller": {}, "methodsName": {}, "methodName": {}, "method": {},
+}
+
+// APICoverageReport 描述一批代理历史请求对 api_map 的覆盖情况。
+type APICoverageReport struct {
+	GeneratedAt     string                 `json:"generated_at"`
+	Source          string                 `json:"source,omitempty"`
+	Format          string                 `json:"format"`
+	TotalRequests   int                    `json:"total_requests"`
+	MatchedRequests int                    `json:"matched_requests"`
+	EndpointCount   int                    `json:"endpoint_count"`
+	ExercisedCount  int                    `json:"exercised_count"`
+	Endpoints       []APICoverageEndpoint  `json:"endpoints"`
+	Unmatched       []APICoverageUnmatched `json:"unmatched,omitempty"`
+	Links           []APICoverageLink      `json:"links"`
+}
+
+// APICoverageEndpoint 是单个源码 API 的覆盖情况；MissingParams 为源码中有、流量中从未出现的字段，
+// ExtraParams 为流量中出现、源码 ParamFields 未声明的字段。
+type APICoverageEndpoint struct {
+	FunctionName   string   `json:"function_name"`
+	ControllerName string   `json:"controller_name"`
+	MethodsName    string   `json:"methods_name"`
+	FilePath       string   `json:"file_path"`
+	Hits           int      `json:"hits"`
+	ParamFields    []string `json:"param_fields,omitempty"`
+	ObservedParams []string `json:"observed_params,omitempty"`
+	MissingParams  []string `json:"missing_params,omitempty"`
+	ExtraParams    []string `json:"extra_params,omitempty"`
+	Paths          []string `json:"paths,omitempty"`
+}
+
+// APICoverageUnmatched 是无法关联到源码 API 的请求，按 method + host + path 聚合。
+type APICoverageUnmatched struct {
+	Method string   `json:"method"`
+	Host   string   `json:"host,omitempty"`
+	Path   string   `json:"path"`
+	Count  int      `json:"count"`
+	Params []string `json:"params,omitempty"`
+}
+
+// APICoverageLink 记录每个请求的关联结果，FunctionName 为空表示未关联。
+type APICoverageLink struct {
+	Index        int    `json:"index"`
+	Method       string `json:"method"`
+	Host         string `json:"host,omitempty"`
+	Path         string `json:"path"`
+	FunctionName string `json:"function_name,omitempty"`
+	FilePath     string `json:"file_path,omitempty"`
+	Confidence   string `json:"confidence,omitempty"`
+}
+
+// LinkTraffic 批量关联代理历史（HAR / Burp XML / mitmproxy / 单个原始请求）到 api_map，
+// 写出 api_coverage.json / api_coverage.md。只采纳 high / medium 置信度的候选。
+func LinkTraffic(rootDir string, data []byte, format, source string) (*APICoverageReport, error) {
+	rootAbs, err := filepath.Abs(rootDir)
+	if err != nil {
+		return nil, fmt.Errorf("解析输出目录失败: %w", err)
+	}
+	apiMap, err := LoadAPIMap(rootAbs)
+	if err != nil {
+		return nil, err
+	}
+	requests, format, err := ParseTraffic(data, format)
+	if err != nil {
+		return nil, err
+	}
+	report := BuildAPICoverage(apiMap, requests)
+	report.Format = format
+	report.Source = source
+	if err := writeAPICoverage(rootAbs, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// BuildAPICoverage 计算请求对源码 API 的覆盖与参数差异
+func BuildAPICoverage(apiMap *APIMapReport, requests []ParsedBurpRequest) *APICoverageReport {
+	report := &APICoverageReport{
+		GeneratedAt:   time.Now().Format("2006-01-02 15:04:05"),
+		TotalRequests: len(requests),
+		EndpointCount: len(apiMap.Endpoints),
+		Endpoints:     make([]APICoverageEndpoint, 0, len(apiMap.Endpoints)),
+		Links:         make([]APICoverageLink, 0, len(requests)),
+	}
+
+	index := make(map[string]int, len(apiMap.Endpoints))
+	observed := make([]map[string]struct{}, len(apiMap.Endpoints))
+	paths := make([][]string, len(apiMap.Endpoints))
+	for i, endpoint := range apiMap.Endpoints {
+		index[endpointKey(endpoint.FilePath, endpoint.FunctionName)] = i
+		observed[i] = make(map[string]struct{})
+		report.Endpoints = append(report.Endpoints, APICoverageEndpoint{
+			FunctionName:   endpoint.FunctionName,
+			ControllerName: endpoint.ControllerName,
+			MethodsName:    endpoint.MethodsName,
+			FilePath:       endpoint.FilePath,
+			ParamFields:    endpoint.ParamFields,
+		})
+	}
+
+	unmatched := make(map[string]*APICoverageUnmatched)
+	for i, request := range requests {
+		link := APICoverageLink{Index: i, Method: request.Method, Host: request.Host, Path: request.Path}
+		matches := matchBurpRequestToAPI(request, apiMap)
+		if len(matches) > 0 && matches[0].Confidence != ASTConfidenceLow {
+			best := matches[0]
+			link.FunctionName, link.FilePath, link.Confidence = best.FunctionName, best.FilePath, best.Confidence
+			position := index[endpointKey(best.FilePath, best.FunctionName)]
+			report.Endpoints[position].Hits++
+			for key := range request.Params {
+				observed[position][key] = struct{}{}
+			}
+			paths[position] = append(paths[position], request.Method+" "+request.Path)
+			report.MatchedRequests++
+		} else {
+			key := request.Method + " " + request.Host + request.Path
+			entry, ok := unmatched[key]
+			if !ok {
+				entry = &APICoverageUnmatched{Method: request.Method, Host: request.Host, Path: request.Path}
+				unmatched[key] = entry
+			}
+			entry.Count++
+			entry.Params = append(entry.Params, mapKeysString(request.Params)...)
+		}
+		report.Links = append(report.Links, link)
+	}
+
+	for i := range report.Endpoints {
+		endpoint := &report.Endpoints[i]
+		if endpoint.Hits == 0 {
+			continue
+		}
+		report.ExercisedCount++
+		endpoint.Paths = dedupeAndSort(paths[i])
+		endpoint.ObservedParams, endpoint.MissingParams, endpoint.ExtraParams = diffCoverageParams(endpoint.ParamFields, observed[i])
+	}
+	for _, entry := range unmatched {
+		entry.Params = dedupeAndSort(entry.Params)
+		report.Unmatched = append(report.Unmatched, *entry)
+	}
+	sort.Slice(report.Unmatched, func(i, j int) bool {
+		if report.Unmatched[i].Count != report.Unmatched[j].Count {
+			return report.Unmatched[i].Count > report.Unmatched[j].Count
+		}
+		return report.Unmatched[i].Method+report.Unmatched[i].Host+report.Unmatched[i].Path <
+			report.Unmatched[j].Method+report.Unmatched[j].Host+report.Unmatched[j].Path
+	})
+	return report
+}
+
+// diffCoverageParams 比较流量参数与源码 ParamFields；JSON 嵌套字段按最后一段与源码字段比较
+func diffCoverageParams(fields []string, observed map[string]struct{}) ([]string, []string, []string) {
+	declared := make(map[string]struct{}, len(fields))
+	for _, field := range fields {
+		declared[field] = struct{}{}
+	}
+
+	seen := make(map[string]struct{})
+	observedList := make([]string, 0, len(observed))
+	extra := make([]string, 0)
+	for key := range observed {
+		observedList = append(observedList, key)
+		leaf := key[strings.LastIndex(key, ".")+1:]
+		seen[key] = struct{}{}
+		seen[leaf] = struct{}{}
+		if _, routing := coverageRoutingParams[leaf]; routing {
+			continue
+		}
+		_, full := declared[key]
+		_, short := declared[leaf]
+		if !full && !short {
+			extra = append(extra, key)
+		}
+	}
+
+	missing := make([]string, 0)
+	for _, field := range fields {
+		if _, ok := seen[field]; !ok {
+			missing = append(missing, field)
+		}
+	}
+	return dedupeAndSort(observedList), dedupeAndSort(missing), dedupeAndSort(extra)
+}
+
+func writeAPICoverage(rootDir string, report *APICoverageReport) error {
+	reportDir := filepath.Join(rootDir, reportDirName)
+	if err := os.MkdirAll(reportDir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(reportDir, apiCoverageJSONFileName), data, 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(reportDir, apiCoverageMDFileName), []byte(buildAPICoverageMarkdown(report)), 0644)
+}
+
+func buildAPICoverageMarkdown(report *APICoverageReport) string {
+	var builder strings.Builder
+	builder.WriteString("# 流量覆盖报告\n\n")
+	builder.WriteString(fmt.Sprintf("- 生成时间: `%s`\n", report.GeneratedAt))
+	if report.Source != "" {
+		builder.WriteString(fmt.Sprintf("- 来源: `%s` (%s)\n", report.Source, report.Format))
+	}
+	builder.WriteString(fmt.Sprintf("- 请求: `%d`，关联到源码 API: `%d`\n", report.TotalRequests, report.MatchedRequests))
+	builder.WriteString(fmt.Sprintf("- 源码 API 覆盖: `%d / %d`\n", report.ExercisedCount, report.EndpointCount))
+
+	builder.WriteString("\n## 已覆盖的 API\n\n")
+	builder.WriteString("| 函数 | Controller | Method | 命中 | 未出现的参数 | 额外参数 |\n")
+	builder.WriteString("|------|------------|--------|------|--------------|----------|\n")
+	untouched := make([]APICoverageEndpoint, 0)
+	for _, endpoint := range report.Endpoints {
+		if endpoint.Hits == 0 {
+			untouched = append(untouched, endpoint)
+			continue
+		}
+		builder.WriteString(fmt.Sprintf("| `%s` | `%s` | `%s` | %d | %s | %s |\n",
+			endpoint.FunctionName, endpoint.ControllerName, endpoint.MethodsName, endpoint.Hits,
+			inlineCodeList(endpoint.MissingParams), inlineCodeList(endpoint.ExtraParams)))
+	}
+
+	if len(untouched) > 0 {
+		builder.WriteString("\n## 未覆盖的 API\n\n")
+		for _, endpoint := range untouched {
+			builder.WriteString(fmt.Sprintf("- `%s` (`%s.%s`) `%s`\n", endpoint.FunctionName, endpoint.ControllerName, endpoint.MethodsName, endpoint.FilePath))
+		}
+	}
+
+	if len(report.Unmatched) > 0 {
+		builder.WriteString("\n## 无源码对应的请求\n\n")
+		builder.WriteString("| 次数 | 请求 | 参数 |\n")
+		builder.WriteString("|------|------|------|\n")
+		for _, entry := range report.Unmatched {
+			builder.WriteString(fmt.Sprintf("| %d | `%s %s%s` | %s |\n", entry.Count, entry.Method, entry.Host, entry.Path, inlineCodeList(entry.Params)))
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,107 @@
+package semantic
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLinkTrafficBuildsCoverageFromAllFormats(t *testing.T) {
+	root := t.TempDir()
+	mustWrite(t, filepath.Join(root, "api_mixed.js"), `var request=require("request.js");
+exports.getECert=function(params){var requestData={userId:params.userId,certType:params.certType,controllerName:"CerInfo",methodsName:"GetECert"};return request.request({url:"",method:"POST",data:requestData})};
+exports.getUser=function(params){var requestData={userId:params.userId,controllerName:"User",methodsName:"GetUser"};return request.request({url:"",method:"POST",data:requestData})};`)
+	mustWrite(t, filepath.Join(root, "request.js"), `exports.request=function(options){return wx.request(options)};`)
+	if _, err := BuildAPIMap(root, []string{"api_mixed.js", "request.js"}); err != nil {
+		t.Fatalf("BuildAPIMap 返回错误: %v", err)
+	}
+
+	har := `{"log":{"version":"1.2","entries":[
+{"request":{"method":"POST","url":"https://api.shop-wx.com.cn/gateway","headers":[{"name":":authority","value":"api.shop-wx.com.cn"},{"name":"Content-Type","value":"application/json"}],"postData":{"mimeType":"application/json","text":"{\"controllerName\":\"CerInfo\",\"methodsName\":\"GetECert\",\"userId\":\"1\",\"sign\":\"abc\"}"}}},
+{"request":{"method":"GET","url":"https://cdn.shop-wx.com.cn/banner?pos=home","headers":[]}}]}}`
+	rawBurp := "POST /gateway HTTP/1.1\r\nContent-Type: application/x-www-form-urlencoded\r\n\r\ncontrollerName=CerInfo&methodsName=GetECert&userId=2"
+	burpXML := `<?xml version="1.0"?>
+<items burpVersion="2023.1">
+  <item>
+    <url><![CDATA[https://api.shop-wx.com.cn/gateway]]></url>
+    <host ip="1.2.3.4">api.shop-wx.com.cn</host>
+    <method><![CDATA[POST]]></method>
+    <request base64="true"><![CDATA[` + base64.StdEncoding.EncodeToString([]byte(rawBurp)) + `]]></request>
+  </item>
+</items>`
+	flow := tnet(map[string]interface{}{"type": "http", "request": map[string]interface{}{
+		"method": []byte("GET"), "scheme": []byte("https"), "host": "cdn.shop-wx.com.cn", "port": 443,
+		"path": []byte("/banner?pos=mine"), "headers": []interface{}{[]interface{}{[]byte("Host"), []byte("cdn.shop-wx.com.cn")}},
+		"content": []byte(""),
+	}})
+
+	cases := []struct {
+		data, format string
+		total        int
+	}{
+		{har, TrafficFormatHAR, 2},
+		{burpXML, TrafficFormatBurpXML, 1},
+		{flow + flow, TrafficFormatMitmproxy, 2},
+		// 以数字开头但不是 tnetstring 的内容按原始报文处理
+		{"3 requests exported\n\nGET /banner?pos=mine HTTP/1.1\nHost: cdn.shop-wx.com.cn\n\n", TrafficFormatRaw, 1},
+	}
+	for _, tc := range cases {
+		requests, format, err := ParseTraffic([]byte(tc.data), "")
+		if err != nil || format != tc.format || len(requests) != tc.total {
+			t.Fatalf("%s 解析失败: format=%s err=%v requests=%#v", tc.format, format, err, requests)
+		}
+	}
+
+	report, err := LinkTraffic(root, []byte(har), "", "history.har")
+	if err != nil {
+		t.Fatalf("LinkTraffic 返回错误: %v", err)
+	}
+	if report.TotalRequests != 2 || report.MatchedRequests != 1 || report.ExercisedCount != 1 || report.EndpointCount != 2 {
+		t.Fatalf("覆盖统计不正确: %#v", report)
+	}
+	var cert APICoverageEndpoint
+	for _, endpoint := range report.Endpoints {
+		if endpoint.FunctionName == "getECert" {
+			cert = endpoint
+		}
+	}
+	if cert.Hits != 1 || strings.Join(cert.MissingParams, ",") != "certType" || strings.Join(cert.ExtraParams, ",") != "sign" {
+		t.Fatalf("参数差异不正确: %#v", cert)
+	}
+	if len(report.Unmatched) != 1 || report.Unmatched[0].Host != "cdn.shop-wx.com.cn" || report.Unmatched[0].Path != "/banner" {
+		t.Fatalf("无源码对应的请求不正确: %#v", report.Unmatched)
+	}
+	data, err := os.ReadFile(filepath.Join(root, ".gwxapkg", "api_coverage.md"))
+	if err != nil || !strings.Contains(string(data), "## 未覆盖的 API") {
+		t.Fatalf("api_coverage.md 缺少未覆盖列表: %v\n%s", err, data)
+	}
+}
+
+// tnet 按 mitmproxy flow 文件使用的 tnetstring 格式编码测试数据
+func tnet(value interface{}) string {
+	switch typed := value.(type) {
+	case []byte:
+		return fmt.Sprintf("%d:%s,", len(typed), typed)
+	case string:
+		return fmt.Sprintf("%d:%s;", len(typed), typed)
+	case int:
+		text := fmt.Sprint(typed)
+		return fmt.Sprintf("%d:%s#", len(text), text)
+	case []interface{}:
+		var body strings.Builder
+		for _, item := range typed {
+			body.WriteString(tnet(item))
+		}
+		return fmt.Sprintf("%d:%s]", body.Len(), body.String())
+	case map[string]interface{}:
+		var body strings.Builder
+		for key, item := range typed {
+			body.WriteString(tnet(key) + tnet(item))
+		}
+		return fmt.Sprintf("%d:%s}", body.Len(), body.String())
+	}
+	panic(fmt.Sprintf("unsupported %T", value))
+}
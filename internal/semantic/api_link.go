@@ -44,17 +44,37 @@ type BurpAPILinkMatch struct {
 	MethodsName    string   `json:"methods_name"`
 	HTTPMethod     string   `json:"http_method,omitempty"`
 	FilePath       string   `json:"file_path"`
+	LineNumber     int      `json:"line_number,omitempty"`
 	ParamFields    []string `json:"param_fields,omitempty"`
 	CallSites      []string `json:"call_sites,omitempty"`
 }
 
+// APILinker 缓存 api_map，供代理等需要反复关联请求的场景复用，不写出报告文件。
+type APILinker struct {
+	apiMap *APIMapReport
+}
+
+// NewAPILinker 读取 rootDir 下的 api_map.json
+func NewAPILinker(rootDir string) (*APILinker, error) {
+	apiMap, err := LoadAPIMap(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	return &APILinker{apiMap: apiMap}, nil
+}
+
+// Match 返回按得分排序的候选源码 API，最多 10 个
+func (l *APILinker) Match(request ParsedBurpRequest) []BurpAPILinkMatch {
+	return matchBurpRequestToAPI(request, l.apiMap)
+}
+
 // LinkBurpRequest 将 Burp 原始请求关联到 api_map 中的源码 API。
 func LinkBurpRequest(rootDir string, rawRequest string) (*BurpAPILinkReport, error) {
 	rootAbs, err := filepath.Abs(rootDir)
 	if err != nil {
 		return nil, fmt.Errorf("解析输出目录失败: %w", err)
 	}
-	apiMap, err := readAPIMap(rootAbs)
+	linker, err := NewAPILinker(rootAbs)
 	if err != nil {
 		return nil, err
 	}
@@ -62,7 +82,7 @@ func LinkBurpRequest(rootDir string, rawRequest string) (*BurpAPILinkReport, err
 	report := &BurpAPILinkReport{
 		GeneratedAt: time.Now().Format("2006-01-02 15:04:05"),
 		Request:     parsed,
-		Matches:     matchBurpRequestToAPI(parsed, apiMap),
+		Matches:     linker.Match(parsed),
 	}
 	if err := writeBurpAPILink(rootAbs, report); err != nil {
 		return nil, err
@@ -70,7 +90,8 @@ func LinkBurpRequest(rootDir string, rawRequest string) (*BurpAPILinkReport, err
 	return report, nil
 }
 
-func readAPIMap(rootDir string) (*APIMapReport, error) {
+// LoadAPIMap 读取 rootDir 下的 .gwxapkg/api_map.json
+func LoadAPIMap(rootDir string) (*APIMapReport, error) {
 	data, err := os.ReadFile(filepath.Join(rootDir, reportDirName, "api_map.json"))
 	if err != nil {
 		return nil, fmt.Errorf("读取 api_map.json 失败: %w", err)
@@ -201,6 +222,7 @@ func scoreBurpEndpoint(request ParsedBurpRequest, endpoint APIEndpointEntry) Bur
 		MethodsName:    endpoint.MethodsName,
 		HTTPMethod:     endpoint.HTTPMethod,
 		FilePath:       endpoint.FilePath,
+		LineNumber:     endpoint.LineNumber,
 		ParamFields:    endpoint.ParamFields,
 		CallSites:      callSiteStrings(endpoint.CallSites),
 	}
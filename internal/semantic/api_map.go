@@ -10,12 +10,16 @@ import (
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/25smoking/Gwxapkg/internal/wxenv"
 )
 
 var (
 	httpMethodPattern     = regexp.MustCompile(`\bmethod\s*:\s*["']([^"']+)["']`)
 	urlLiteralPattern     = regexp.MustCompile(`\burl\s*:\s*["']([^"']*)["']`)
 	objectKeyPattern      = regexp.MustCompile(`(?:^|[,{]\s*)([A-Za-z_$][\w$]*)\s*:`)
+	integerLiteralPattern = regexp.MustCompile(`^-?\d+$`)
+	numberLiteralPattern  = regexp.MustCompile(`^-?\d*\.\d+(?:[eE][-+]?\d+)?$`)
 	requireAliasPattern   = regexp.MustCompile(`(?m)(?:\b(?:var|let|const)\s+|,\s*)?([A-Za-z_$][\w$]*)\s*=\s*require\s*\(\s*["']([^"']+\.js)["']\s*\)`)
 	exportReexportPattern = regexp.MustCompile(`\bexports\.([A-Za-z_$][\w$]*)\s*=\s*([A-Za-z_$][\w$]*)\.([A-Za-z_$][\w$]*)\s*;?`)
 )
@@ -27,19 +31,25 @@ type APIMapReport struct {
 	Endpoints        []APIEndpointEntry `json:"endpoints"`
 	SplitModules     []APISplitModule   `json:"split_modules,omitempty"`
 	CallChains       []APICallChain     `json:"call_chains,omitempty"`
+	// RuntimeRequests 运行时追踪到、但无法归属到接口函数的请求
+	RuntimeRequests []wxenv.Request `json:"runtime_requests,omitempty"`
 }
 
 // APIEndpointEntry 描述一个导出函数最终对应的后端接口。
 type APIEndpointEntry struct {
-	FunctionName     string        `json:"function_name"`
-	ControllerName   string        `json:"controller_name"`
-	MethodsName      string        `json:"methods_name"`
-	HTTPMethod       string        `json:"http_method,omitempty"`
-	URL              string        `json:"url,omitempty"`
-	FilePath         string        `json:"file_path"`
-	OriginalFilePath string        `json:"original_file_path,omitempty"`
-	ParamFields      []string      `json:"param_fields,omitempty"`
-	CallSites        []APICallSite `json:"call_sites,omitempty"`
+	FunctionName     string            `json:"function_name"`
+	ControllerName   string            `json:"controller_name"`
+	MethodsName      string            `json:"methods_name"`
+	HTTPMethod       string            `json:"http_method,omitempty"`
+	URL              string            `json:"url,omitempty"`
+	FilePath         string            `json:"file_path"`
+	LineNumber       int               `json:"line_number,omitempty"`
+	OriginalFilePath string            `json:"original_file_path,omitempty"`
+	ParamFields      []string          `json:"param_fields,omitempty"`
+	ParamTypes       map[string]string `json:"param_types,omitempty"`
+	CallSites        []APICallSite     `json:"call_sites,omitempty"`
+	// Observed 运行时追踪中实际发出的请求
+	Observed []wxenv.Request `json:"observed,omitempty"`
 }
 
 // APICallSite 描述页面或模块中的调用点。
@@ -90,6 +100,7 @@ type apiFunction struct {
 	FilePath         string
 	OriginalFilePath string
 	ParamFields      []string
+	ParamTypes       map[string]string
 	Block            string
 	StartOffset      int
 	EndOffset        int
@@ -161,14 +172,19 @@ func BuildAPIMap(rootDir string, jsFiles []string) (*APIMapReport, error) {
 			HTTPMethod:       endpoint.HTTPMethod,
 			URL:              endpoint.URL,
 			FilePath:         endpoint.FilePath,
+			LineNumber:       endpoint.StartLine,
 			OriginalFilePath: endpoint.OriginalFilePath,
 			ParamFields:      endpoint.ParamFields,
+			ParamTypes:       endpoint.ParamTypes,
 			CallSites:        callSites[key],
 		}
 		report.Endpoints = append(report.Endpoints, entry)
 	}
 
 	sortAPIMap(report)
+	if trace, err := wxenv.LoadTrace(RequestTracePath(rootDir)); err == nil {
+		attachRequestTrace(report, trace)
+	}
 	report.CallChains = BuildAPICallChains(rootDir, report)
 	if err := writeAPIMap(rootDir, report); err != nil {
 		return nil, err
@@ -305,6 +321,7 @@ func extractAPIFunctions(relPath, content string) []apiFunction {
 			FilePath:         relPath,
 			OriginalFilePath: relPath,
 			ParamFields:      extractParamFields(block),
+			ParamTypes:       extractParamTypes(block),
 			Block:            ensureStatement(block),
 			StartOffset:      match[0],
 			EndOffset:        end + 1,
@@ -623,6 +640,7 @@ func buildAPIMapMarkdown(report *APIMapReport) string {
 			inlineCodeList(calls),
 		))
 	}
+	builder.WriteString(buildRuntimeRequestMarkdown(report))
 	return builder.String()
 }
 
@@ -641,20 +659,28 @@ func sortAPIMap(report *APIMapReport) {
 	})
 }
 
-func extractParamFields(block string) []string {
+// paramObjectText 返回函数体中第一个 var x = {...} 对象字面量
+func paramObjectText(block string) string {
 	match := firstObjectVarPattern.FindStringIndex(block)
 	if len(match) != 2 {
-		return nil
+		return ""
 	}
 	openBrace := strings.LastIndex(block[:match[1]], "{")
 	if openBrace < 0 {
-		return nil
+		return ""
 	}
 	end := findMatchingBrace(block, openBrace)
 	if end <= openBrace {
+		return ""
+	}
+	return block[openBrace : end+1]
+}
+
+func extractParamFields(block string) []string {
+	objectText := paramObjectText(block)
+	if objectText == "" {
 		return nil
 	}
-	objectText := block[openBrace : end+1]
 	fields := make([]string, 0)
 	for _, match := range objectKeyPattern.FindAllStringSubmatch(objectText, -1) {
 		if len(match) != 2 {
@@ -669,6 +695,54 @@ func extractParamFields(block string) []string {
 	return dedupeAndSort(fields)
 }
 
+// extractParamTypes 按参数对象顶层属性的字面量推断类型，例如 page:1、flag:!0、list:[]
+func extractParamTypes(block string) map[string]string {
+	objectText := paramObjectText(block)
+	if objectText == "" {
+		return nil
+	}
+	types := make(map[string]string)
+	for _, property := range splitTopLevelArgs(objectText[1 : len(objectText)-1]) {
+		key, value, ok := strings.Cut(property, ":")
+		if !ok {
+			continue
+		}
+		key = strings.Trim(strings.TrimSpace(key), `"'`)
+		if key == "controllerName" || key == "methodsName" || key == "methodName" {
+			continue
+		}
+		if kind := inferLiteralType(strings.TrimSpace(value)); kind != "" {
+			types[key] = kind
+		}
+	}
+	if len(types) == 0 {
+		return nil
+	}
+	return types
+}
+
+func inferLiteralType(value string) string {
+	switch {
+	case value == "":
+		return ""
+	case strings.HasPrefix(value, `"`), strings.HasPrefix(value, "'"), strings.HasPrefix(value, "`"),
+		strings.HasPrefix(value, "String("), strings.HasPrefix(value, "JSON.stringify("),
+		strings.HasSuffix(value, ".toString()"), strings.HasSuffix(value, `+""`), strings.HasSuffix(value, `+''`):
+		return "string"
+	case value == "true", value == "false", value == "!0", value == "!1", strings.HasPrefix(value, "!!"):
+		return "boolean"
+	case integerLiteralPattern.MatchString(value), strings.HasPrefix(value, "parseInt("):
+		return "integer"
+	case numberLiteralPattern.MatchString(value), strings.HasPrefix(value, "parseFloat("), strings.HasPrefix(value, "Number("):
+		return "number"
+	case strings.HasPrefix(value, "["):
+		return "array"
+	case strings.HasPrefix(value, "{"):
+		return "object"
+	}
+	return ""
+}
+
 func firstSubmatch(pattern *regexp.Regexp, text string) string {
 	match := pattern.FindStringSubmatch(text)
 	if len(match) != 2 {
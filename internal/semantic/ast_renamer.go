@@ -235,6 +235,52 @@ func RenameIdentifiersWithOptions(rootDir string, jsFiles []string, options ASTR
 	return report, nil
 }
 
+// renameIdentifiersIncremental 只对 jsFiles 做 AST 重命名，并把结果与上次的 ast_rename_map.json 合并。
+// 这些文件是重新解包得到的，先清理上次留下的写回前备份，回滚时才能恢复到本次的原始内容。
+func renameIdentifiersIncremental(rootDir string, jsFiles []string, options ASTRenameOptions) (*ASTRenameReport, error) {
+	var previous ASTRenameReport
+	if data, err := os.ReadFile(filepath.Join(rootDir, reportDirName, astRenameReportFileName)); err == nil {
+		_ = json.Unmarshal(data, &previous)
+	}
+	for _, rel := range jsFiles {
+		_ = os.Remove(filepath.Join(rootDir, reportDirName, preASTSourcesDirName, filepath.FromSlash(rel)))
+	}
+
+	report, err := RenameIdentifiersWithOptions(rootDir, jsFiles, options)
+	if err != nil {
+		return nil, err
+	}
+
+	current := make(map[string]struct{}, len(jsFiles))
+	for _, rel := range jsFiles {
+		current[rel] = struct{}{}
+	}
+	for _, file := range previous.Files {
+		if _, ok := current[file.FilePath]; ok {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(rootDir, filepath.FromSlash(file.FilePath))); err != nil {
+			continue
+		}
+		report.Files = append(report.Files, file)
+	}
+	report.TotalRenames, report.CandidateCount, report.RenamedFiles = 0, 0, 0
+	for _, file := range report.Files {
+		report.CandidateCount += len(file.Renames)
+		if file.RenameCount > 0 {
+			report.RenamedFiles++
+			report.TotalRenames += file.RenameCount
+		}
+	}
+	sort.Slice(report.Files, func(i, j int) bool {
+		return report.Files[i].FilePath < report.Files[j].FilePath
+	})
+	if err := writeASTRenameReport(rootDir, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
 func normalizeASTRenameOptions(options ASTRenameOptions) ASTRenameOptions {
 	mode := strings.ToLower(strings.TrimSpace(options.Mode))
 	switch mode {
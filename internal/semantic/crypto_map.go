@@ -0,0 +1,670 @@
+package semantic
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	cryptoMapJSONFileName = "crypto_map.json"
+	cryptoMapMDFileName   = "crypto_map.md"
+)
+
+// 签名函数与接口的关联方式
+const (
+	cryptoViaSameModule     = "same-module"     // 与接口函数位于同一文件
+	cryptoViaCallSite       = "call-site"       // 所在文件调用了该接口函数
+	cryptoViaRequire        = "require"         // 接口文件直接 require 了加密所在文件
+	cryptoViaRequestWrapper = "request-wrapper" // 经由一层请求封装间接引用
+)
+
+var (
+	cryptoEncodedParsePattern  = regexp.MustCompile(`\.(Utf8|Hex|Base64|Latin1)\.parse\(\s*(?:"([^"]*)"|'([^']*)'|([A-Za-z_$][\w$.]*))\s*\)`)
+	cryptoStringLiteralPattern = regexp.MustCompile(`^(?:"([^"]*)"|'([^']*)')$`)
+	cryptoIdentifierPattern    = regexp.MustCompile(`^[A-Za-z_$][\w$]*(?:\.[A-Za-z_$][\w$]*)*$`)
+	cryptoModeOptionPattern    = regexp.MustCompile(`\bmode\s*:\s*["']?(?:[\w$]+\.)*([A-Za-z]+)`)
+	cryptoPaddingOptionPattern = regexp.MustCompile(`\bpadding\s*:\s*["']?(?:[\w$]+\.)*([\w#]+)`)
+	cryptoIVOptionPattern      = regexp.MustCompile(`\biv\s*:\s*([^,}]+)`)
+
+	signSortPattern        = regexp.MustCompile(`\.sort\(\s*\)|Object\.keys\([^)]*\)\s*\.sort\(`)
+	signJoinPattern        = regexp.MustCompile(`\.join\(\s*["']([^"']*)["']\s*\)`)
+	signPairPattern        = regexp.MustCompile(`["']=["']|["']&["']`)
+	signSecretConcat       = regexp.MustCompile(`(?i)["']&?(?:key|secret|appsecret|app_secret|salt|signkey|sign_key)=["']\s*\+\s*([A-Za-z_$][\w$.]*|"[^"]*"|'[^']*')`)
+	signSecretNamePattern  = regexp.MustCompile(`\b([\w$]*(?:[Ss]ecret|[Ss]alt|[Ss]ign[Kk]ey|SIGN_KEY|APP_KEY))\b`)
+	signLiteralConcat      = regexp.MustCompile(`\+\s*["']([A-Za-z0-9_\-]{12,})["']|["']([A-Za-z0-9_\-]{12,})["']\s*\+`)
+	signFieldPattern       = regexp.MustCompile(`["']?\b(sign|signature|sig)["']?\s*[:=][^=]`)
+	signNonceFieldsPattern = regexp.MustCompile(`\b(timestamp|timeStamp|nonce|nonceStr|noncestr)\b`)
+)
+
+// CryptoMapReport 汇总加解密库调用与签名函数，并关联到 API 地图中的接口函数。
+type CryptoMapReport struct {
+	GeneratedAt string         `json:"generated_at"`
+	Libraries   []string       `json:"libraries,omitempty"`
+	Usages      []CryptoUsage  `json:"usages"`
+	Signers     []CryptoSigner `json:"signers"`
+}
+
+// CryptoUsage 描述一次加解密 / 摘要调用及能静态还原的 key、iv、模式。
+type CryptoUsage struct {
+	FilePath    string          `json:"file_path"`
+	LineNumber  int             `json:"line_number"`
+	Function    string          `json:"function,omitempty"`
+	Library     string          `json:"library"`
+	Algorithm   string          `json:"algorithm"`
+	Operation   string          `json:"operation"`
+	Mode        string          `json:"mode,omitempty"`
+	Padding     string          `json:"padding,omitempty"`
+	Key         string          `json:"key,omitempty"`
+	KeyEncoding string          `json:"key_encoding,omitempty"`
+	IV          string          `json:"iv,omitempty"`
+	Notes       []string        `json:"notes,omitempty"`
+	Expression  string          `json:"expression"`
+	APIs        []CryptoAPILink `json:"apis,omitempty"`
+
+	offset int
+}
+
+// CryptoSigner 描述疑似请求签名函数（参数排序拼接 + 密钥后取摘要）。
+type CryptoSigner struct {
+	FilePath   string          `json:"file_path"`
+	LineNumber int             `json:"line_number"`
+	Function   string          `json:"function"`
+	Algorithm  string          `json:"algorithm"`
+	SortsKeys  bool            `json:"sorts_keys"`
+	Separator  string          `json:"separator,omitempty"`
+	Secret     string          `json:"secret,omitempty"`
+	SignField  string          `json:"sign_field,omitempty"`
+	Evidence   []string        `json:"evidence"`
+	Confidence string          `json:"confidence"`
+	APIs       []CryptoAPILink `json:"apis,omitempty"`
+}
+
+// CryptoAPILink 指向受加密 / 签名影响的接口函数。
+type CryptoAPILink struct {
+	FunctionName   string `json:"function_name"`
+	ControllerName string `json:"controller_name,omitempty"`
+	MethodsName    string `json:"methods_name,omitempty"`
+	FilePath       string `json:"file_path"`
+	Via            string `json:"via"`
+}
+
+type cryptoCallSpec struct {
+	pattern    *regexp.Regexp
+	library    string
+	keyArg     int
+	optionsArg int
+	describe   func(match []string) (algorithm, operation string)
+}
+
+func fixedCrypto(algorithm, operation string) func([]string) (string, string) {
+	return func([]string) (string, string) { return algorithm, operation }
+}
+
+var cryptoCallSpecs = []cryptoCallSpec{
+	{
+		pattern: regexp.MustCompile(`\.(AES|DES|TripleDES|RC4|Rabbit)\.(encrypt|decrypt)\s*\(`),
+		library: "CryptoJS", keyArg: 1, optionsArg: 2,
+		describe: func(m []string) (string, string) { return m[1], m[2] },
+	},
+	{
+		pattern: regexp.MustCompile(`\.(Hmac(?:MD5|SHA1|SHA224|SHA256|SHA384|SHA512|SHA3|SM3))\s*\(`),
+		library: "CryptoJS", keyArg: 1, optionsArg: -1,
+		describe: func(m []string) (string, string) { return m[1], "hmac" },
+	},
+	{
+		pattern: regexp.MustCompile(`\.(MD5|SHA1|SHA224|SHA256|SHA384|SHA512|SHA3|RIPEMD160)\s*\(`),
+		library: "CryptoJS", keyArg: -1, optionsArg: -1,
+		describe: func(m []string) (string, string) { return m[1], "hash" },
+	},
+	{
+		pattern: regexp.MustCompile(`\.(setPublicKey|setPrivateKey)\s*\(`),
+		library: "jsencrypt", keyArg: 0, optionsArg: -1,
+		describe: func(m []string) (string, string) {
+			if m[1] == "setPrivateKey" {
+				return "RSA", "load-private-key"
+			}
+			return "RSA", "load-public-key"
+		},
+	},
+	{
+		pattern: regexp.MustCompile(`\bsm2\.(doEncrypt|doDecrypt|doSignature)\s*\(`),
+		library: "sm-crypto", keyArg: 1, optionsArg: -1,
+		describe: func(m []string) (string, string) {
+			return "SM2", map[string]string{"doEncrypt": "encrypt", "doDecrypt": "decrypt", "doSignature": "sign"}[m[1]]
+		},
+	},
+	{
+		pattern: regexp.MustCompile(`\bsm2\.doVerifySignature\s*\(`),
+		library: "sm-crypto", keyArg: 2, optionsArg: -1,
+		describe: fixedCrypto("SM2", "verify"),
+	},
+	{
+		pattern: regexp.MustCompile(`\bsm4\.(encrypt|decrypt)\s*\(`),
+		library: "sm-crypto", keyArg: 1, optionsArg: 2,
+		describe: func(m []string) (string, string) { return "SM4", m[1] },
+	},
+	{
+		pattern: regexp.MustCompile(`\bsm3\)?\s*\(`),
+		library: "sm-crypto", keyArg: -1, optionsArg: -1,
+		describe: fixedCrypto("SM3", "hash"),
+	},
+	{
+		pattern: regexp.MustCompile(`\b(hex_md5|hexMD5|md5|hex_sha1|sha1|sha256)\s*\(`),
+		library: "md5/sha 工具函数", keyArg: -1, optionsArg: -1,
+		describe: func(m []string) (string, string) {
+			name := strings.ToUpper(strings.TrimPrefix(strings.TrimPrefix(m[1], "hex_"), "hex"))
+			return name, "hash"
+		},
+	},
+}
+
+// BuildCryptoMap 定位加解密库调用、还原硬编码 key/iv/模式，识别签名函数并关联到 apiMap 中的接口，
+// 输出 crypto_map.json / crypto_map.md。apiMap 为 nil 时只输出调用清单。
+func BuildCryptoMap(rootDir string, apiMap *APIMapReport) (*CryptoMapReport, error) {
+	jsFiles, err := collectAllJSFiles(rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &CryptoMapReport{
+		GeneratedAt: time.Now().Format("2006-01-02 15:04:05"),
+		Usages:      make([]CryptoUsage, 0),
+		Signers:     make([]CryptoSigner, 0),
+	}
+	contents := make(map[string]string, len(jsFiles))
+	for _, relPath := range jsFiles {
+		data, err := os.ReadFile(filepath.Join(rootDir, filepath.FromSlash(relPath)))
+		if err != nil {
+			return nil, err
+		}
+		contents[relPath] = string(data)
+	}
+
+	linker := newCryptoLinker(contents, apiMap)
+	libraries := make(map[string]bool)
+	for _, relPath := range jsFiles {
+		text := contents[relPath]
+		functions := collectJSFunctionRanges(text)
+		usages := extractCryptoUsages(relPath, text, functions)
+		if len(usages) == 0 {
+			continue
+		}
+		links := linker.link(relPath)
+		for i := range usages {
+			usages[i].APIs = links
+			libraries[usages[i].Library] = true
+		}
+		report.Usages = append(report.Usages, usages...)
+		for _, signer := range detectSigners(relPath, text, functions, usages) {
+			signer.APIs = links
+			report.Signers = append(report.Signers, signer)
+		}
+	}
+	for library := range libraries {
+		report.Libraries = append(report.Libraries, library)
+	}
+	sort.Strings(report.Libraries)
+
+	if err := writeCryptoMap(rootDir, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func extractCryptoUsages(relPath, text string, functions []jsFunctionRange) []CryptoUsage {
+	usages := make([]CryptoUsage, 0)
+	seen := make(map[int]bool)
+	for _, spec := range cryptoCallSpecs {
+		for _, loc := range spec.pattern.FindAllStringSubmatchIndex(text, -1) {
+			open := loc[1] - 1
+			if seen[open] || isFunctionDeclarationAt(text, loc[0]) {
+				continue
+			}
+			end := findMatchingDelimiter(text, open, '(', ')')
+			if end < 0 {
+				continue
+			}
+			seen[open] = true
+
+			match := make([]string, len(loc)/2)
+			for i := range match {
+				if loc[2*i] >= 0 {
+					match[i] = text[loc[2*i]:loc[2*i+1]]
+				}
+			}
+			algorithm, operation := spec.describe(match)
+			start := expressionStart(text, loc[0])
+			usage := CryptoUsage{
+				FilePath:   relPath,
+				LineNumber: lineNumberAtOffset(text, start),
+				Library:    spec.library,
+				Algorithm:  algorithm,
+				Operation:  operation,
+				Expression: compactExpression(text[start : end+1]),
+				offset:     start,
+			}
+			if fn := innermostFunctionAt(functions, start); fn != nil {
+				usage.Function = fn.Name
+			}
+			args := splitTopLevelArgs(text[open+1 : end])
+			if spec.keyArg >= 0 && spec.keyArg < len(args) {
+				usage.Key, usage.KeyEncoding = resolveCryptoValue(text, args[spec.keyArg], start, 0)
+			}
+			if spec.optionsArg >= 0 && spec.optionsArg < len(args) {
+				applyCipherOptions(&usage, text, args[spec.optionsArg], start)
+			}
+			applyCipherDefaults(&usage, args)
+			usages = append(usages, usage)
+		}
+	}
+	sort.Slice(usages, func(i, j int) bool { return usages[i].offset < usages[j].offset })
+	return usages
+}
+
+// isFunctionDeclarationAt 排除 function md5(...) 这类工具函数自身的定义
+func isFunctionDeclarationAt(text string, offset int) bool {
+	return strings.HasSuffix(strings.TrimRight(text[:offset], " \t"), "function")
+}
+
+// expressionStart 向前扩展到调用接收者，例如 .AES.encrypt 扩展为 CryptoJS.AES.encrypt
+func expressionStart(text string, offset int) int {
+	for offset > 0 {
+		ch := text[offset-1]
+		if ch == '.' || ch == '_' || ch == '$' || ch >= '0' && ch <= '9' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' {
+			offset--
+			continue
+		}
+		break
+	}
+	return offset
+}
+
+func compactExpression(expression string) string {
+	expression = strings.Join(strings.Fields(expression), " ")
+	if runes := []rune(expression); len(runes) > 160 {
+		return string(runes[:160]) + "..."
+	}
+	return expression
+}
+
+func splitTopLevelArgs(argsText string) []string {
+	args := make([]string, 0, 3)
+	depth, last := 0, 0
+	var quote byte
+	for i := 0; i < len(argsText); i++ {
+		ch := argsText[i]
+		if quote != 0 {
+			if ch == '\\' {
+				i++
+			} else if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch ch {
+		case '"', '\'', '`':
+			quote = ch
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, strings.TrimSpace(argsText[last:i]))
+				last = i + 1
+			}
+		}
+	}
+	if tail := strings.TrimSpace(argsText[last:]); tail != "" || len(args) > 0 {
+		args = append(args, tail)
+	}
+	return args
+}
+
+// resolveCryptoValue 还原 key / iv 表达式：字符串字面量、enc.Xxx.parse("...")，
+// 或同文件内以 name = "..." / name: "..." 赋值的标识符（优先取调用点之前最近的赋值）。
+func resolveCryptoValue(text, expr string, offset, depth int) (string, string) {
+	expr = strings.TrimSpace(expr)
+	if match := cryptoStringLiteralPattern.FindStringSubmatch(expr); match != nil {
+		return match[1] + match[2], ""
+	}
+	if match := cryptoEncodedParsePattern.FindStringSubmatch(expr); match != nil {
+		encoding := strings.ToLower(match[1])
+		if match[4] == "" {
+			return match[2] + match[3], encoding
+		}
+		value, _ := resolveCryptoValue(text, match[4], offset, depth+1)
+		return value, encoding
+	}
+	if depth >= 2 || !cryptoIdentifierPattern.MatchString(expr) {
+		return "", ""
+	}
+
+	name := expr[strings.LastIndex(expr, ".")+1:]
+	assignPattern := regexp.MustCompile(`(?:^|[^\w$.])` + regexp.QuoteMeta(name) + `\s*[:=]\s*([^,;}\n]+)`)
+	candidates := assignPattern.FindAllStringSubmatchIndex(text, -1)
+	ordered := make([][]int, 0, len(candidates))
+	for i := len(candidates) - 1; i >= 0; i-- {
+		if candidates[i][0] < offset {
+			ordered = append(ordered, candidates[i])
+		}
+	}
+	for _, candidate := range candidates {
+		if candidate[0] >= offset {
+			ordered = append(ordered, candidate)
+		}
+	}
+	for _, candidate := range ordered {
+		rhs := strings.TrimSpace(text[candidate[2]:candidate[3]])
+		if strings.HasPrefix(rhs, "=") || rhs == expr {
+			continue
+		}
+		if value, encoding := resolveCryptoValue(text, rhs, candidate[0], depth+1); value != "" {
+			return value, encoding
+		}
+	}
+	return "", ""
+}
+
+func applyCipherOptions(usage *CryptoUsage, text, options string, offset int) {
+	if match := cryptoModeOptionPattern.FindStringSubmatch(options); match != nil {
+		usage.Mode = strings.ToUpper(match[1])
+	}
+	if match := cryptoPaddingOptionPattern.FindStringSubmatch(options); match != nil {
+		usage.Padding = match[1]
+	}
+	if match := cryptoIVOptionPattern.FindStringSubmatch(options); match != nil {
+		usage.IV, _ = resolveCryptoValue(text, match[1], offset, 0)
+	}
+}
+
+// applyCipherDefaults 补充库的默认行为，复现加密时最容易踩坑的就是这些隐式约定
+func applyCipherDefaults(usage *CryptoUsage, args []string) {
+	switch {
+	case usage.Library == "CryptoJS" && (usage.Operation == "encrypt" || usage.Operation == "decrypt"):
+		if usage.Key != "" && usage.KeyEncoding == "" {
+			usage.KeyEncoding = "passphrase"
+			usage.Notes = append(usage.Notes, "key 为普通字符串，CryptoJS 按口令处理，经 EVP_BytesToKey 派生 key/iv")
+		}
+		if usage.Mode == "" {
+			usage.Mode = "CBC"
+			usage.Notes = append(usage.Notes, "未指定 mode，使用 CryptoJS 默认 CBC")
+		}
+		if usage.Padding == "" {
+			usage.Padding = "Pkcs7"
+		}
+	case usage.Algorithm == "SM2" && (usage.Operation == "encrypt" || usage.Operation == "decrypt"):
+		usage.Mode = "C1C3C2"
+		if len(args) > 2 && strings.TrimSpace(args[2]) == "0" {
+			usage.Mode = "C1C2C3"
+		} else if len(args) <= 2 {
+			usage.Notes = append(usage.Notes, "未指定 cipherMode，sm-crypto 默认 C1C3C2")
+		}
+	case usage.Algorithm == "SM4" && usage.Mode == "":
+		usage.Mode = "ECB"
+		usage.Notes = append(usage.Notes, "未指定 mode，sm-crypto 默认 ECB")
+	}
+}
+
+// detectSigners 以摘要 / HMAC 调用所在函数为单位，按排序、拼接、密钥、sign 字段等特征打分
+func detectSigners(relPath, text string, functions []jsFunctionRange, usages []CryptoUsage) []CryptoSigner {
+	signers := make([]CryptoSigner, 0)
+	seen := make(map[int]bool)
+	for _, usage := range usages {
+		if usage.Operation != "hash" && usage.Operation != "hmac" {
+			continue
+		}
+		fn := innermostFunctionAt(functions, usage.offset)
+		if fn == nil || seen[fn.Start] {
+			continue
+		}
+		seen[fn.Start] = true
+
+		body := text[fn.Start:fn.End]
+		signer := CryptoSigner{
+			FilePath:   relPath,
+			LineNumber: fn.Line,
+			Function:   fn.Name,
+			Algorithm:  usage.Algorithm,
+			Evidence:   make([]string, 0),
+		}
+		score := 0
+		if signSortPattern.MatchString(body) {
+			signer.SortsKeys = true
+			signer.Evidence = append(signer.Evidence, "参数按键名排序")
+			score++
+		}
+		if match := signJoinPattern.FindStringSubmatch(body); match != nil {
+			signer.Separator = match[1]
+			signer.Evidence = append(signer.Evidence, fmt.Sprintf("以 %q 拼接参数", match[1]))
+			score++
+		} else if signPairPattern.MatchString(body) {
+			signer.Evidence = append(signer.Evidence, "按 key=value 拼接参数")
+			score++
+		}
+		if usage.Operation == "hmac" && usage.Key != "" {
+			signer.Secret = usage.Key
+		} else {
+			signer.Secret = signSecret(text, body, fn.Start)
+		}
+		if signer.Secret != "" {
+			signer.Evidence = append(signer.Evidence, "拼接硬编码密钥")
+			score++
+		}
+		if match := signFieldPattern.FindStringSubmatch(body); match != nil {
+			signer.SignField = match[1]
+			signer.Evidence = append(signer.Evidence, "写入 "+match[1]+" 字段")
+			score++
+		}
+		if signNonceFieldsPattern.MatchString(body) {
+			signer.Evidence = append(signer.Evidence, "包含 timestamp / nonce")
+			score++
+		}
+
+		switch {
+		case score >= 3:
+			signer.Confidence = "high"
+		case score >= 2 || score >= 1 && strings.Contains(strings.ToLower(fn.Name), "sign"):
+			signer.Confidence = "medium"
+		default:
+			continue
+		}
+		signers = append(signers, signer)
+	}
+	return signers
+}
+
+func signSecret(text, body string, offset int) string {
+	if match := signSecretConcat.FindStringSubmatch(body); match != nil {
+		if value, _ := resolveCryptoValue(text, match[1], offset, 0); value != "" {
+			return value
+		}
+	}
+	for _, match := range signSecretNamePattern.FindAllStringSubmatch(body, -1) {
+		if value, _ := resolveCryptoValue(text, match[1], offset, 0); value != "" {
+			return value
+		}
+	}
+	if match := signLiteralConcat.FindStringSubmatch(body); match != nil {
+		return match[1] + match[2]
+	}
+	return ""
+}
+
+// cryptoLinker 依据 API 地图和 require 关系，把加密所在文件关联到接口函数
+type cryptoLinker struct {
+	endpointsByFile map[string][]APIEndpointEntry
+	callersByFile   map[string][]APIEndpointEntry
+	requiredBy      map[string][]string
+}
+
+func newCryptoLinker(contents map[string]string, apiMap *APIMapReport) *cryptoLinker {
+	linker := &cryptoLinker{
+		endpointsByFile: make(map[string][]APIEndpointEntry),
+		callersByFile:   make(map[string][]APIEndpointEntry),
+		requiredBy:      make(map[string][]string),
+	}
+	if apiMap != nil {
+		for _, endpoint := range apiMap.Endpoints {
+			linker.endpointsByFile[endpoint.FilePath] = append(linker.endpointsByFile[endpoint.FilePath], endpoint)
+			for _, call := range endpoint.CallSites {
+				linker.callersByFile[call.FilePath] = append(linker.callersByFile[call.FilePath], endpoint)
+			}
+		}
+	}
+
+	known := make(map[string]string, len(contents))
+	for relPath := range contents {
+		known[relPath] = relPath
+	}
+	for relPath, text := range contents {
+		for _, literal := range uniqueRequires(text) {
+			target := resolveRequirePath(relPath, literal, known)
+			if _, ok := known[target]; ok && target != relPath {
+				linker.requiredBy[target] = append(linker.requiredBy[target], relPath)
+			}
+		}
+	}
+	for target := range linker.requiredBy {
+		sort.Strings(linker.requiredBy[target])
+	}
+	return linker
+}
+
+func (l *cryptoLinker) link(relPath string) []CryptoAPILink {
+	links := make([]CryptoAPILink, 0)
+	seen := make(map[string]bool)
+	add := func(endpoints []APIEndpointEntry, via string) {
+		for _, endpoint := range endpoints {
+			key := endpointKey(endpoint.FilePath, endpoint.FunctionName)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			links = append(links, CryptoAPILink{
+				FunctionName:   endpoint.FunctionName,
+				ControllerName: endpoint.ControllerName,
+				MethodsName:    endpoint.MethodsName,
+				FilePath:       endpoint.FilePath,
+				Via:            via,
+			})
+		}
+	}
+
+	add(l.endpointsByFile[relPath], cryptoViaSameModule)
+	add(l.callersByFile[relPath], cryptoViaCallSite)
+	for _, importer := range l.requiredBy[relPath] {
+		add(l.endpointsByFile[importer], cryptoViaRequire)
+	}
+	for _, importer := range l.requiredBy[relPath] {
+		for _, wrapperImporter := range l.requiredBy[importer] {
+			add(l.endpointsByFile[wrapperImporter], cryptoViaRequestWrapper)
+		}
+	}
+	if len(links) == 0 {
+		return nil
+	}
+	return links
+}
+
+func writeCryptoMap(rootDir string, report *CryptoMapReport) error {
+	reportDir := filepath.Join(rootDir, reportDirName)
+	if err := os.MkdirAll(reportDir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(reportDir, cryptoMapJSONFileName), data, 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(reportDir, cryptoMapMDFileName), []byte(buildCryptoMapMarkdown(report)), 0644)
+}
+
+func buildCryptoMapMarkdown(report *CryptoMapReport) string {
+	var builder strings.Builder
+	builder.WriteString("# 加解密与签名地图\n\n")
+	builder.WriteString(fmt.Sprintf("- 生成时间: `%s`\n", report.GeneratedAt))
+	builder.WriteString(fmt.Sprintf("- 加解密库: %s\n", inlineCodeList(report.Libraries)))
+	builder.WriteString(fmt.Sprintf("- 加解密调用: `%d`\n", len(report.Usages)))
+	builder.WriteString(fmt.Sprintf("- 签名函数: `%d`\n", len(report.Signers)))
+
+	builder.WriteString("\n## 签名函数\n\n")
+	if len(report.Signers) == 0 {
+		builder.WriteString("未识别到签名函数。\n")
+	} else {
+		builder.WriteString("| 函数 | 文件 | 算法 | 密钥 | 依据 | 置信度 | 关联接口 |\n")
+		builder.WriteString("|------|------|------|------|------|--------|----------|\n")
+		for _, signer := range report.Signers {
+			builder.WriteString(fmt.Sprintf("| `%s` | `%s:%d` | `%s` | %s | %s | %s | %s |\n",
+				signer.Function,
+				signer.FilePath, signer.LineNumber,
+				signer.Algorithm,
+				inlineCodeList([]string{markdownCell(signer.Secret)}),
+				strings.Join(signer.Evidence, "<br/>"),
+				signer.Confidence,
+				cryptoLinkList(signer.APIs),
+			))
+		}
+	}
+
+	builder.WriteString("\n## 加解密调用\n\n")
+	if len(report.Usages) == 0 {
+		builder.WriteString("未识别到加解密库调用。\n")
+		return builder.String()
+	}
+	builder.WriteString("| 位置 | 库 | 算法 | 操作 | 模式 / 填充 | Key | IV | 关联接口 |\n")
+	builder.WriteString("|------|----|------|------|-------------|-----|----|----------|\n")
+	for _, usage := range report.Usages {
+		modePadding := strings.Trim(usage.Mode+" / "+usage.Padding, " /")
+		key := markdownCell(usage.Key)
+		if key != "" && usage.KeyEncoding != "" {
+			key += " (" + usage.KeyEncoding + ")"
+		}
+		builder.WriteString(fmt.Sprintf("| `%s:%d` | %s | `%s` | %s | %s | %s | %s | %s |\n",
+			usage.FilePath, usage.LineNumber,
+			usage.Library,
+			usage.Algorithm,
+			usage.Operation,
+			emptyAsDash(modePadding),
+			inlineCodeList([]string{key}),
+			inlineCodeList([]string{markdownCell(usage.IV)}),
+			cryptoLinkList(usage.APIs),
+		))
+		for _, note := range usage.Notes {
+			builder.WriteString(fmt.Sprintf("|  |  |  | > %s |  |  |  |  |\n", note))
+		}
+	}
+	return builder.String()
+}
+
+func cryptoLinkList(links []CryptoAPILink) string {
+	const limit = 5
+	items := make([]string, 0, limit+1)
+	for i, link := range links {
+		if i == limit {
+			items = append(items, fmt.Sprintf("等 %d 个", len(links)))
+			break
+		}
+		name := link.FunctionName
+		if link.ControllerName != "" {
+			name = link.ControllerName + "." + link.MethodsName
+		}
+		items = append(items, fmt.Sprintf("`%s` (%s)", name, link.Via))
+	}
+	if len(items) == 0 {
+		return "-"
+	}
+	return strings.Join(items, "<br/>")
+}
+
+func markdownCell(value string) string {
+	return strings.ReplaceAll(value, "|", `\|`)
+}
@@ -0,0 +1,65 @@
+package semantic
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildCryptoMapLinksSignerAndCiphers(t *testing.T) {
+	root := t.TempDir()
+	mustWrite(t, filepath.Join(root, "utils/sign.js"), `var CryptoJS=require("../lib/crypto-js.js");
+var SECRET="d41f3b8a9c2e4f7a";
+function makeSign(params){params.timestamp=Date.now();var keys=Object.keys(params).sort();var str=keys.map(function(k){return k+"="+params[k]}).join("&");return CryptoJS.MD5(str+"&key="+SECRET).toString().toUpperCase()}
+function encryptBody(data){var key=CryptoJS.enc.Utf8.parse("1234567890abcdef");var iv=CryptoJS.enc.Utf8.parse("abcdef1234567890");return CryptoJS.AES.encrypt(JSON.stringify(data),key,{iv:iv,mode:CryptoJS.mode.CBC,padding:CryptoJS.pad.Pkcs7}).toString()}
+function md5(s){return s}
+module.exports={makeSign:makeSign,encryptBody:encryptBody};`)
+	mustWrite(t, filepath.Join(root, "request.js"), `var sign=require("utils/sign.js");
+exports.request=function(options){options.data.sign=sign.makeSign(options.data);return wx.request(options)};`)
+	mustWrite(t, filepath.Join(root, "api_mixed.js"), `var request=require("request.js");
+exports.getECert=function(params){var requestData={userId:params.userId,controllerName:"CerInfo",methodsName:"GetECert"};return request.request({url:"",method:"GET",data:requestData})};`)
+	mustWrite(t, filepath.Join(root, "pages/index/index.js"), `var api=require("../../api_mixed.js");var o=require("../../lib/sm-crypto.js");
+Page({onLoad:function(){var body=o.sm4.encrypt(JSON.stringify({id:1}),"0123456789abcdeffedcba9876543210",{mode:"cbc",iv:"fedcba98765432100123456789abcdef"});api.getECert({userId:body})}});`)
+
+	apiMap, err := BuildAPIMap(root, []string{"api_mixed.js", "request.js", "utils/sign.js", "pages/index/index.js"})
+	if err != nil {
+		t.Fatalf("BuildAPIMap 返回错误: %v", err)
+	}
+	report, err := BuildCryptoMap(root, apiMap)
+	if err != nil {
+		t.Fatalf("BuildCryptoMap 返回错误: %v", err)
+	}
+
+	if len(report.Signers) != 1 {
+		t.Fatalf("应识别 1 个签名函数: %#v", report.Signers)
+	}
+	signer := report.Signers[0]
+	if signer.Function != "makeSign" || signer.Algorithm != "MD5" || !signer.SortsKeys ||
+		signer.Separator != "&" || signer.Secret != "d41f3b8a9c2e4f7a" || signer.Confidence != "high" {
+		t.Fatalf("签名函数识别不正确: %#v", signer)
+	}
+	if len(signer.APIs) != 1 || signer.APIs[0].MethodsName != "GetECert" || signer.APIs[0].Via != cryptoViaRequestWrapper {
+		t.Fatalf("签名函数应经请求封装关联到 GetECert: %#v", signer.APIs)
+	}
+
+	usages := make(map[string]CryptoUsage)
+	for _, usage := range report.Usages {
+		usages[usage.Algorithm] = usage
+	}
+	if len(report.Usages) != 3 {
+		t.Fatalf("应识别 MD5、AES、SM4 三处调用（md5 定义不计）: %#v", report.Usages)
+	}
+	aes := usages["AES"]
+	if aes.Function != "encryptBody" || aes.Key != "1234567890abcdef" || aes.KeyEncoding != "utf8" ||
+		aes.IV != "abcdef1234567890" || aes.Mode != "CBC" || aes.Padding != "Pkcs7" {
+		t.Fatalf("AES 参数还原不正确: %#v", aes)
+	}
+	sm4 := usages["SM4"]
+	if sm4.Key != "0123456789abcdeffedcba9876543210" || sm4.Mode != "CBC" || sm4.IV != "fedcba98765432100123456789abcdef" {
+		t.Fatalf("SM4 参数还原不正确: %#v", sm4)
+	}
+	if len(sm4.APIs) != 1 || sm4.APIs[0].Via != cryptoViaCallSite {
+		t.Fatalf("页面内加密应经调用点关联到接口: %#v", sm4.APIs)
+	}
+	assertExists(t, filepath.Join(root, ".gwxapkg/crypto_map.json"))
+	assertExists(t, filepath.Join(root, ".gwxapkg/crypto_map.md"))
+}
@@ -0,0 +1,109 @@
+package semantic
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/25smoking/Gwxapkg/internal/wxenv"
+)
+
+// DefaultEvalTimeout eval 默认执行时限，模块加载与函数调用分别计时
+const DefaultEvalTimeout = wxenv.DefaultTimeout
+
+// EvalOptions 描述一次沙箱调用。
+type EvalOptions struct {
+	// Module 相对输出目录的模块路径，例如 utils/crypto.js
+	Module string
+	// Function 导出函数名，支持 a.b 形式的属性路径；为空时直接返回 module.exports
+	Function string
+	// Args 调用参数，JSON 数组；非数组时视为单个参数
+	Args string
+	// Storage wx.getStorageSync 读取的本地缓存 JSON
+	Storage string
+	// Timeout 为 0 时使用 DefaultEvalTimeout
+	Timeout time.Duration
+}
+
+// EvalResult 是沙箱调用的返回值与执行期间的副作用。
+type EvalResult struct {
+	Value    interface{}     `json:"value"`
+	Requests []wxenv.Request `json:"requests,omitempty"`
+	Logs     []string        `json:"logs,omitempty"`
+}
+
+// EvalModule 在 wxenv 运行环境中加载已还原的模块并调用导出函数。
+// 沙箱内只有 wx / uni / getApp 桩与限定在 rootDir 内的 require，没有文件与网络能力；
+// wx.request 只记录参数，console 输出记录在 Logs 中。返回 Promise 时取其结果。
+func EvalModule(rootDir string, options EvalOptions) (*EvalResult, error) {
+	rootAbs, err := filepath.Abs(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	storage, err := parseEvalStorage(options.Storage)
+	if err != nil {
+		return nil, err
+	}
+	env, err := wxenv.New(rootAbs, wxenv.Options{Storage: storage, Timeout: options.Timeout})
+	if err != nil {
+		return nil, err
+	}
+
+	modulePath := options.Module
+	if filepath.IsAbs(modulePath) {
+		if rel, err := filepath.Rel(rootAbs, modulePath); err == nil {
+			modulePath = rel
+		}
+	}
+	args, err := parseEvalArgs(options.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := env.Require(modulePath)
+	if err != nil {
+		return nil, err
+	}
+	if options.Function != "" {
+		if value, err = env.Call(value, options.Function, args...); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &EvalResult{Logs: env.Logs()}
+	if result.Value, err = env.Export(value); err != nil {
+		return nil, err
+	}
+	if result.Requests, err = env.Requests(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func parseEvalStorage(raw string) (map[string]interface{}, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var storage map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &storage); err != nil {
+		return nil, fmt.Errorf("storage 不是合法 JSON 对象: %w", err)
+	}
+	return storage, nil
+}
+
+func parseEvalArgs(raw string) ([]interface{}, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		return nil, fmt.Errorf("参数不是合法 JSON: %w", err)
+	}
+	if args, ok := parsed.([]interface{}); ok {
+		return args, nil
+	}
+	return []interface{}{parsed}, nil
+}
@@ -0,0 +1,55 @@
+package semantic
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/25smoking/Gwxapkg/internal/wxenv"
+)
+
+func TestEvalModuleCallsExportedFunctions(t *testing.T) {
+	root := t.TempDir()
+	mustWrite(t, filepath.Join(root, "config.json"), `{"salt":"-s1"}`)
+	mustWrite(t, filepath.Join(root, "utils/md5.js"), `exports.hexMD5=function(s){return "md5("+s+")"};`)
+	mustWrite(t, filepath.Join(root, "utils/crypto.js"), `var md5=require("./md5");var cfg=require("../config.json");var missing=require("./not-restored.js");
+exports.decrypt=function(blob){return md5.hexMD5(blob)+cfg.salt};
+exports.buildURL=function(p){return "https://"+wx.getStorageSync("host")+p};
+exports.double=function(x){return Promise.resolve(x*2)};
+exports.loop=function(){while(true){}};
+exports.nested={sign:function(a,b){console.log("signing",a);uni.request({url:"/s",data:{a:a}});return this.prefix+(a+b)},prefix:"S:"};`)
+
+	cases := []struct {
+		function, args, storage string
+		want                    interface{}
+	}{
+		{"decrypt", `["blob"]`, "", "md5(blob)-s1"},
+		{"buildURL", `"/api"`, `{"host":"api.shop-wx.com.cn"}`, "https://api.shop-wx.com.cn/api"},
+		{"double", `[21]`, "", float64(42)},
+	}
+	for _, tc := range cases {
+		result, err := EvalModule(root, EvalOptions{Module: "utils/crypto.js", Function: tc.function, Args: tc.args, Storage: tc.storage})
+		if err != nil {
+			t.Fatalf("%s 执行失败: %v", tc.function, err)
+		}
+		if result.Value != tc.want {
+			t.Fatalf("%s 返回 %#v，期望 %#v", tc.function, result.Value, tc.want)
+		}
+	}
+
+	result, err := EvalModule(root, EvalOptions{Module: "utils/crypto", Function: "nested.sign", Args: `[1, 2]`})
+	if err != nil {
+		t.Fatalf("nested.sign 执行失败: %v", err)
+	}
+	if result.Value != "S:3" || strings.Join(result.Logs, ";") != "signing 1" || len(result.Requests) != 1 || result.Requests[0].URL != "/s" {
+		t.Fatalf("this、console 与 uni.request 捕获不正确: %#v", result)
+	}
+
+	if _, err := EvalModule(root, EvalOptions{Module: "utils/crypto.js", Function: "loop", Timeout: 50 * time.Millisecond}); err != wxenv.ErrTimeout {
+		t.Fatalf("死循环应超时: %v", err)
+	}
+	if _, err := EvalModule(root, EvalOptions{Module: "../outside.js"}); err == nil {
+		t.Fatalf("越出输出目录的模块应被拒绝")
+	}
+}
@@ -41,6 +41,10 @@ type Report struct {
 	APICallChainJSONPath  string            `json:"api_call_chain_json_path,omitempty"`
 	APICallChainMDPath    string            `json:"api_call_chain_md_path,omitempty"`
 	APIPseudoMarkdownPath string            `json:"api_pseudo_markdown_path,omitempty"`
+	CryptoUsageCount      int               `json:"crypto_usage_count"`
+	CryptoSignerCount     int               `json:"crypto_signer_count"`
+	CryptoMapJSONPath     string            `json:"crypto_map_json_path,omitempty"`
+	CryptoMapMDPath       string            `json:"crypto_map_md_path,omitempty"`
 	ASTRenamedCount       int               `json:"ast_renamed_count"`
 	ASTRenamedFiles       int               `json:"ast_renamed_files"`
 	ASTRenameMapPath      string            `json:"ast_rename_map_path,omitempty"`
@@ -114,11 +118,7 @@ func RewriteProjectWithOptions(rootDir string, options RewriteOptions) (*Report,
 	if err != nil {
 		return nil, fmt.Errorf("解析输出目录失败: %w", err)
 	}
-	if strings.TrimSpace(options.ASTRename.Mode) == "" {
-		options.ASTRename = DefaultASTRenameOptions()
-	} else {
-		options.ASTRename = normalizeASTRenameOptions(options.ASTRename)
-	}
+	options = normalizeRewriteOptions(options)
 
 	modules, allJSFiles, err := collectModules(rootAbs)
 	if err != nil {
@@ -142,6 +142,11 @@ func RewriteProjectWithOptions(rootDir string, options RewriteOptions) (*Report,
 			return nil, err
 		}
 		attachAPIMapReport(report, apiMap)
+		cryptoMap, err := BuildCryptoMap(rootAbs, apiMap)
+		if err != nil {
+			return nil, err
+		}
+		attachCryptoMapReport(report, cryptoMap)
 
 		latestJSFiles, err := collectAllJSFiles(rootAbs)
 		if err != nil {
@@ -212,6 +217,11 @@ func RewriteProjectWithOptions(rootDir string, options RewriteOptions) (*Report,
 		return nil, err
 	}
 	attachAPIMapReport(report, apiMap)
+	cryptoMap, err := BuildCryptoMap(rootAbs, apiMap)
+	if err != nil {
+		return nil, err
+	}
+	attachCryptoMapReport(report, cryptoMap)
 
 	latestJSFiles, err := collectAllJSFiles(rootAbs)
 	if err != nil {
@@ -240,6 +250,179 @@ func RewriteProjectWithOptions(rootDir string, options RewriteOptions) (*Report,
 	return report, nil
 }
 
+// RewriteFilesWithOptions 只对 files 中新增或变化的 JS 文件做模块重命名、AST 还原与 source map 恢复，
+// 结果与上次的 semantic_module_map.json 合并，供增量处理新到达的分包使用。
+// files 为相对 rootDir 的路径；接口映射与加密映射依赖跨文件调用关系，仍按全部文件重建。
+func RewriteFilesWithOptions(rootDir string, files []string, options RewriteOptions) (*Report, error) {
+	rootAbs, err := filepath.Abs(rootDir)
+	if err != nil {
+		return nil, fmt.Errorf("解析输出目录失败: %w", err)
+	}
+	options = normalizeRewriteOptions(options)
+
+	report, ok := readExistingReport(rootAbs)
+	if !ok {
+		report = &Report{}
+	}
+	report.GeneratedAt = time.Now().Format("2006-01-02 15:04:05")
+	if report.PathMap == nil {
+		report.PathMap = map[string]string{}
+	}
+
+	allJSFiles, err := collectAllJSFiles(rootAbs)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]struct{}, len(allJSFiles))
+	for _, rel := range allJSFiles {
+		existing[rel] = struct{}{}
+	}
+	affected := make([]string, 0, len(files))
+	for _, rel := range dedupeAndSort(files) {
+		rel = filepath.ToSlash(rel)
+		if _, ok := existing[rel]; ok {
+			affected = append(affected, rel)
+		}
+	}
+
+	modules := make([]*moduleInfo, 0)
+	for _, rel := range affected {
+		if !isHashModule(rel) {
+			continue
+		}
+		fullPath := filepath.Join(rootAbs, filepath.FromSlash(rel))
+		data, err := os.ReadFile(fullPath)
+		if err != nil {
+			return nil, fmt.Errorf("扫描 JS 模块失败: %w", err)
+		}
+		modules = append(modules, analyzeModule(rel, fullPath, string(data)))
+	}
+
+	// 新文件中的 require 可能指向上次已重命名的模块，改写时合并两次的映射；
+	// 实际移动文件只用本次的映射
+	pathMap := buildRenamePlan(modules, allJSFiles)
+	requireMap := make(map[string]string, len(report.PathMap)+len(pathMap))
+	for from, to := range report.PathMap {
+		requireMap[from] = to
+	}
+	for from, to := range pathMap {
+		requireMap[from] = to
+	}
+	if len(requireMap) > 0 && len(affected) > 0 {
+		rewritten, err := rewriteRequiresAndSource(rootAbs, affected, requireMap, modules)
+		if err != nil {
+			return nil, err
+		}
+		report.RewrittenRequireCount += rewritten
+	}
+	if len(pathMap) > 0 {
+		if err := renameFiles(rootAbs, pathMap); err != nil {
+			return nil, err
+		}
+	}
+	for from, to := range pathMap {
+		report.PathMap[from] = to
+	}
+
+	replaced := make(map[string]struct{}, len(modules))
+	for _, module := range modules {
+		replaced[module.relPath] = struct{}{}
+	}
+	merged := make([]ModuleReport, 0, len(report.Modules)+len(modules))
+	for _, module := range report.Modules {
+		if _, ok := replaced[module.OriginalPath]; !ok {
+			merged = append(merged, module)
+		}
+	}
+	for _, module := range modules {
+		semanticPath := module.relPath
+		if next, ok := pathMap[module.relPath]; ok {
+			semanticPath = next
+		}
+		merged = append(merged, ModuleReport{
+			OriginalPath: module.relPath,
+			SemanticPath: semanticPath,
+			Role:         module.role,
+			Reason:       module.reason,
+			Exports:      module.exports,
+			Controllers:  module.controllers,
+			Methods:      module.methods,
+			Dependencies: module.dependencies,
+		})
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].OriginalPath < merged[j].OriginalPath
+	})
+	report.Modules = merged
+	report.RenamedCount = 0
+	for _, module := range report.Modules {
+		if module.SemanticPath != module.OriginalPath {
+			report.RenamedCount++
+		}
+	}
+
+	latestJSFiles, err := collectAllJSFiles(rootAbs)
+	if err != nil {
+		return nil, err
+	}
+	apiMap, err := BuildAPIMap(rootAbs, latestJSFiles)
+	if err != nil {
+		return nil, err
+	}
+	attachAPIMapReport(report, apiMap)
+	cryptoMap, err := BuildCryptoMap(rootAbs, apiMap)
+	if err != nil {
+		return nil, err
+	}
+	attachCryptoMapReport(report, cryptoMap)
+
+	affectedJSFiles := remapJSFiles(affected, pathMap)
+	if options.ASTRename.Mode != ASTRenameModeOff && len(affectedJSFiles) > 0 {
+		// RenameIdentifiersWithOptions 在文件列表为空时会处理全部文件，这里只在有变化时调用
+		astReport, err := renameIdentifiersIncremental(rootAbs, affectedJSFiles, options.ASTRename)
+		if err != nil {
+			return nil, err
+		}
+		attachASTRenameReport(report, astReport)
+	}
+
+	sourceReports, err := recoverSourceMaps(rootAbs, affectedJSFiles)
+	if err != nil {
+		return nil, err
+	}
+	recovered := make(map[string]struct{}, len(sourceReports))
+	for _, sourceReport := range sourceReports {
+		recovered[sourceReport.MapPath] = struct{}{}
+	}
+	for _, sourceReport := range report.SourceMaps {
+		if _, ok := recovered[sourceReport.MapPath]; !ok {
+			sourceReports = append(sourceReports, sourceReport)
+		}
+	}
+	sort.Slice(sourceReports, func(i, j int) bool {
+		return sourceReports[i].MapPath < sourceReports[j].MapPath
+	})
+	report.SourceMaps = sourceReports
+	report.SourceMapRecovered = 0
+	for _, sourceReport := range sourceReports {
+		report.SourceMapRecovered += len(sourceReport.RecoveredFiles)
+	}
+
+	if err := writeReport(rootAbs, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func normalizeRewriteOptions(options RewriteOptions) RewriteOptions {
+	if strings.TrimSpace(options.ASTRename.Mode) == "" {
+		options.ASTRename = DefaultASTRenameOptions()
+	} else {
+		options.ASTRename = normalizeASTRenameOptions(options.ASTRename)
+	}
+	return options
+}
+
 func attachAPIMapReport(report *Report, apiMap *APIMapReport) {
 	if report == nil || apiMap == nil {
 		return
@@ -254,6 +437,16 @@ func attachAPIMapReport(report *Report, apiMap *APIMapReport) {
 	report.APIPseudoMarkdownPath = path.Join(reportDirName, apiPseudoMDFileName)
 }
 
+func attachCryptoMapReport(report *Report, cryptoMap *CryptoMapReport) {
+	if report == nil || cryptoMap == nil {
+		return
+	}
+	report.CryptoUsageCount = len(cryptoMap.Usages)
+	report.CryptoSignerCount = len(cryptoMap.Signers)
+	report.CryptoMapJSONPath = path.Join(reportDirName, cryptoMapJSONFileName)
+	report.CryptoMapMDPath = path.Join(reportDirName, cryptoMapMDFileName)
+}
+
 func attachASTRenameReport(report *Report, astReport *ASTRenameReport) {
 	if report == nil || astReport == nil {
 		return
@@ -624,11 +817,16 @@ func rewriteRequestWrapperVariables(content string) (string, bool) {
 }
 
 func findMatchingBrace(content string, openBrace int) int {
+	return findMatchingDelimiter(content, openBrace, '{', '}')
+}
+
+// findMatchingDelimiter 跳过字符串与注释，返回与 content[open] 配对的结束符位置
+func findMatchingDelimiter(content string, open int, openCh, closeCh byte) int {
 	depth := 0
 	inSingle, inDouble, inTemplate := false, false, false
 	inLineComment, inBlockComment := false, false
 
-	for i := openBrace; i < len(content); i++ {
+	for i := open; i < len(content); i++ {
 		ch := content[i]
 		if inLineComment {
 			if ch == '\n' {
@@ -676,9 +874,9 @@ func findMatchingBrace(content string, openBrace int) int {
 			inDouble = true
 		case '`':
 			inTemplate = true
-		case '{':
+		case openCh:
 			depth++
-		case '}':
+		case closeCh:
 			depth--
 			if depth == 0 {
 				return i
@@ -932,16 +1130,25 @@ func writeReport(rootDir string, report *Report) error {
 	return os.WriteFile(filepath.Join(reportDir, "semantic_module_map.json"), data, 0644)
 }
 
-func readExistingReport(rootDir string) (*Report, bool) {
+// LoadReport 读取 rootDir 下的 .gwxapkg/semantic_module_map.json
+func LoadReport(rootDir string) (*Report, error) {
 	data, err := os.ReadFile(filepath.Join(rootDir, reportDirName, "semantic_module_map.json"))
 	if err != nil {
-		return nil, false
+		return nil, fmt.Errorf("读取 semantic_module_map.json 失败: %w", err)
 	}
 	var report Report
 	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("解析 semantic_module_map.json 失败: %w", err)
+	}
+	return &report, nil
+}
+
+func readExistingReport(rootDir string) (*Report, bool) {
+	report, err := LoadReport(rootDir)
+	if err != nil {
 		return nil, false
 	}
-	return &report, true
+	return report, true
 }
 
 func uniqueMatches(pattern *regexp.Regexp, text string) []string {
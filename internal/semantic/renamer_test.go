@@ -58,6 +58,41 @@ exports.request=function(r){return wx.request({url:t.config.baseApiUrl+r.url,met
 	}
 }
 
+func TestRewriteFilesOnlyTouchesAffectedFilesAndMergesReport(t *testing.T) {
+	root := t.TempDir()
+	mustWrite(t, filepath.Join(root, "8D7469A633221CDFEB1201A10B047D74.js"), `exports.config={baseApiUrl:"https://example.test/api/"};`)
+	mustWrite(t, filepath.Join(root, "pages/index/index.js"), `var c=require("../../8D7469A633221CDFEB1201A10B047D74.js");Page({});`)
+	options := RewriteOptions{ASTRename: ASTRenameOptions{Mode: ASTRenameModeOff}}
+	if _, err := RewriteProjectWithOptions(root, options); err != nil {
+		t.Fatalf("RewriteProjectWithOptions 返回错误: %v", err)
+	}
+	assertExists(t, filepath.Join(root, "config.js"))
+
+	// 新分包中的哈希模块与引用上次已重命名模块的页面
+	mustWrite(t, filepath.Join(root, "pkgA/07AF917533221CDF61C9F97247647D74.js"), `exports.request=function(r){return wx.request({url:r.url})};`)
+	mustWrite(t, filepath.Join(root, "pkgA/pages/detail.js"), `var c=require("../../8D7469A633221CDFEB1201A10B047D74.js"),r=require("../07AF917533221CDF61C9F97247647D74.js");Page({});`)
+	// 未受影响的文件即使仍是哈希名也不应处理
+	mustWrite(t, filepath.Join(root, "A3DE0D7433221CDFC5B86573AF447D74.js"), `exports.x=1;`)
+
+	report, err := RewriteFilesWithOptions(root, []string{"pkgA/07AF917533221CDF61C9F97247647D74.js", "pkgA/pages/detail.js"}, options)
+	if err != nil {
+		t.Fatalf("RewriteFilesWithOptions 返回错误: %v", err)
+	}
+	assertExists(t, filepath.Join(root, "pkgA/request.js"))
+	assertExists(t, filepath.Join(root, "A3DE0D7433221CDFC5B86573AF447D74.js"))
+	detail := mustRead(t, filepath.Join(root, "pkgA/pages/detail.js"))
+	if !strings.Contains(detail, `require("../../config.js")`) || !strings.Contains(detail, `require("../request.js")`) {
+		t.Fatalf("新文件的 require 应按两次的映射改写: %s", detail)
+	}
+	if report.RenamedCount != 2 || report.PathMap["8D7469A633221CDFEB1201A10B047D74.js"] != "config.js" {
+		t.Fatalf("应合并上次的模块映射: %#v", report)
+	}
+	loaded, err := LoadReport(root)
+	if err != nil || len(loaded.Modules) != 2 {
+		t.Fatalf("合并后的报告应写回: %v %#v", err, loaded)
+	}
+}
+
 func TestRewriteProjectRecoversSourcesContent(t *testing.T) {
 	root := t.TempDir()
 	mustWrite(t, filepath.Join(root, "app.js"), "console.log(1);\n//# sourceMappingURL=app.js.map\n")
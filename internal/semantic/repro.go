@@ -0,0 +1,503 @@
+package semantic
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/25smoking/Gwxapkg/internal/wxenv"
+	"github.com/dop251/goja"
+)
+
+const (
+	reproDirName    = "repro"
+	reproRunTimeout = 5 * time.Second
+)
+
+var (
+	reproExportFunctionPattern = regexp.MustCompile(`(?m)\b(?:module\.)?exports\.([A-Za-z_$][\w$]*)\s*=\s*function\s*[A-Za-z_$]?[\w$]*\s*\([^)]*\)\s*\{`)
+	reproExportAliasPattern    = regexp.MustCompile(`\b(?:module\.)?exports\.([A-Za-z_$][\w$]*)\s*=\s*([A-Za-z_$][\w$]*)\s*[;,\n}]`)
+	reproModuleExportsPattern  = regexp.MustCompile(`\bmodule\.exports\s*=\s*\{([^{}]*)\}`)
+	reproMemberPattern         = regexp.MustCompile(`(?:^|[^\w$.])([A-Za-z_$][\w$]*)\s*\.\s*([A-Za-z_$][\w$]*)`)
+	reproThisMemberPattern     = regexp.MustCompile(`\bthis\.([A-Za-z_$][\w$]*)`)
+	reproIdentifierPattern     = regexp.MustCompile(`(?:^|[^\w$.])([A-Za-z_$][\w$]*)`)
+	reproFileStemPattern       = regexp.MustCompile(`[^\w\-]+`)
+)
+
+// ReproOptions 控制签名复现脚本的生成。
+type ReproOptions struct {
+	// API 选择接口函数：函数名、Controller.Method 或 file.js:function
+	API string
+	// OutputDir 为空时写入 <root>/.gwxapkg/repro
+	OutputDir string
+}
+
+// ReproReport 描述一次签名复现脚本生成的结果。
+type ReproReport struct {
+	GeneratedAt    string           `json:"generated_at"`
+	Endpoint       APIEndpointEntry `json:"endpoint"`
+	Functions      []ReproFunction  `json:"functions"`
+	Modules        []string         `json:"modules"`
+	StubbedModules []string         `json:"stubbed_modules,omitempty"`
+	ScriptPath     string           `json:"script_path"`
+	HarnessPath    string           `json:"harness_path"`
+}
+
+// ReproFunction 是调用闭包中的一个函数；Located 为 false 时无法定位函数体，整模块打包。
+type ReproFunction struct {
+	FilePath   string `json:"file_path"`
+	Name       string `json:"name"`
+	LineNumber int    `json:"line_number,omitempty"`
+	Located    bool   `json:"located"`
+}
+
+type reproModule struct {
+	text      string
+	functions map[string]jsFunctionRange
+	exports   map[string]string
+	aliases   map[string]string
+	requires  map[string]string
+}
+
+// BuildSigningRepro 以 api_map.json 中的接口函数为入口，沿 require 图追踪计算请求参数 / 签名
+// 所需的函数闭包，把涉及的模块打包成可由 Node 或 goja 直接运行的独立脚本，并生成 goja 的 Go 调用模板。
+// 脚本中的 wx.request 只记录请求参数而不发出请求。
+func BuildSigningRepro(rootDir string, options ReproOptions) (*ReproReport, error) {
+	rootAbs, err := filepath.Abs(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	apiMap, err := LoadAPIMap(rootAbs)
+	if err != nil {
+		return nil, err
+	}
+	pathMap := map[string]string{}
+	if existing, ok := readExistingReport(rootAbs); ok && existing.PathMap != nil {
+		pathMap = existing.PathMap
+	}
+	endpoint, err := selectReproEndpoint(apiMap, options.API, pathMap)
+	if err != nil {
+		return nil, err
+	}
+
+	jsFiles, err := collectAllJSFiles(rootAbs)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]string, len(jsFiles))
+	for _, relPath := range jsFiles {
+		known[relPath] = relPath
+	}
+	modules := make(map[string]*reproModule)
+	loadModule := func(relPath string) *reproModule {
+		if module, ok := modules[relPath]; ok {
+			return module
+		}
+		if _, ok := known[relPath]; !ok {
+			return nil
+		}
+		data, err := os.ReadFile(filepath.Join(rootAbs, filepath.FromSlash(relPath)))
+		if err != nil {
+			return nil
+		}
+		module := parseReproModule(relPath, string(data), known)
+		modules[relPath] = module
+		return module
+	}
+
+	report := &ReproReport{
+		GeneratedAt: time.Now().Format("2006-01-02 15:04:05"),
+		Endpoint:    *endpoint,
+		Functions:   make([]ReproFunction, 0),
+	}
+	if loadModule(endpoint.FilePath) == nil {
+		return nil, fmt.Errorf("接口文件不存在: %s", endpoint.FilePath)
+	}
+
+	// 广度优先追踪：函数体里的 alias.member 进入被 require 的模块，裸调用与 this.xxx 留在本模块
+	type node struct{ file, name string }
+	queue := []node{{endpoint.FilePath, endpoint.FunctionName}}
+	visited := make(map[node]bool)
+	bundled := make(map[string]bool)
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+		module := loadModule(current.file)
+		if module == nil {
+			continue
+		}
+		bundled[current.file] = true
+
+		fn, ok := module.lookup(current.name)
+		entry := ReproFunction{FilePath: current.file, Name: current.name, Located: ok}
+		if !ok {
+			report.Functions = append(report.Functions, entry)
+			continue
+		}
+		entry.LineNumber = fn.Line
+		report.Functions = append(report.Functions, entry)
+
+		body := module.text[fn.Start:fn.End]
+		for _, match := range reproMemberPattern.FindAllStringSubmatch(body, -1) {
+			if target, ok := module.aliases[match[1]]; ok {
+				queue = append(queue, node{target, match[2]})
+			}
+		}
+		for _, match := range reproThisMemberPattern.FindAllStringSubmatch(body, -1) {
+			if _, ok := module.lookup(match[1]); ok {
+				queue = append(queue, node{current.file, match[1]})
+			}
+		}
+		for _, match := range reproIdentifierPattern.FindAllStringSubmatch(body, -1) {
+			if match[1] == fn.Name {
+				continue
+			}
+			if _, ok := module.functions[match[1]]; ok {
+				queue = append(queue, node{current.file, match[1]})
+			}
+		}
+	}
+
+	for relPath := range bundled {
+		report.Modules = append(report.Modules, relPath)
+	}
+	sort.Strings(report.Modules)
+	stubbed := make(map[string]bool)
+	for _, relPath := range report.Modules {
+		for literal, target := range modules[relPath].requires {
+			if !bundled[target] {
+				stubbed[literal] = true
+			}
+		}
+	}
+	for literal := range stubbed {
+		report.StubbedModules = append(report.StubbedModules, literal)
+	}
+	sort.Strings(report.StubbedModules)
+
+	outputDir := options.OutputDir
+	if outputDir == "" {
+		outputDir = filepath.Join(rootAbs, reportDirName, reproDirName)
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, err
+	}
+	stem := reproFileStem(endpoint)
+	report.ScriptPath = filepath.Join(outputDir, stem+".js")
+	report.HarnessPath = filepath.Join(outputDir, stem+"_harness.go")
+	if err := os.WriteFile(report.ScriptPath, []byte(buildReproScript(report, modules, bundled)), 0644); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(report.HarnessPath, []byte(buildReproHarness(report, stem)), 0644); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// RunSigningRepro 在 goja 中执行复现脚本，返回入口函数触发的全部 wx.request 参数。
+// params 为接口入参 JSON，storage 为 wx.getStorageSync 读取到的本地缓存 JSON（token 等）。
+func RunSigningRepro(scriptPath, params, storage string) ([]wxenv.Request, error) {
+	source, err := os.ReadFile(scriptPath)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(params) == "" {
+		params = "{}"
+	}
+	if strings.TrimSpace(storage) == "" {
+		storage = "{}"
+	}
+
+	vm := goja.New()
+	timer := time.AfterFunc(reproRunTimeout, func() { vm.Interrupt("执行超时") })
+	defer timer.Stop()
+
+	if _, err := vm.RunScript(filepath.Base(scriptPath), string(source)); err != nil {
+		return nil, fmt.Errorf("加载复现脚本失败: %w", err)
+	}
+	run, ok := goja.AssertFunction(vm.Get("__gwxRun"))
+	if !ok {
+		return nil, fmt.Errorf("复现脚本缺少 __gwxRun")
+	}
+	if _, err := run(goja.Undefined(), vm.ToValue(params), vm.ToValue(storage)); err != nil {
+		return nil, fmt.Errorf("执行入口函数失败: %w", err)
+	}
+	result, ok := goja.AssertFunction(vm.Get("__gwxResult"))
+	if !ok {
+		return nil, fmt.Errorf("复现脚本缺少 __gwxResult")
+	}
+	value, err := result(goja.Undefined())
+	if err != nil {
+		return nil, err
+	}
+	var requests []wxenv.Request
+	if err := json.Unmarshal([]byte(value.String()), &requests); err != nil {
+		return nil, fmt.Errorf("解析捕获的请求失败: %w", err)
+	}
+	return requests, nil
+}
+
+// selectReproEndpoint 按 file.js:function、Controller.Method、函数名的顺序匹配接口；
+// file 可以写语义重命名前的原始路径。
+func selectReproEndpoint(apiMap *APIMapReport, spec string, pathMap map[string]string) (*APIEndpointEntry, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, fmt.Errorf("请指定接口函数")
+	}
+	matches := make([]*APIEndpointEntry, 0)
+	for i := range apiMap.Endpoints {
+		endpoint := &apiMap.Endpoints[i]
+		if file, name, ok := strings.Cut(spec, ":"); ok {
+			if name == endpoint.FunctionName && (file == endpoint.FilePath || pathMap[file] == endpoint.FilePath || file == endpoint.OriginalFilePath) {
+				matches = append(matches, endpoint)
+			}
+			continue
+		}
+		if spec == endpoint.ControllerName+"."+endpoint.MethodsName || spec == endpoint.FunctionName {
+			matches = append(matches, endpoint)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("api_map.json 中没有接口 %s", spec)
+	case 1:
+		return matches[0], nil
+	}
+	candidates := make([]string, 0, len(matches))
+	for _, endpoint := range matches {
+		candidates = append(candidates, endpoint.FilePath+":"+endpoint.FunctionName)
+	}
+	return nil, fmt.Errorf("接口 %s 有多个候选，请用 file.js:function 指定: %s", spec, strings.Join(candidates, ", "))
+}
+
+func parseReproModule(relPath, text string, known map[string]string) *reproModule {
+	module := &reproModule{
+		text:      text,
+		functions: make(map[string]jsFunctionRange),
+		exports:   make(map[string]string),
+		aliases:   make(map[string]string),
+		requires:  make(map[string]string),
+	}
+	ranges := collectJSFunctionRanges(text)
+	for _, match := range reproExportFunctionPattern.FindAllStringSubmatchIndex(text, -1) {
+		open := match[1] - 1
+		end := findMatchingBrace(text, open)
+		if end <= open {
+			continue
+		}
+		ranges = append(ranges, jsFunctionRange{
+			Name:  text[match[2]:match[3]],
+			Kind:  "export",
+			Start: match[0],
+			End:   end + 1,
+			Line:  lineNumberAtOffset(text, match[0]),
+		})
+	}
+	for _, fn := range ranges {
+		if existing, ok := module.functions[fn.Name]; !ok || fn.Start < existing.Start {
+			module.functions[fn.Name] = fn
+		}
+	}
+
+	for _, match := range reproExportAliasPattern.FindAllStringSubmatch(text, -1) {
+		module.exports[match[1]] = match[2]
+	}
+	for _, match := range reproModuleExportsPattern.FindAllStringSubmatch(text, -1) {
+		for _, pair := range strings.Split(match[1], ",") {
+			key, value, ok := strings.Cut(pair, ":")
+			key = strings.Trim(strings.TrimSpace(key), `"'`)
+			if !ok {
+				value = key
+			}
+			if key != "" {
+				module.exports[key] = strings.TrimSpace(value)
+			}
+		}
+	}
+
+	for _, literal := range uniqueRequires(text) {
+		module.requires[literal] = resolveRequirePath(relPath, literal, known)
+	}
+	for _, match := range requireAliasPattern.FindAllStringSubmatch(text, -1) {
+		module.aliases[match[1]] = resolveRequirePath(relPath, match[2], known)
+	}
+	return module
+}
+
+func (m *reproModule) lookup(name string) (jsFunctionRange, bool) {
+	if fn, ok := m.functions[name]; ok {
+		return fn, true
+	}
+	if local, ok := m.exports[name]; ok {
+		fn, ok := m.functions[local]
+		return fn, ok
+	}
+	return jsFunctionRange{}, false
+}
+
+func reproFileStem(endpoint *APIEndpointEntry) string {
+	stem := endpoint.FunctionName
+	if endpoint.ControllerName != "" && endpoint.MethodsName != "" {
+		stem = endpoint.ControllerName + "_" + endpoint.MethodsName
+	}
+	return strings.Trim(reproFileStemPattern.ReplaceAllString(stem, "_"), "_")
+}
+
+func buildReproScript(report *ReproReport, modules map[string]*reproModule, bundled map[string]bool) string {
+	endpoint := report.Endpoint
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("// Gwxapkg 签名复现脚本: %s.%s (%s)\n", endpoint.ControllerName, endpoint.MethodsName, endpoint.FunctionName))
+	builder.WriteString(fmt.Sprintf("// 生成时间: %s\n", report.GeneratedAt))
+	builder.WriteString(fmt.Sprintf("// 入口: %s %s\n", endpoint.FilePath, endpoint.FunctionName))
+	if len(endpoint.ParamFields) > 0 {
+		builder.WriteString(fmt.Sprintf("// 参数字段: %s\n", strings.Join(endpoint.ParamFields, ", ")))
+	}
+	builder.WriteString("// 调用闭包:\n")
+	for _, fn := range report.Functions {
+		if fn.Located {
+			builder.WriteString(fmt.Sprintf("//   - %s:%d %s\n", fn.FilePath, fn.LineNumber, fn.Name))
+		} else {
+			builder.WriteString(fmt.Sprintf("//   - %s %s（未定位函数体，整模块打包）\n", fn.FilePath, fn.Name))
+		}
+	}
+	if len(report.StubbedModules) > 0 {
+		builder.WriteString(fmt.Sprintf("// 未进入闭包、以空桩替代的 require: %s\n", strings.Join(report.StubbedModules, ", ")))
+	}
+	builder.WriteString("//\n")
+	builder.WriteString("// Node: node " + filepath.Base(report.ScriptPath) + ` '{"参数":"值"}' '{"缓存键":"值"}'` + "\n")
+	builder.WriteString("// wx.request 只记录参数不发请求，输出即为最终的 url / header / data（含签名）。\n\n")
+	builder.WriteString(wxenv.Shim)
+
+	builder.WriteString("var __gwxModules = {\n")
+	for _, relPath := range report.Modules {
+		module := modules[relPath]
+		deps := make(map[string]string)
+		for literal, target := range module.requires {
+			if bundled[target] {
+				deps[literal] = target
+			}
+		}
+		depsJSON, _ := json.Marshal(deps)
+		builder.WriteString(fmt.Sprintf("  %q: {\n    deps: %s,\n    factory: function (module, exports, require) {\n", relPath, depsJSON))
+		builder.WriteString(module.text)
+		builder.WriteString("\n    }\n  },\n")
+	}
+	builder.WriteString("};\n\n")
+
+	builder.WriteString(fmt.Sprintf("var __gwxEntry = %q;\nvar __gwxEntryFunction = %q;\n\n", endpoint.FilePath, endpoint.FunctionName))
+	builder.WriteString(reproRunner)
+	return builder.String()
+}
+
+// buildReproHarness 生成独立的 goja 调用模板，逻辑与 RunSigningRepro 一致
+func buildReproHarness(report *ReproReport, stem string) string {
+	return fmt.Sprintf(reproHarnessTemplate,
+		report.Endpoint.ControllerName+"."+report.Endpoint.MethodsName,
+		stem,
+		filepath.Base(report.ScriptPath),
+	)
+}
+
+// reproRunner 以打包的模块表实现 require，入口函数在 wxenv.Shim 提供的桩环境中执行
+const reproRunner = `var __gwxCache = {};
+
+function __gwxLoad(path) {
+  if (__gwxCache[path]) {
+    return __gwxCache[path].exports;
+  }
+  var definition = __gwxModules[path];
+  var module = { exports: {} };
+  __gwxCache[path] = module;
+  definition.factory.call(module.exports, module, module.exports, function (literal) {
+    var target = definition.deps[literal];
+    return target ? __gwxLoad(target) : __gwxStub;
+  });
+  return module.exports;
+}
+
+function __gwxRun(paramsJSON, storageJSON) {
+  __gwxRequests = [];
+  __gwxStorage = JSON.parse(storageJSON || "{}");
+  __gwxCache = {};
+  var api = __gwxLoad(__gwxEntry);
+  var fn = api[__gwxEntryFunction];
+  if (typeof fn !== "function") {
+    throw new Error("入口模块未导出 " + __gwxEntryFunction);
+  }
+  var result = fn.call(api, JSON.parse(paramsJSON || "{}"));
+  if (result && typeof result.then === "function") {
+    result.then(null, function () {});
+  }
+}
+
+function __gwxResult() {
+  return JSON.stringify(__gwxRequests, null, 2);
+}
+
+if (typeof process !== "undefined" && typeof require === "function" && typeof module !== "undefined" && require.main === module) {
+  __gwxRun(process.argv[2] || "{}", process.argv[3] || "{}");
+  setTimeout(function () { console.log(__gwxResult()); }, 0);
+}
+`
+
+const reproHarnessTemplate = `//go:build ignore
+
+// Gwxapkg 签名复现调用模板: %[1]s
+//
+// 用法（需在依赖 github.com/dop251/goja 的 Go 模块中运行，例如 go mod init repro && go get github.com/dop251/goja）:
+//
+//	go run %[2]s_harness.go -params '{"userId":"1"}' -storage '{"token":"..."}'
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dop251/goja"
+)
+
+func main() {
+	script := flag.String("script", %[3]q, "复现脚本路径")
+	params := flag.String("params", "{}", "接口入参 JSON")
+	storage := flag.String("storage", "{}", "wx.getStorageSync 读取的本地缓存 JSON")
+	flag.Parse()
+
+	source, err := os.ReadFile(*script)
+	if err != nil {
+		fail(err)
+	}
+	vm := goja.New()
+	timer := time.AfterFunc(5*time.Second, func() { vm.Interrupt("执行超时") })
+	defer timer.Stop()
+
+	if _, err := vm.RunScript(*script, string(source)); err != nil {
+		fail(err)
+	}
+	run, _ := goja.AssertFunction(vm.Get("__gwxRun"))
+	if _, err := run(goja.Undefined(), vm.ToValue(*params), vm.ToValue(*storage)); err != nil {
+		fail(err)
+	}
+	result, _ := goja.AssertFunction(vm.Get("__gwxResult"))
+	value, err := result(goja.Undefined())
+	if err != nil {
+		fail(err)
+	}
+	fmt.Println(value.String())
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
+`
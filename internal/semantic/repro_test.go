@@ -0,0 +1,55 @@
+package semantic
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildSigningReproReproducesSignature(t *testing.T) {
+	root := t.TempDir()
+	mustWrite(t, filepath.Join(root, "lib/md5.js"), `exports.hexMD5=function(s){return "md5("+s+")"};`)
+	mustWrite(t, filepath.Join(root, "utils/sign.js"), `var md5=require("../lib/md5.js");
+var SALT="k9Qm2Lx7";
+function makeSign(data){var keys=Object.keys(data).sort();var parts=[];for(var i=0;i<keys.length;i++){parts.push(keys[i]+"="+data[keys[i]])}return md5.hexMD5(parts.join("&")+"&key="+SALT)}
+module.exports={makeSign:makeSign};`)
+	mustWrite(t, filepath.Join(root, "config/env.js"), `throw new Error("env 不应被加载");`)
+	mustWrite(t, filepath.Join(root, "request.js"), `var sign=require("utils/sign.js");var env=require("config/env.js");
+exports.request=function(options){var data=options.data||{};data.timestamp=1700000000;data.sign=sign.makeSign(data);return new Promise(function(resolve,reject){wx.request({url:"https://api.shop-wx.com.cn/gateway"+options.url,method:options.method,data:data,header:{token:wx.getStorageSync("token")},success:resolve,fail:reject})})};
+exports.host=function(){return env.host};`)
+	mustWrite(t, filepath.Join(root, "api_mixed.js"), `var request=require("request.js");
+exports.getECert=function(params){var requestData={userId:params.userId,controllerName:"CerInfo",methodsName:"GetECert"};return request.request({url:"/cert",method:"GET",data:requestData})};`)
+
+	if _, err := BuildAPIMap(root, []string{"api_mixed.js", "request.js", "utils/sign.js", "lib/md5.js", "config/env.js"}); err != nil {
+		t.Fatalf("BuildAPIMap 返回错误: %v", err)
+	}
+	report, err := BuildSigningRepro(root, ReproOptions{API: "CerInfo.GetECert"})
+	if err != nil {
+		t.Fatalf("BuildSigningRepro 返回错误: %v", err)
+	}
+	if strings.Join(report.StubbedModules, ",") != "config/env.js" {
+		t.Fatalf("闭包外的模块应以空桩替代: %#v", report.StubbedModules)
+	}
+	names := make([]string, 0, len(report.Functions))
+	for _, fn := range report.Functions {
+		names = append(names, fn.Name)
+	}
+	if strings.Join(names, ",") != "getECert,request,makeSign,hexMD5" {
+		t.Fatalf("调用闭包不正确: %v", names)
+	}
+	assertExists(t, report.HarnessPath)
+
+	requests, err := RunSigningRepro(report.ScriptPath, `{"userId":"42"}`, `{"token":"t-1"}`)
+	if err != nil {
+		t.Fatalf("RunSigningRepro 返回错误: %v", err)
+	}
+	if len(requests) != 1 {
+		t.Fatalf("应捕获 1 个请求: %#v", requests)
+	}
+	request := requests[0]
+	data, _ := request.Data.(map[string]interface{})
+	want := "md5(controllerName=CerInfo&methodsName=GetECert&timestamp=1700000000&userId=42&key=k9Qm2Lx7)"
+	if request.URL != "https://api.shop-wx.com.cn/gateway/cert" || data["sign"] != want || request.Header["token"] != "t-1" {
+		t.Fatalf("复现的请求不正确: %#v", request)
+	}
+}
@@ -0,0 +1,112 @@
+package semantic
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/25smoking/Gwxapkg/internal/wxenv"
+)
+
+// RequestTracePath 返回输出目录下请求追踪文件的路径
+func RequestTracePath(rootDir string) string {
+	return filepath.Join(rootDir, reportDirName, wxenv.TraceFileName)
+}
+
+// ApplyRequestTrace 把运行时追踪到的请求并入 api_map：能对应到接口函数的记录在 Observed 中，
+// 其余放入 RuntimeRequests。api_map.json 不存在时返回错误。
+func ApplyRequestTrace(rootDir string, trace *wxenv.Trace) (*APIMapReport, error) {
+	report, err := LoadAPIMap(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	attachRequestTrace(report, trace)
+	if err := writeAPIMap(rootDir, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// attachRequestTrace 按 controllerName / methodsName 参数或 URL 路径把请求归属到接口函数
+func attachRequestTrace(report *APIMapReport, trace *wxenv.Trace) {
+	for i := range report.Endpoints {
+		report.Endpoints[i].Observed = nil
+	}
+	report.RuntimeRequests = nil
+	if trace == nil {
+		return
+	}
+	for _, request := range trace.Requests {
+		if index := matchTracedRequest(report, request); index >= 0 {
+			report.Endpoints[index].Observed = append(report.Endpoints[index].Observed, request)
+			continue
+		}
+		report.RuntimeRequests = append(report.RuntimeRequests, request)
+	}
+}
+
+func matchTracedRequest(report *APIMapReport, request wxenv.Request) int {
+	data, _ := request.Data.(map[string]interface{})
+	controller, _ := data["controllerName"].(string)
+	method, _ := data["methodsName"].(string)
+	if controller != "" && method != "" {
+		for i, endpoint := range report.Endpoints {
+			if endpoint.ControllerName == controller && endpoint.MethodsName == method {
+				return i
+			}
+		}
+	}
+
+	requestPath := urlPath(request.URL)
+	if requestPath == "" {
+		return -1
+	}
+	for i, endpoint := range report.Endpoints {
+		endpointPath := urlPath(endpoint.URL)
+		if endpointPath == "" || endpointPath == "/" || !strings.HasSuffix(requestPath, endpointPath) {
+			continue
+		}
+		if endpoint.HTTPMethod != "" && !strings.EqualFold(endpoint.HTTPMethod, request.Method) {
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+func urlPath(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	if parsed, err := url.Parse(rawURL); err == nil {
+		return strings.TrimSuffix(parsed.Path, "/")
+	}
+	return rawURL
+}
+
+func buildRuntimeRequestMarkdown(report *APIMapReport) string {
+	rows := make([]string, 0)
+	for _, endpoint := range report.Endpoints {
+		for _, request := range endpoint.Observed {
+			rows = append(rows, runtimeRequestRow(request, "`"+endpoint.FunctionName+"`"))
+		}
+	}
+	for _, request := range report.RuntimeRequests {
+		rows = append(rows, runtimeRequestRow(request, "-"))
+	}
+	if len(rows) == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+	builder.WriteString("\n## 运行时请求\n\n")
+	builder.WriteString("| 页面 | HTTP | URL | 接口函数 |\n")
+	builder.WriteString("|------|------|-----|----------|\n")
+	for _, row := range rows {
+		builder.WriteString(row)
+	}
+	return builder.String()
+}
+
+func runtimeRequestRow(request wxenv.Request, function string) string {
+	return fmt.Sprintf("| `%s` | `%s` | `%s` | %s |\n", emptyAsDash(request.Page), emptyAsDash(request.Method), emptyAsDash(request.URL), function)
+}
@@ -0,0 +1,45 @@
+package semantic
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/25smoking/Gwxapkg/internal/wxenv"
+)
+
+func TestApplyRequestTraceAttachesObservedRequests(t *testing.T) {
+	root := t.TempDir()
+	mustWrite(t, filepath.Join(root, "api_mixed.js"), `var request=require("request.js");
+exports.getECert=function(params){var requestData={userId:params.userId,controllerName:"CerInfo",methodsName:"GetECert"};return request.request({url:"",method:"GET",data:requestData})};`)
+	mustWrite(t, filepath.Join(root, "request.js"), `exports.request=function(options){return wx.request(options)};`)
+	if _, err := BuildAPIMap(root, []string{"api_mixed.js", "request.js"}); err != nil {
+		t.Fatalf("BuildAPIMap 返回错误: %v", err)
+	}
+
+	trace := &wxenv.Trace{Requests: []wxenv.Request{
+		{URL: "https://api.shop-wx.com.cn/gateway", Method: "GET", Data: map[string]interface{}{"controllerName": "CerInfo", "methodsName": "GetECert", "userId": "1"}, Page: "pages/index/index"},
+		{URL: "https://api.shop-wx.com.cn/banner", Method: "GET", Page: "pages/index/index"},
+	}}
+	if err := wxenv.WriteTrace(RequestTracePath(root), trace); err != nil {
+		t.Fatal(err)
+	}
+	report, err := ApplyRequestTrace(root, trace)
+	if err != nil {
+		t.Fatalf("ApplyRequestTrace 返回错误: %v", err)
+	}
+	if len(report.Endpoints) != 1 || len(report.Endpoints[0].Observed) != 1 || len(report.RuntimeRequests) != 1 {
+		t.Fatalf("请求应按 controllerName / methodsName 归属到接口: %#v", report)
+	}
+
+	rebuilt, err := BuildAPIMap(root, []string{"api_mixed.js", "request.js"})
+	if err != nil {
+		t.Fatalf("BuildAPIMap 返回错误: %v", err)
+	}
+	if len(rebuilt.Endpoints[0].Observed) != 1 {
+		t.Fatalf("重新生成 api_map 时应自动并入已有追踪")
+	}
+	if markdown := buildAPIMapMarkdown(rebuilt); !strings.Contains(markdown, "## 运行时请求") || !strings.Contains(markdown, "`getECert`") {
+		t.Fatalf("api_map.md 缺少运行时请求:\n%s", markdown)
+	}
+}
@@ -0,0 +1,348 @@
+package semantic
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// 代理历史的导入格式
+const (
+	TrafficFormatAuto      = "auto"
+	TrafficFormatHAR       = "har"
+	TrafficFormatBurpXML   = "burp-xml"
+	TrafficFormatMitmproxy = "mitmproxy"
+	TrafficFormatRaw       = "raw"
+)
+
+// ParseTraffic 解析 HAR、Burp「Save items」XML、mitmproxy flow 文件或单个原始请求，
+// 返回与 ParseBurpRequest 相同结构的请求列表。format 为空或 auto 时按内容识别格式。
+func ParseTraffic(data []byte, format string) ([]ParsedBurpRequest, string, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" || format == TrafficFormatAuto {
+		format = detectTrafficFormat(data)
+	}
+
+	var (
+		raws []string
+		err  error
+	)
+	switch format {
+	case TrafficFormatHAR:
+		raws, err = parseHARRequests(data)
+	case TrafficFormatBurpXML:
+		raws, err = parseBurpXMLRequests(data)
+	case TrafficFormatMitmproxy:
+		raws, err = parseMitmproxyRequests(data)
+	case TrafficFormatRaw:
+		raws = []string{string(data)}
+	default:
+		return nil, format, fmt.Errorf("不支持的流量格式: %s（可选 har / burp-xml / mitmproxy / raw）", format)
+	}
+	if err != nil {
+		return nil, format, err
+	}
+
+	requests := make([]ParsedBurpRequest, 0, len(raws))
+	for _, raw := range raws {
+		request := ParseBurpRequest(raw)
+		if request.Method == "" {
+			continue
+		}
+		requests = append(requests, request)
+	}
+	return requests, format, nil
+}
+
+func detectTrafficFormat(data []byte) string {
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("{")) && bytes.Contains(trimmed[:min(len(trimmed), 512)], []byte(`"log"`)):
+		return TrafficFormatHAR
+	case bytes.HasPrefix(trimmed, []byte("<?xml")) || bytes.HasPrefix(trimmed, []byte("<items")):
+		return TrafficFormatBurpXML
+	case hasTNetStringHeader(trimmed):
+		return TrafficFormatMitmproxy
+	}
+	return TrafficFormatRaw
+}
+
+// hasTNetStringHeader 判断数据是否以完整的 tnetstring 开头：十进制长度、冒号、不超出数据的负载与合法的类型标记。
+// 只以数字开头的原始报文或文本导出不会被误判为 mitmproxy flow。
+func hasTNetStringHeader(data []byte) bool {
+	colon := bytes.IndexByte(data, ':')
+	if colon <= 0 || colon > 12 {
+		return false
+	}
+	for _, c := range data[:colon] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	length, err := strconv.Atoi(string(data[:colon]))
+	if err != nil {
+		return false
+	}
+	end := colon + 1 + length
+	return end < len(data) && bytes.IndexByte([]byte(",;#^!~]}"), data[end]) >= 0
+}
+
+// buildRawRequest 把结构化请求还原为 HTTP 原始报文，复用 ParseBurpRequest 的解析逻辑
+func buildRawRequest(method, rawURL string, headers [][2]string, body string) string {
+	target := rawURL
+	host := ""
+	if parsed, err := url.Parse(rawURL); err == nil && parsed.Host != "" {
+		host = parsed.Host
+		target = parsed.RequestURI()
+	}
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("%s %s HTTP/1.1\n", strings.ToUpper(method), target))
+	hasHost := false
+	for _, header := range headers {
+		if strings.EqualFold(header[0], "host") {
+			hasHost = true
+		}
+		builder.WriteString(header[0] + ": " + header[1] + "\n")
+	}
+	if !hasHost && host != "" {
+		builder.WriteString("Host: " + host + "\n")
+	}
+	builder.WriteString("\n")
+	builder.WriteString(body)
+	return builder.String()
+}
+
+type harFile struct {
+	Log struct {
+		Entries []struct {
+			Request struct {
+				Method  string `json:"method"`
+				URL     string `json:"url"`
+				Headers []struct {
+					Name  string `json:"name"`
+					Value string `json:"value"`
+				} `json:"headers"`
+				PostData *struct {
+					MimeType string `json:"mimeType"`
+					Text     string `json:"text"`
+					Params   []struct {
+						Name  string `json:"name"`
+						Value string `json:"value"`
+					} `json:"params"`
+				} `json:"postData"`
+			} `json:"request"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+func parseHARRequests(data []byte) ([]string, error) {
+	var har harFile
+	if err := json.Unmarshal(data, &har); err != nil {
+		return nil, fmt.Errorf("解析 HAR 失败: %w", err)
+	}
+	raws := make([]string, 0, len(har.Log.Entries))
+	for _, entry := range har.Log.Entries {
+		request := entry.Request
+		headers := make([][2]string, 0, len(request.Headers))
+		for _, header := range request.Headers {
+			// HTTP/2 伪首部不属于原始报文
+			if strings.HasPrefix(header.Name, ":") {
+				continue
+			}
+			headers = append(headers, [2]string{header.Name, header.Value})
+		}
+		body := ""
+		if post := request.PostData; post != nil {
+			body = post.Text
+			if body == "" && len(post.Params) > 0 {
+				values := url.Values{}
+				for _, param := range post.Params {
+					values.Add(param.Name, param.Value)
+				}
+				body = values.Encode()
+			}
+			if post.MimeType != "" && !hasHeader(headers, "content-type") {
+				headers = append(headers, [2]string{"Content-Type", post.MimeType})
+			}
+		}
+		raws = append(raws, buildRawRequest(request.Method, request.URL, headers, body))
+	}
+	return raws, nil
+}
+
+type burpItems struct {
+	Items []struct {
+		URL     string `xml:"url"`
+		Host    string `xml:"host"`
+		Method  string `xml:"method"`
+		Path    string `xml:"path"`
+		Request struct {
+			Base64 string `xml:"base64,attr"`
+			Data   string `xml:",chardata"`
+		} `xml:"request"`
+	} `xml:"item"`
+}
+
+func parseBurpXMLRequests(data []byte) ([]string, error) {
+	var items burpItems
+	if err := xml.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("解析 Burp XML 失败: %w", err)
+	}
+	raws := make([]string, 0, len(items.Items))
+	for _, item := range items.Items {
+		raw := item.Request.Data
+		if item.Request.Base64 == "true" {
+			decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(raw))
+			if err != nil {
+				return nil, fmt.Errorf("解码 Burp 请求失败 (%s): %w", item.URL, err)
+			}
+			raw = string(decoded)
+		}
+		raw = strings.TrimLeft(raw, "\r\n\t ")
+		if raw == "" {
+			raw = buildRawRequest(item.Method, item.URL, nil, "")
+		} else if item.Host != "" && !strings.Contains(strings.ToLower(raw), "\nhost:") {
+			head, body, _ := strings.Cut(strings.ReplaceAll(raw, "\r\n", "\n"), "\n\n")
+			raw = head + "\nHost: " + item.Host + "\n\n" + body
+		}
+		raws = append(raws, raw)
+	}
+	return raws, nil
+}
+
+func hasHeader(headers [][2]string, name string) bool {
+	for _, header := range headers {
+		if strings.EqualFold(header[0], name) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseMitmproxyRequests 解析 mitmproxy 的 flow 文件：连续的 tnetstring 字典，每个 HTTP flow 含 request 字段
+func parseMitmproxyRequests(data []byte) ([]string, error) {
+	raws := make([]string, 0)
+	for rest := data; len(bytes.TrimSpace(rest)) > 0; {
+		value, next, err := parseTNetString(bytes.TrimLeft(rest, " \r\n\t"))
+		if err != nil {
+			return nil, fmt.Errorf("解析 mitmproxy flow 失败: %w", err)
+		}
+		rest = next
+		flow, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		request, ok := flow["request"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		raws = append(raws, mitmproxyRawRequest(request))
+	}
+	return raws, nil
+}
+
+func mitmproxyRawRequest(request map[string]interface{}) string {
+	field := func(name string) string {
+		return tnetText(request[name])
+	}
+	scheme := field("scheme")
+	if scheme == "" {
+		scheme = "https"
+	}
+	host := field("host")
+	if port, ok := request["port"].(int64); ok && port != 0 && !(scheme == "https" && port == 443) && !(scheme == "http" && port == 80) {
+		host += ":" + strconv.FormatInt(port, 10)
+	}
+
+	headers := make([][2]string, 0)
+	if list, ok := request["headers"].([]interface{}); ok {
+		for _, item := range list {
+			pair, ok := item.([]interface{})
+			if !ok || len(pair) != 2 {
+				continue
+			}
+			headers = append(headers, [2]string{tnetText(pair[0]), tnetText(pair[1])})
+		}
+	}
+	body := field("content")
+	return buildRawRequest(field("method"), scheme+"://"+host+field("path"), headers, body)
+}
+
+func tnetText(value interface{}) string {
+	switch typed := value.(type) {
+	case string:
+		return typed
+	case []byte:
+		return string(typed)
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+// parseTNetString 解析一个 tnetstring 值（mitmproxy 扩展了 ; 表示 unicode 字符串），返回剩余数据
+func parseTNetString(data []byte) (interface{}, []byte, error) {
+	colon := bytes.IndexByte(data, ':')
+	if colon <= 0 || colon > 12 {
+		return nil, nil, errors.New("缺少长度前缀")
+	}
+	length, err := strconv.Atoi(string(data[:colon]))
+	if err != nil || length < 0 {
+		return nil, nil, fmt.Errorf("非法长度前缀 %q", data[:colon])
+	}
+	end := colon + 1 + length
+	if end >= len(data) {
+		return nil, nil, errors.New("数据被截断")
+	}
+	payload, tag, rest := data[colon+1:end], data[end], data[end+1:]
+
+	switch tag {
+	case ',':
+		return append([]byte(nil), payload...), rest, nil
+	case ';':
+		return string(payload), rest, nil
+	case '#':
+		value, err := strconv.ParseInt(string(payload), 10, 64)
+		return value, rest, err
+	case '^':
+		value, err := strconv.ParseFloat(string(payload), 64)
+		return value, rest, err
+	case '!':
+		return string(payload) == "true", rest, nil
+	case '~':
+		return nil, rest, nil
+	case ']':
+		list := make([]interface{}, 0)
+		for len(payload) > 0 {
+			item, next, err := parseTNetString(payload)
+			if err != nil {
+				return nil, nil, err
+			}
+			list = append(list, item)
+			payload = next
+		}
+		return list, rest, nil
+	case '}':
+		dict := make(map[string]interface{})
+		for len(payload) > 0 {
+			key, next, err := parseTNetString(payload)
+			if err != nil {
+				return nil, nil, err
+			}
+			value, after, err := parseTNetString(next)
+			if err != nil {
+				return nil, nil, err
+			}
+			dict[tnetText(key)] = value
+			payload = after
+		}
+		return dict, rest, nil
+	}
+	return nil, nil, fmt.Errorf("未知类型标记 %q", tag)
+}
@@ -0,0 +1,48 @@
+package session
+
+import (
+	"github.com/25smoking/Gwxapkg/internal/config"
+	"github.com/25smoking/Gwxapkg/internal/scanner"
+)
+
+// Session 单次处理流程的运行上下文。
+// 包管理器、敏感数据收集器、扫描规则与待清理文件都挂在 Session 上，而不是进程级单例，
+// 因此同一进程内的多次运行（例如 batch 并发处理多个 AppID）互不干扰。
+type Session struct {
+	AppID     string
+	OutputDir string
+	Pretty    bool
+	NoClean   bool
+
+	Manager   *config.WxapkgManager
+	Deletions *config.FileDeletionManager
+	Collector *scanner.DataCollector
+	Rules     []*scanner.CompiledRule
+	// Verifiers 本次运行额外的凭据校验器，在内置离线校验器之后执行
+	Verifiers []scanner.Verifier
+	// Warn 接收不中断处理的告警，为空时丢弃；解包阶段会被多个 goroutine 并发调用
+	Warn func(format string, args ...interface{})
+}
+
+// New 创建新的运行上下文；收集器默认为空，需要扫描时调用 EnableCollector。
+func New(appID, outputDir string) *Session {
+	return &Session{
+		AppID:     appID,
+		OutputDir: outputDir,
+		Pretty:    true,
+		Manager:   config.NewWxapkgManager(),
+		Deletions: config.NewFileDeletionManager(),
+	}
+}
+
+// EnableCollector 使用 rules 为本次运行创建敏感数据收集器
+func (s *Session) EnableCollector(rules []*scanner.CompiledRule) *scanner.DataCollector {
+	s.Rules = rules
+	if s.Collector == nil {
+		s.Collector = scanner.NewCollector(s.AppID)
+		if len(s.Verifiers) > 0 {
+			s.Collector.SetVerifiers(append(scanner.DefaultVerifiers(), s.Verifiers...))
+		}
+	}
+	return s.Collector
+}
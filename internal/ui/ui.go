@@ -3,6 +3,7 @@ package ui
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
@@ -23,6 +24,16 @@ var (
 	dim     = color.New(color.FgHiBlack)
 )
 
+// SetOutput 设置提示信息的输出位置。-json 等机器可读模式改为 stderr，保证 stdout 只有结果
+func SetOutput(w io.Writer) {
+	color.Output = w
+}
+
+// Output 返回提示信息当前的输出位置
+func Output() io.Writer {
+	return color.Output
+}
+
 // Banner 打印程序横幅
 func Banner() {
 	cyan.Println(`
@@ -148,15 +159,31 @@ func PrintUsage() {
 	white.Println("  scan                          扫描本地小程序（交互式选择解包）")
 	white.Println("  scan -watch                   交互选择后只监听缺失分包下载，不执行解包")
 	white.Println("  scan --verbose                扫描并输出候选路径诊断")
+	white.Println("  scan -root=<镜像目录>          扫描挂载的 Linux / Android / iOS 设备镜像")
+	white.Println("  scan -json [-name/-since/-id]  以 JSON 输出扫描结果（含包类型与加密状态）")
+	white.Println("  scan -select=all|<编号>|<AppID> 非交互解包，可与筛选参数组合")
 	white.Println("  all -id=<AppID>               自动查找并处理指定小程序")
 	white.Println("  all -id=<AppID> -watch        只监听指定小程序缺失分包下载，不执行解包")
 	white.Println("  all -id=wx1,wx2,wx3           批量处理（逗号分隔）")
 	white.Println("  all -id-file=ids.txt          批量处理（文件，每行一个 AppID）")
 	white.Println("  all --all                     处理所有已缓存的小程序")
 	white.Println("  all --all --verbose           扫描全部缓存并输出候选路径诊断")
+	white.Println("  batch -id=wx1,wx2 -concurrency=4  并发处理多个小程序，输出互相隔离")
+	white.Println("  daemon [-settle=10s] [-out=<目录>]  常驻监听缓存目录，新包下载稳定后自动解包并合并")
+	white.Println("  inspect -in=<文件> -id=<AppID>  查看包索引、类型与 wcc 版本，不解包")
+	white.Println("  diff -old=<目录> -new=<目录>    对比两个版本的页面、接口与敏感信息变化")
+	white.Println("  history -id=<AppID>           列出已归档的历史版本，-diff=latest~1,latest 对比")
 	white.Println("  scan-only -dir=<目录>          对已解包目录独立扫描并生成报告")
+	white.Println("  baseline -report=<报告>        由 sensitive_report.json 生成基线文件")
+	white.Println("  rules test [-dir=<目录>]       校验规则包与内嵌样例")
+	white.Println("  names [-import=<CSV>]         列出或导入本地小程序名称库，-export=<CSV> 导出")
 	white.Println("  semantic -dir=<目录>           对已解包目录做源码语义反混淆")
 	white.Println("  api-link -dir=<目录>            将 Burp 原始请求关联到源码 API")
+	white.Println("  api-link -dir=<目录> -traffic=<文件>  批量关联 HAR / Burp XML / mitmproxy 历史并生成覆盖报告")
+	white.Println("  repro -dir=<目录> -api=<接口>   生成接口请求签名的离线复现脚本（Node / goja）")
+	white.Println("  eval -dir=<目录> -module=<模块> -func=<函数>  在 goja 沙箱中调用已还原模块的导出函数")
+	white.Println("  trace -dir=<目录> [-pages=<路由>]  在 wx 桩环境中运行页面 onLoad，记录发出的请求")
+	white.Println("  proxy -dir=<目录> [-listen=<地址>]  中间人代理，在响应头与实时日志中标注请求对应的源码函数与页面")
 	white.Println("  repack -in=<目录> -id=<AppID>  重新打包为客户端可用 wxapkg")
 	fmt.Println()
 	cyan.Println("直接使用:")
@@ -169,13 +196,31 @@ func PrintUsage() {
 	dim.Println("  -noClean     保留中间文件 (默认: false)")
 	dim.Println("  -save        保存解密文件 (默认: false)")
 	dim.Println("  -sensitive   获取敏感数据 (默认: true)")
+	dim.Println("  -sarif       额外导出 SARIF 2.1.0 扫描报告 (默认: false)")
+	dim.Println("  -openapi     导出 OpenAPI 3 文档 openapi.yaml，可与 -postman 分开使用 (默认: false)")
+	dim.Println("  -baseline    基线文件，其中的发现标记为已知，报告默认只展示新发现")
+	dim.Println("  -suppress    忽略规则文件 (YAML: rule_id/content_hash/path/expires/justification)")
+	dim.Println("  -show-all    报告中保留已知与已忽略的发现 (默认: false)")
 	dim.Println("  -workspace   保留可精确回包的隐藏工作区 (默认: false)")
+	dim.Println("  -full        忽略 manifest 中的包哈希，全部重新处理 (默认: 只处理新增或变化的分包)")
+	dim.Println("  -trace       运行页面生命周期，追踪请求并并入 api_map 与 Postman (默认: false)")
 	dim.Println("  -watch       只监听缺失分包下载，不执行解包")
 	dim.Println("  -ast-rename  AST 还原策略: off / report / safe / deep (默认: deep，激进写回)")
 	dim.Println("  -ast-diff    生成 AST 重命名 diff 报告 (默认: true)")
 	dim.Println("  -ast-patch   生成 AST 重命名 patch (默认: true)")
 	dim.Println("  repack -id   生成加密包，适用于回写微信客户端")
 	dim.Println("  repack -raw  生成未加密包，仅供测试")
-	dim.Println("  scan-only -format  报告格式: json / excel / html / both (默认: both)")
+	dim.Println("  batch -out   输出根目录，每个 AppID 写入 <out>/<AppID>")
+	dim.Println("  daemon -log  JSON Lines 事件日志；-initial 启动时先处理全部已缓存小程序；-concurrency 同时处理数")
+	dim.Println("  inspect -match    包内路径 glob，逗号分隔 (支持 **)")
+	dim.Println("  inspect -extract  只提取匹配条目到指定目录，-raw 不做格式化")
+	dim.Println("  diff -id/-out     输入为 wxapkg 时需要 -id；报告默认写入 <new>/.gwxapkg")
+	dim.Println("  diff -old/-new    也可以是版本选择器: latest、latest~N、缓存版本号或版本 ID 前缀")
+	dim.Println("  repro -params/-storage  试运行复现脚本时的接口入参与本地缓存 JSON")
+	dim.Println("  eval -args   调用参数 JSON 数组；-timeout 执行时限 (默认: 2s)；-json 输出请求与日志")
+	dim.Println("  trace -query/-storage  onLoad 参数 (id=1&type=2) 与本地缓存 JSON；-postman 导出追踪到的请求")
+	dim.Println("  proxy -ca-dir  根证书目录 (默认: ~/.gwxapkg/proxy)；-insecure 不校验上游证书；-log 写入 JSON Lines 日志")
+	dim.Println("  -archive     scan/all/daemon 归档缓存包到 .gwxapkg/versions (默认: false)")
+	dim.Println("  scan-only -format  报告格式: json / excel / html / sarif / both / all，可逗号组合 (默认: both)")
 	fmt.Println()
 }
@@ -0,0 +1,182 @@
+package unpack
+
+import (
+	"encoding/json"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/25smoking/Gwxapkg/internal/enum"
+	"github.com/25smoking/Gwxapkg/internal/util"
+)
+
+// wccVersionSources 按优先级尝试读取 __wcc_version__ 的包内文件
+var wccVersionSources = []string{enum.PageFrameHtml, enum.AppWxss, enum.Page_Frame, enum.PageFrame}
+
+// EntryInfo inspect 输出的单个索引项
+type EntryInfo struct {
+	Name       string `json:"name"`
+	Offset     uint32 `json:"offset"`
+	Size       uint32 `json:"size"`
+	OutputPath string `json:"output_path"`          // 解包时实际写出的相对路径，重名时会追加序号
+	Subpackage string `json:"subpackage,omitempty"` // 所属分包根目录，主包文件为空
+}
+
+// Inspection 不解包时可得到的包信息
+type Inspection struct {
+	SourcePath   string          `json:"source_path"`
+	Encrypted    bool            `json:"encrypted"`
+	Size         int64           `json:"size"`
+	Header       Header          `json:"header"`
+	Type         enum.WxapkgType `json:"type"`
+	Root         string          `json:"root"`
+	WccVersion   string          `json:"wcc_version,omitempty"`
+	Subpackages  []string        `json:"subpackages,omitempty"` // app-config.json 中声明的分包根目录
+	TotalEntries int             `json:"total_entries"`
+	Entries      []EntryInfo     `json:"entries"`
+}
+
+// Inspect 读取索引并规划输出路径，但不写出任何文件。
+// patterns 为空时列出全部条目，否则只保留匹配任一 glob 的条目；Encrypted 由调用方填写。
+func Inspect(reader *Reader, patterns []string) (*Inspection, error) {
+	plan, err := analyzePackage(reader, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	inspection := &Inspection{
+		SourcePath:   reader.SourcePath,
+		Size:         reader.Size(),
+		Header:       reader.Header,
+		Type:         util.GetWxapkgType(plan.FileNames),
+		TotalEntries: len(plan.Files),
+		Entries:      []EntryInfo{},
+	}
+	inspection.Root = packageRoot(inspection.Type, plan.FileNames)
+	inspection.WccVersion = readWccVersion(reader)
+	inspection.Subpackages = declaredSubpackages(reader)
+
+	matcher := util.NewGlobMatcher(patterns)
+	for _, file := range plan.Files {
+		if !matcher.Match(file.EntryName) {
+			continue
+		}
+		inspection.Entries = append(inspection.Entries, EntryInfo{
+			Name:       file.EntryName,
+			Offset:     file.Offset,
+			Size:       file.Size,
+			OutputPath: file.RelativePath,
+			Subpackage: subpackageOf(file.EntryName, inspection),
+		})
+	}
+
+	return inspection, nil
+}
+
+// ExtractEntries 只写出匹配 patterns 的条目，输出路径与完整解包时一致
+func ExtractEntries(reader *Reader, outputDir string, patterns []string, options Options) ([]string, error) {
+	plan, err := analyzePackage(reader, outputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	matcher := util.NewGlobMatcher(patterns)
+	selected := plan.Files[:0:0]
+	var names []string
+	for _, file := range plan.Files {
+		if matcher.Match(file.EntryName) {
+			selected = append(selected, file)
+			names = append(names, file.EntryName)
+		}
+	}
+	plan.Files = selected
+	plan.FileNames = names
+
+	if err := writePlannedFiles(plan, reader, options); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// packageRoot 主包根目录为 "/"，分包与插件取全部条目的公共目录
+func packageRoot(wxapkgType enum.WxapkgType, names []string) string {
+	switch wxapkgType {
+	case enum.APP_SUBPACKAGE_V1, enum.APP_SUBPACKAGE_V2, enum.GAME_SUBPACKAGE, enum.APP_PLUGIN_V1, enum.GAME_PLUGIN:
+	default:
+		return "/"
+	}
+
+	var common []string
+	for i, name := range names {
+		dir := strings.Split(strings.Trim(path.Dir("/"+strings.TrimLeft(name, "/")), "/"), "/")
+		if i == 0 {
+			common = dir
+			continue
+		}
+		n := 0
+		for n < len(common) && n < len(dir) && common[n] == dir[n] {
+			n++
+		}
+		common = common[:n]
+	}
+
+	root := strings.Join(common, "/")
+	if root == "" {
+		return "/"
+	}
+	return "/" + root + "/"
+}
+
+func readWccVersion(reader *Reader) string {
+	for _, source := range wccVersionSources {
+		for _, file := range reader.Files {
+			if path.Base(file.Name) != source {
+				continue
+			}
+			content, err := io.ReadAll(reader.section(file.Offset, file.Size))
+			if err != nil {
+				continue
+			}
+			if version := util.ParseWccVersion(content); version != "" {
+				return version
+			}
+		}
+	}
+	return ""
+}
+
+func declaredSubpackages(reader *Reader) []string {
+	entry, err := reader.Open(enum.App_Config)
+	if err != nil {
+		return nil
+	}
+	var config AppConfig
+	if err := json.NewDecoder(entry).Decode(&config); err != nil {
+		return nil
+	}
+
+	roots := make([]string, 0, len(config.SubPackages))
+	for _, subPackage := range config.SubPackages {
+		root := strings.Trim(subPackage.Root, "/")
+		if root != "" {
+			roots = append(roots, "/"+root+"/")
+		}
+	}
+	return roots
+}
+
+func subpackageOf(name string, inspection *Inspection) string {
+	entry := "/" + strings.TrimLeft(name, "/")
+	if inspection.Root != "/" {
+		if strings.HasPrefix(entry, inspection.Root) {
+			return inspection.Root
+		}
+		return ""
+	}
+	for _, root := range inspection.Subpackages {
+		if strings.HasPrefix(entry, root) {
+			return root
+		}
+	}
+	return ""
+}
@@ -0,0 +1,126 @@
+package unpack
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/25smoking/Gwxapkg/internal/enum"
+	"github.com/25smoking/Gwxapkg/internal/util"
+)
+
+func TestInspectReportsTypeRootAndSubpackages(t *testing.T) {
+	plain := buildTestWxapkg([]testEntry{
+		{Name: "/app-config.json", Content: []byte(`{"pages":["pages/index/index"],"subPackages":[{"root":"pkgA","pages":["list/list"]}]}`)},
+		{Name: "/common.app.js", Content: []byte("var a=1;")},
+		{Name: "/app-wxss.js", Content: []byte(`var __wcc_version__='v0.5vv_20211229_syb_scopedata';`)},
+		{Name: "/pages/index/index.js", Content: []byte("Page({})")},
+		{Name: "/pkgA/list/list.js", Content: []byte("Page({})")},
+	})
+	reader, err := NewReader(bytes.NewReader(plain), int64(len(plain)), "main.wxapkg")
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	inspection, err := Inspect(reader, []string{"**/*.js"})
+	if err != nil {
+		t.Fatalf("inspect 失败: %v", err)
+	}
+	if inspection.Type != enum.App_V3 || inspection.Root != "/" {
+		t.Fatalf("类型或根目录错误: %s %s", inspection.Type, inspection.Root)
+	}
+	if inspection.WccVersion != "v0.5vv_20211229_syb_scopedata" {
+		t.Fatalf("wcc 版本错误: %q", inspection.WccVersion)
+	}
+	if len(inspection.Subpackages) != 1 || inspection.Subpackages[0] != "/pkgA/" {
+		t.Fatalf("声明分包错误: %#v", inspection.Subpackages)
+	}
+	if inspection.TotalEntries != 5 || len(inspection.Entries) != 4 {
+		t.Fatalf("过滤结果错误: total=%d matched=%d", inspection.TotalEntries, len(inspection.Entries))
+	}
+	for _, entry := range inspection.Entries {
+		want := ""
+		if entry.Name == "/pkgA/list/list.js" {
+			want = "/pkgA/"
+		}
+		if entry.Subpackage != want {
+			t.Fatalf("%s 的分包归属错误: %q", entry.Name, entry.Subpackage)
+		}
+	}
+
+	sub := buildTestWxapkg([]testEntry{
+		{Name: "/pkgA/page-frame.js", Content: []byte("var x;")},
+		{Name: "/pkgA/list/list.js", Content: []byte("Page({})")},
+	})
+	reader, err = NewReader(bytes.NewReader(sub), int64(len(sub)), "sub.wxapkg")
+	if err != nil {
+		t.Fatalf("解析分包失败: %v", err)
+	}
+	inspection, err = Inspect(reader, nil)
+	if err != nil {
+		t.Fatalf("inspect 分包失败: %v", err)
+	}
+	if inspection.Type != enum.APP_SUBPACKAGE_V1 || inspection.Root != "/pkgA/" {
+		t.Fatalf("分包类型或根目录错误: %s %s", inspection.Type, inspection.Root)
+	}
+}
+
+func TestExtractEntriesWritesOnlySelectedFiles(t *testing.T) {
+	raw := []byte("Page({data:{}})")
+	plain := buildTestWxapkg([]testEntry{
+		{Name: "/pages/index/index.js", Content: raw},
+		{Name: "/pages/index/index.wxss", Content: []byte("page{}")},
+		{Name: "/app-config.json", Content: []byte(`{}`)},
+	})
+	reader, err := NewReader(bytes.NewReader(plain), int64(len(plain)), "main.wxapkg")
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	outputDir := t.TempDir()
+	options := DefaultOptions()
+	options.Raw = true
+	names, err := ExtractEntries(reader, outputDir, []string{"pages/**/*.js"}, options)
+	if err != nil {
+		t.Fatalf("提取失败: %v", err)
+	}
+	if len(names) != 1 || names[0] != "/pages/index/index.js" {
+		t.Fatalf("提取列表错误: %#v", names)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "pages", "index", "index.js"))
+	if err != nil {
+		t.Fatalf("应写出选中文件: %v", err)
+	}
+	if !bytes.Equal(content, raw) {
+		t.Fatalf("-raw 提取应保持原始字节: %q", content)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "app-config.json")); !os.IsNotExist(err) {
+		t.Fatalf("未匹配的条目不应写出")
+	}
+}
+
+func TestEntryMatcherGlobs(t *testing.T) {
+	cases := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*.json", "/pages/index/index.json", true},
+		{"pages/*.js", "/pages/index/index.js", false},
+		{"pages/**/*.js", "/pages/index/index.js", true},
+		{"/pages/**", "/pages/a/b/c.wxml", true},
+		{"**/index.?s", "/pages/index/index.js", true},
+		{"app-*.js", "/app-service.js", true},
+		{"页面/**/*.js", "/页面/首页/入口.js", true},
+		{"页面/?页/*.js", "/页面/首页/入口.js", true},
+		{"订单*.wxml", "/pages/订单详情.wxml", true},
+		{"页面/**/*.js", "/pages/首页/入口.js", false},
+	}
+	for _, tc := range cases {
+		if got := util.NewGlobMatcher([]string{tc.pattern}).Match(tc.name); got != tc.want {
+			t.Fatalf("%s 匹配 %s: got %v want %v", tc.pattern, tc.name, got, tc.want)
+		}
+	}
+}
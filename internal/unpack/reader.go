@@ -0,0 +1,208 @@
+package unpack
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Header wxapkg 文件头字段
+type Header struct {
+	Info1           uint32 `json:"info1"`
+	IndexInfoLength uint32 `json:"index_info_length"`
+	BodyInfoLength  uint32 `json:"body_info_length"`
+	FileCount       uint32 `json:"file_count"`
+}
+
+// Reader 基于 io.ReaderAt 的 wxapkg 读取器。
+// 构造时只解析头部与索引，文件内容通过 Open 按需读取，内存占用与包体大小无关。
+type Reader struct {
+	Header     Header
+	Files      []WxapkgFile
+	SourcePath string
+
+	src   io.ReaderAt
+	size  int64
+	index map[string]int
+}
+
+// positionReader 记录已读取的字节数，用于校验索引段边界
+type positionReader struct {
+	reader *bufio.Reader
+	pos    uint64
+}
+
+func (r *positionReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.pos += uint64(n)
+	return n, err
+}
+
+// NewReader 解析 wxapkg 头部与索引。src 必须是明文包，加密包请先经过 decrypt.NewReader。
+func NewReader(src io.ReaderAt, size int64, sourcePath string) (*Reader, error) {
+	reader := &positionReader{reader: bufio.NewReaderSize(io.NewSectionReader(src, 0, size), 64*1024)}
+
+	var firstMark byte
+	if err := binary.Read(reader, binary.BigEndian, &firstMark); err != nil {
+		return nil, wrapStageError(sourcePath, stageHeaderValidation, "", fmt.Errorf("读取首标记失败: %w", err))
+	}
+	if firstMark != 0xBE {
+		return nil, wrapStageError(sourcePath, stageHeaderValidation, "", fmt.Errorf("无效的 wxapkg 文件: 首标记不正确"))
+	}
+
+	var header Header
+	if err := binary.Read(reader, binary.BigEndian, &header.Info1); err != nil {
+		return nil, wrapStageError(sourcePath, stageHeaderValidation, "", fmt.Errorf("读取 info1 失败: %w", err))
+	}
+	if err := binary.Read(reader, binary.BigEndian, &header.IndexInfoLength); err != nil {
+		return nil, wrapStageError(sourcePath, stageHeaderValidation, "", fmt.Errorf("读取索引段长度失败: %w", err))
+	}
+	if err := binary.Read(reader, binary.BigEndian, &header.BodyInfoLength); err != nil {
+		return nil, wrapStageError(sourcePath, stageHeaderValidation, "", fmt.Errorf("读取数据段长度失败: %w", err))
+	}
+
+	if uint64(header.IndexInfoLength)+uint64(header.BodyInfoLength) > uint64(size) {
+		return nil, wrapStageError(sourcePath, stageHeaderValidation, "", fmt.Errorf(
+			"文件长度不足: 索引段(%d) + 数据段(%d) > 文件总长度(%d)",
+			header.IndexInfoLength, header.BodyInfoLength, size,
+		))
+	}
+
+	var lastMark byte
+	if err := binary.Read(reader, binary.BigEndian, &lastMark); err != nil {
+		return nil, wrapStageError(sourcePath, stageHeaderValidation, "", fmt.Errorf("读取尾标记失败: %w", err))
+	}
+	if lastMark != 0xED {
+		return nil, wrapStageError(sourcePath, stageHeaderValidation, "", fmt.Errorf("无效的 wxapkg 文件: 尾标记不正确"))
+	}
+
+	if err := binary.Read(reader, binary.BigEndian, &header.FileCount); err != nil {
+		return nil, wrapStageError(sourcePath, stageIndexAnalysis, "", fmt.Errorf("读取文件数量失败: %w", err))
+	}
+	if header.FileCount > maxFileCount {
+		return nil, wrapStageError(sourcePath, stageIndexAnalysis, "", fmt.Errorf("文件数量 %d 超出上限 %d", header.FileCount, maxFileCount))
+	}
+
+	expectedIndexEnd := uint64(size) - uint64(header.BodyInfoLength)
+	if expectedIndexEnd < reader.pos {
+		return nil, wrapStageError(sourcePath, stageHeaderValidation, "", fmt.Errorf(
+			"索引区结束位置异常: 当前位置 %d, 预期结束位置 %d",
+			reader.pos, expectedIndexEnd,
+		))
+	}
+
+	files := make([]WxapkgFile, 0, header.FileCount)
+	index := make(map[string]int, header.FileCount)
+
+	for i := uint32(0); i < header.FileCount; i++ {
+		var wxFile WxapkgFile
+		if err := binary.Read(reader, binary.BigEndian, &wxFile.NameLen); err != nil {
+			return nil, wrapStageError(sourcePath, stageIndexAnalysis, fmt.Sprintf("#%d", i), fmt.Errorf("读取文件名长度失败: %w", err))
+		}
+
+		if wxFile.NameLen == 0 || wxFile.NameLen > maxFileNameLength {
+			return nil, wrapStageError(sourcePath, stageIndexAnalysis, fmt.Sprintf("#%d", i), fmt.Errorf(
+				"文件名长度 %d 不合理，允许范围为 1-%d",
+				wxFile.NameLen, maxFileNameLength,
+			))
+		}
+
+		nameBytes := make([]byte, wxFile.NameLen)
+		if _, err := io.ReadFull(reader, nameBytes); err != nil {
+			return nil, wrapStageError(sourcePath, stageIndexAnalysis, fmt.Sprintf("#%d", i), fmt.Errorf("读取文件名失败: %w", err))
+		}
+		wxFile.Name = string(nameBytes)
+
+		if err := binary.Read(reader, binary.BigEndian, &wxFile.Offset); err != nil {
+			return nil, wrapStageError(sourcePath, stageIndexAnalysis, wxFile.Name, fmt.Errorf("读取文件偏移量失败: %w", err))
+		}
+		if err := binary.Read(reader, binary.BigEndian, &wxFile.Size); err != nil {
+			return nil, wrapStageError(sourcePath, stageIndexAnalysis, wxFile.Name, fmt.Errorf("读取文件大小失败: %w", err))
+		}
+		if wxFile.Size > maxSingleFileSize {
+			return nil, wrapStageError(sourcePath, stageIndexAnalysis, wxFile.Name, fmt.Errorf(
+				"文件大小 %d 超出上限 %d",
+				wxFile.Size, maxSingleFileSize,
+			))
+		}
+
+		fileEnd := uint64(wxFile.Offset) + uint64(wxFile.Size)
+		if fileEnd > uint64(size) {
+			return nil, wrapStageError(sourcePath, stageIndexAnalysis, wxFile.Name, fmt.Errorf(
+				"文件结束位置 %d 超出文件总长度 %d",
+				fileEnd, size,
+			))
+		}
+
+		if reader.pos > expectedIndexEnd {
+			return nil, wrapStageError(sourcePath, stageIndexAnalysis, wxFile.Name, fmt.Errorf(
+				"索引读取超出预期范围: 当前位置 %d, 预期索引结束位置 %d",
+				reader.pos, expectedIndexEnd,
+			))
+		}
+
+		if _, exists := index[entryKey(wxFile.Name)]; !exists {
+			index[entryKey(wxFile.Name)] = len(files)
+		}
+		files = append(files, wxFile)
+	}
+
+	if reader.pos != expectedIndexEnd {
+		return nil, wrapStageError(sourcePath, stageIndexAnalysis, "", fmt.Errorf(
+			"索引段长度不符: 读取到位置 %d, 预期结束位置 %d",
+			reader.pos, expectedIndexEnd,
+		))
+	}
+
+	return &Reader{
+		Header:     header,
+		Files:      files,
+		SourcePath: sourcePath,
+		src:        src,
+		size:       size,
+		index:      index,
+	}, nil
+}
+
+// Size 返回明文包总长度
+func (r *Reader) Size() int64 {
+	return r.size
+}
+
+// FileNames 返回索引中的全部包内路径
+func (r *Reader) FileNames() []string {
+	names := make([]string, 0, len(r.Files))
+	for _, file := range r.Files {
+		names = append(names, file.Name)
+	}
+	return names
+}
+
+// Lookup 按包内路径查找索引项，路径前导 "/" 可省略
+func (r *Reader) Lookup(name string) (WxapkgFile, bool) {
+	idx, ok := r.index[entryKey(name)]
+	if !ok {
+		return WxapkgFile{}, false
+	}
+	return r.Files[idx], true
+}
+
+// Open 返回指定包内文件的读取器，不会预先读取文件内容
+func (r *Reader) Open(name string) (io.Reader, error) {
+	file, ok := r.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("包内文件不存在: %s: %w", name, os.ErrNotExist)
+	}
+	return r.section(file.Offset, file.Size), nil
+}
+
+func (r *Reader) section(offset, size uint32) *io.SectionReader {
+	return io.NewSectionReader(r.src, int64(offset), int64(size))
+}
+
+func entryKey(name string) string {
+	return strings.TrimLeft(strings.ReplaceAll(name, "\\", "/"), "/")
+}
@@ -0,0 +1,125 @@
+package unpack
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/25smoking/Gwxapkg/internal/decrypt"
+)
+
+type testEntry struct {
+	Name    string
+	Content []byte
+}
+
+func buildTestWxapkg(entries []testEntry) []byte {
+	indexLength := 4
+	bodyLength := 0
+	for _, entry := range entries {
+		indexLength += 4 + len(entry.Name) + 8
+		bodyLength += len(entry.Content)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte(0xBE)
+	binary.Write(&buf, binary.BigEndian, uint32(0))
+	binary.Write(&buf, binary.BigEndian, uint32(indexLength))
+	binary.Write(&buf, binary.BigEndian, uint32(bodyLength))
+	buf.WriteByte(0xED)
+	binary.Write(&buf, binary.BigEndian, uint32(len(entries)))
+
+	offset := 14 + indexLength
+	for _, entry := range entries {
+		binary.Write(&buf, binary.BigEndian, uint32(len(entry.Name)))
+		buf.WriteString(entry.Name)
+		binary.Write(&buf, binary.BigEndian, uint32(offset))
+		binary.Write(&buf, binary.BigEndian, uint32(len(entry.Content)))
+		offset += len(entry.Content)
+	}
+	for _, entry := range entries {
+		buf.Write(entry.Content)
+	}
+	return buf.Bytes()
+}
+
+func TestReaderOpenReadsEncryptedEntriesOnDemand(t *testing.T) {
+	appJS := []byte(strings.Repeat("console.log('hello');\n", 200))
+	entries := []testEntry{
+		{Name: "/app-config.json", Content: []byte(`{"pages":["pages/index/index"]}`)},
+		{Name: "/app-service.js", Content: appJS},
+	}
+	plain := buildTestWxapkg(entries)
+
+	encrypted, err := decrypt.EncryptWxapkg(plain, "wx1234567890abcdef")
+	if err != nil {
+		t.Fatalf("加密测试包失败: %v", err)
+	}
+
+	decrypted, err := decrypt.NewReader(bytes.NewReader(encrypted), int64(len(encrypted)), "wx1234567890abcdef")
+	if err != nil {
+		t.Fatalf("构造解密读取器失败: %v", err)
+	}
+	if !decrypted.Encrypted() {
+		t.Fatalf("应识别为加密包")
+	}
+	if decrypted.Size() != int64(len(plain)) {
+		t.Fatalf("明文长度错误: got %d want %d", decrypted.Size(), len(plain))
+	}
+
+	reader, err := NewReader(decrypted, decrypted.Size(), "test.wxapkg")
+	if err != nil {
+		t.Fatalf("解析索引失败: %v", err)
+	}
+	if reader.Header.FileCount != 2 {
+		t.Fatalf("文件数量错误: %d", reader.Header.FileCount)
+	}
+
+	entry, err := reader.Open("app-service.js")
+	if err != nil {
+		t.Fatalf("打开包内文件失败: %v", err)
+	}
+	content, err := io.ReadAll(entry)
+	if err != nil {
+		t.Fatalf("读取包内文件失败: %v", err)
+	}
+	if !bytes.Equal(content, appJS) {
+		t.Fatalf("跨越 AES 前缀与 XOR 尾部的文件内容不一致")
+	}
+
+	if _, err := reader.Open("/missing.js"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("不存在的文件应返回 ErrNotExist，实际: %v", err)
+	}
+}
+
+func TestUnpackWxapkgFromWritesPlannedFiles(t *testing.T) {
+	plain := buildTestWxapkg([]testEntry{
+		{Name: "/pages/index/index.json", Content: []byte(`{"navigationBarTitleText":"首页"}`)},
+		{Name: "/../escape.txt", Content: []byte("blocked")},
+	})
+
+	outputDir := t.TempDir()
+	_, err := UnpackWxapkgFrom(bytes.NewReader(plain), int64(len(plain)), "test.wxapkg", outputDir, DefaultOptions())
+	if err == nil {
+		t.Fatalf("目录穿越的包内路径应被拒绝")
+	}
+
+	plain = buildTestWxapkg([]testEntry{
+		{Name: "/pages/index/index.json", Content: []byte(`{"navigationBarTitleText":"首页"}`)},
+	})
+	files, err := UnpackWxapkgFrom(bytes.NewReader(plain), int64(len(plain)), "test.wxapkg", outputDir, DefaultOptions())
+	if err != nil {
+		t.Fatalf("解包失败: %v", err)
+	}
+	if len(files) != 1 || files[0] != "/pages/index/index.json" {
+		t.Fatalf("文件列表错误: %#v", files)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "pages", "index", "index.json")); err != nil {
+		t.Fatalf("应写出包内文件: %v", err)
+	}
+}
@@ -3,7 +3,6 @@ package unpack
 import (
 	"bufio"
 	"bytes"
-	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
@@ -14,11 +13,8 @@ import (
 	"strings"
 	"sync"
 
-	"github.com/25smoking/Gwxapkg/internal/key"
 	"github.com/25smoking/Gwxapkg/internal/scanner"
 
-	"github.com/25smoking/Gwxapkg/internal/config"
-
 	formatter2 "github.com/25smoking/Gwxapkg/internal/formatter"
 )
 
@@ -81,133 +77,58 @@ func (e *packageStageError) Unwrap() error {
 	return e.Err
 }
 
+// Options 单次解包的运行配置
+type Options struct {
+	Pretty    bool                    // 美化 JS 输出
+	Raw       bool                    // 原样写出包内字节，不做格式化与反混淆
+	Collector *scanner.DataCollector  // 不为空时，每个写出的文件都会同步做敏感扫描与接口提取
+	Rules     []*scanner.CompiledRule // 敏感扫描使用的规则
+	Warn      func(err error)         // 接收不中断解包的告警（例如单个文件扫描失败），为空时丢弃
+}
+
+// DefaultOptions 美化输出且不做扫描
+func DefaultOptions() Options {
+	return Options{Pretty: true}
+}
+
 // UnpackWxapkg 解包 wxapkg 文件并将内容保存到指定目录。
 func UnpackWxapkg(data []byte, sourcePath string, outputDir string) ([]string, error) {
-	plan, err := analyzePackage(data, sourcePath, outputDir)
+	return UnpackWxapkgFrom(bytes.NewReader(data), int64(len(data)), sourcePath, outputDir, DefaultOptions())
+}
+
+// UnpackWxapkgFrom 从 io.ReaderAt 流式解包，文件内容逐个按需读取，不会整体载入内存。
+func UnpackWxapkgFrom(src io.ReaderAt, size int64, sourcePath string, outputDir string, options Options) ([]string, error) {
+	reader, err := NewReader(src, size, sourcePath)
 	if err != nil {
 		return nil, err
 	}
 
-	reader := bytes.NewReader(data)
-	if err := writePlannedFiles(plan, reader); err != nil {
+	plan, err := analyzePackage(reader, outputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writePlannedFiles(plan, reader, options); err != nil {
 		return nil, err
 	}
 
 	return plan.FileNames, nil
 }
 
-func analyzePackage(data []byte, sourcePath string, outputDir string) (*packagePlan, error) {
-	reader := bytes.NewReader(data)
+func analyzePackage(reader *Reader, outputDir string) (*packagePlan, error) {
+	sourcePath := reader.SourcePath
 
 	outputAbs, err := filepath.Abs(outputDir)
 	if err != nil {
 		return nil, wrapStageError(sourcePath, stagePathPlanning, "", fmt.Errorf("解析输出目录失败: %w", err))
 	}
 
-	var firstMark byte
-	if err := binary.Read(reader, binary.BigEndian, &firstMark); err != nil {
-		return nil, wrapStageError(sourcePath, stageHeaderValidation, "", fmt.Errorf("读取首标记失败: %w", err))
-	}
-	if firstMark != 0xBE {
-		return nil, wrapStageError(sourcePath, stageHeaderValidation, "", fmt.Errorf("无效的 wxapkg 文件: 首标记不正确"))
-	}
-
-	var info1, indexInfoLength, bodyInfoLength uint32
-	if err := binary.Read(reader, binary.BigEndian, &info1); err != nil {
-		return nil, wrapStageError(sourcePath, stageHeaderValidation, "", fmt.Errorf("读取 info1 失败: %w", err))
-	}
-	if err := binary.Read(reader, binary.BigEndian, &indexInfoLength); err != nil {
-		return nil, wrapStageError(sourcePath, stageHeaderValidation, "", fmt.Errorf("读取索引段长度失败: %w", err))
-	}
-	if err := binary.Read(reader, binary.BigEndian, &bodyInfoLength); err != nil {
-		return nil, wrapStageError(sourcePath, stageHeaderValidation, "", fmt.Errorf("读取数据段长度失败: %w", err))
-	}
-
-	if uint64(indexInfoLength)+uint64(bodyInfoLength) > uint64(len(data)) {
-		return nil, wrapStageError(sourcePath, stageHeaderValidation, "", fmt.Errorf(
-			"文件长度不足: 索引段(%d) + 数据段(%d) > 文件总长度(%d)",
-			indexInfoLength, bodyInfoLength, len(data),
-		))
-	}
-
-	var lastMark byte
-	if err := binary.Read(reader, binary.BigEndian, &lastMark); err != nil {
-		return nil, wrapStageError(sourcePath, stageHeaderValidation, "", fmt.Errorf("读取尾标记失败: %w", err))
-	}
-	if lastMark != 0xED {
-		return nil, wrapStageError(sourcePath, stageHeaderValidation, "", fmt.Errorf("无效的 wxapkg 文件: 尾标记不正确"))
-	}
-
-	var fileCount uint32
-	if err := binary.Read(reader, binary.BigEndian, &fileCount); err != nil {
-		return nil, wrapStageError(sourcePath, stageIndexAnalysis, "", fmt.Errorf("读取文件数量失败: %w", err))
-	}
-	if fileCount > maxFileCount {
-		return nil, wrapStageError(sourcePath, stageIndexAnalysis, "", fmt.Errorf("文件数量 %d 超出上限 %d", fileCount, maxFileCount))
-	}
-
-	expectedIndexEnd := uint64(reader.Size()) - uint64(bodyInfoLength)
-	currentPos := uint64(reader.Size()) - uint64(reader.Len())
-	if expectedIndexEnd < currentPos {
-		return nil, wrapStageError(sourcePath, stageHeaderValidation, "", fmt.Errorf(
-			"索引区结束位置异常: 当前位置 %d, 预期结束位置 %d",
-			currentPos, expectedIndexEnd,
-		))
-	}
-
-	fileNames := make([]string, 0, fileCount)
-	plans := make([]plannedFile, 0, fileCount)
-	usedFiles := make(map[string]struct{}, fileCount)
-	usedDirs := make(map[string]struct{}, fileCount)
-
-	for i := uint32(0); i < fileCount; i++ {
-		var wxFile WxapkgFile
-		if err := binary.Read(reader, binary.BigEndian, &wxFile.NameLen); err != nil {
-			return nil, wrapStageError(sourcePath, stageIndexAnalysis, fmt.Sprintf("#%d", i), fmt.Errorf("读取文件名长度失败: %w", err))
-		}
-
-		if wxFile.NameLen == 0 || wxFile.NameLen > maxFileNameLength {
-			return nil, wrapStageError(sourcePath, stageIndexAnalysis, fmt.Sprintf("#%d", i), fmt.Errorf(
-				"文件名长度 %d 不合理，允许范围为 1-%d",
-				wxFile.NameLen, maxFileNameLength,
-			))
-		}
-
-		nameBytes := make([]byte, wxFile.NameLen)
-		if _, err := io.ReadFull(reader, nameBytes); err != nil {
-			return nil, wrapStageError(sourcePath, stageIndexAnalysis, fmt.Sprintf("#%d", i), fmt.Errorf("读取文件名失败: %w", err))
-		}
-		wxFile.Name = string(nameBytes)
-
-		if err := binary.Read(reader, binary.BigEndian, &wxFile.Offset); err != nil {
-			return nil, wrapStageError(sourcePath, stageIndexAnalysis, wxFile.Name, fmt.Errorf("读取文件偏移量失败: %w", err))
-		}
-		if err := binary.Read(reader, binary.BigEndian, &wxFile.Size); err != nil {
-			return nil, wrapStageError(sourcePath, stageIndexAnalysis, wxFile.Name, fmt.Errorf("读取文件大小失败: %w", err))
-		}
-		if wxFile.Size > maxSingleFileSize {
-			return nil, wrapStageError(sourcePath, stageIndexAnalysis, wxFile.Name, fmt.Errorf(
-				"文件大小 %d 超出上限 %d",
-				wxFile.Size, maxSingleFileSize,
-			))
-		}
-
-		fileEnd := uint64(wxFile.Offset) + uint64(wxFile.Size)
-		if fileEnd > uint64(len(data)) {
-			return nil, wrapStageError(sourcePath, stageIndexAnalysis, wxFile.Name, fmt.Errorf(
-				"文件结束位置 %d 超出文件总长度 %d",
-				fileEnd, len(data),
-			))
-		}
-
-		currentPos = uint64(reader.Size()) - uint64(reader.Len())
-		if currentPos > expectedIndexEnd {
-			return nil, wrapStageError(sourcePath, stageIndexAnalysis, wxFile.Name, fmt.Errorf(
-				"索引读取超出预期范围: 当前位置 %d, 预期索引结束位置 %d",
-				currentPos, expectedIndexEnd,
-			))
-		}
+	fileNames := make([]string, 0, len(reader.Files))
+	plans := make([]plannedFile, 0, len(reader.Files))
+	usedFiles := make(map[string]struct{}, len(reader.Files))
+	usedDirs := make(map[string]struct{}, len(reader.Files))
 
+	for i, wxFile := range reader.Files {
 		relativePath, fullPath, err := planOutputPath(outputAbs, wxFile.Name, usedFiles, usedDirs)
 		if err != nil {
 			return nil, wrapStageError(sourcePath, stagePathPlanning, wxFile.Name, err)
@@ -215,7 +136,7 @@ func analyzePackage(data []byte, sourcePath string, outputDir string) (*packageP
 
 		fileNames = append(fileNames, wxFile.Name)
 		plans = append(plans, plannedFile{
-			Index:        int(i),
+			Index:        i,
 			EntryName:    wxFile.Name,
 			RelativePath: relativePath,
 			FullPath:     fullPath,
@@ -224,14 +145,6 @@ func analyzePackage(data []byte, sourcePath string, outputDir string) (*packageP
 		})
 	}
 
-	currentPos = uint64(reader.Size()) - uint64(reader.Len())
-	if currentPos != expectedIndexEnd {
-		return nil, wrapStageError(sourcePath, stageIndexAnalysis, "", fmt.Errorf(
-			"索引段长度不符: 读取到位置 %d, 预期结束位置 %d",
-			currentPos, expectedIndexEnd,
-		))
-	}
-
 	return &packagePlan{
 		SourcePath: sourcePath,
 		OutputDir:  outputAbs,
@@ -354,7 +267,7 @@ func isWithinBaseDir(baseDir string, targetPath string) (bool, error) {
 	return !strings.HasPrefix(rel, ".."+string(os.PathSeparator)), nil
 }
 
-func writePlannedFiles(plan *packagePlan, reader io.ReaderAt) error {
+func writePlannedFiles(plan *packagePlan, reader *Reader, options Options) error {
 	workerCount := runtime.NumCPU() * 2
 	if workerCount < 4 {
 		workerCount = 4
@@ -378,7 +291,7 @@ func writePlannedFiles(plan *packagePlan, reader io.ReaderAt) error {
 		go func() {
 			defer wg.Done()
 			for file := range fileChan {
-				if err := processPlannedFile(plan.SourcePath, file, reader, &bufferPool); err != nil {
+				if err := processPlannedFile(plan.SourcePath, file, reader, options, &bufferPool); err != nil {
 					errChan <- err
 				}
 			}
@@ -400,13 +313,13 @@ func writePlannedFiles(plan *packagePlan, reader io.ReaderAt) error {
 	return nil
 }
 
-func processPlannedFile(sourcePath string, file plannedFile, reader io.ReaderAt, bufferPool *sync.Pool) error {
+func processPlannedFile(sourcePath string, file plannedFile, reader *Reader, options Options, bufferPool *sync.Pool) error {
 	dir := filepath.Dir(file.FullPath)
 	if err := os.MkdirAll(dir, 0755); err != nil && !os.IsExist(err) {
 		return wrapStageError(sourcePath, stageFileWrite, file.RelativePath, fmt.Errorf("创建目录失败: %w", err))
 	}
 
-	sectionReader := io.NewSectionReader(reader, int64(file.Offset), int64(file.Size))
+	sectionReader := reader.section(file.Offset, file.Size)
 
 	buf := bufferPool.Get().(*bytes.Buffer)
 	defer bufferPool.Put(buf)
@@ -419,8 +332,8 @@ func processPlannedFile(sourcePath string, file plannedFile, reader io.ReaderAt,
 
 	ext := filepath.Ext(file.EntryName)
 	var jsResult *formatter2.DeobfuscationResult
-	formatter, err := formatter2.GetFormatter(ext)
-	if err == nil {
+	formatter, err := formatter2.GetFormatterWithOptions(ext, formatter2.Options{Pretty: options.Pretty})
+	if err == nil && !options.Raw {
 		if fileFormatter, ok := formatter.(formatter2.FileFormatter); ok {
 			content, jsResult, err = fileFormatter.FormatFile(content, file.RelativePath)
 		} else {
@@ -445,34 +358,18 @@ func processPlannedFile(sourcePath string, file plannedFile, reader io.ReaderAt,
 		return wrapStageError(sourcePath, stageFileWrite, file.RelativePath, fmt.Errorf("刷新缓冲区失败: %w", err))
 	}
 
-	configManager := config.NewSharedConfigManager()
-	shouldScan := false
-	if sensitive, ok := configManager.Get("sensitive"); ok {
-		if enabled, ok := sensitive.(bool); ok && enabled {
-			shouldScan = true
+	if collector := options.Collector; collector != nil {
+		if jsResult != nil && jsResult.IsObfuscated {
+			collector.AddObfuscatedFile(scanner.ObfuscatedFile{
+				FilePath:   file.RelativePath,
+				Score:      jsResult.Score,
+				Techniques: jsResult.Techniques,
+				Status:     jsResult.Status,
+				Tag:        formatter2.BuildObfuscatedTag(jsResult),
+			})
 		}
-	}
-	if postman, ok := configManager.Get("postman"); ok {
-		if enabled, ok := postman.(bool); ok && enabled {
-			shouldScan = true
-		}
-	}
-
-	if shouldScan {
-		collector := key.GetCollector()
-		if collector != nil {
-			if jsResult != nil && jsResult.IsObfuscated {
-				collector.AddObfuscatedFile(scanner.ObfuscatedFile{
-					FilePath:   file.RelativePath,
-					Score:      jsResult.Score,
-					Techniques: jsResult.Techniques,
-					Status:     jsResult.Status,
-					Tag:        formatter2.BuildObfuscatedTag(jsResult),
-				})
-			}
-			if err := scanner.ScanFile(file.RelativePath, content, collector); err != nil {
-				fmt.Printf("警告: %v\n", wrapStageError(sourcePath, stageSensitiveScan, file.RelativePath, err))
-			}
+		if err := scanner.ScanFile(file.RelativePath, content, options.Rules, collector); err != nil && options.Warn != nil {
+			options.Warn(wrapStageError(sourcePath, stageSensitiveScan, file.RelativePath, err))
 		}
 	}
 
@@ -20,6 +20,7 @@ import (
 // XssParser 结构体定义
 type XssParser struct {
 	OutputDir string
+	Deletions *config.FileDeletionManager // 本次运行的待删除文件清单
 }
 
 // 相对路径转换
@@ -197,7 +198,10 @@ func (p *XssParser) Parse(option config.WxapkgInfo) error {
 	}
 
 	// 创建文件删除管理器
-	manager := config.NewFileDeletionManager()
+	manager := p.Deletions
+	if manager == nil {
+		manager = config.NewFileDeletionManager()
+	}
 
 	var runList = make(map[string]string)
 	var result = make(map[string]string)
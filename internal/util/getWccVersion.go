@@ -5,6 +5,8 @@ import (
 	"regexp"
 )
 
+var wccVersionPattern = regexp.MustCompile(`__wcc_version__\s*=\s*['"]([^'"]+)['"]`)
+
 // GetWccVersion 从源代码字符串中提取 __wcc_version__ 的值
 func GetWccVersion(source string) string {
 	if source == "" {
@@ -13,18 +15,14 @@ func GetWccVersion(source string) string {
 
 	// 读取source文件内容
 	content, _ := os.ReadFile(source)
+	return ParseWccVersion(content)
+}
 
-	// 定义正则表达式，用于匹配 __wcc_version__ 的值
-	regex := regexp.MustCompile(`__wcc_version__\s*=\s*['"]([^'"]+)['"]`)
-
-	// 查找匹配项
-	matches := regex.FindStringSubmatch(string(content))
-
-	// 如果匹配成功并捕获到版本号，则返回版本号
+// ParseWccVersion 从内存中的源码提取 __wcc_version__ 的值，未找到时返回空字符串
+func ParseWccVersion(content []byte) string {
+	matches := wccVersionPattern.FindSubmatch(content)
 	if len(matches) > 1 {
-		return matches[1]
+		return string(matches[1])
 	}
-
-	// 未找到匹配项，返回空字符串
 	return ""
 }
@@ -0,0 +1,75 @@
+package util
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// GlobMatcher 路径 glob 匹配：* 与 ? 不跨目录，** 可跨多级目录；
+// 不含 "/" 的模式只匹配文件名，与 .gitignore 的习惯一致。
+type GlobMatcher struct {
+	patterns []*regexp.Regexp
+	baseOnly []bool
+}
+
+// NewGlobMatcher 编译一组 glob，空模式会被忽略
+func NewGlobMatcher(patterns []string) *GlobMatcher {
+	matcher := &GlobMatcher{}
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(strings.ReplaceAll(pattern, "\\", "/"))
+		if pattern == "" {
+			continue
+		}
+		matcher.baseOnly = append(matcher.baseOnly, !strings.Contains(pattern, "/"))
+		matcher.patterns = append(matcher.patterns, globToRegexp(strings.TrimLeft(pattern, "/")))
+	}
+	return matcher
+}
+
+// Match 没有任何模式时匹配全部路径
+func (m *GlobMatcher) Match(name string) bool {
+	if len(m.patterns) == 0 {
+		return true
+	}
+	name = strings.TrimLeft(strings.ReplaceAll(name, "\\", "/"), "/")
+	for i, pattern := range m.patterns {
+		target := name
+		if m.baseOnly[i] {
+			target = path.Base(name)
+		}
+		if pattern.MatchString(target) {
+			return true
+		}
+	}
+	return false
+}
+
+func globToRegexp(pattern string) *regexp.Regexp {
+	// 按 rune 处理，中文等多字节字符不能被拆成单个字节转义
+	runes := []rune(pattern)
+	var builder strings.Builder
+	builder.WriteString("^")
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; r {
+		case '*':
+			if i+1 < len(runes) && runes[i+1] == '*' {
+				i++
+				if i+1 < len(runes) && runes[i+1] == '/' {
+					i++
+					builder.WriteString("(?:.*/)?")
+				} else {
+					builder.WriteString(".*")
+				}
+			} else {
+				builder.WriteString("[^/]*")
+			}
+		case '?':
+			builder.WriteString("[^/]")
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	builder.WriteString("$")
+	return regexp.MustCompile(builder.String())
+}
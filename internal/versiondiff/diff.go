@@ -0,0 +1,533 @@
+package versiondiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/25smoking/Gwxapkg/internal/analyzer"
+	"github.com/25smoking/Gwxapkg/internal/scanner"
+	"github.com/25smoking/Gwxapkg/internal/semantic"
+)
+
+const reportDirName = ".gwxapkg"
+
+// Snapshot 一个已解包输出目录中可用于对比的产物
+type Snapshot struct {
+	Dir    string
+	Label  string
+	Routes *analyzer.RouteManifest
+	APIMap *semantic.APIMapReport
+	Scan   *scanner.ScanReport
+}
+
+// Side 报告中描述的对比一侧
+type Side struct {
+	Label     string `json:"label"`
+	Dir       string `json:"dir"`
+	HasRoutes bool   `json:"has_routes"`
+	HasAPIMap bool   `json:"has_api_map"`
+	HasScan   bool   `json:"has_scan"`
+}
+
+// Report 两个版本之间的差异
+type Report struct {
+	AppID       string `json:"appid,omitempty"`
+	GeneratedAt string `json:"generated_at"`
+	Old         Side   `json:"old"`
+	New         Side   `json:"new"`
+
+	Pages           PageDiff        `json:"pages"`
+	APIEndpoints    EndpointDiff    `json:"api_endpoints"`
+	Findings        FindingDiff     `json:"findings"`
+	NavigationEdges EdgeDiff        `json:"navigation_edges"`
+	Components      []ComponentDiff `json:"components,omitempty"`
+	Summary         Summary         `json:"summary"`
+	Notes           []string        `json:"notes,omitempty"`
+}
+
+// PageDiff 页面增删改
+type PageDiff struct {
+	Added   []string     `json:"added,omitempty"`
+	Removed []string     `json:"removed,omitempty"`
+	Changed []PageChange `json:"changed,omitempty"`
+}
+
+// PageChange 同一路由在两个版本间变化的字段
+type PageChange struct {
+	Route  string   `json:"route"`
+	Fields []string `json:"fields"`
+}
+
+// Endpoint 统一 api_map.json 与扫描报告中的接口
+type Endpoint struct {
+	Key         string   `json:"key"`
+	Method      string   `json:"method,omitempty"`
+	URL         string   `json:"url,omitempty"`
+	Name        string   `json:"name,omitempty"`
+	FilePath    string   `json:"file_path,omitempty"`
+	ParamFields []string `json:"param_fields,omitempty"`
+	Sources     []string `json:"sources"`
+}
+
+// EndpointChange 同一接口参数字段的变化
+type EndpointChange struct {
+	Key           string   `json:"key"`
+	AddedParams   []string `json:"added_params,omitempty"`
+	RemovedParams []string `json:"removed_params,omitempty"`
+}
+
+// EndpointDiff 接口增删改
+type EndpointDiff struct {
+	Added   []Endpoint       `json:"added,omitempty"`
+	Removed []Endpoint       `json:"removed,omitempty"`
+	Changed []EndpointChange `json:"changed,omitempty"`
+}
+
+// FindingDiff 敏感信息增删，按 规则 + 内容 去重
+type FindingDiff struct {
+	Added   []scanner.SensitiveItem `json:"added,omitempty"`
+	Removed []scanner.SensitiveItem `json:"removed,omitempty"`
+}
+
+// Edge 页面跳转边，按 源页面 + 目标 + 跳转方式 去重
+type Edge struct {
+	SourcePage string `json:"source_page"`
+	Target     string `json:"target"`
+	Method     string `json:"method"`
+	SourceFile string `json:"source_file,omitempty"`
+}
+
+// EdgeDiff 跳转边增删
+type EdgeDiff struct {
+	Added   []Edge `json:"added,omitempty"`
+	Removed []Edge `json:"removed,omitempty"`
+}
+
+// ComponentDiff 单个页面组件与依赖的变化
+type ComponentDiff struct {
+	Route               string   `json:"route"`
+	AddedComponents     []string `json:"added_components,omitempty"`
+	RemovedComponents   []string `json:"removed_components,omitempty"`
+	AddedDependencies   []string `json:"added_dependencies,omitempty"`
+	RemovedDependencies []string `json:"removed_dependencies,omitempty"`
+}
+
+// Summary 差异计数
+type Summary struct {
+	AddedPages        int `json:"added_pages"`
+	RemovedPages      int `json:"removed_pages"`
+	ChangedPages      int `json:"changed_pages"`
+	AddedEndpoints    int `json:"added_endpoints"`
+	RemovedEndpoints  int `json:"removed_endpoints"`
+	ChangedEndpoints  int `json:"changed_endpoints"`
+	AddedFindings     int `json:"added_findings"`
+	AddedHighRisk     int `json:"added_high_risk"`
+	RemovedFindings   int `json:"removed_findings"`
+	AddedEdges        int `json:"added_navigation_edges"`
+	RemovedEdges      int `json:"removed_navigation_edges"`
+	ChangedComponents int `json:"changed_component_pages"`
+}
+
+// Load 读取输出目录中的 route_manifest.json、.gwxapkg/api_map.json 与 sensitive_report.json。
+// route_manifest.json 缺失时会现场分析页面路由；其余产物缺失时对应部分不参与对比。
+func Load(dir, label string) (*Snapshot, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取目录失败: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s 不是目录", dir)
+	}
+	if label == "" {
+		label = filepath.Base(filepath.Clean(dir))
+	}
+
+	snapshot := &Snapshot{Dir: dir, Label: label}
+
+	var manifest analyzer.RouteManifest
+	if ok, err := readJSON(filepath.Join(dir, "route_manifest.json"), &manifest); err != nil {
+		return nil, err
+	} else if ok {
+		snapshot.Routes = &manifest
+	} else if routes, err := analyzer.AnalyzeMiniProgram(dir, ""); err == nil {
+		snapshot.Routes = routes
+	}
+
+	var apiMap semantic.APIMapReport
+	if ok, err := readJSON(filepath.Join(dir, reportDirName, "api_map.json"), &apiMap); err != nil {
+		return nil, err
+	} else if ok {
+		snapshot.APIMap = &apiMap
+	}
+
+	var scan scanner.ScanReport
+	if ok, err := readJSON(filepath.Join(dir, "sensitive_report.json"), &scan); err != nil {
+		return nil, err
+	} else if ok {
+		snapshot.Scan = &scan
+	}
+
+	return snapshot, nil
+}
+
+func readJSON(path string, target interface{}) (bool, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("读取 %s 失败: %w", path, err)
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return false, fmt.Errorf("解析 %s 失败: %w", path, err)
+	}
+	return true, nil
+}
+
+// Compare 对比两个快照
+func Compare(oldSnapshot, newSnapshot *Snapshot) *Report {
+	report := &Report{
+		GeneratedAt: time.Now().Format(time.RFC3339),
+		Old:         describe(oldSnapshot),
+		New:         describe(newSnapshot),
+	}
+	if newSnapshot.Routes != nil {
+		report.AppID = newSnapshot.Routes.AppID
+	}
+	if report.AppID == "" && newSnapshot.Scan != nil {
+		report.AppID = newSnapshot.Scan.AppID
+	}
+
+	if oldSnapshot.Routes != nil && newSnapshot.Routes != nil {
+		comparePages(report, oldSnapshot.Routes, newSnapshot.Routes)
+		compareEdges(report, oldSnapshot.Routes, newSnapshot.Routes)
+	} else {
+		report.Notes = append(report.Notes, "至少一侧缺少页面路由清单，已跳过页面、跳转边与组件对比")
+	}
+
+	compareEndpoints(report, collectEndpoints(oldSnapshot), collectEndpoints(newSnapshot))
+
+	if oldSnapshot.Scan != nil && newSnapshot.Scan != nil {
+		compareFindings(report, oldSnapshot.Scan, newSnapshot.Scan)
+	} else {
+		report.Notes = append(report.Notes, "至少一侧缺少 sensitive_report.json，已跳过敏感信息对比")
+	}
+
+	report.Summary = Summary{
+		AddedPages:        len(report.Pages.Added),
+		RemovedPages:      len(report.Pages.Removed),
+		ChangedPages:      len(report.Pages.Changed),
+		AddedEndpoints:    len(report.APIEndpoints.Added),
+		RemovedEndpoints:  len(report.APIEndpoints.Removed),
+		ChangedEndpoints:  len(report.APIEndpoints.Changed),
+		AddedFindings:     len(report.Findings.Added),
+		RemovedFindings:   len(report.Findings.Removed),
+		AddedEdges:        len(report.NavigationEdges.Added),
+		RemovedEdges:      len(report.NavigationEdges.Removed),
+		ChangedComponents: len(report.Components),
+	}
+	for _, item := range report.Findings.Added {
+		if item.Confidence == "high" {
+			report.Summary.AddedHighRisk++
+		}
+	}
+	return report
+}
+
+// HasChanges 是否存在任何差异
+func (r *Report) HasChanges() bool {
+	s := r.Summary
+	return s.AddedPages+s.RemovedPages+s.ChangedPages+
+		s.AddedEndpoints+s.RemovedEndpoints+s.ChangedEndpoints+
+		s.AddedFindings+s.RemovedFindings+
+		s.AddedEdges+s.RemovedEdges+s.ChangedComponents > 0
+}
+
+func describe(snapshot *Snapshot) Side {
+	return Side{
+		Label:     snapshot.Label,
+		Dir:       snapshot.Dir,
+		HasRoutes: snapshot.Routes != nil,
+		HasAPIMap: snapshot.APIMap != nil,
+		HasScan:   snapshot.Scan != nil,
+	}
+}
+
+func comparePages(report *Report, oldRoutes, newRoutes *analyzer.RouteManifest) {
+	oldPages := make(map[string]analyzer.PageNode, len(oldRoutes.Pages))
+	for _, page := range oldRoutes.Pages {
+		oldPages[page.Route] = page
+	}
+	newPages := make(map[string]analyzer.PageNode, len(newRoutes.Pages))
+	for _, page := range newRoutes.Pages {
+		newPages[page.Route] = page
+	}
+
+	for _, route := range slices.Sorted(maps.Keys(newPages)) {
+		newPage := newPages[route]
+		oldPage, ok := oldPages[route]
+		if !ok {
+			report.Pages.Added = append(report.Pages.Added, route)
+			continue
+		}
+
+		if fields := changedPageFields(oldPage, newPage); len(fields) > 0 {
+			report.Pages.Changed = append(report.Pages.Changed, PageChange{Route: route, Fields: fields})
+		}
+
+		addedComponents, removedComponents := diffStrings(oldPage.UsingComponents, newPage.UsingComponents)
+		addedDependencies, removedDependencies := diffStrings(oldPage.Dependencies, newPage.Dependencies)
+		if len(addedComponents)+len(removedComponents)+len(addedDependencies)+len(removedDependencies) > 0 {
+			report.Components = append(report.Components, ComponentDiff{
+				Route:               route,
+				AddedComponents:     addedComponents,
+				RemovedComponents:   removedComponents,
+				AddedDependencies:   addedDependencies,
+				RemovedDependencies: removedDependencies,
+			})
+		}
+	}
+	for _, route := range slices.Sorted(maps.Keys(oldPages)) {
+		if _, ok := newPages[route]; !ok {
+			report.Pages.Removed = append(report.Pages.Removed, route)
+		}
+	}
+}
+
+func changedPageFields(oldPage, newPage analyzer.PageNode) []string {
+	var fields []string
+	if oldPage.Title != newPage.Title {
+		fields = append(fields, "title")
+	}
+	if oldPage.PackageType != newPage.PackageType || oldPage.PackageRoot != newPage.PackageRoot {
+		fields = append(fields, "package")
+	}
+	if oldPage.IsEntry != newPage.IsEntry {
+		fields = append(fields, "entry")
+	}
+	if oldPage.IsTabBar != newPage.IsTabBar {
+		fields = append(fields, "tab_bar")
+	}
+	if oldPage.Files != newPage.Files {
+		fields = append(fields, "files")
+	}
+	if !sameStrings(oldPage.UsingComponents, newPage.UsingComponents) {
+		fields = append(fields, "using_components")
+	}
+	if !sameStrings(oldPage.Dependencies, newPage.Dependencies) {
+		fields = append(fields, "dependencies")
+	}
+	if !sameStrings(pageAPIKeys(oldPage.APIUsage), pageAPIKeys(newPage.APIUsage)) {
+		fields = append(fields, "api_usage")
+	}
+	return fields
+}
+
+func pageAPIKeys(usage []analyzer.PageAPIUsage) []string {
+	keys := make([]string, 0, len(usage))
+	for _, item := range usage {
+		keys = append(keys, endpointKey(item.Method, item.RawURL))
+	}
+	return keys
+}
+
+func compareEdges(report *Report, oldRoutes, newRoutes *analyzer.RouteManifest) {
+	oldEdges := collectEdges(oldRoutes.NavigationEdges)
+	newEdges := collectEdges(newRoutes.NavigationEdges)
+	for _, key := range slices.Sorted(maps.Keys(newEdges)) {
+		if _, ok := oldEdges[key]; !ok {
+			report.NavigationEdges.Added = append(report.NavigationEdges.Added, newEdges[key])
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(oldEdges)) {
+		if _, ok := newEdges[key]; !ok {
+			report.NavigationEdges.Removed = append(report.NavigationEdges.Removed, oldEdges[key])
+		}
+	}
+}
+
+func collectEdges(edges []analyzer.NavigationEdge) map[string]Edge {
+	result := make(map[string]Edge, len(edges))
+	for _, edge := range edges {
+		target := edge.TargetPage
+		if target == "" {
+			target = edge.RawTarget
+		}
+		key := edge.SourcePage + "\x00" + target + "\x00" + edge.Method
+		if _, exists := result[key]; exists {
+			continue
+		}
+		result[key] = Edge{SourcePage: edge.SourcePage, Target: target, Method: edge.Method, SourceFile: edge.SourceFile}
+	}
+	return result
+}
+
+// collectEndpoints 合并语义 API 地图与正则扫描得到的接口；同一 method+url 只保留一条并记录来源
+func collectEndpoints(snapshot *Snapshot) map[string]*Endpoint {
+	result := make(map[string]*Endpoint)
+	add := func(endpoint Endpoint, source string) {
+		if existing, ok := result[endpoint.Key]; ok {
+			if !containsString(existing.Sources, source) {
+				existing.Sources = append(existing.Sources, source)
+			}
+			existing.ParamFields = mergeStrings(existing.ParamFields, endpoint.ParamFields)
+			return
+		}
+		endpoint.Sources = []string{source}
+		result[endpoint.Key] = &endpoint
+	}
+
+	if snapshot.APIMap != nil {
+		for _, entry := range snapshot.APIMap.Endpoints {
+			url := entry.URL
+			if url == "" && (entry.ControllerName != "" || entry.MethodsName != "") {
+				url = strings.Trim(entry.ControllerName+"/"+entry.MethodsName, "/")
+			}
+			if url == "" {
+				continue
+			}
+			add(Endpoint{
+				Key:         endpointKey(entry.HTTPMethod, url),
+				Method:      strings.ToUpper(entry.HTTPMethod),
+				URL:         url,
+				Name:        entry.FunctionName,
+				FilePath:    entry.FilePath,
+				ParamFields: mergeStrings(nil, entry.ParamFields),
+			}, "api_map")
+		}
+	}
+	if snapshot.Scan != nil {
+		for _, entry := range snapshot.Scan.APIEndpoints {
+			if entry.RawURL == "" {
+				continue
+			}
+			add(Endpoint{
+				Key:      endpointKey(entry.Method, entry.RawURL),
+				Method:   strings.ToUpper(entry.Method),
+				URL:      entry.RawURL,
+				Name:     entry.Name,
+				FilePath: entry.FilePath,
+			}, "scan")
+		}
+	}
+	return result
+}
+
+func endpointKey(method, url string) string {
+	method = strings.ToUpper(strings.TrimSpace(method))
+	if method == "" {
+		method = "ANY"
+	}
+	return method + " " + strings.TrimSpace(url)
+}
+
+func compareEndpoints(report *Report, oldEndpoints, newEndpoints map[string]*Endpoint) {
+	for _, key := range slices.Sorted(maps.Keys(newEndpoints)) {
+		newEndpoint := newEndpoints[key]
+		oldEndpoint, ok := oldEndpoints[key]
+		if !ok {
+			report.APIEndpoints.Added = append(report.APIEndpoints.Added, *newEndpoint)
+			continue
+		}
+		added, removed := diffStrings(oldEndpoint.ParamFields, newEndpoint.ParamFields)
+		if len(added)+len(removed) > 0 {
+			report.APIEndpoints.Changed = append(report.APIEndpoints.Changed, EndpointChange{
+				Key:           key,
+				AddedParams:   added,
+				RemovedParams: removed,
+			})
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(oldEndpoints)) {
+		if _, ok := newEndpoints[key]; !ok {
+			report.APIEndpoints.Removed = append(report.APIEndpoints.Removed, *oldEndpoints[key])
+		}
+	}
+}
+
+func compareFindings(report *Report, oldScan, newScan *scanner.ScanReport) {
+	oldItems := collectFindings(oldScan)
+	newItems := collectFindings(newScan)
+	for _, key := range slices.Sorted(maps.Keys(newItems)) {
+		if _, ok := oldItems[key]; !ok {
+			report.Findings.Added = append(report.Findings.Added, newItems[key])
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(oldItems)) {
+		if _, ok := newItems[key]; !ok {
+			report.Findings.Removed = append(report.Findings.Removed, oldItems[key])
+		}
+	}
+}
+
+// collectFindings 文件路径在版本间经常因混淆哈希变化，因此只按规则与命中内容去重。
+// 基线已知与已忽略的发现同样计入，两个版本只有一个使用 -baseline / -suppress 时不会被误判为删除；
+// 这些发现保留 status 字段，差异报告中可以区分。
+func collectFindings(scan *scanner.ScanReport) map[string]scanner.SensitiveItem {
+	result := make(map[string]scanner.SensitiveItem, len(scan.Items)+len(scan.KnownItems)+len(scan.SuppressedItems))
+	for _, items := range [][]scanner.SensitiveItem{scan.Items, scan.KnownItems, scan.SuppressedItems} {
+		for _, item := range items {
+			key := item.RuleID + "\x00" + item.Content
+			if _, exists := result[key]; !exists {
+				result[key] = item
+			}
+		}
+	}
+	return result
+}
+
+func diffStrings(oldValues, newValues []string) (added, removed []string) {
+	oldSet := make(map[string]struct{}, len(oldValues))
+	for _, value := range oldValues {
+		oldSet[value] = struct{}{}
+	}
+	newSet := make(map[string]struct{}, len(newValues))
+	for _, value := range newValues {
+		newSet[value] = struct{}{}
+	}
+	for _, value := range slices.Sorted(maps.Keys(newSet)) {
+		if _, ok := oldSet[value]; !ok {
+			added = append(added, value)
+		}
+	}
+	for _, value := range slices.Sorted(maps.Keys(oldSet)) {
+		if _, ok := newSet[value]; !ok {
+			removed = append(removed, value)
+		}
+	}
+	return added, removed
+}
+
+func sameStrings(left, right []string) bool {
+	added, removed := diffStrings(left, right)
+	return len(added) == 0 && len(removed) == 0
+}
+
+func mergeStrings(left, right []string) []string {
+	set := make(map[string]struct{}, len(left)+len(right))
+	for _, value := range left {
+		set[value] = struct{}{}
+	}
+	for _, value := range right {
+		set[value] = struct{}{}
+	}
+	if len(set) == 0 {
+		return nil
+	}
+	return slices.Sorted(maps.Keys(set))
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
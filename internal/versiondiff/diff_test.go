@@ -0,0 +1,157 @@
+package versiondiff
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/25smoking/Gwxapkg/internal/analyzer"
+	"github.com/25smoking/Gwxapkg/internal/scanner"
+	"github.com/25smoking/Gwxapkg/internal/semantic"
+)
+
+func writeSnapshot(t *testing.T, routes *analyzer.RouteManifest, apiMap *semantic.APIMapReport, scan *scanner.ScanReport) string {
+	t.Helper()
+	dir := t.TempDir()
+	write := func(path string, value interface{}) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(dir, "route_manifest.json"), routes)
+	if apiMap != nil {
+		write(filepath.Join(dir, reportDirName, "api_map.json"), apiMap)
+	}
+	if scan != nil {
+		write(filepath.Join(dir, "sensitive_report.json"), scan)
+	}
+	return dir
+}
+
+func TestCompareReportsPagesEndpointsFindingsAndEdges(t *testing.T) {
+	oldDir := writeSnapshot(t,
+		&analyzer.RouteManifest{
+			AppID: "wx123",
+			Pages: []analyzer.PageNode{
+				{Route: "pages/index/index", Title: "首页", UsingComponents: []string{"components/card/card"}},
+				{Route: "pages/legacy/legacy"},
+			},
+			NavigationEdges: []analyzer.NavigationEdge{
+				{SourcePage: "pages/index/index", TargetPage: "pages/legacy/legacy", Method: "navigateTo"},
+			},
+		},
+		&semantic.APIMapReport{Endpoints: []semantic.APIEndpointEntry{
+			{FunctionName: "getUser", HTTPMethod: "get", URL: "/api/user", ParamFields: []string{"id"}},
+			{FunctionName: "oldLogin", HTTPMethod: "POST", URL: "/api/login/v1"},
+		}},
+		&scanner.ScanReport{Items: []scanner.SensitiveItem{
+			{RuleID: "phone", Content: "13800000000", Confidence: "medium"},
+			{RuleID: "email", Content: "ops@example.com", Confidence: "low"},
+		}},
+	)
+	newDir := writeSnapshot(t,
+		&analyzer.RouteManifest{
+			AppID: "wx123",
+			Pages: []analyzer.PageNode{
+				{Route: "pages/index/index", Title: "首页", UsingComponents: []string{"components/card/card", "components/pay/pay"}},
+				{Route: "pages/pay/pay"},
+			},
+			NavigationEdges: []analyzer.NavigationEdge{
+				{SourcePage: "pages/index/index", TargetPage: "pages/pay/pay", Method: "navigateTo"},
+			},
+		},
+		&semantic.APIMapReport{Endpoints: []semantic.APIEndpointEntry{
+			{FunctionName: "getUser", HTTPMethod: "GET", URL: "/api/user", ParamFields: []string{"id", "token"}},
+		}},
+		&scanner.ScanReport{
+			Items: []scanner.SensitiveItem{
+				{RuleID: "phone", Content: "13800000000", FilePath: "renamed.js", Confidence: "medium"},
+				{RuleID: "aliyun_ak", Content: "LTAI0000000000000000", Confidence: "high"},
+			},
+			// 新版本使用 -suppress 扫描：已忽略的发现仍然存在，不是删除
+			SuppressedItems: []scanner.SensitiveItem{
+				{RuleID: "email", Content: "ops@example.com", Confidence: "low", Status: scanner.StatusSuppressed},
+			},
+			APIEndpoints: []scanner.APIEndpoint{{Method: "POST", RawURL: "/api/pay/create"}},
+		},
+	)
+
+	oldSnapshot, err := Load(oldDir, "v1")
+	if err != nil {
+		t.Fatalf("读取旧版本失败: %v", err)
+	}
+	newSnapshot, err := Load(newDir, "v2")
+	if err != nil {
+		t.Fatalf("读取新版本失败: %v", err)
+	}
+	report := Compare(oldSnapshot, newSnapshot)
+
+	if report.AppID != "wx123" || report.Old.Label != "v1" || report.New.Label != "v2" {
+		t.Fatalf("报告元信息错误: %+v %+v %+v", report.AppID, report.Old, report.New)
+	}
+	if len(report.Pages.Added) != 1 || report.Pages.Added[0] != "pages/pay/pay" {
+		t.Fatalf("新增页面错误: %#v", report.Pages.Added)
+	}
+	if len(report.Pages.Removed) != 1 || report.Pages.Removed[0] != "pages/legacy/legacy" {
+		t.Fatalf("删除页面错误: %#v", report.Pages.Removed)
+	}
+	if len(report.Components) != 1 || len(report.Components[0].AddedComponents) != 1 {
+		t.Fatalf("组件依赖变化错误: %#v", report.Components)
+	}
+
+	if len(report.APIEndpoints.Added) != 1 || report.APIEndpoints.Added[0].Key != "POST /api/pay/create" {
+		t.Fatalf("新增接口错误: %#v", report.APIEndpoints.Added)
+	}
+	if len(report.APIEndpoints.Removed) != 1 || report.APIEndpoints.Removed[0].Key != "POST /api/login/v1" {
+		t.Fatalf("删除接口错误: %#v", report.APIEndpoints.Removed)
+	}
+	if len(report.APIEndpoints.Changed) != 1 || report.APIEndpoints.Changed[0].AddedParams[0] != "token" {
+		t.Fatalf("接口参数变化错误: %#v", report.APIEndpoints.Changed)
+	}
+
+	// 同一命中只是换了文件位置，不应算作新增
+	if len(report.Findings.Added) != 1 || report.Findings.Added[0].RuleID != "aliyun_ak" {
+		t.Fatalf("新增敏感信息错误: %#v", report.Findings.Added)
+	}
+	if report.Summary.AddedHighRisk != 1 || len(report.Findings.Removed) != 0 {
+		t.Fatalf("敏感信息统计错误: %+v", report.Summary)
+	}
+
+	if len(report.NavigationEdges.Added) != 1 || report.NavigationEdges.Added[0].Target != "pages/pay/pay" {
+		t.Fatalf("新增跳转边错误: %#v", report.NavigationEdges.Added)
+	}
+	if len(report.NavigationEdges.Removed) != 1 {
+		t.Fatalf("删除跳转边错误: %#v", report.NavigationEdges.Removed)
+	}
+	if !report.HasChanges() || len(report.Notes) != 0 {
+		t.Fatalf("差异状态错误: changes=%v notes=%#v", report.HasChanges(), report.Notes)
+	}
+}
+
+func TestCompareNotesMissingScanReport(t *testing.T) {
+	manifest := &analyzer.RouteManifest{Pages: []analyzer.PageNode{{Route: "pages/index/index"}}}
+	oldSnapshot, err := Load(writeSnapshot(t, manifest, nil, nil), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newSnapshot, err := Load(writeSnapshot(t, manifest, nil, &scanner.ScanReport{}), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := Compare(oldSnapshot, newSnapshot)
+	if report.HasChanges() {
+		t.Fatalf("相同路由不应产生差异: %+v", report.Summary)
+	}
+	if len(report.Notes) != 1 || report.Old.HasScan || !report.New.HasScan {
+		t.Fatalf("缺少扫描报告时应给出说明: %#v", report.Notes)
+	}
+}
@@ -0,0 +1,358 @@
+package versionstore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// storeDirName 版本库位于输出目录的 .gwxapkg 下，解包、扫描与回包都会跳过该目录
+	storeDirName    = ".gwxapkg/versions"
+	objectsDirName  = "objects"
+	packagesDirName = "packages"
+	metaFileName    = "meta.json"
+	hashPrefixLen   = 12
+)
+
+// ErrVersionNotFound 按选择器找不到版本
+var ErrVersionNotFound = errors.New("未找到匹配的版本")
+
+var unsafeVersionChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// PackageFile 版本中的单个包文件，内容按 SHA-256 存放在 objects 下
+type PackageFile struct {
+	Name    string `json:"name"`
+	SHA256  string `json:"sha256"`
+	Size    int64  `json:"size"`
+	ModTime string `json:"mod_time,omitempty"`
+}
+
+// Version 一组内容完全相同的包文件
+type Version struct {
+	ID             string        `json:"id"`
+	Sequence       int           `json:"sequence"`
+	AppID          string        `json:"appid"`
+	LocatorVersion string        `json:"locator_version,omitempty"`
+	WccVersion     string        `json:"wcc_version,omitempty"`
+	Hash           string        `json:"hash"`
+	SourcePath     string        `json:"source_path,omitempty"`
+	FirstSeen      string        `json:"first_seen"`
+	LastSeen       string        `json:"last_seen"`
+	SeenCount      int           `json:"seen_count"`
+	TotalSize      int64         `json:"total_size"`
+	Packages       []PackageFile `json:"packages"`
+
+	// Dir 版本目录，不写入 meta.json
+	Dir string `json:"-"`
+}
+
+// PackageDir 版本内包文件所在目录，可直接作为解包输入
+func (v *Version) PackageDir() string {
+	return filepath.Join(v.Dir, packagesDirName)
+}
+
+// AddInput 归档一组包文件所需的信息
+type AddInput struct {
+	AppID          string
+	LocatorVersion string // 微信缓存目录中的版本号
+	WccVersion     string
+	SourcePath     string // 包文件所在目录，用于计算相对路径
+	Files          []string
+}
+
+// Store 单个 AppID 输出目录下的版本库
+type Store struct {
+	Root string
+}
+
+// Open 打开 outputDir 下的版本库，目录在首次写入时创建
+func Open(outputDir string) *Store {
+	return &Store{Root: filepath.Join(outputDir, filepath.FromSlash(storeDirName))}
+}
+
+// Add 归档一组包文件。内容与已有版本完全一致时只刷新 LastSeen，返回的 created 为 false。
+func (s *Store) Add(input AddInput) (*Version, bool, error) {
+	if len(input.Files) == 0 {
+		return nil, false, fmt.Errorf("没有可归档的包文件")
+	}
+
+	packages := make([]PackageFile, 0, len(input.Files))
+	seen := make(map[string]bool, len(input.Files))
+	var totalSize int64
+	for _, file := range input.Files {
+		name := packageName(input.SourcePath, file)
+		if seen[name] {
+			return nil, false, fmt.Errorf("包文件名重复: %s", name)
+		}
+		sum, size, err := s.storeObject(file)
+		if err != nil {
+			return nil, false, err
+		}
+		pkg := PackageFile{Name: name, SHA256: sum, Size: size}
+		if info, err := os.Stat(file); err == nil {
+			pkg.ModTime = info.ModTime().Format(time.RFC3339)
+		}
+		packages = append(packages, pkg)
+		seen[name] = true
+		totalSize += size
+	}
+	sort.Slice(packages, func(i, j int) bool { return packages[i].Name < packages[j].Name })
+
+	hash := setHash(packages)
+	now := time.Now().Format(time.RFC3339)
+
+	versions, err := s.List()
+	if err != nil {
+		return nil, false, err
+	}
+	sequence := 0
+	for _, existing := range versions {
+		sequence = max(sequence, existing.Sequence)
+		if existing.Hash != hash {
+			continue
+		}
+		existing.LastSeen = now
+		existing.SeenCount++
+		if existing.WccVersion == "" {
+			existing.WccVersion = input.WccVersion
+		}
+		return existing, false, writeMeta(existing)
+	}
+
+	version := &Version{
+		ID:             versionID(input.LocatorVersion, hash),
+		Sequence:       sequence + 1,
+		AppID:          input.AppID,
+		LocatorVersion: input.LocatorVersion,
+		WccVersion:     input.WccVersion,
+		Hash:           hash,
+		SourcePath:     input.SourcePath,
+		FirstSeen:      now,
+		LastSeen:       now,
+		SeenCount:      1,
+		TotalSize:      totalSize,
+		Packages:       packages,
+	}
+	version.Dir = filepath.Join(s.Root, version.ID)
+
+	for _, pkg := range packages {
+		target := filepath.Join(version.PackageDir(), filepath.FromSlash(pkg.Name))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return nil, false, fmt.Errorf("创建版本目录失败: %w", err)
+		}
+		if err := linkOrCopy(s.objectPath(pkg.SHA256), target); err != nil {
+			return nil, false, err
+		}
+	}
+	if err := writeMeta(version); err != nil {
+		return nil, false, err
+	}
+	return version, true, nil
+}
+
+// List 按归档先后升序列出全部版本
+func (s *Store) List() ([]*Version, error) {
+	entries, err := os.ReadDir(s.Root)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取版本库失败: %w", err)
+	}
+
+	var versions []*Version
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == objectsDirName {
+			continue
+		}
+		dir := filepath.Join(s.Root, entry.Name())
+		data, err := os.ReadFile(filepath.Join(dir, metaFileName))
+		if err != nil {
+			continue
+		}
+		var version Version
+		if err := json.Unmarshal(data, &version); err != nil {
+			return nil, fmt.Errorf("解析 %s 失败: %w", filepath.Join(dir, metaFileName), err)
+		}
+		version.Dir = dir
+		versions = append(versions, &version)
+	}
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].Sequence < versions[j].Sequence
+	})
+	return versions, nil
+}
+
+// Resolve 按选择器查找版本，支持：
+//   - latest / latest~N   最新版本及其之前第 N 个
+//   - 完整版本 ID
+//   - 微信缓存版本号（多个时取最新）
+//   - 版本 ID 或内容哈希的唯一前缀
+func (s *Store) Resolve(selector string) (*Version, error) {
+	versions, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("%w: 版本库为空 (%s)", ErrVersionNotFound, s.Root)
+	}
+
+	selector = strings.TrimSpace(selector)
+	if selector == "" || selector == "latest" {
+		return versions[len(versions)-1], nil
+	}
+	if rest, ok := strings.CutPrefix(selector, "latest~"); ok {
+		offset, err := strconv.Atoi(rest)
+		if err != nil || offset < 0 {
+			return nil, fmt.Errorf("无效的版本选择器: %s", selector)
+		}
+		if offset >= len(versions) {
+			return nil, fmt.Errorf("%w: %s（共 %d 个版本）", ErrVersionNotFound, selector, len(versions))
+		}
+		return versions[len(versions)-1-offset], nil
+	}
+
+	for _, version := range versions {
+		if version.ID == selector {
+			return version, nil
+		}
+	}
+
+	for i := len(versions) - 1; i >= 0; i-- {
+		if versions[i].LocatorVersion == selector {
+			return versions[i], nil
+		}
+	}
+
+	var matched []*Version
+	for _, version := range versions {
+		if strings.HasPrefix(version.ID, selector) || strings.HasPrefix(version.Hash, selector) {
+			matched = append(matched, version)
+		}
+	}
+	if len(matched) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrVersionNotFound, selector)
+	}
+	if len(matched) > 1 {
+		return nil, fmt.Errorf("版本选择器 %s 匹配到 %d 个版本，请使用更长的前缀", selector, len(matched))
+	}
+	return matched[0], nil
+}
+
+func (s *Store) objectPath(sum string) string {
+	return filepath.Join(s.Root, objectsDirName, sum[:2], sum)
+}
+
+// storeObject 把文件按内容哈希写入 objects，已存在时直接复用
+func (s *Store) storeObject(file string) (string, int64, error) {
+	src, err := os.Open(file)
+	if err != nil {
+		return "", 0, fmt.Errorf("读取包文件失败: %w", err)
+	}
+	defer src.Close()
+
+	objectsDir := filepath.Join(s.Root, objectsDirName)
+	if err := os.MkdirAll(objectsDir, 0755); err != nil {
+		return "", 0, fmt.Errorf("创建版本库失败: %w", err)
+	}
+	tmp, err := os.CreateTemp(objectsDir, "incoming-*")
+	if err != nil {
+		return "", 0, fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hasher), src)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", 0, fmt.Errorf("写入版本库失败: %w", err)
+	}
+
+	sum := hex.EncodeToString(hasher.Sum(nil))
+	target := s.objectPath(sum)
+	if _, err := os.Stat(target); err == nil {
+		return sum, size, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return "", 0, fmt.Errorf("创建版本库失败: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return "", 0, fmt.Errorf("写入版本库失败: %w", err)
+	}
+	return sum, size, nil
+}
+
+// linkOrCopy 优先硬链接到对象文件，跨设备等情况退回复制
+func linkOrCopy(src, dst string) error {
+	if err := os.Link(src, dst); err == nil {
+		return nil
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("读取版本库对象失败: %w", err)
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("写入版本目录失败: %w", err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("写入版本目录失败: %w", err)
+	}
+	return out.Close()
+}
+
+func writeMeta(version *Version) error {
+	if err := os.MkdirAll(version.Dir, 0755); err != nil {
+		return fmt.Errorf("创建版本目录失败: %w", err)
+	}
+	data, err := json.MarshalIndent(version, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(version.Dir, metaFileName), data, 0644); err != nil {
+		return fmt.Errorf("写入版本元数据失败: %w", err)
+	}
+	return nil
+}
+
+// packageName 包文件相对于来源目录的路径，无法计算时退回文件名
+func packageName(sourcePath, file string) string {
+	if sourcePath != "" {
+		if rel, err := filepath.Rel(sourcePath, file); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.Base(file)
+}
+
+// setHash 由包名与内容哈希计算整组文件的指纹，与归档顺序无关
+func setHash(packages []PackageFile) string {
+	hasher := sha256.New()
+	for _, pkg := range packages {
+		fmt.Fprintf(hasher, "%s\x00%s\n", pkg.Name, pkg.SHA256)
+	}
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+func versionID(locatorVersion, hash string) string {
+	prefix := strings.Trim(unsafeVersionChars.ReplaceAllString(locatorVersion, "_"), "_")
+	if prefix == "" {
+		prefix = "local"
+	}
+	return prefix + "-" + hash[:hashPrefixLen]
+}
@@ -0,0 +1,138 @@
+package versionstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writePackages(t *testing.T, dir string, files map[string]string) []string {
+	t.Helper()
+	var paths []string
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func countObjects(t *testing.T, store *Store) int {
+	t.Helper()
+	count := 0
+	err := filepath.WalkDir(filepath.Join(store.Root, objectsDirName), func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestAddDeduplicatesVersionsAndObjects(t *testing.T) {
+	store := Open(t.TempDir())
+
+	v1Dir := filepath.Join(t.TempDir(), "45")
+	v1Files := writePackages(t, v1Dir, map[string]string{
+		"__APP__.wxapkg":    "main-v1",
+		"pkgA/sub.wxapkg":   "shared-sub",
+		"__PLUGINCODE__.wx": "ignored-name",
+	})
+	first, created, err := store.Add(AddInput{AppID: "wx123", LocatorVersion: "45", SourcePath: v1Dir, Files: v1Files})
+	if err != nil || !created {
+		t.Fatalf("首次归档失败: created=%v err=%v", created, err)
+	}
+	if first.Sequence != 1 || first.ID[:3] != "45-" || len(first.Packages) != 3 {
+		t.Fatalf("版本信息错误: %+v", first)
+	}
+
+	// 相同内容再次归档只刷新出现记录
+	again, created, err := store.Add(AddInput{AppID: "wx123", LocatorVersion: "45", SourcePath: v1Dir, Files: v1Files})
+	if err != nil || created || again.ID != first.ID || again.SeenCount != 2 {
+		t.Fatalf("重复归档应复用版本: created=%v %+v err=%v", created, again, err)
+	}
+
+	v2Dir := filepath.Join(t.TempDir(), "46")
+	v2Files := writePackages(t, v2Dir, map[string]string{
+		"__APP__.wxapkg":    "main-v2",
+		"pkgA/sub.wxapkg":   "shared-sub",
+		"__PLUGINCODE__.wx": "ignored-name",
+	})
+	second, created, err := store.Add(AddInput{AppID: "wx123", LocatorVersion: "46", SourcePath: v2Dir, Files: v2Files})
+	if err != nil || !created || second.Sequence != 2 {
+		t.Fatalf("新版本归档失败: created=%v %+v err=%v", created, second, err)
+	}
+
+	// 两个版本共 6 个包文件，其中 2 个内容相同
+	if got := countObjects(t, store); got != 4 {
+		t.Fatalf("对象应按内容去重: got %d", got)
+	}
+
+	content, err := os.ReadFile(filepath.Join(second.PackageDir(), "pkgA", "sub.wxapkg"))
+	if err != nil || string(content) != "shared-sub" {
+		t.Fatalf("版本目录应保留原始目录结构: %q %v", content, err)
+	}
+
+	versions, err := store.List()
+	if err != nil || len(versions) != 2 || versions[0].ID != first.ID || versions[1].ID != second.ID {
+		t.Fatalf("版本列表错误: %v %+v", err, versions)
+	}
+}
+
+func TestResolveSelectors(t *testing.T) {
+	store := Open(t.TempDir())
+	source := t.TempDir()
+
+	var ids []string
+	for i, content := range []string{"a", "b", "c"} {
+		files := writePackages(t, source, map[string]string{"__APP__.wxapkg": content})
+		locatorVersion := "45"
+		if i == 2 {
+			locatorVersion = "46"
+		}
+		version, _, err := store.Add(AddInput{AppID: "wx123", LocatorVersion: locatorVersion, SourcePath: source, Files: files})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, version.ID)
+	}
+
+	cases := map[string]string{
+		"":         ids[2],
+		"latest":   ids[2],
+		"latest~1": ids[1],
+		"latest~2": ids[0],
+		ids[0]:     ids[0],
+		"45":       ids[1],
+		"46":       ids[2],
+		ids[0][:8]: ids[0],
+	}
+	for selector, want := range cases {
+		version, err := store.Resolve(selector)
+		if err != nil {
+			t.Fatalf("解析 %q 失败: %v", selector, err)
+		}
+		if version.ID != want {
+			t.Fatalf("解析 %q: got %s want %s", selector, version.ID, want)
+		}
+	}
+
+	if _, err := store.Resolve("latest~3"); !errors.Is(err, ErrVersionNotFound) {
+		t.Fatalf("越界选择器应返回 ErrVersionNotFound: %v", err)
+	}
+	if _, err := store.Resolve("45-"); err == nil {
+		t.Fatalf("有歧义的前缀应报错")
+	}
+}
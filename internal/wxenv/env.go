@@ -0,0 +1,418 @@
+package wxenv
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+)
+
+// DefaultTimeout 单次模块加载、函数调用或页面运行的默认时限
+const DefaultTimeout = 2 * time.Second
+
+// flushLimit 单轮最多执行的排队回调数，防止回调里反复 setTimeout 形成死循环
+const flushLimit = 1000
+
+// ErrTimeout 执行超时
+var ErrTimeout = errors.New("执行超时")
+
+// Options 运行环境配置。
+type Options struct {
+	// Storage wx.getStorageSync 读取的本地缓存
+	Storage map[string]interface{}
+	// Timeout 为 0 时使用 DefaultTimeout
+	Timeout time.Duration
+}
+
+// Env 是加载了 Shim 的 goja 运行环境，require 限定在 rootDir 内。
+// Env 不是并发安全的，同一时刻只能由一个 goroutine 使用。
+type Env struct {
+	vm       *goja.Runtime
+	rootDir  string
+	timeout  time.Duration
+	modules  map[string]*goja.Object
+	pageDefs map[string]int
+	stub     goja.Value
+}
+
+// Request 是运行期间捕获到的一次 wx.request / uploadFile / downloadFile 调用。
+type Request struct {
+	API    string                 `json:"api,omitempty"`
+	URL    string                 `json:"url"`
+	Method string                 `json:"method,omitempty"`
+	Header map[string]interface{} `json:"header,omitempty"`
+	Data   interface{}            `json:"data,omitempty"`
+	Page   string                 `json:"page,omitempty"`
+}
+
+// StorageWrite 是运行期间的一次 wx.setStorage(Sync) 写入。
+type StorageWrite struct {
+	Key   string      `json:"key"`
+	Value interface{} `json:"value"`
+	Page  string      `json:"page,omitempty"`
+}
+
+// New 创建运行环境。rootDir 为已还原的小程序目录。
+func New(rootDir string, options Options) (*Env, error) {
+	rootAbs, err := filepath.Abs(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	timeout := options.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	env := &Env{
+		vm:       goja.New(),
+		rootDir:  rootAbs,
+		timeout:  timeout,
+		modules:  make(map[string]*goja.Object),
+		pageDefs: make(map[string]int),
+	}
+	if _, err := env.vm.RunString(Shim); err != nil {
+		return nil, fmt.Errorf("初始化小程序运行环境失败: %w", err)
+	}
+	if options.Storage != nil {
+		storage, err := env.fromJSON(options.Storage)
+		if err != nil {
+			return nil, err
+		}
+		env.vm.Set("__gwxStorage", storage)
+	}
+	env.stub = env.vm.Get("__gwxStub")
+	return env, nil
+}
+
+// Runtime 返回底层 goja 运行时，供调用方注入额外的全局对象
+func (e *Env) Runtime() *goja.Runtime {
+	return e.vm
+}
+
+// Resolve 按小程序 require 语义解析模块路径：先相对当前文件，再相对根目录与 miniprogram_npm，
+// 可省略 .js / .json 或指向目录下的 index.js；越出根目录的路径一律拒绝
+func (e *Env) Resolve(current, literal string) (string, bool) {
+	literal = strings.TrimSpace(filepath.ToSlash(literal))
+	if literal == "" {
+		return "", false
+	}
+	var bases []string
+	if strings.HasPrefix(literal, "/") {
+		bases = append(bases, path.Clean(strings.TrimPrefix(literal, "/")))
+	} else {
+		bases = append(bases, path.Join(path.Dir(current), literal))
+		if !strings.HasPrefix(literal, ".") {
+			bases = append(bases, path.Clean(literal), path.Join("miniprogram_npm", literal))
+		}
+	}
+	for _, base := range bases {
+		if base == ".." || strings.HasPrefix(base, "../") {
+			return "", false
+		}
+		for _, candidate := range []string{base, base + ".js", base + ".json", path.Join(base, "index.js")} {
+			info, err := os.Stat(filepath.Join(e.rootDir, filepath.FromSlash(candidate)))
+			if err == nil && !info.IsDir() {
+				return candidate, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Require 加载模块并返回 module.exports；relPath 相对 rootDir
+func (e *Env) Require(relPath string) (goja.Value, error) {
+	target, ok := e.Resolve("", relPath)
+	if !ok {
+		return nil, fmt.Errorf("模块不存在或越出输出目录: %s", relPath)
+	}
+	var exports goja.Value
+	err := e.run(func() error {
+		var err error
+		exports, err = e.load(target)
+		return err
+	})
+	return exports, err
+}
+
+// Call 调用 exports 上的函数，name 支持 a.b 形式的属性路径，this 指向所属对象。
+// 调用后执行排队的回调；返回 Promise 时取其结果。
+func (e *Env) Call(exports goja.Value, name string, args ...interface{}) (goja.Value, error) {
+	var value goja.Value
+	err := e.run(func() error {
+		receiver := exports
+		target := exports
+		for _, part := range strings.Split(name, ".") {
+			if target == nil || goja.IsUndefined(target) || goja.IsNull(target) {
+				return fmt.Errorf("导出中不存在 %s", name)
+			}
+			receiver = target
+			target = target.ToObject(e.vm).Get(part)
+		}
+		fn, ok := goja.AssertFunction(target)
+		if !ok {
+			return fmt.Errorf("%s 不是函数", name)
+		}
+		values := make([]goja.Value, 0, len(args))
+		for _, arg := range args {
+			values = append(values, e.vm.ToValue(arg))
+		}
+		var err error
+		if value, err = fn(receiver, values...); err != nil {
+			return err
+		}
+		return e.flush()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return settlePromise(value)
+}
+
+// LoadApp 加载 app.js 并触发 onLaunch / onShow；没有 app.js 时什么也不做
+func (e *Env) LoadApp() error {
+	if _, ok := e.Resolve("", "app.js"); !ok {
+		return nil
+	}
+	if _, err := e.Require("app.js"); err != nil {
+		return err
+	}
+	return e.run(func() error {
+		if err := e.callGlobal("__gwxRunApp"); err != nil {
+			return err
+		}
+		return e.flush()
+	})
+}
+
+// RunPage 加载页面脚本，以 query 为参数依次触发 onLoad / onShow / onReady（组件式页面还会触发
+// created / attached / ready），并执行期间排队的回调。生命周期内的异常记录在 Errors 中而不中断运行。
+func (e *Env) RunPage(route string, query map[string]string) error {
+	route = strings.TrimSuffix(strings.TrimPrefix(filepath.ToSlash(route), "/"), ".js")
+	index, ok := e.pageDefs[route]
+	if !ok {
+		before, err := e.pageDefCount()
+		if err != nil {
+			return err
+		}
+		if _, err := e.Require(route + ".js"); err != nil {
+			return err
+		}
+		after, err := e.pageDefCount()
+		if err != nil {
+			return err
+		}
+		if after == before {
+			return fmt.Errorf("%s 未调用 Page 或 Component", route)
+		}
+		index = after - 1
+		e.pageDefs[route] = index
+	}
+
+	if query == nil {
+		query = map[string]string{}
+	}
+	queryValue, err := e.fromJSON(query)
+	if err != nil {
+		return err
+	}
+	return e.run(func() error {
+		if err := e.callGlobal("__gwxRunPage", route, index, queryValue); err != nil {
+			return err
+		}
+		return e.flush()
+	})
+}
+
+// Requests 返回到目前为止捕获的全部请求
+func (e *Env) Requests() ([]Request, error) {
+	var requests []Request
+	err := e.exportJSON("__gwxRequests", &requests)
+	return requests, err
+}
+
+// StorageWrites 返回到目前为止的全部缓存写入
+func (e *Env) StorageWrites() ([]StorageWrite, error) {
+	var writes []StorageWrite
+	err := e.exportJSON("__gwxStorageWrites", &writes)
+	return writes, err
+}
+
+// Logs 返回 console 输出
+func (e *Env) Logs() []string {
+	var logs []string
+	_ = e.vm.ExportTo(e.vm.Get("__gwxLogs"), &logs)
+	return logs
+}
+
+// Errors 返回生命周期与回调中被吞掉的异常
+func (e *Env) Errors() []string {
+	var errs []string
+	_ = e.vm.ExportTo(e.vm.Get("__gwxErrors"), &errs)
+	return errs
+}
+
+// Export 把 JS 值按 JSON 语义转换为 Go 值；无法序列化的值（函数等）返回其字符串形式
+func (e *Env) Export(value goja.Value) (interface{}, error) {
+	if value == nil || goja.IsUndefined(value) {
+		return nil, nil
+	}
+	stringify, _ := goja.AssertFunction(e.vm.Get("JSON").ToObject(e.vm).Get("stringify"))
+	encoded, err := stringify(goja.Undefined(), value)
+	if err != nil {
+		return nil, fmt.Errorf("返回值无法序列化: %w", err)
+	}
+	if goja.IsUndefined(encoded) {
+		return value.String(), nil
+	}
+	var result interface{}
+	if err := json.Unmarshal([]byte(encoded.String()), &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// load 以 CommonJS 方式执行模块，并缓存 module 对象以支持循环引用
+func (e *Env) load(relPath string) (goja.Value, error) {
+	if module, ok := e.modules[relPath]; ok {
+		return module.Get("exports"), nil
+	}
+	data, err := os.ReadFile(filepath.Join(e.rootDir, filepath.FromSlash(relPath)))
+	if err != nil {
+		return nil, err
+	}
+	module := e.vm.NewObject()
+	exports := e.vm.NewObject()
+	module.Set("exports", exports)
+	e.modules[relPath] = module
+
+	if strings.HasSuffix(relPath, ".json") {
+		var parsed interface{}
+		if err := json.Unmarshal(data, &parsed); err != nil {
+			return nil, fmt.Errorf("%s 不是合法 JSON: %w", relPath, err)
+		}
+		module.Set("exports", e.vm.ToValue(parsed))
+		return module.Get("exports"), nil
+	}
+
+	wrapper, err := e.vm.RunScript(relPath, "(function (module, exports, require) {\n"+string(data)+"\n})")
+	if err != nil {
+		return nil, err
+	}
+	factory, _ := goja.AssertFunction(wrapper)
+	if _, err := factory(exports, module, exports, e.requireFrom(relPath)); err != nil {
+		return nil, err
+	}
+	return module.Get("exports"), nil
+}
+
+// requireFrom 返回当前模块的 require；找不到的模块返回空桩，保证顶层代码能继续执行
+func (e *Env) requireFrom(current string) goja.Value {
+	return e.vm.ToValue(func(call goja.FunctionCall) goja.Value {
+		target, ok := e.Resolve(current, call.Argument(0).String())
+		if !ok {
+			return e.stub
+		}
+		value, err := e.load(target)
+		if err != nil {
+			panic(e.vm.NewGoError(err))
+		}
+		return value
+	})
+}
+
+// flush 反复执行排队的回调，直到队列清空；每轮结束后 goja 会执行 Promise 微任务，可能产生新的回调
+func (e *Env) flush() error {
+	for round := 0; round < flushLimit; round++ {
+		value, err := e.callGlobalValue("__gwxFlush", flushLimit)
+		if err != nil {
+			return err
+		}
+		if value.ToInteger() == 0 {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (e *Env) pageDefCount() (int, error) {
+	defs := e.vm.Get("__gwxPageDefs")
+	if defs == nil {
+		return 0, errors.New("运行环境未初始化")
+	}
+	return int(defs.ToObject(e.vm).Get("length").ToInteger()), nil
+}
+
+func (e *Env) callGlobal(name string, args ...interface{}) error {
+	_, err := e.callGlobalValue(name, args...)
+	return err
+}
+
+func (e *Env) callGlobalValue(name string, args ...interface{}) (goja.Value, error) {
+	fn, ok := goja.AssertFunction(e.vm.Get(name))
+	if !ok {
+		return nil, fmt.Errorf("运行环境缺少 %s", name)
+	}
+	values := make([]goja.Value, 0, len(args))
+	for _, arg := range args {
+		if value, ok := arg.(goja.Value); ok {
+			values = append(values, value)
+			continue
+		}
+		values = append(values, e.vm.ToValue(arg))
+	}
+	return fn(goja.Undefined(), values...)
+}
+
+// fromJSON 把 Go 值转换为普通 JS 对象，避免 goja 对 Go map 的包装影响 JSON.stringify 与属性枚举
+func (e *Env) fromJSON(value interface{}) (goja.Value, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return e.callGlobalValue("__gwxParseJSON", string(data))
+}
+
+func (e *Env) exportJSON(name string, target interface{}) error {
+	stringify, _ := goja.AssertFunction(e.vm.Get("JSON").ToObject(e.vm).Get("stringify"))
+	encoded, err := stringify(goja.Undefined(), e.vm.Get(name))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(encoded.String()), target)
+}
+
+func (e *Env) run(fn func() error) error {
+	timer := time.AfterFunc(e.timeout, func() { e.vm.Interrupt(ErrTimeout) })
+	defer timer.Stop()
+	defer e.vm.ClearInterrupt()
+
+	err := fn()
+	var interrupted *goja.InterruptedError
+	if errors.As(err, &interrupted) {
+		return ErrTimeout
+	}
+	return err
+}
+
+// settlePromise 函数返回 Promise 时取出结果；微任务在调用返回前已由 goja 执行完毕
+func settlePromise(value goja.Value) (goja.Value, error) {
+	if value == nil {
+		return value, nil
+	}
+	promise, ok := value.Export().(*goja.Promise)
+	if !ok {
+		return value, nil
+	}
+	switch promise.State() {
+	case goja.PromiseStateFulfilled:
+		return promise.Result(), nil
+	case goja.PromiseStateRejected:
+		return nil, fmt.Errorf("Promise 被拒绝: %s", promise.Result().String())
+	}
+	return nil, errors.New("Promise 未完成，函数可能依赖真实网络或用户交互")
+}
@@ -0,0 +1,80 @@
+package wxenv
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTraceAppRecordsPageRequests(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "app.json", `{"pages":["pages/index/index","pages/broken/broken"],"subPackages":[{"root":"pkg","pages":["detail/detail"]}]}`)
+	writeFile(t, root, "app.js", `App({globalData:{host:"https://api.shop-wx.com.cn"},onLaunch:function(){wx.login({success:function(res){wx.setStorageSync("code",res.code)}})}});`)
+	writeFile(t, root, "utils/http.js", `var app=getApp();
+module.exports=function(path,data,method){return new Promise(function(resolve){wx.request({url:app.globalData.host+path,method:method,data:data,header:{token:wx.getStorageSync("token")},success:resolve})})};`)
+	writeFile(t, root, "pages/index/index.js", `var http=require("../../utils/http");
+Page({data:{list:[]},onLoad:function(query){var self=this;http("/goods/list",{id:query.id},"post").then(function(res){self.setData({"list[0]":res.statusCode});return http("/goods/detail",{id:self.data.list[0]})})}});`)
+	writeFile(t, root, "pages/broken/broken.js", `Page({onLoad:function(){wx.request({url:"/before"});throw new Error("boom")}});`)
+	writeFile(t, root, "pkg/detail/detail.js", `var paging=Behavior({methods:{load:function(){uni.request({url:"/pkg/page",data:{size:this.data.size}})}}});
+Component({behaviors:[paging],properties:{size:{type:Number,value:20}},lifetimes:{attached:function(){this.load()}}});`)
+
+	trace, err := TraceApp(root, TraceOptions{Query: map[string]string{"id": "7"}, Storage: map[string]interface{}{"token": "t-1"}})
+	if err != nil {
+		t.Fatalf("TraceApp 返回错误: %v", err)
+	}
+	if len(trace.Requests) != 4 {
+		t.Fatalf("应捕获 4 个请求: %#v", trace.Requests)
+	}
+	list := trace.Requests[0]
+	data, _ := list.Data.(map[string]interface{})
+	if list.URL != "https://api.shop-wx.com.cn/goods/list" || list.Method != "POST" || list.Header["token"] != "t-1" || data["id"] != "7" || list.Page != "pages/index/index" {
+		t.Fatalf("页面请求记录不正确: %#v", list)
+	}
+	detail := trace.Requests[1]
+	if detail.URL != "https://api.shop-wx.com.cn/goods/detail" || detail.Method != "GET" || detail.Data.(map[string]interface{})["id"] != float64(200) {
+		t.Fatalf("success 回调与 setData 后的请求不正确: %#v", detail)
+	}
+	if trace.Pages[1].RequestCount != 1 || len(trace.Errors) != 1 || trace.Errors[0] != "pages/broken/broken onLoad: boom" {
+		t.Fatalf("生命周期异常应记录而不中断: %#v %#v", trace.Pages, trace.Errors)
+	}
+	if trace.Pages[2].Route != "pkg/detail/detail" || trace.Requests[3].Data.(map[string]interface{})["size"] != float64(20) {
+		t.Fatalf("分包组件页面的 behaviors 与 properties 不正确: %#v", trace.Requests[3])
+	}
+	if len(trace.StorageWrites) != 1 || trace.StorageWrites[0].Key != "code" || trace.StorageWrites[0].Page != "app" {
+		t.Fatalf("缓存写入记录不正确: %#v", trace.StorageWrites)
+	}
+
+	endpoints := trace.APIEndpoints()
+	if endpoints[0].Body != `{"id":"7"}` || endpoints[0].Headers["token"] != "t-1" || endpoints[0].FilePath != "pages/index/index.js" {
+		t.Fatalf("POST 请求应转换为 JSON 请求体: %#v", endpoints[0])
+	}
+	if endpoints[1].RawURL != "https://api.shop-wx.com.cn/goods/detail?id=200" || endpoints[1].SourceRule != TraceSourceRule {
+		t.Fatalf("GET 请求的 data 应拼接为查询参数: %#v", endpoints[1])
+	}
+}
+
+func TestEnvRejectsModulesOutsideRoot(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "lib/index.js", `module.exports={ok:true};`)
+	env, err := New(root, Options{})
+	if err != nil {
+		t.Fatalf("New 返回错误: %v", err)
+	}
+	if target, ok := env.Resolve("pages/a/a.js", "lib"); !ok || target != "lib/index.js" {
+		t.Fatalf("应按根目录解析到 index.js: %q", target)
+	}
+	if _, ok := env.Resolve("pages/a/a.js", "../../../outside.js"); ok {
+		t.Fatalf("越出根目录的模块应被拒绝")
+	}
+}
+
+func writeFile(t *testing.T, root, relPath, content string) {
+	t.Helper()
+	target := filepath.Join(root, filepath.FromSlash(relPath))
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(target, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
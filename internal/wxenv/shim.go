@@ -0,0 +1,295 @@
+package wxenv
+
+// Shim 是小程序运行环境的桩脚本，可在 goja 与 Node 中直接执行：
+//   - wx.request / uploadFile / downloadFile 只记录 url、method、header、data 到 __gwxRequests，
+//     success / complete 回调以 200 空响应排入 __gwxTimers，由宿主调用 __gwxFlush 执行；
+//   - wx.setStorage(Sync) 写入 __gwxStorage 并记录到 __gwxStorageWrites，读取取自宿主注入的缓存；
+//   - Page / Component 登记到 __gwxPageDefs，由 __gwxRunPage 构造页面实例并依次触发生命周期；
+//   - 未实现的 wx / uni API 与无法加载的模块返回可任意链式调用的空桩。
+//
+// repro 复现脚本会原样内嵌本脚本，因此不能依赖宿主注入的函数。
+const Shim = `var __gwxRequests = [];
+var __gwxStorageWrites = [];
+var __gwxLogs = [];
+var __gwxErrors = [];
+var __gwxStorage = {};
+var __gwxTimers = [];
+var __gwxPageDefs = [];
+var __gwxPageStack = [];
+var __gwxCurrentRoute = "";
+var __gwxStub = (function () {
+  var stub;
+  var handler = {
+    get: function (target, key) {
+      if (key === Symbol.toPrimitive || key === "toString" || key === "valueOf") {
+        return function () { return ""; };
+      }
+      if (key === "then") {
+        return undefined;
+      }
+      return stub;
+    },
+    apply: function () { return stub; },
+    construct: function () { return stub; }
+  };
+  stub = new Proxy(function () {}, handler);
+  return stub;
+})();
+
+function __gwxClone(value) {
+  if (value === undefined || typeof value === "function") {
+    return undefined;
+  }
+  try {
+    return JSON.parse(JSON.stringify(value));
+  } catch (e) {
+    return String(value);
+  }
+}
+
+function __gwxParseJSON(text) {
+  return JSON.parse(text);
+}
+
+function __gwxError(label, e) {
+  var message = e && e.message ? e.message : String(e);
+  __gwxErrors.push((__gwxCurrentRoute ? __gwxCurrentRoute + " " : "") + label + ": " + message);
+}
+
+function __gwxDefer(fn, args) {
+  if (typeof fn === "function") {
+    __gwxTimers.push({ fn: fn, args: args || [], route: __gwxCurrentRoute });
+  }
+  return __gwxTimers.length;
+}
+
+// __gwxFlush 执行排队的回调与定时器，返回本轮执行的数量
+function __gwxFlush(limit) {
+  var count = 0;
+  while (__gwxTimers.length > 0 && count < limit) {
+    var task = __gwxTimers.shift();
+    count++;
+    __gwxCurrentRoute = task.route;
+    try {
+      task.fn.apply(null, task.args);
+    } catch (e) {
+      __gwxError("callback", e);
+    }
+  }
+  return count;
+}
+
+// __gwxAsync 模拟异步 API：传入回调时排队执行，否则按新版基础库返回 Promise
+function __gwxAsync(options, result) {
+  options = options || {};
+  if (typeof options.success !== "function" && typeof options.fail !== "function" && typeof options.complete !== "function") {
+    return Promise.resolve(result);
+  }
+  __gwxDefer(options.success, [result]);
+  __gwxDefer(options.complete, [result]);
+}
+
+function __gwxCapture(api, defaultMethod) {
+  return function (options) {
+    options = options || {};
+    var record = {
+      api: api,
+      url: String(options.url || ""),
+      method: String(options.method || defaultMethod).toUpperCase(),
+      header: __gwxClone(options.header || options.headers) || {},
+      page: __gwxCurrentRoute
+    };
+    var data = api === "uploadFile" ? options.formData : options.data;
+    if (data !== undefined) {
+      record.data = __gwxClone(data);
+    }
+    __gwxRequests.push(record);
+    var response = { statusCode: 200, data: {}, header: {}, errMsg: api + ":ok" };
+    __gwxDefer(options.success, [response]);
+    __gwxDefer(options.complete, [response]);
+    return { abort: function () {}, onProgressUpdate: function () {}, onHeadersReceived: function () {}, offHeadersReceived: function () {} };
+  };
+}
+
+function __gwxSetStorage(key, value) {
+  __gwxStorage[key] = value;
+  __gwxStorageWrites.push({ key: String(key), value: __gwxClone(value), page: __gwxCurrentRoute });
+}
+
+function __gwxGetStorage(key) {
+  return key in __gwxStorage ? __gwxStorage[key] : "";
+}
+
+var __gwxSystemInfo = { brand: "devtools", model: "iPhone 14", platform: "devtools", system: "iOS 16.0", version: "8.0.40", SDKVersion: "3.0.0", language: "zh_CN", pixelRatio: 2, screenWidth: 375, screenHeight: 812, windowWidth: 375, windowHeight: 812, statusBarHeight: 44 };
+
+var wx = new Proxy({
+  request: __gwxCapture("request", "GET"),
+  uploadFile: __gwxCapture("uploadFile", "POST"),
+  downloadFile: __gwxCapture("downloadFile", "GET"),
+  getStorageSync: __gwxGetStorage,
+  setStorageSync: __gwxSetStorage,
+  removeStorageSync: function (key) { delete __gwxStorage[key]; },
+  clearStorageSync: function () { __gwxStorage = {}; },
+  getStorage: function (options) {
+    options = options || {};
+    return __gwxAsync(options, { data: __gwxGetStorage(options.key), errMsg: "getStorage:ok" });
+  },
+  setStorage: function (options) {
+    options = options || {};
+    __gwxSetStorage(options.key, options.data);
+    return __gwxAsync(options, { errMsg: "setStorage:ok" });
+  },
+  getSystemInfoSync: function () { return __gwxClone(__gwxSystemInfo); },
+  getSystemInfo: function (options) { return __gwxAsync(options, __gwxClone(__gwxSystemInfo)); },
+  getAccountInfoSync: function () { return { miniProgram: { appId: "", envVersion: "release", version: "" } }; },
+  getLaunchOptionsSync: function () { return { path: __gwxCurrentRoute, query: {}, scene: 1001 }; },
+  getEnterOptionsSync: function () { return { path: __gwxCurrentRoute, query: {}, scene: 1001 }; },
+  login: function (options) { return __gwxAsync(options, { code: "gwx-mock-code", errMsg: "login:ok" }); },
+  checkSession: function (options) { return __gwxAsync(options, { errMsg: "checkSession:ok" }); },
+  getSetting: function (options) { return __gwxAsync(options, { authSetting: {}, errMsg: "getSetting:ok" }); },
+  getNetworkType: function (options) { return __gwxAsync(options, { networkType: "wifi", errMsg: "getNetworkType:ok" }); },
+  showModal: function (options) { return __gwxAsync(options, { confirm: true, cancel: false, errMsg: "showModal:ok" }); },
+  canIUse: function () { return true; }
+}, {
+  get: function (target, key) { return key in target ? target[key] : __gwxStub; }
+});
+var uni = wx;
+
+if (typeof globalThis.console === "undefined") {
+  globalThis.console = (function () {
+    function record() {
+      var parts = [];
+      for (var i = 0; i < arguments.length; i++) {
+        var value = arguments[i];
+        parts.push(typeof value === "string" ? value : JSON.stringify(value));
+      }
+      __gwxLogs.push(parts.join(" "));
+    }
+    return { log: record, info: record, warn: record, error: record, debug: record };
+  })();
+}
+if (typeof globalThis.setTimeout === "undefined") {
+  globalThis.setTimeout = function (fn) { return __gwxDefer(fn, Array.prototype.slice.call(arguments, 2)); };
+  globalThis.setInterval = function (fn) { return __gwxDefer(fn, Array.prototype.slice.call(arguments, 2)); };
+  globalThis.clearTimeout = function () {};
+  globalThis.clearInterval = function () {};
+}
+
+var __gwxApp = { globalData: {} };
+function getApp() { return __gwxApp; }
+function getCurrentPages() { return __gwxPageStack.slice(); }
+function App(options) {
+  __gwxApp = options || {};
+  if (!__gwxApp.globalData) {
+    __gwxApp.globalData = {};
+  }
+}
+function Page(options) { __gwxPageDefs.push({ kind: "page", options: options || {} }); }
+function Component(options) { __gwxPageDefs.push({ kind: "component", options: options || {} }); }
+function Behavior(options) { return options || {}; }
+
+function __gwxInvoke(self, fn, args, label) {
+  if (typeof fn !== "function") {
+    return;
+  }
+  try {
+    var result = fn.apply(self, args || []);
+    if (result && typeof result.then === "function") {
+      var route = __gwxCurrentRoute;
+      result.then(null, function (e) {
+        __gwxCurrentRoute = route;
+        __gwxError(label, e);
+      });
+    }
+  } catch (e) {
+    __gwxError(label, e);
+  }
+}
+
+function __gwxSetPath(target, path, value) {
+  var keys = String(path).replace(/\[(\d+)\]/g, ".$1").split(".");
+  var current = target;
+  for (var i = 0; i < keys.length - 1; i++) {
+    if (current[keys[i]] === null || typeof current[keys[i]] !== "object") {
+      current[keys[i]] = /^\d+$/.test(keys[i + 1]) ? [] : {};
+    }
+    current = current[keys[i]];
+  }
+  current[keys[keys.length - 1]] = value;
+}
+
+function __gwxRunApp() {
+  var launch = { path: "", query: {}, scene: 1001 };
+  __gwxCurrentRoute = "app";
+  __gwxInvoke(__gwxApp, __gwxApp.onLaunch, [launch], "onLaunch");
+  __gwxInvoke(__gwxApp, __gwxApp.onShow, [launch], "onShow");
+}
+
+// __gwxRunPage 以 __gwxPageDefs[index] 构造页面实例，依次触发组件与页面生命周期
+function __gwxRunPage(route, index, query) {
+  var def = __gwxPageDefs[index];
+  var options = def.options;
+  __gwxCurrentRoute = route;
+
+  var page = { route: route, __route__: route, options: query, data: {} };
+  var sources = [];
+  var behaviors = options.behaviors || [];
+  for (var i = 0; i < behaviors.length; i++) {
+    if (behaviors[i] && typeof behaviors[i] === "object") {
+      sources.push(behaviors[i]);
+    }
+  }
+  sources.push(options);
+  for (var s = 0; s < sources.length; s++) {
+    var source = sources[s];
+    var data = typeof source.data === "function" ? source.data() : source.data;
+    var cloned = __gwxClone(data) || {};
+    for (var key in cloned) {
+      page.data[key] = cloned[key];
+    }
+    var properties = source.properties || {};
+    for (var name in properties) {
+      var property = properties[name];
+      if (!(name in page.data)) {
+        page.data[name] = property && typeof property === "object" && "value" in property ? __gwxClone(property.value) : null;
+      }
+    }
+    var methods = def.kind === "component" ? (source.methods || {}) : source;
+    for (var method in methods) {
+      if (typeof methods[method] === "function") {
+        page[method] = methods[method];
+      }
+    }
+  }
+  page.properties = page.data;
+  page.setData = function (patch, callback) {
+    for (var path in patch || {}) {
+      __gwxSetPath(this.data, path, patch[path]);
+    }
+    __gwxDefer(callback, []);
+  };
+  page.triggerEvent = function () {};
+  page.selectComponent = function () { return __gwxStub; };
+  page.selectAllComponents = function () { return []; };
+  page.createSelectorQuery = function () { return __gwxStub; };
+  page.getOpenerEventChannel = function () { return __gwxStub; };
+  __gwxPageStack = [page];
+
+  if (def.kind === "component") {
+    var lifetimes = options.lifetimes || {};
+    var pageLifetimes = options.pageLifetimes || {};
+    __gwxInvoke(page, lifetimes.created || options.created, [], "created");
+    __gwxInvoke(page, lifetimes.attached || options.attached, [], "attached");
+    __gwxInvoke(page, page.onLoad, [query], "onLoad");
+    __gwxInvoke(page, pageLifetimes.show, [], "pageLifetimes.show");
+    __gwxInvoke(page, page.onShow, [], "onShow");
+    __gwxInvoke(page, lifetimes.ready || options.ready, [], "ready");
+    __gwxInvoke(page, page.onReady, [], "onReady");
+    return;
+  }
+  __gwxInvoke(page, page.onLoad, [query], "onLoad");
+  __gwxInvoke(page, page.onShow, [], "onShow");
+  __gwxInvoke(page, page.onReady, [], "onReady");
+}
+
+`
@@ -0,0 +1,268 @@
+package wxenv
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/25smoking/Gwxapkg/internal/scanner"
+)
+
+// TraceFileName 请求追踪文件名，写在输出目录的 .gwxapkg 下
+const TraceFileName = "request_trace.json"
+
+// TraceSourceRule 追踪得到的接口在扫描报告中的来源标记
+const TraceSourceRule = "runtime_trace"
+
+// Trace 是一次页面运行追踪的结果。
+type Trace struct {
+	GeneratedAt   string         `json:"generated_at"`
+	Pages         []PageResult   `json:"pages"`
+	Requests      []Request      `json:"requests"`
+	StorageWrites []StorageWrite `json:"storage_writes,omitempty"`
+	Logs          []string       `json:"logs,omitempty"`
+	Errors        []string       `json:"errors,omitempty"`
+}
+
+// PageResult 单个页面的运行结果；Error 为页面脚本无法加载或运行超时的原因。
+type PageResult struct {
+	Route        string `json:"route"`
+	RequestCount int    `json:"request_count"`
+	Error        string `json:"error,omitempty"`
+}
+
+// TraceOptions 追踪配置。
+type TraceOptions struct {
+	// Pages 要运行的页面路由，为空时取 app.json 中的全部页面（含分包）
+	Pages []string
+	// Query 传给每个页面 onLoad 的参数
+	Query map[string]string
+	// Storage wx.getStorageSync 读取的本地缓存（token 等）
+	Storage map[string]interface{}
+	// Timeout 单个页面的运行时限，为 0 时使用 DefaultTimeout
+	Timeout time.Duration
+}
+
+type appConfig struct {
+	Pages       []string           `json:"pages"`
+	SubPackages []subPackageConfig `json:"subPackages"`
+	Subpackages []subPackageConfig `json:"subpackages"`
+}
+
+type subPackageConfig struct {
+	Root  string   `json:"root"`
+	Pages []string `json:"pages"`
+}
+
+// TraceApp 在同一个运行环境中先执行 app.js，再依次运行页面的 onLoad / onShow / onReady，
+// 记录期间发出的全部请求与缓存写入。单个页面失败不影响其他页面。
+func TraceApp(rootDir string, options TraceOptions) (*Trace, error) {
+	pages := options.Pages
+	if len(pages) == 0 {
+		var err error
+		if pages, err = AppPages(rootDir); err != nil {
+			return nil, err
+		}
+	}
+	env, err := New(rootDir, Options{Storage: options.Storage, Timeout: options.Timeout})
+	if err != nil {
+		return nil, err
+	}
+
+	trace := &Trace{
+		GeneratedAt: time.Now().Format("2006-01-02 15:04:05"),
+		Pages:       make([]PageResult, 0, len(pages)),
+	}
+	var loadErrors []string
+	if err := env.LoadApp(); err != nil {
+		loadErrors = append(loadErrors, "app: "+err.Error())
+	}
+	for _, route := range pages {
+		result := PageResult{Route: normalizeRoute(route)}
+		if err := env.RunPage(result.Route, options.Query); err != nil {
+			result.Error = err.Error()
+		}
+		trace.Pages = append(trace.Pages, result)
+	}
+
+	if trace.Requests, err = env.Requests(); err != nil {
+		return nil, err
+	}
+	if trace.StorageWrites, err = env.StorageWrites(); err != nil {
+		return nil, err
+	}
+	trace.Logs = env.Logs()
+	trace.Errors = append(loadErrors, env.Errors()...)
+
+	counts := make(map[string]int)
+	for _, request := range trace.Requests {
+		counts[request.Page]++
+	}
+	for i := range trace.Pages {
+		trace.Pages[i].RequestCount = counts[trace.Pages[i].Route]
+	}
+	return trace, nil
+}
+
+// AppPages 返回 app.json 中声明的主包与分包页面路由
+func AppPages(rootDir string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(rootDir, "app.json"))
+	if err != nil {
+		return nil, fmt.Errorf("读取 app.json 失败: %w", err)
+	}
+	var config appConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("解析 app.json 失败: %w", err)
+	}
+
+	seen := make(map[string]struct{})
+	pages := make([]string, 0, len(config.Pages))
+	add := func(route string) {
+		route = normalizeRoute(route)
+		if _, ok := seen[route]; ok || route == "" {
+			return
+		}
+		seen[route] = struct{}{}
+		pages = append(pages, route)
+	}
+	for _, page := range config.Pages {
+		add(page)
+	}
+	for _, sub := range append(config.SubPackages, config.Subpackages...) {
+		for _, page := range sub.Pages {
+			add(path.Join(sub.Root, page))
+		}
+	}
+	return pages, nil
+}
+
+// WriteTrace 把追踪结果写入 filename
+func WriteTrace(filename string, trace *Trace) error {
+	data, err := json.MarshalIndent(trace, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+// LoadTrace 读取 WriteTrace 写出的追踪文件
+func LoadTrace(filename string) (*Trace, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var trace Trace
+	if err := json.Unmarshal(data, &trace); err != nil {
+		return nil, fmt.Errorf("解析请求追踪失败: %w", err)
+	}
+	return &trace, nil
+}
+
+// APIEndpoints 把追踪到的请求转换为扫描报告中的接口，供 Postman 导出使用：
+// GET 请求的 data 拼接为查询参数，其他方法的 data 作为 JSON 请求体
+func (t *Trace) APIEndpoints() []scanner.APIEndpoint {
+	endpoints := make([]scanner.APIEndpoint, 0, len(t.Requests))
+	for _, request := range t.Requests {
+		if request.URL == "" {
+			continue
+		}
+		method := strings.ToUpper(request.Method)
+		if method == "" {
+			method = "GET"
+		}
+		rawURL := request.URL
+		body := ""
+		if request.Data != nil {
+			if method == "GET" || method == "HEAD" {
+				rawURL = appendQuery(rawURL, request.Data)
+			} else if encoded, err := json.Marshal(request.Data); err == nil {
+				body = string(encoded)
+			}
+		}
+
+		filePath := request.Page
+		if filePath != "" && filePath != "app" {
+			filePath += ".js"
+		} else if filePath == "app" {
+			filePath = "app.js"
+		}
+		endpoints = append(endpoints, scanner.APIEndpoint{
+			Name:       scanner.APIName(method, rawURL),
+			Method:     method,
+			RawURL:     rawURL,
+			FilePath:   filePath,
+			SourceRule: TraceSourceRule,
+			Context:    fmt.Sprintf("wx.%s @ %s", emptyAs(request.API, "request"), emptyAs(request.Page, "-")),
+			Headers:    stringifyHeader(request.Header),
+			Body:       body,
+		})
+	}
+	return endpoints
+}
+
+func appendQuery(rawURL string, data interface{}) string {
+	fields, ok := data.(map[string]interface{})
+	if !ok || len(fields) == 0 {
+		return rawURL
+	}
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, url.QueryEscape(key)+"="+url.QueryEscape(scalarString(fields[key])))
+	}
+	separator := "?"
+	if strings.Contains(rawURL, "?") {
+		separator = "&"
+	}
+	return rawURL + separator + strings.Join(parts, "&")
+}
+
+func stringifyHeader(header map[string]interface{}) map[string]string {
+	if len(header) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(header))
+	for key, value := range header {
+		result[key] = scalarString(value)
+	}
+	return result
+}
+
+func scalarString(value interface{}) string {
+	switch typed := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return typed
+	case map[string]interface{}, []interface{}:
+		encoded, _ := json.Marshal(typed)
+		return string(encoded)
+	}
+	return fmt.Sprint(value)
+}
+
+func normalizeRoute(route string) string {
+	route = strings.TrimSpace(filepath.ToSlash(route))
+	route = strings.TrimPrefix(route, "/")
+	return strings.TrimSuffix(route, ".js")
+}
+
+func emptyAs(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -19,6 +21,8 @@ import (
 	"github.com/25smoking/Gwxapkg/internal/semantic"
 	"github.com/25smoking/Gwxapkg/internal/ui"
 	"github.com/25smoking/Gwxapkg/internal/util"
+	"github.com/25smoking/Gwxapkg/internal/wxenv"
+	"github.com/25smoking/Gwxapkg/pkg/wxapkg"
 )
 
 func main() {
@@ -40,9 +44,45 @@ func main() {
 		case "api-link":
 			handleAPILinkCommand(os.Args[2:])
 			return
+		case "repro":
+			handleReproCommand(os.Args[2:])
+			return
+		case "eval":
+			handleEvalCommand(os.Args[2:])
+			return
+		case "trace":
+			handleTraceCommand(os.Args[2:])
+			return
+		case "proxy":
+			handleProxyCommand(os.Args[2:])
+			return
 		case "repack":
 			handleRepackCommand(os.Args[2:])
 			return
+		case "batch":
+			handleBatchCommand(os.Args[2:])
+			return
+		case "daemon":
+			handleDaemonCommand(os.Args[2:])
+			return
+		case "inspect":
+			handleInspectCommand(os.Args[2:])
+			return
+		case "diff":
+			handleDiffCommand(os.Args[2:])
+			return
+		case "history":
+			handleHistoryCommand(os.Args[2:])
+			return
+		case "baseline":
+			handleBaselineCommand(os.Args[2:])
+			return
+		case "rules":
+			handleRulesCommand(os.Args[2:])
+			return
+		case "names":
+			handleNamesCommand(os.Args[2:])
+			return
 		}
 	}
 
@@ -62,15 +102,26 @@ func handleAllCommand(args []string) {
 	appIDFile := allFlags.String("id-file", "", "AppID 列表文件路径（每行一个）")
 	allApps := allFlags.Bool("all", false, "处理所有已缓存的小程序")
 	verbose := allFlags.Bool("verbose", false, "显示扫描候选路径诊断")
+	root := allFlags.String("root", "", "在挂载的设备镜像或拷贝目录中查找缓存（Linux / Android / iOS）")
+	offline := allFlags.Bool("offline", defaultOffline(), "离线模式：不访问在线名称数据源，只使用包内元数据与本地名称库")
+	nameDB := allFlags.String("name-db", "", "本地名称库路径（默认 ~/.gwxapkg/app_names.json）")
 	outputDir := allFlags.String("out", "", "输出目录路径")
 	restoreDir := allFlags.Bool("restore", true, "是否还原工程目录结构")
 	pretty := allFlags.Bool("pretty", true, "是否美化输出")
 	noClean := allFlags.Bool("noClean", false, "是否保留中间文件")
 	save := allFlags.Bool("save", false, "是否保存解密后的文件")
 	sensitive := allFlags.Bool("sensitive", true, "是否获取敏感数据")
+	sarif := allFlags.Bool("sarif", false, "是否额外导出 SARIF 2.1.0 扫描报告")
+	baseline := allFlags.String("baseline", "", "基线文件或上一次的 sensitive_report.json，其中的发现标记为已知")
+	suppress := allFlags.String("suppress", "", "忽略规则文件（YAML）")
+	showAll := allFlags.Bool("show-all", false, "报告中保留已知与已忽略的发现")
 	postman := allFlags.Bool("postman", false, "是否导出 Postman Collection")
+	openAPI := allFlags.Bool("openapi", false, "是否导出 OpenAPI 3 文档")
+	trace := allFlags.Bool("trace", false, "是否在 wx 桩环境中运行页面并记录发出的请求")
 	workspace := allFlags.Bool("workspace", false, "是否保留可精确回包的工作区")
+	full := allFlags.Bool("full", false, "忽略上次的 manifest，全部重新解包与分析")
 	watch := allFlags.Bool("watch", false, "只监听缺失分包下载，不执行解包")
+	archive := allFlags.Bool("archive", false, "是否把当前缓存的包文件归档到版本库")
 	astRename := allFlags.String("ast-rename", semantic.ASTRenameModeDeep, "AST 重命名模式: off/report/safe/deep")
 	astDiff := allFlags.Bool("ast-diff", true, "是否生成 AST 重命名 diff 报告")
 	astPatch := allFlags.Bool("ast-patch", true, "是否生成 AST 重命名 patch")
@@ -79,29 +130,119 @@ func handleAllCommand(args []string) {
 
 	ui.Banner()
 
-	// 收集 AppID 列表
+	appIDs, programs, ok := collectAppIDs(*appID, *appIDFile, *allApps, buildScanOptions(*verbose, *root, *offline, *nameDB), "all")
+	if !ok {
+		return
+	}
+	triage, ok := internalcmd.LoadTriage(*baseline, *suppress, *showAll)
+	if !ok {
+		return
+	}
+	if *watch && len(appIDs) > 1 {
+		ui.Error("-watch 只支持单个 AppID，请使用 all -id=<AppID> -watch")
+		return
+	}
+
+	ui.Info("准备处理 %d 个小程序", len(appIDs))
+	fmt.Println()
+
+	// 扫描已缓存的小程序
+	if programs == nil {
+		var err error
+		programs, err = scanPrograms(buildScanOptions(*verbose, *root, *offline, *nameDB))
+		if err != nil {
+			ui.Error("扫描失败: %v", err)
+			return
+		}
+	}
+
+	// 建立 AppID -> MiniProgramInfo 映射
+	programMap := make(map[string]*locator.MiniProgramInfo)
+	for i := range programs {
+		programMap[programs[i].AppID] = &programs[i]
+	}
+
+	// 逐个处理
+	for i, id := range appIDs {
+		if len(appIDs) > 1 {
+			ui.PrintDivider()
+			ui.Step(i+1, len(appIDs), "处理: %s", id)
+		}
+
+		matched, ok := programMap[id]
+		if !ok {
+			ui.Error("未找到 AppID: %s，跳过", id)
+			continue
+		}
+
+		displayName := matched.AppID
+		if matched.AppName != "" {
+			displayName = matched.AppName + " (" + matched.AppID + ")"
+		}
+		ui.Success("找到小程序: %s （版本 %s, %d 个文件）", displayName, matched.Version, len(matched.Files))
+
+		resolvedOutputDir := *outputDir
+		if resolvedOutputDir == "" {
+			resolvedOutputDir = internalcmd.DetermineOutputDir(matched.Path, id)
+		}
+		var archived *locator.MiniProgramInfo
+		if *archive {
+			archived = matched
+			archivePackages(resolvedOutputDir, archived)
+		}
+		if *watch {
+			ui.Info("watch 模式只监听分包下载，不执行解包；需要合并源码时请退出后运行普通 scan 或 all")
+			report := buildWatchReport(id, matched.Path, resolvedOutputDir)
+			watchPackageDownloads(id, matched.Path, resolvedOutputDir, report, archived)
+			continue
+		}
+
+		options := wxapkg.DefaultOptions(id, matched.Input())
+		options.OutputDir = resolvedOutputDir
+		options.Restore = *restoreDir
+		options.Pretty = *pretty
+		options.NoClean = *noClean
+		options.Save = *save
+		options.Sensitive = *sensitive
+		options.SARIF = *sarif
+		options.Triage = triage
+		options.Postman = *postman
+		options.OpenAPI = *openAPI
+		options.Trace = *trace
+		options.Workspace = *workspace
+		options.Full = *full
+		options.Rewrite = buildRewriteOptions(*astRename, *astDiff, *astPatch)
+		cmd.ExecuteWithOptions(options)
+	}
+
+	ui.PrintDivider()
+	ui.Success("全部处理完成! (%d 个小程序)", len(appIDs))
+}
+
+// collectAppIDs 解析 -id / -id-file / --all 三种 AppID 来源；--all 模式会顺带返回扫描结果
+func collectAppIDs(appID, appIDFile string, allApps bool, scanOptions locator.ScanOptions, command string) ([]string, []locator.MiniProgramInfo, bool) {
 	var appIDs []string
 	var programs []locator.MiniProgramInfo
 
-	if *allApps {
+	if allApps {
 		// --all 模式：扫描所有已缓存小程序
 		ui.Info("正在扫描所有已缓存的小程序...")
-		ui.Info("名称优先从包内元数据提取；模板类运行时名称补查失败时将留空")
+		printNameLookupNotice(scanOptions.Offline)
 		var err error
-		programs, err = scanPrograms(*verbose)
+		programs, err = scanPrograms(scanOptions)
 		if err != nil {
 			ui.Error("扫描失败: %v", err)
-			return
+			return nil, nil, false
 		}
 		for _, p := range programs {
 			appIDs = append(appIDs, p.AppID)
 		}
-	} else if *appIDFile != "" {
+	} else if appIDFile != "" {
 		// 从文件读取 AppID
-		data, err := os.ReadFile(*appIDFile)
+		data, err := os.ReadFile(appIDFile)
 		if err != nil {
 			ui.Error("读取 AppID 文件失败: %v", err)
-			return
+			return nil, nil, false
 		}
 		for _, line := range strings.Split(string(data), "\n") {
 			line = strings.TrimSpace(line)
@@ -109,9 +250,9 @@ func handleAllCommand(args []string) {
 				appIDs = append(appIDs, line)
 			}
 		}
-	} else if *appID != "" {
+	} else if appID != "" {
 		// 逗号分隔或单个 AppID
-		for _, id := range strings.Split(*appID, ",") {
+		for _, id := range strings.Split(appID, ",") {
 			id = strings.TrimSpace(id)
 			if id != "" {
 				appIDs = append(appIDs, id)
@@ -120,40 +261,77 @@ func handleAllCommand(args []string) {
 	}
 
 	if len(appIDs) == 0 {
-		ui.Error("请指定 AppID: ./Gwxapkg all -id=<AppID>")
+		ui.Error("请指定 AppID: ./Gwxapkg %s -id=<AppID>", command)
 		ui.Info("或使用 -id-file=ids.txt 指定文件，或 --all 处理全部")
+		return nil, nil, false
+	}
+	return appIDs, programs, true
+}
+
+// handleBatchCommand 处理 batch 子命令：并发处理多个 AppID，每个 AppID 使用独立的运行上下文与输出目录
+func handleBatchCommand(args []string) {
+	batchFlags := flag.NewFlagSet("batch", flag.ExitOnError)
+	appID := batchFlags.String("id", "", "微信小程序的AppID，支持逗号分隔多个")
+	appIDFile := batchFlags.String("id-file", "", "AppID 列表文件路径（每行一个）")
+	allApps := batchFlags.Bool("all", false, "处理所有已缓存的小程序")
+	verbose := batchFlags.Bool("verbose", false, "显示扫描候选路径诊断")
+	root := batchFlags.String("root", "", "在挂载的设备镜像或拷贝目录中查找缓存（Linux / Android / iOS）")
+	offline := batchFlags.Bool("offline", true, "离线模式：不访问在线名称数据源，只使用包内元数据与本地名称库")
+	nameDB := batchFlags.String("name-db", "", "本地名称库路径（默认 ~/.gwxapkg/app_names.json）")
+	concurrency := batchFlags.Int("concurrency", 2, "同时处理的小程序数量")
+	outputDir := batchFlags.String("out", "", "输出根目录，每个 AppID 写入 <out>/<AppID>")
+	restoreDir := batchFlags.Bool("restore", true, "是否还原工程目录结构")
+	pretty := batchFlags.Bool("pretty", true, "是否美化输出")
+	noClean := batchFlags.Bool("noClean", false, "是否保留中间文件")
+	save := batchFlags.Bool("save", false, "是否保存解密后的文件")
+	sensitive := batchFlags.Bool("sensitive", true, "是否获取敏感数据")
+	sarif := batchFlags.Bool("sarif", false, "是否额外导出 SARIF 2.1.0 扫描报告")
+	baseline := batchFlags.String("baseline", "", "基线文件或上一次的 sensitive_report.json，其中的发现标记为已知")
+	suppress := batchFlags.String("suppress", "", "忽略规则文件（YAML）")
+	showAll := batchFlags.Bool("show-all", false, "报告中保留已知与已忽略的发现")
+	postman := batchFlags.Bool("postman", false, "是否导出 Postman Collection")
+	openAPI := batchFlags.Bool("openapi", false, "是否导出 OpenAPI 3 文档")
+	trace := batchFlags.Bool("trace", false, "是否在 wx 桩环境中运行页面并记录发出的请求")
+	workspace := batchFlags.Bool("workspace", false, "是否保留可精确回包的工作区")
+	full := batchFlags.Bool("full", false, "忽略上次的 manifest，全部重新解包与分析")
+	astRename := batchFlags.String("ast-rename", semantic.ASTRenameModeDeep, "AST 重命名模式: off/report/safe/deep")
+	astDiff := batchFlags.Bool("ast-diff", true, "是否生成 AST 重命名 diff 报告")
+	astPatch := batchFlags.Bool("ast-patch", true, "是否生成 AST 重命名 patch")
+
+	batchFlags.Parse(args)
+
+	ui.Banner()
+
+	appIDs, programs, ok := collectAppIDs(*appID, *appIDFile, *allApps, buildScanOptions(*verbose, *root, *offline, *nameDB), "batch")
+	if !ok {
 		return
 	}
-	if *watch && len(appIDs) > 1 {
-		ui.Error("-watch 只支持单个 AppID，请使用 all -id=<AppID> -watch")
+	triage, ok := internalcmd.LoadTriage(*baseline, *suppress, *showAll)
+	if !ok {
 		return
 	}
-
-	ui.Info("准备处理 %d 个小程序", len(appIDs))
-	fmt.Println()
-
-	// 扫描已缓存的小程序
 	if programs == nil {
 		var err error
-		programs, err = scanPrograms(*verbose)
+		programs, err = scanPrograms(buildScanOptions(*verbose, *root, *offline, *nameDB))
 		if err != nil {
 			ui.Error("扫描失败: %v", err)
 			return
 		}
 	}
 
-	// 建立 AppID -> MiniProgramInfo 映射
 	programMap := make(map[string]*locator.MiniProgramInfo)
 	for i := range programs {
 		programMap[programs[i].AppID] = &programs[i]
 	}
 
-	// 逐个处理
-	for i, id := range appIDs {
-		if len(appIDs) > 1 {
-			ui.PrintDivider()
-			ui.Step(i+1, len(appIDs), "处理: %s", id)
+	rewrite := buildRewriteOptions(*astRename, *astDiff, *astPatch)
+	seen := make(map[string]struct{})
+	var jobs []wxapkg.Options
+	for _, id := range appIDs {
+		if _, dup := seen[id]; dup {
+			continue
 		}
+		seen[id] = struct{}{}
 
 		matched, ok := programMap[id]
 		if !ok {
@@ -161,105 +339,267 @@ func handleAllCommand(args []string) {
 			continue
 		}
 
-		displayName := matched.AppID
-		if matched.AppName != "" {
-			displayName = matched.AppName + " (" + matched.AppID + ")"
+		options := wxapkg.DefaultOptions(id, matched.Input())
+		if *outputDir != "" {
+			options.OutputDir = filepath.Join(*outputDir, id)
 		}
-		ui.Success("找到小程序: %s （版本 %s, %d 个文件）", displayName, matched.Version, len(matched.Files))
+		options.Restore = *restoreDir
+		options.Pretty = *pretty
+		options.NoClean = *noClean
+		options.Save = *save
+		options.Sensitive = *sensitive
+		options.SARIF = *sarif
+		options.Triage = triage
+		options.Postman = *postman
+		options.OpenAPI = *openAPI
+		options.Trace = *trace
+		options.Workspace = *workspace
+		options.Full = *full
+		options.Rewrite = rewrite
+		jobs = append(jobs, options)
+	}
 
-		resolvedOutputDir := *outputDir
-		if resolvedOutputDir == "" {
-			resolvedOutputDir = internalcmd.DetermineOutputDir(matched.Path, id)
-		}
-		if *watch {
-			ui.Info("watch 模式只监听分包下载，不执行解包；需要合并源码时请退出后运行普通 scan 或 all")
-			report := buildWatchReport(id, matched.Path, resolvedOutputDir)
-			watchPackageDownloads(id, matched.Path, resolvedOutputDir, report)
-			continue
-		}
+	if len(jobs) == 0 {
+		ui.Error("没有可处理的小程序")
+		return
+	}
 
-		rewriteOptions := buildRewriteOptions(*astRename, *astDiff, *astPatch)
-		cmd.ExecuteWithOptions(id, matched.Path, resolvedOutputDir, ".wxapkg", *restoreDir, *pretty, *noClean, *save, *sensitive, *postman, *workspace, rewriteOptions)
+	if *restoreDir {
+		printASTRenameNotice(rewrite.ASTRename)
 	}
+	ui.Info("准备并发处理 %d 个小程序（并发数 %d）", len(jobs), *concurrency)
+	fmt.Println()
 
+	results := cmd.ExecuteBatch(jobs, *concurrency)
 	ui.PrintDivider()
-	ui.Success("全部处理完成! (%d 个小程序)", len(appIDs))
+	ui.Success("批量处理完成! (%d 个小程序)", len(results))
+}
+
+// handleDaemonCommand 处理 daemon 子命令：常驻监听所有缓存目录，新分包下载稳定后自动重新处理对应 AppID
+func handleDaemonCommand(args []string) {
+	daemonFlags := flag.NewFlagSet("daemon", flag.ExitOnError)
+	verbose := daemonFlags.Bool("verbose", false, "显示扫描候选路径诊断")
+	root := daemonFlags.String("root", "", "在挂载的设备镜像或拷贝目录中查找缓存（Linux / Android / iOS）")
+	offline := daemonFlags.Bool("offline", true, "离线模式：不访问在线名称数据源，只使用包内元数据与本地名称库")
+	nameDB := daemonFlags.String("name-db", "", "本地名称库路径（默认 ~/.gwxapkg/app_names.json）")
+	settle := daemonFlags.Duration("settle", 10*time.Second, "包集合保持不变多久后开始处理")
+	rescan := daemonFlags.Duration("rescan", time.Minute, "定期重新收集缓存目录并全量比对的间隔")
+	initial := daemonFlags.Bool("initial", false, "启动后先处理所有已缓存的小程序")
+	concurrency := daemonFlags.Int("concurrency", 2, "同时处理的小程序数量")
+	logPath := daemonFlags.String("log", "", "JSON Lines 事件日志路径（默认 <out>/daemon_events.jsonl 或 ~/.gwxapkg/daemon_events.jsonl）")
+	outputDir := daemonFlags.String("out", "", "输出根目录，每个 AppID 写入 <out>/<AppID>")
+	archive := daemonFlags.Bool("archive", false, "是否把每次处理的包文件归档到版本库")
+	restoreDir := daemonFlags.Bool("restore", true, "是否还原工程目录结构")
+	pretty := daemonFlags.Bool("pretty", true, "是否美化输出")
+	sensitive := daemonFlags.Bool("sensitive", true, "是否获取敏感数据")
+	sarif := daemonFlags.Bool("sarif", false, "是否额外导出 SARIF 2.1.0 扫描报告")
+	baseline := daemonFlags.String("baseline", "", "基线文件或上一次的 sensitive_report.json，其中的发现标记为已知")
+	suppress := daemonFlags.String("suppress", "", "忽略规则文件（YAML）")
+	showAll := daemonFlags.Bool("show-all", false, "报告中保留已知与已忽略的发现")
+	postman := daemonFlags.Bool("postman", false, "是否导出 Postman Collection")
+	openAPI := daemonFlags.Bool("openapi", false, "是否导出 OpenAPI 3 文档")
+	full := daemonFlags.Bool("full", false, "忽略上次的 manifest，全部重新解包与分析")
+	astRename := daemonFlags.String("ast-rename", semantic.ASTRenameModeDeep, "AST 重命名模式: off/report/safe/deep")
+	astDiff := daemonFlags.Bool("ast-diff", true, "是否生成 AST 重命名 diff 报告")
+	astPatch := daemonFlags.Bool("ast-patch", true, "是否生成 AST 重命名 patch")
+
+	daemonFlags.Parse(args)
+
+	ui.Banner()
+
+	triage, ok := internalcmd.LoadTriage(*baseline, *suppress, *showAll)
+	if !ok {
+		return
+	}
+
+	scanOptions := buildScanOptions(*verbose, *root, *offline, *nameDB)
+	printNameLookupNotice(scanOptions.Offline)
+
+	pipeline := wxapkg.DefaultOptions("", "")
+	pipeline.Restore = *restoreDir
+	pipeline.Pretty = *pretty
+	pipeline.Sensitive = *sensitive
+	pipeline.SARIF = *sarif
+	pipeline.Triage = triage
+	pipeline.Postman = *postman
+	pipeline.OpenAPI = *openAPI
+	pipeline.Full = *full
+	pipeline.Rewrite = buildRewriteOptions(*astRename, *astDiff, *astPatch)
+	if *restoreDir {
+		printASTRenameNotice(pipeline.Rewrite.ASTRename)
+	}
+
+	err := cmd.RunDaemon(cmd.DaemonOptions{
+		Scan:        scanOptions,
+		Settle:      *settle,
+		Rescan:      *rescan,
+		Initial:     *initial,
+		Concurrency: *concurrency,
+		Archive:     *archive,
+		OutputRoot:  *outputDir,
+		LogPath:     *logPath,
+		Pipeline:    pipeline,
+	})
+	if err != nil {
+		ui.Error("%v", err)
+		os.Exit(1)
+	}
 }
 
-// handleScanCommand 处理 scan 子命令（交互式选择解包）
+// handleScanCommand 处理 scan 子命令：默认交互式选择解包，-select 非交互处理，-json 只输出扫描结果
 func handleScanCommand(args []string) {
 	scanFlags := flag.NewFlagSet("scan", flag.ExitOnError)
 	verbose := scanFlags.Bool("verbose", false, "显示扫描候选路径诊断")
+	root := scanFlags.String("root", "", "在挂载的设备镜像或拷贝目录中查找缓存（Linux / Android / iOS）")
+	offline := scanFlags.Bool("offline", defaultOffline(), "离线模式：不访问在线名称数据源，只使用包内元数据与本地名称库")
+	nameDB := scanFlags.String("name-db", "", "本地名称库路径（默认 ~/.gwxapkg/app_names.json）")
+	jsonOutput := scanFlags.Bool("json", false, "以 JSON 输出扫描结果（含包大小、类型与加密状态），不解包")
+	nameFilter := scanFlags.String("name", "", "只保留名称或 AppID 包含该文本的小程序")
+	since := scanFlags.String("since", "", "只保留该时间之后更新的版本，例如 24h、7d、2024-06-01")
+	idPattern := scanFlags.String("id", "", "只保留 AppID 匹配该正则的小程序")
+	selectPrograms := scanFlags.String("select", "", "非交互选择：all、列表编号或 AppID，可逗号分隔")
 	postman := scanFlags.Bool("postman", false, "是否导出 Postman Collection")
+	openAPI := scanFlags.Bool("openapi", false, "是否导出 OpenAPI 3 文档")
+	trace := scanFlags.Bool("trace", false, "是否在 wx 桩环境中运行页面并记录发出的请求")
+	full := scanFlags.Bool("full", false, "忽略上次的 manifest，全部重新解包与分析")
 	watch := scanFlags.Bool("watch", false, "只监听缺失分包下载，不执行解包")
+	archive := scanFlags.Bool("archive", false, "是否把当前缓存的包文件归档到版本库")
 	astRename := scanFlags.String("ast-rename", semantic.ASTRenameModeDeep, "AST 重命名模式: off/report/safe/deep")
 	astDiff := scanFlags.Bool("ast-diff", true, "是否生成 AST 重命名 diff 报告")
 	astPatch := scanFlags.Bool("ast-patch", true, "是否生成 AST 重命名 patch")
 	scanFlags.Parse(args)
+	if *jsonOutput {
+		// stdout 只保留 JSON 数组，错误、名称库告警与 -verbose 诊断改写到 stderr
+		ui.SetOutput(os.Stderr)
+	}
 
-	ui.Banner()
-	ui.Info("正在扫描微信小程序目录...")
-	ui.Info("名称优先从包内元数据提取；模板类运行时名称补查失败时将留空")
-	fmt.Println()
+	filter, err := locator.NewProgramFilter(*nameFilter, *since, *idPattern, time.Now())
+	if err != nil {
+		ui.Error("%v", err)
+		os.Exit(2)
+	}
+
+	scanOptions := buildScanOptions(*verbose, *root, *offline, *nameDB)
+	if !*jsonOutput {
+		ui.Banner()
+		ui.Info("正在扫描微信小程序目录...")
+		printNameLookupNotice(scanOptions.Offline)
+		fmt.Println()
+	}
 
-	programs, err := scanPrograms(*verbose)
+	programs, err := scanPrograms(scanOptions)
 	if err != nil {
 		ui.Error("扫描失败: %v", err)
+		os.Exit(1)
+	}
+	programs = locator.FilterPrograms(programs, filter)
+
+	if *jsonOutput {
+		indices := make([]int, len(programs))
+		for i := range programs {
+			indices[i] = i
+		}
+		if *selectPrograms != "" && len(programs) > 0 {
+			if indices, err = locator.SelectPrograms(programs, *selectPrograms); err != nil {
+				ui.Error("%v", err)
+				os.Exit(2)
+			}
+		}
+		if err := internalcmd.PrintProgramsJSON(internalcmd.DescribePrograms(programs, indices)); err != nil {
+			ui.Error("%v", err)
+			os.Exit(1)
+		}
 		return
 	}
 
 	if len(programs) == 0 {
-		ui.Warning("未找到任何微信小程序缓存")
+		ui.Warning("未找到任何符合条件的微信小程序缓存")
 		return
 	}
 
-	ui.Success("找到 %d 个小程序", len(programs))
-	ui.PrintDivider()
-	fmt.Println()
+	var indices []int
+	if *selectPrograms != "" {
+		if indices, err = locator.SelectPrograms(programs, *selectPrograms); err != nil {
+			ui.Error("%v", err)
+			os.Exit(2)
+		}
+		ui.Success("找到 %d 个小程序，已选择 %d 个", len(programs), len(indices))
+	} else {
+		ui.Success("找到 %d 个小程序", len(programs))
+		ui.PrintDivider()
+		fmt.Println()
 
-	for i, p := range programs {
-		ui.PrintMiniProgramWithName(i+1, p.AppID, p.AppName, p.Version, p.UpdateTime, len(p.Files), p.Path)
-	}
+		for i, p := range programs {
+			ui.PrintMiniProgramWithName(i+1, p.AppID, p.AppName, p.Version, p.UpdateTime, len(p.Files), p.Path)
+		}
 
-	ui.PrintDivider()
+		ui.PrintDivider()
 
-	// 交互式选择
-	choice := ui.Prompt(len(programs))
-	if choice == -1 {
-		ui.Info("已退出")
+		// 交互式选择
+		choice := ui.Prompt(len(programs))
+		if choice == -1 {
+			ui.Info("已退出")
+			return
+		}
+		indices = []int{choice - 1}
+	}
+	if *watch && len(indices) > 1 {
+		ui.Error("-watch 只支持单个小程序，请用 -select=<编号或 AppID> 指定")
 		return
 	}
 
-	selected := programs[choice-1]
-	displayName := selected.AppID
-	if selected.AppName != "" {
-		displayName = selected.AppName + " (" + selected.AppID + ")"
-	}
-	ui.Success("已选择: %s", displayName)
-	fmt.Println()
+	for i, index := range indices {
+		selected := programs[index]
+		displayName := selected.AppID
+		if selected.AppName != "" {
+			displayName = selected.AppName + " (" + selected.AppID + ")"
+		}
+		if len(indices) > 1 {
+			ui.PrintDivider()
+			ui.Step(i+1, len(indices), "处理: %s", displayName)
+		} else {
+			ui.Success("已选择: %s", displayName)
+		}
+		fmt.Println()
 
-	outputDir := internalcmd.DetermineOutputDir(selected.Path, selected.AppID)
-	if *watch {
-		ui.Info("完整性报告读取目录: %s", outputDir)
-	} else {
-		ui.Info("解包结果将保存到: %s", outputDir)
-	}
-	fmt.Println()
+		outputDir := internalcmd.DetermineOutputDir(selected.Path, selected.AppID)
+		if *watch {
+			ui.Info("完整性报告读取目录: %s", outputDir)
+		} else {
+			ui.Info("解包结果将保存到: %s", outputDir)
+		}
+		fmt.Println()
 
-	if *watch {
-		ui.Info("watch 模式只监听分包下载，不执行解包；需要合并源码时请退出后运行普通 scan 或 all")
-		report := buildWatchReport(selected.AppID, selected.Path, outputDir)
-		watchPackageDownloads(selected.AppID, selected.Path, outputDir, report)
-		ui.PrintDivider()
-		ui.Success("watch 已结束")
-		return
-	}
+		var archived *locator.MiniProgramInfo
+		if *archive {
+			archived = &selected
+			archivePackages(outputDir, archived)
+		}
+		if *watch {
+			ui.Info("watch 模式只监听分包下载，不执行解包；需要合并源码时请退出后运行普通 scan 或 all")
+			report := buildWatchReport(selected.AppID, selected.Path, outputDir)
+			watchPackageDownloads(selected.AppID, selected.Path, outputDir, report, archived)
+			ui.PrintDivider()
+			ui.Success("watch 已结束")
+			return
+		}
 
-	// 直接进入解包流程（复用 all 命令的默认参数）
-	rewriteOptions := buildRewriteOptions(*astRename, *astDiff, *astPatch)
-	cmd.ExecuteWithOptions(selected.AppID, selected.Path, outputDir, ".wxapkg", true, true, false, false, true, *postman, false, rewriteOptions)
+		// 直接进入解包流程（复用 all 命令的默认参数）
+		options := wxapkg.DefaultOptions(selected.AppID, selected.Input())
+		options.OutputDir = outputDir
+		options.Postman = *postman
+		options.OpenAPI = *openAPI
+		options.Trace = *trace
+		options.Full = *full
+		options.Rewrite = buildRewriteOptions(*astRename, *astDiff, *astPatch)
+		cmd.ExecuteWithOptions(options)
+	}
 
 	ui.PrintDivider()
+	if len(indices) > 1 {
+		ui.Success("全部处理完成! (%d 个小程序)", len(indices))
+		return
+	}
 	ui.Success("处理完成!")
 }
 
@@ -275,7 +615,8 @@ func buildWatchReport(appID, inputDir, outputDir string) *packagecheck.Report {
 	return nil
 }
 
-func watchPackageDownloads(appID, inputDir, outputDir string, report *packagecheck.Report) {
+// watchPackageDownloads 轮询缓存目录；archived 不为空时每次捕获新包都会归档一个新版本
+func watchPackageDownloads(appID, inputDir, outputDir string, report *packagecheck.Report, archived *locator.MiniProgramInfo) {
 	if report.IsFull() {
 		ui.Success("分包已完整，无需进入 watch")
 		return
@@ -316,12 +657,30 @@ func watchPackageDownloads(appID, inputDir, outputDir string, report *packageche
 				ui.Success("捕获新 wxapkg: %s", file)
 			}
 			known = current
+			if archived != nil {
+				archived.Files = mapKeys(current)
+				archivePackages(outputDir, archived)
+			}
 			report = buildWatchReport(appID, inputDir, outputDir)
 			printWatchProgress(report, len(known))
 		}
 	}
 }
 
+// archivePackages 归档小程序当前缓存的包文件；失败不影响后续解包
+func archivePackages(outputDir string, program *locator.MiniProgramInfo) {
+	version, created, err := internalcmd.ArchiveVersion(outputDir, program.AppID, program.Version, program.Path, program.Files)
+	if err != nil {
+		ui.Warning("归档版本失败: %v", err)
+		return
+	}
+	if created {
+		ui.Success("已归档新版本: %s", version.ID)
+	} else {
+		ui.Info("包内容与已归档版本 %s 一致，未重复保存", version.ID)
+	}
+}
+
 func printWatchProgress(report *packagecheck.Report, cachedPackageCount int) {
 	ui.Info("   - 当前已缓存 wxapkg: %d", cachedPackageCount)
 	if report == nil || report.Status == packagecheck.StatusUnknown {
@@ -384,13 +743,48 @@ func mapKeys(values map[string]struct{}) []string {
 	return result
 }
 
-func scanPrograms(verbose bool) ([]locator.MiniProgramInfo, error) {
-	report, err := locator.ScanWithOptions(locator.ScanOptions{Verbose: verbose})
+// defaultOffline CI 环境（设置了 CI 环境变量）默认离线，避免向第三方泄露 AppID 或在隔离网络中卡住
+func defaultOffline() bool {
+	return os.Getenv("CI") != ""
+}
+
+// buildScanOptions 组装扫描参数并加载本地名称库；名称库不可用时只告警，不影响扫描
+func buildScanOptions(verbose bool, root string, offline bool, nameDB string) locator.ScanOptions {
+	options := locator.ScanOptions{Verbose: verbose, Root: root, Offline: offline}
+	path, err := internalcmd.ResolveNameDB(nameDB)
+	if err != nil {
+		ui.Warning("定位名称库失败，本次不使用: %v", err)
+		return options
+	}
+	cache, err := locator.LoadNameCache(path)
+	if err != nil {
+		ui.Warning("加载名称库失败，本次不使用: %v", err)
+		return options
+	}
+	options.NameCache = cache
+	return options
+}
+
+func printNameLookupNotice(offline bool) {
+	if offline {
+		ui.Info("离线模式：名称只从包内元数据与本地名称库获取，不访问在线数据源")
+		return
+	}
+	ui.Info("名称优先从包内元数据提取，其次查本地名称库与在线数据源（-offline 可关闭在线查询）")
+}
+
+func scanPrograms(options locator.ScanOptions) ([]locator.MiniProgramInfo, error) {
+	report, err := locator.ScanWithOptions(options)
 	if err != nil {
 		return nil, err
 	}
+	if options.NameCache != nil {
+		if err := options.NameCache.Save(); err != nil {
+			ui.Warning("%v", err)
+		}
+	}
 
-	if verbose {
+	if options.Verbose {
 		printScanDiagnostics(report.Diagnostics)
 	}
 
@@ -409,7 +803,7 @@ func printScanDiagnostics(diagnostics []locator.ScanDiagnostic) {
 	}
 
 	if len(diagnostics) > 0 {
-		fmt.Println()
+		fmt.Fprintln(ui.Output())
 	}
 }
 
@@ -428,9 +822,13 @@ func handleScanOnlyCommand(args []string) {
 	f := flag.NewFlagSet("scan-only", flag.ExitOnError)
 	dir := f.String("dir", "", "已解包的目录路径")
 	appID := f.String("id", "", "AppID（可选，用于报告标题）")
-	format := f.String("format", "both", "报告格式: json / excel / html / both")
+	format := f.String("format", "both", "报告格式: json / excel / html / sarif / both / all，可逗号分隔组合")
 	out := f.String("out", "", "报告输出目录（默认与 -dir 相同）")
 	postman := f.Bool("postman", false, "是否导出 Postman Collection")
+	openAPI := f.Bool("openapi", false, "是否导出 OpenAPI 3 文档")
+	baseline := f.String("baseline", "", "基线文件或上一次的 sensitive_report.json，其中的发现标记为已知")
+	suppress := f.String("suppress", "", "忽略规则文件（YAML）")
+	showAll := f.Bool("show-all", false, "报告中保留已知与已忽略的发现")
 	f.Parse(args)
 
 	ui.Banner()
@@ -444,7 +842,11 @@ func handleScanOnlyCommand(args []string) {
 		return
 	}
 
-	internalcmd.ScanOnly(*dir, *appID, *format, *out, *postman)
+	triage, ok := internalcmd.LoadTriage(*baseline, *suppress, *showAll)
+	if !ok {
+		return
+	}
+	internalcmd.ScanOnly(*dir, *appID, *format, *out, *postman, *openAPI, triage)
 }
 
 func handleSemanticCommand(args []string) {
@@ -514,6 +916,13 @@ func handleSemanticCommand(args []string) {
 		ui.Success("API 调用链: %s", filepath.Join(expandedDir, ".gwxapkg", "api_call_chain.md"))
 		ui.Success("API 伪代码: %s", filepath.Join(expandedDir, ".gwxapkg", "api_pseudo.md"))
 	}
+	if report.CryptoUsageCount > 0 {
+		ui.Success("加解密地图: %s", filepath.Join(expandedDir, ".gwxapkg", "crypto_map.md"))
+		ui.Info("   - 加解密调用: %d | 签名函数: %d",
+			report.CryptoUsageCount,
+			report.CryptoSignerCount,
+		)
+	}
 	if report.ASTRenamedCount > 0 {
 		ui.Success("AST 重命名报告: %s", filepath.Join(expandedDir, ".gwxapkg", "ast_rename_map.json"))
 		ui.Info("   - AST 重命名: %d | 文件数: %d",
@@ -527,6 +936,8 @@ func handleAPILinkCommand(args []string) {
 	f := flag.NewFlagSet("api-link", flag.ExitOnError)
 	dir := f.String("dir", "", "已解包目录路径")
 	burpFile := f.String("burp-file", "", "Burp 原始请求文件")
+	traffic := f.String("traffic", "", "代理历史文件：HAR、Burp Save items XML 或 mitmproxy flow，批量关联并生成覆盖报告")
+	format := f.String("format", semantic.TrafficFormatAuto, "代理历史格式: auto / har / burp-xml / mitmproxy / raw")
 	f.Parse(args)
 
 	ui.Banner()
@@ -535,7 +946,7 @@ func handleAPILinkCommand(args []string) {
 		*dir = f.Arg(0)
 	}
 	if *dir == "" {
-		ui.Error("请指定目录: ./Gwxapkg api-link -dir=<已解包目录> -burp-file=<raw_request.txt>")
+		ui.Error("请指定目录: ./Gwxapkg api-link -dir=<已解包目录> -burp-file=<raw_request.txt> | -traffic=<history.har>")
 		return
 	}
 
@@ -545,6 +956,11 @@ func handleAPILinkCommand(args []string) {
 		expandedDir = *dir
 	}
 
+	if *traffic != "" {
+		handleTrafficLink(expandedDir, *traffic, *format)
+		return
+	}
+
 	var raw []byte
 	if *burpFile != "" {
 		expandedFile, err := util.ExpandHomePath(*burpFile)
@@ -578,6 +994,424 @@ func handleAPILinkCommand(args []string) {
 	ui.Info("   - 匹配候选: %d", len(report.Matches))
 }
 
+// handleTrafficLink 批量关联代理历史文件并输出覆盖报告
+func handleTrafficLink(dir, trafficFile, format string) {
+	expandedFile, err := util.ExpandHomePath(trafficFile)
+	if err != nil {
+		ui.Warning("展开代理历史文件失败，继续使用原路径: %v", err)
+		expandedFile = trafficFile
+	}
+	data, err := os.ReadFile(expandedFile)
+	if err != nil {
+		ui.Error("读取代理历史失败: %v", err)
+		return
+	}
+
+	report, err := semantic.LinkTraffic(dir, data, format, expandedFile)
+	if err != nil {
+		ui.Error("代理历史关联失败: %v", err)
+		return
+	}
+	ui.Success("流量覆盖报告: %s", filepath.Join(dir, ".gwxapkg", "api_coverage.md"))
+	ui.Info("   - 格式: %s | 请求: %d | 关联到源码: %d | 无源码对应: %d 类",
+		report.Format,
+		report.TotalRequests,
+		report.MatchedRequests,
+		len(report.Unmatched),
+	)
+	ui.Info("   - 源码 API 覆盖: %d / %d", report.ExercisedCount, report.EndpointCount)
+}
+
+// handleReproCommand 处理 repro 子命令：为 api_map 中的接口生成离线签名复现脚本，并用 goja 试运行一次
+func handleReproCommand(args []string) {
+	f := flag.NewFlagSet("repro", flag.ExitOnError)
+	dir := f.String("dir", "", "已解包目录路径（需先执行 semantic）")
+	api := f.String("api", "", "接口函数：函数名、Controller.Method 或 file.js:function")
+	params := f.String("params", "{}", "试运行时的接口入参 JSON")
+	storage := f.String("storage", "{}", "试运行时 wx.getStorageSync 读取的本地缓存 JSON")
+	out := f.String("out", "", "输出目录（默认 <dir>/.gwxapkg/repro）")
+	f.Parse(args)
+
+	ui.Banner()
+
+	if *dir == "" && f.NArg() > 0 {
+		*dir = f.Arg(0)
+	}
+	if *dir == "" || *api == "" {
+		ui.Error("用法: ./Gwxapkg repro -dir=<已解包目录> -api=<Controller.Method> [-params='{...}']")
+		return
+	}
+	expandedDir, err := util.ExpandHomePath(*dir)
+	if err != nil {
+		ui.Warning("展开目录失败，继续使用原路径: %v", err)
+		expandedDir = *dir
+	}
+
+	report, err := semantic.BuildSigningRepro(expandedDir, semantic.ReproOptions{API: *api, OutputDir: *out})
+	if err != nil {
+		ui.Error("生成签名复现脚本失败: %v", err)
+		return
+	}
+	ui.Success("复现脚本: %s", report.ScriptPath)
+	ui.Success("goja 调用模板: %s", report.HarnessPath)
+	ui.Info("   - 闭包函数: %d | 打包模块: %d | 空桩模块: %d",
+		len(report.Functions),
+		len(report.Modules),
+		len(report.StubbedModules),
+	)
+
+	requests, err := semantic.RunSigningRepro(report.ScriptPath, *params, *storage)
+	if err != nil {
+		ui.Warning("试运行失败，可调整 -params / -storage 后重试: %v", err)
+		return
+	}
+	if len(requests) == 0 {
+		ui.Warning("试运行未捕获到 wx.request，入口函数可能依赖异步回调或未打包的模块")
+		return
+	}
+	for _, request := range requests {
+		method := request.Method
+		if method == "" {
+			method = "GET"
+		}
+		data, _ := json.Marshal(request.Data)
+		header, _ := json.Marshal(request.Header)
+		ui.Info("   %s %s", method, request.URL)
+		ui.Info("     header: %s", header)
+		ui.Info("     data:   %s", data)
+	}
+}
+
+// handleEvalCommand 处理 eval 子命令：在 goja 沙箱中加载已还原模块并调用导出函数，结果写到 stdout
+func handleEvalCommand(args []string) {
+	f := flag.NewFlagSet("eval", flag.ExitOnError)
+	dir := f.String("dir", "", "已解包目录路径，require 只能解析到该目录内")
+	module := f.String("module", "", "模块路径（相对 -dir），例如 utils/crypto.js")
+	function := f.String("func", "", "导出函数名，支持 a.b；为空时输出 module.exports")
+	callArgs := f.String("args", "[]", "调用参数 JSON 数组，非数组时作为单个参数")
+	storage := f.String("storage", "", "wx.getStorageSync 读取的本地缓存 JSON")
+	timeout := f.Duration("timeout", semantic.DefaultEvalTimeout, "执行时限（含模块加载）")
+	asJSON := f.Bool("json", false, "以 JSON 输出返回值、捕获的请求与 console 日志")
+	f.Parse(args)
+
+	if *dir == "" || *module == "" {
+		ui.Error("用法: ./Gwxapkg eval -dir=<已解包目录> -module=<模块> -func=<导出函数> -args='[...]'")
+		return
+	}
+	expandedDir, err := util.ExpandHomePath(*dir)
+	if err != nil {
+		expandedDir = *dir
+	}
+
+	result, err := semantic.EvalModule(expandedDir, semantic.EvalOptions{
+		Module:   *module,
+		Function: *function,
+		Args:     *callArgs,
+		Storage:  *storage,
+		Timeout:  *timeout,
+	})
+	if err != nil {
+		ui.Error("执行失败: %v", err)
+		os.Exit(1)
+	}
+
+	if *asJSON {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+	for _, line := range result.Logs {
+		ui.Info("console: %s", line)
+	}
+	for _, request := range result.Requests {
+		data, _ := json.Marshal(request.Data)
+		ui.Info("wx.request: %s %s %s", request.Method, request.URL, data)
+	}
+	if text, ok := result.Value.(string); ok {
+		fmt.Println(text)
+		return
+	}
+	data, _ := json.MarshalIndent(result.Value, "", "  ")
+	fmt.Println(string(data))
+}
+
+// handleTraceCommand 处理 trace 子命令：在 wx 桩环境中运行页面生命周期，记录发出的请求
+func handleTraceCommand(args []string) {
+	f := flag.NewFlagSet("trace", flag.ExitOnError)
+	dir := f.String("dir", "", "已解包目录路径（需包含 app.json）")
+	pages := f.String("pages", "", "要运行的页面路由，逗号分隔；为空时运行 app.json 中的全部页面")
+	query := f.String("query", "", "传给 onLoad 的参数，例如 id=1&type=2")
+	storage := f.String("storage", "", "wx.getStorageSync 读取的本地缓存 JSON")
+	timeout := f.Duration("timeout", wxenv.DefaultTimeout, "单个页面的执行时限")
+	postman := f.Bool("postman", false, "是否把追踪到的请求导出为 Postman Collection")
+	f.Parse(args)
+
+	ui.Banner()
+
+	if *dir == "" && f.NArg() > 0 {
+		*dir = f.Arg(0)
+	}
+	if *dir == "" {
+		ui.Error("用法: ./Gwxapkg trace -dir=<已解包目录> [-pages=pages/index/index] [-query='id=1']")
+		return
+	}
+	expandedDir, err := util.ExpandHomePath(*dir)
+	if err != nil {
+		ui.Warning("展开目录失败，继续使用原路径: %v", err)
+		expandedDir = *dir
+	}
+
+	options := wxenv.TraceOptions{Timeout: *timeout}
+	for _, page := range strings.Split(*pages, ",") {
+		if page = strings.TrimSpace(page); page != "" {
+			options.Pages = append(options.Pages, page)
+		}
+	}
+	if *query != "" {
+		values, err := url.ParseQuery(*query)
+		if err != nil {
+			ui.Error("query 格式错误: %v", err)
+			return
+		}
+		options.Query = make(map[string]string, len(values))
+		for key := range values {
+			options.Query[key] = values.Get(key)
+		}
+	}
+	if *storage != "" {
+		if err := json.Unmarshal([]byte(*storage), &options.Storage); err != nil {
+			ui.Error("storage 不是合法 JSON 对象: %v", err)
+			return
+		}
+	}
+	internalcmd.Trace(expandedDir, options, *postman)
+}
+
+// handleProxyCommand 处理 proxy 子命令：中间人代理实时关联请求与源码位置
+func handleProxyCommand(args []string) {
+	f := flag.NewFlagSet("proxy", flag.ExitOnError)
+	dir := f.String("dir", "", "已解包目录路径（需先生成 .gwxapkg/api_map.json）")
+	listen := f.String("listen", "127.0.0.1:8080", "代理监听地址")
+	caDir := f.String("ca-dir", "~/.gwxapkg/proxy", "根证书目录，不存在时自动生成")
+	insecure := f.Bool("insecure", false, "不校验上游服务器证书")
+	logPath := f.String("log", "", "把每条请求以 JSON Lines 追加写入该文件")
+	f.Parse(args)
+
+	ui.Banner()
+
+	if *dir == "" && f.NArg() > 0 {
+		*dir = f.Arg(0)
+	}
+	if *dir == "" {
+		ui.Error("用法: ./Gwxapkg proxy -dir=<已解包目录> [-listen=127.0.0.1:8080]")
+		return
+	}
+	expandedDir, err := util.ExpandHomePath(*dir)
+	if err != nil {
+		ui.Warning("展开目录失败，继续使用原路径: %v", err)
+		expandedDir = *dir
+	}
+	expandedCADir, err := util.ExpandHomePath(*caDir)
+	if err != nil {
+		ui.Error("展开证书目录失败: %v", err)
+		return
+	}
+
+	internalcmd.Proxy(internalcmd.ProxyOptions{
+		Dir:      expandedDir,
+		Listen:   *listen,
+		CADir:    expandedCADir,
+		Insecure: *insecure,
+		LogPath:  *logPath,
+	})
+}
+
+// handleInspectCommand 处理 inspect 子命令：只读索引，不做完整解包
+func handleInspectCommand(args []string) {
+	f := flag.NewFlagSet("inspect", flag.ExitOnError)
+	input := f.String("in", "", "wxapkg 文件或目录路径")
+	appID := f.String("id", "", "小程序 AppID（加密包必填）")
+	format := f.String("format", "table", "输出格式: table / json")
+	match := f.String("match", "", "包内路径 glob，逗号分隔，例如 pages/**/*.js,*.json")
+	extract := f.String("extract", "", "只提取匹配的条目到该目录")
+	raw := f.Bool("raw", false, "提取时原样写出，不做格式化")
+	f.Parse(args)
+
+	if *input == "" && f.NArg() > 0 {
+		*input = f.Arg(0)
+	}
+	if !strings.EqualFold(*format, "json") {
+		ui.Banner()
+	}
+	if *input == "" {
+		ui.Error("请指定输入: ./Gwxapkg inspect -in=<文件或目录> [-id=<AppID>]")
+		return
+	}
+
+	var patterns []string
+	for _, pattern := range strings.Split(*match, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+
+	internalcmd.Inspect(internalcmd.InspectOptions{
+		AppID:      *appID,
+		Input:      *input,
+		Format:     *format,
+		Patterns:   patterns,
+		ExtractDir: *extract,
+		Raw:        *raw,
+	})
+}
+
+// handleDiffCommand 处理 diff 子命令：对比同一小程序的两个版本
+func handleDiffCommand(args []string) {
+	f := flag.NewFlagSet("diff", flag.ExitOnError)
+	oldInput := f.String("old", "", "旧版本：已解包目录，或 wxapkg 文件/目录")
+	newInput := f.String("new", "", "新版本：已解包目录，或 wxapkg 文件/目录")
+	appID := f.String("id", "", "小程序 AppID（输入为 wxapkg 时必填）")
+	oldLabel := f.String("old-label", "", "旧版本在报告中的名称，默认取目录名")
+	newLabel := f.String("new-label", "", "新版本在报告中的名称，默认取目录名")
+	outputDir := f.String("out", "", "报告输出目录，默认 <new>/.gwxapkg")
+	storeDir := f.String("store", "", "版本库所在的输出目录，默认 output/<AppID>")
+	f.Parse(args)
+
+	ui.Banner()
+
+	if *oldInput == "" && f.NArg() > 0 {
+		*oldInput = f.Arg(0)
+	}
+	if *newInput == "" && f.NArg() > 1 {
+		*newInput = f.Arg(1)
+	}
+	if *oldInput == "" || *newInput == "" {
+		ui.Error("请指定两个版本: ./Gwxapkg diff -old=<旧版本> -new=<新版本> [-id=<AppID>]")
+		return
+	}
+
+	paths := []*string{oldInput, newInput}
+	for _, path := range paths {
+		expanded, err := util.ExpandHomePath(*path)
+		if err != nil {
+			ui.Warning("展开路径失败，继续使用原路径: %v", err)
+			continue
+		}
+		*path = expanded
+	}
+
+	if *storeDir == "" && *appID != "" {
+		*storeDir = internalcmd.DetermineOutputDir("", *appID)
+	}
+
+	ui.Info("版本对比: %s -> %s", *oldInput, *newInput)
+	cmd.ExecuteDiff(cmd.DiffOptions{
+		AppID:     *appID,
+		Old:       *oldInput,
+		New:       *newInput,
+		OldLabel:  *oldLabel,
+		NewLabel:  *newLabel,
+		OutputDir: *outputDir,
+		StoreDir:  *storeDir,
+	})
+}
+
+// handleHistoryCommand 处理 history 子命令：列出版本库，或按版本选择器对比两个版本
+func handleHistoryCommand(args []string) {
+	f := flag.NewFlagSet("history", flag.ExitOnError)
+	appID := f.String("id", "", "小程序 AppID")
+	outputDir := f.String("out", "", "输出目录路径，默认 output/<AppID>")
+	format := f.String("format", "table", "输出格式: table / json")
+	diff := f.String("diff", "", "对比两个版本，格式 <旧>,<新>，例如 latest~1,latest")
+	f.Parse(args)
+
+	if *appID == "" && f.NArg() > 0 {
+		*appID = f.Arg(0)
+	}
+	if *format != "json" {
+		ui.Banner()
+	}
+	if *appID == "" {
+		ui.Error("请指定 AppID: ./Gwxapkg history -id=<AppID>")
+		return
+	}
+	if *outputDir == "" {
+		*outputDir = internalcmd.DetermineOutputDir("", *appID)
+	}
+
+	if *diff == "" {
+		internalcmd.History(*outputDir, *format)
+		return
+	}
+
+	selectors := strings.Split(*diff, ",")
+	if len(selectors) != 2 {
+		ui.Error("-diff 需要两个版本选择器，例如 -diff=latest~1,latest")
+		return
+	}
+	cmd.ExecuteDiff(cmd.DiffOptions{
+		AppID:    *appID,
+		Old:      strings.TrimSpace(selectors[0]),
+		New:      strings.TrimSpace(selectors[1]),
+		StoreDir: *outputDir,
+	})
+}
+
+// handleBaselineCommand 处理 baseline 子命令：由扫描报告生成基线文件
+func handleBaselineCommand(args []string) {
+	f := flag.NewFlagSet("baseline", flag.ExitOnError)
+	report := f.String("report", "", "上一次生成的 sensitive_report.json")
+	out := f.String("out", "", "基线输出路径，默认与报告同目录的 sensitive_baseline.json")
+	f.Parse(args)
+
+	ui.Banner()
+
+	if *report == "" && f.NArg() > 0 {
+		*report = f.Arg(0)
+	}
+	if *report == "" {
+		ui.Error("请指定扫描报告: ./Gwxapkg baseline -report=<sensitive_report.json> [-out=<baseline.json>]")
+		return
+	}
+
+	internalcmd.WriteBaseline(*report, *out)
+}
+
+// handleRulesCommand 处理 rules 子命令，目前支持 rules test
+func handleRulesCommand(args []string) {
+	if len(args) == 0 || args[0] != "test" {
+		ui.Banner()
+		ui.Error("用法: ./Gwxapkg rules test [-dir=<规则包目录>] [规则包文件...]")
+		return
+	}
+
+	f := flag.NewFlagSet("rules test", flag.ExitOnError)
+	dir := f.String("dir", "", "规则包目录，默认校验当前生效的基础规则与 config/rules.d")
+	f.Parse(args[1:])
+
+	ui.Banner()
+
+	paths := f.Args()
+	if *dir != "" {
+		paths = append([]string{*dir}, paths...)
+	}
+	if !internalcmd.TestRules(paths) {
+		os.Exit(1)
+	}
+}
+
+// handleNamesCommand 处理 names 子命令：导入、导出或列出本地名称库
+func handleNamesCommand(args []string) {
+	f := flag.NewFlagSet("names", flag.ExitOnError)
+	db := f.String("db", "", "本地名称库路径（默认 ~/.gwxapkg/app_names.json）")
+	importCSV := f.String("import", "", "导入 CSV：appid,name[,source[,fetched_at]]")
+	exportCSV := f.String("export", "", "把名称库导出为 CSV")
+	f.Parse(args)
+
+	ui.Banner()
+	internalcmd.Names(internalcmd.NamesOptions{DB: *db, Import: *importCSV, Export: *exportCSV})
+}
+
 func handleRepackCommand(args []string) {
 	repackFlags := flag.NewFlagSet("repack", flag.ExitOnError)
 	inputDir := repackFlags.String("in", "", "输入目录路径")
@@ -614,8 +1448,15 @@ func handleDefaultCommand() {
 	noClean := flag.Bool("noClean", false, "是否保留中间文件")
 	save := flag.Bool("save", false, "是否保存解密后的文件")
 	sensitive := flag.Bool("sensitive", true, "是否获取敏感数据")
+	sarif := flag.Bool("sarif", false, "是否额外导出 SARIF 2.1.0 扫描报告")
+	baseline := flag.String("baseline", "", "基线文件或上一次的 sensitive_report.json，其中的发现标记为已知")
+	suppress := flag.String("suppress", "", "忽略规则文件（YAML）")
+	showAll := flag.Bool("show-all", false, "报告中保留已知与已忽略的发现")
 	postman := flag.Bool("postman", false, "是否导出 Postman Collection")
+	openAPI := flag.Bool("openapi", false, "是否导出 OpenAPI 3 文档")
+	trace := flag.Bool("trace", false, "是否在 wx 桩环境中运行页面并记录发出的请求")
 	workspace := flag.Bool("workspace", false, "是否保留可精确回包的工作区")
+	full := flag.Bool("full", false, "忽略上次的 manifest，全部重新解包与分析")
 	astRename := flag.String("ast-rename", semantic.ASTRenameModeDeep, "AST 重命名模式: off/report/safe/deep")
 	astDiff := flag.Bool("ast-diff", true, "是否生成 AST 重命名 diff 报告")
 	astPatch := flag.Bool("ast-patch", true, "是否生成 AST 重命名 patch")
@@ -628,10 +1469,30 @@ func handleDefaultCommand() {
 		ui.PrintUsage()
 		return
 	}
+	triage, ok := internalcmd.LoadTriage(*baseline, *suppress, *showAll)
+	if !ok {
+		return
+	}
 
 	ui.Info("开始处理小程序: %s", *appID)
 	ui.PrintDivider()
-	cmd.ExecuteWithOptions(*appID, *input, *outputDir, *fileExt, *restoreDir, *pretty, *noClean, *save, *sensitive, *postman, *workspace, buildRewriteOptions(*astRename, *astDiff, *astPatch))
+	options := wxapkg.DefaultOptions(*appID, *input)
+	options.OutputDir = *outputDir
+	options.FileExt = *fileExt
+	options.Restore = *restoreDir
+	options.Pretty = *pretty
+	options.NoClean = *noClean
+	options.Save = *save
+	options.Sensitive = *sensitive
+	options.SARIF = *sarif
+	options.Triage = triage
+	options.Postman = *postman
+	options.OpenAPI = *openAPI
+	options.Trace = *trace
+	options.Workspace = *workspace
+	options.Full = *full
+	options.Rewrite = buildRewriteOptions(*astRename, *astDiff, *astPatch)
+	cmd.ExecuteWithOptions(options)
 	ui.PrintDivider()
 	ui.Success("处理完成!")
 }
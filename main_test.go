@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/25smoking/Gwxapkg/pkg/wxapkg"
+	"github.com/fatih/color"
+)
+
+func TestScanJSONKeepsStdoutMachineReadable(t *testing.T) {
+	root := t.TempDir()
+	cacheDir := filepath.Join(root, "AppDomain-com.tencent.xin/Library/WechatPrivate/ffee00/WeApp/LocalCache/release/wxfedcba9876543210")
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		t.Fatalf("创建缓存目录失败: %v", err)
+	}
+	writer := wxapkg.NewWriter()
+	if err := writer.AddBytes("/app-config.json", []byte(`{"appName":"苹果测试商城"}`)); err != nil {
+		t.Fatalf("添加文件失败: %v", err)
+	}
+	var data bytes.Buffer
+	if _, err := writer.WriteTo(&data); err != nil {
+		t.Fatalf("写出 wxapkg 失败: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(cacheDir, "12.wxapkg"), data.Bytes(), 0644); err != nil {
+		t.Fatalf("写入测试包失败: %v", err)
+	}
+
+	stdout, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatalf("创建临时文件失败: %v", err)
+	}
+	stderr, err := os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatalf("创建临时文件失败: %v", err)
+	}
+	// 模拟终端：提示信息默认与 stdout 是同一个输出
+	originalStdout, originalStderr, originalOutput := os.Stdout, os.Stderr, color.Output
+	os.Stdout, os.Stderr, color.Output = stdout, stderr, stdout
+	defer func() {
+		os.Stdout, os.Stderr, color.Output = originalStdout, originalStderr, originalOutput
+	}()
+
+	handleScanCommand([]string{"-json", "-verbose", "-offline", "-root=" + root, "-name-db=" + filepath.Join(t.TempDir(), "names.json")})
+
+	stdoutData, _ := os.ReadFile(stdout.Name())
+	stderrData, _ := os.ReadFile(stderr.Name())
+	var programs []map[string]interface{}
+	if err := json.Unmarshal(stdoutData, &programs); err != nil {
+		t.Fatalf("stdout 应只包含 JSON 数组: %v\n%s", err, stdoutData)
+	}
+	if len(programs) != 1 || programs[0]["appid"] != "wxfedcba9876543210" {
+		t.Fatalf("扫描结果不正确: %s", stdoutData)
+	}
+	if !strings.Contains(string(stderrData), "hit") {
+		t.Fatalf("-verbose 诊断应写到 stderr: %q", stderrData)
+	}
+}
@@ -0,0 +1,216 @@
+// Package wxapkg 是 Gwxapkg 的公开库接口。
+//
+// 它提供三层能力：
+//   - Archive / Writer：按需读取与写出 wxapkg 包；
+//   - DecryptFile / Encrypt / NewDecryptReader：PC 端 V1MMWX 加密格式的解密与加密；
+//   - Run：完整的解包、还原、语义反混淆、敏感扫描与路由分析流水线。
+//
+// 命令行工具只是这些接口之上的一层薄封装，外部 Go 工具可以直接依赖本包，无需调用二进制。
+package wxapkg
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/25smoking/Gwxapkg/internal/decrypt"
+	"github.com/25smoking/Gwxapkg/internal/pack"
+	"github.com/25smoking/Gwxapkg/internal/unpack"
+	"github.com/25smoking/Gwxapkg/internal/util"
+)
+
+// Header wxapkg 文件头字段
+type Header = unpack.Header
+
+// Entry wxapkg 索引项，Offset 为相对明文包起点的偏移
+type Entry = unpack.WxapkgFile
+
+// Inspection 包头、类型、wcc 版本与索引概览
+type Inspection = unpack.Inspection
+
+// Archive 已打开的 wxapkg 包。只解析头部与索引，文件内容通过 Open 按需读取。
+type Archive struct {
+	reader    *unpack.Reader
+	decrypted *decrypt.File
+}
+
+// OpenArchive 打开磁盘上的 wxapkg；加密包会使用 appID 解密，明文包忽略 appID。
+func OpenArchive(path, appID string) (*Archive, error) {
+	decrypted, err := decrypt.Open(path, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := unpack.NewReader(decrypted, decrypted.Size(), path)
+	if err != nil {
+		decrypted.Close()
+		return nil, err
+	}
+	return &Archive{reader: reader, decrypted: decrypted}, nil
+}
+
+// NewArchive 基于任意 io.ReaderAt 打开 wxapkg，适合内存数据或自定义存储。
+func NewArchive(src io.ReaderAt, size int64, appID string) (*Archive, error) {
+	decrypted, err := decrypt.NewReader(src, size, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := unpack.NewReader(decrypted, decrypted.Size(), "")
+	if err != nil {
+		return nil, err
+	}
+	return &Archive{reader: reader, decrypted: decrypted}, nil
+}
+
+// Header 返回文件头字段
+func (a *Archive) Header() Header {
+	return a.reader.Header
+}
+
+// Entries 返回索引项副本，顺序与包内索引一致
+func (a *Archive) Entries() []Entry {
+	return append([]Entry(nil), a.reader.Files...)
+}
+
+// Names 返回全部包内路径
+func (a *Archive) Names() []string {
+	return a.reader.FileNames()
+}
+
+// Encrypted 返回源文件是否为加密包
+func (a *Archive) Encrypted() bool {
+	return a.decrypted.Encrypted()
+}
+
+// Size 返回明文包长度
+func (a *Archive) Size() int64 {
+	return a.reader.Size()
+}
+
+// Type 根据文件列表推断包类型（主包、分包、插件、游戏等）
+func (a *Archive) Type() PackageType {
+	return util.GetWxapkgType(a.reader.FileNames())
+}
+
+// Open 返回包内文件内容读取器，路径前导 "/" 可省略
+func (a *Archive) Open(name string) (io.Reader, error) {
+	return a.reader.Open(name)
+}
+
+// ReadFile 读取包内单个文件的全部内容
+func (a *Archive) ReadFile(name string) ([]byte, error) {
+	entry, err := a.reader.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(entry)
+}
+
+// Extract 将包内文件写到 outputDir，JS/JSON/HTML 会按内置格式化器美化，不做敏感扫描。
+func (a *Archive) Extract(outputDir string) ([]string, error) {
+	return unpack.UnpackWxapkgFrom(a.decrypted, a.decrypted.Size(), a.reader.SourcePath, outputDir, unpack.DefaultOptions())
+}
+
+// Inspect 返回包概览；patterns 为包内路径 glob（支持 **），为空时列出全部条目
+func (a *Archive) Inspect(patterns ...string) (*Inspection, error) {
+	inspection, err := unpack.Inspect(a.reader, patterns)
+	if err != nil {
+		return nil, err
+	}
+	inspection.Encrypted = a.decrypted.Encrypted()
+	return inspection, nil
+}
+
+// ExtractMatching 只把匹配 patterns 的条目原样写到 outputDir，输出路径与 Extract 一致
+func (a *Archive) ExtractMatching(outputDir string, patterns ...string) ([]string, error) {
+	options := unpack.DefaultOptions()
+	options.Raw = true
+	return unpack.ExtractEntries(a.reader, outputDir, patterns, options)
+}
+
+// Close 释放底层文件句柄
+func (a *Archive) Close() error {
+	return a.decrypted.Close()
+}
+
+// Writer 以明文格式构建 wxapkg，需要微信客户端可识别的包时再调用 Encrypt。
+type Writer struct {
+	files []pack.WxapkgFile
+	names map[string]struct{}
+}
+
+// NewWriter 创建空的 wxapkg 构建器
+func NewWriter() *Writer {
+	return &Writer{names: make(map[string]struct{})}
+}
+
+// AddBytes 添加内存中的文件内容
+func (w *Writer) AddBytes(name string, data []byte) error {
+	return w.add(pack.WxapkgFile{Name: name, Size: uint32(len(data)), Data: data})
+}
+
+// AddFile 添加磁盘文件，内容在 WriteTo 时才读取
+func (w *Writer) AddFile(name, path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s 是目录", path)
+	}
+	return w.add(pack.WxapkgFile{Name: name, Size: uint32(info.Size()), Source: path})
+}
+
+func (w *Writer) add(file pack.WxapkgFile) error {
+	name := "/" + trimEntryName(file.Name)
+	if name == "/" {
+		return fmt.Errorf("包内路径为空")
+	}
+	if _, exists := w.names[name]; exists {
+		return fmt.Errorf("包内路径重复: %s", name)
+	}
+	w.names[name] = struct{}{}
+
+	file.Name = name
+	file.NameLen = uint32(len(name))
+	w.files = append(w.files, file)
+	return nil
+}
+
+// WriteTo 按包内路径排序后写出明文 wxapkg
+func (w *Writer) WriteTo(dst io.Writer) (int64, error) {
+	files := append([]pack.WxapkgFile(nil), w.files...)
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+
+	var offset uint32
+	for i := range files {
+		files[i].Offset = offset
+		offset += files[i].Size
+	}
+
+	counter := &countingWriter{w: dst}
+	err := pack.WriteArchive(counter, files)
+	return counter.n, err
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func trimEntryName(name string) string {
+	for len(name) > 0 && (name[0] == '/' || name[0] == '\\') {
+		name = name[1:]
+	}
+	return name
+}
@@ -0,0 +1,76 @@
+package wxapkg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriterArchiveRoundTripWithEncryption(t *testing.T) {
+	writer := NewWriter()
+	appService := []byte(strings.Repeat("App({onLaunch:function(){}});\n", 80))
+	if err := writer.AddBytes("app-service.js", appService); err != nil {
+		t.Fatalf("添加文件失败: %v", err)
+	}
+	if err := writer.AddBytes("/app-config.json", []byte(`{"pages":["pages/index/index"]}`)); err != nil {
+		t.Fatalf("添加文件失败: %v", err)
+	}
+	if err := writer.AddBytes("app-config.json", nil); err == nil {
+		t.Fatalf("重复路径应返回错误")
+	}
+
+	var plain bytes.Buffer
+	if _, err := writer.WriteTo(&plain); err != nil {
+		t.Fatalf("写出 wxapkg 失败: %v", err)
+	}
+
+	encrypted, err := Encrypt(plain.Bytes(), "wxabcdef0123456789")
+	if err != nil {
+		t.Fatalf("加密失败: %v", err)
+	}
+
+	archive, err := NewArchive(bytes.NewReader(encrypted), int64(len(encrypted)), "wxabcdef0123456789")
+	if err != nil {
+		t.Fatalf("打开加密包失败: %v", err)
+	}
+	defer archive.Close()
+
+	if !archive.Encrypted() {
+		t.Fatalf("应识别为加密包")
+	}
+	if got := strings.Join(archive.Names(), ","); got != "/app-config.json,/app-service.js" {
+		t.Fatalf("索引顺序错误: %s", got)
+	}
+
+	content, err := archive.ReadFile("/app-service.js")
+	if err != nil {
+		t.Fatalf("读取包内文件失败: %v", err)
+	}
+	if !bytes.Equal(content, appService) {
+		t.Fatalf("往返后的文件内容不一致")
+	}
+}
+
+// 明文不足 1023 字节时整包都在 AES 前缀中，没有 XOR 尾部；加密不能越界，解密后的前缀应与明文一致
+func TestEncryptHandlesDataShorterThanAESPrefix(t *testing.T) {
+	plain := []byte("short wxapkg payload")
+	encrypted, err := Encrypt(plain, "wxabcdef0123456789")
+	if err != nil {
+		t.Fatalf("加密失败: %v", err)
+	}
+	if len(encrypted) != len("V1MMWX")+1024 {
+		t.Fatalf("加密结果长度错误: %d", len(encrypted))
+	}
+
+	reader, err := NewDecryptReader(bytes.NewReader(encrypted), int64(len(encrypted)), "wxabcdef0123456789")
+	if err != nil {
+		t.Fatalf("解密失败: %v", err)
+	}
+	decrypted := make([]byte, len(plain))
+	if _, err := reader.ReadAt(decrypted, 0); err != nil {
+		t.Fatalf("读取解密内容失败: %v", err)
+	}
+	if !bytes.Equal(decrypted, plain) {
+		t.Fatalf("解密后的前缀与明文不一致: %q", decrypted)
+	}
+}
@@ -0,0 +1,35 @@
+package wxapkg
+
+import "sync"
+
+// BatchResult 批量模式下单个 AppID 的处理结果
+type BatchResult struct {
+	AppID  string
+	Result *Result
+	Err    error
+}
+
+// RunBatch 并发执行多个流水线，每个任务使用独立的运行上下文，结果顺序与 jobs 一致。
+// concurrency 小于 1 时按 1 处理；各任务的 OutputDir 需互不相同，否则输出会互相覆盖。
+func RunBatch(jobs []Options, concurrency int) []BatchResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]BatchResult, len(jobs))
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(index int, options Options) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			result, err := Run(options)
+			results[index] = BatchResult{AppID: options.AppID, Result: result, Err: err}
+		}(i, job)
+	}
+	wg.Wait()
+	return results
+}
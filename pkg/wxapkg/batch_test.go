@@ -0,0 +1,117 @@
+package wxapkg
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestPackage(t *testing.T, dir, appID, apiURL string) string {
+	t.Helper()
+
+	writer := NewWriter()
+	service := fmt.Sprintf("wx.request({url:%q,method:\"POST\"});\n", apiURL) +
+		"var payConfig = {mchKey: \"Hq7Lm2Pz9Xc4Vb8Nk3Rt6Wy1Js5Df0Ga\"};\n" +
+		strings.Repeat("console.log('padding');\n", 60)
+	if err := writer.AddBytes("/app-service.js", []byte(service)); err != nil {
+		t.Fatalf("添加文件失败: %v", err)
+	}
+	if err := writer.AddBytes("/app-config.json", []byte(`{"pages":["pages/index/index"]}`)); err != nil {
+		t.Fatalf("添加文件失败: %v", err)
+	}
+
+	var plain bytes.Buffer
+	if _, err := writer.WriteTo(&plain); err != nil {
+		t.Fatalf("写出 wxapkg 失败: %v", err)
+	}
+	encrypted, err := Encrypt(plain.Bytes(), appID)
+	if err != nil {
+		t.Fatalf("加密失败: %v", err)
+	}
+
+	path := filepath.Join(dir, appID+".wxapkg")
+	if err := os.WriteFile(path, encrypted, 0644); err != nil {
+		t.Fatalf("写入测试包失败: %v", err)
+	}
+	return path
+}
+
+// stubVerifier 认领所有发现，用于确认校验器只作用于传入它的那次运行
+type stubVerifier struct{}
+
+func (stubVerifier) Name() string { return "stub" }
+
+func (stubVerifier) Verify(item SensitiveItem) (Verification, bool) {
+	return Verification{Status: "stub_checked"}, true
+}
+
+func TestRunBatchIsolatesConcurrentRuns(t *testing.T) {
+	root := t.TempDir()
+	apps := map[string]string{
+		"wx1111111111111111": "https://one.example.com/api/first",
+		"wx2222222222222222": "https://two.example.com/api/second",
+	}
+
+	var jobs []Options
+	for appID, apiURL := range apps {
+		input := writeTestPackage(t, t.TempDir(), appID, apiURL)
+		options := DefaultOptions(appID, input)
+		options.OutputDir = filepath.Join(root, appID)
+		options.Restore = false
+		options.Workspace = true
+		jobs = append(jobs, options)
+	}
+	jobs[0].Verifiers = []Verifier{stubVerifier{}}
+
+	results := RunBatch(jobs, len(jobs))
+	if len(results) != len(jobs) {
+		t.Fatalf("结果数量错误: %d", len(results))
+	}
+
+	for i, item := range results {
+		if item.Err != nil {
+			t.Fatalf("%s 处理失败: %v", item.AppID, item.Err)
+		}
+		if item.AppID != jobs[i].AppID {
+			t.Fatalf("结果顺序应与任务一致: got %s want %s", item.AppID, jobs[i].AppID)
+		}
+
+		own := apps[item.AppID]
+		var urls []string
+		for _, endpoint := range item.Result.Scan.APIEndpoints {
+			urls = append(urls, endpoint.RawURL)
+		}
+		joined := strings.Join(urls, ",")
+		if !strings.Contains(joined, own) {
+			t.Fatalf("%s 应包含自身接口 %s，实际: %s", item.AppID, own, joined)
+		}
+		for otherID, other := range apps {
+			if otherID != item.AppID && strings.Contains(joined, other) {
+				t.Fatalf("%s 混入了 %s 的接口: %s", item.AppID, otherID, joined)
+			}
+		}
+
+		stubbed := 0
+		for _, finding := range item.Result.Scan.Items {
+			if finding.VerifiedBy == "stub" {
+				stubbed++
+			}
+		}
+		if (i == 0) != (stubbed > 0) {
+			t.Fatalf("%s 的校验器不应影响其他任务: 命中 %d 条，共 %d 条", item.AppID, stubbed, len(item.Result.Scan.Items))
+		}
+
+		manifest, err := os.ReadFile(item.Result.Artifacts.Manifest)
+		if err != nil {
+			t.Fatalf("读取 manifest 失败: %v", err)
+		}
+		for otherID := range apps {
+			if otherID != item.AppID && strings.Contains(string(manifest), otherID) {
+				t.Fatalf("%s 的 manifest 混入了 %s 的包", item.AppID, otherID)
+			}
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package wxapkg
+
+import (
+	"io"
+
+	"github.com/25smoking/Gwxapkg/internal/decrypt"
+)
+
+// DecryptReader 以 io.ReaderAt 形式提供解密后的明文内容
+type DecryptReader = decrypt.File
+
+// DecryptFile 解密磁盘上的 wxapkg 并返回完整明文；大包请使用 OpenArchive 按需读取。
+func DecryptFile(path, appID string) ([]byte, error) {
+	return decrypt.DecryptWxapkg(path, appID)
+}
+
+// NewDecryptReader 基于 io.ReaderAt 构造流式解密读取器，只在构造时解密 AES 前缀
+func NewDecryptReader(src io.ReaderAt, size int64, appID string) (*DecryptReader, error) {
+	return decrypt.NewReader(src, size, appID)
+}
+
+// IsEncrypted 判断数据是否为 V1MMWX 加密格式
+func IsEncrypted(src io.ReaderAt, size int64) (bool, error) {
+	return decrypt.IsEncrypted(src, size)
+}
+
+// Encrypt 将明文 wxapkg 加密为微信客户端可识别的 V1MMWX 格式
+func Encrypt(data []byte, appID string) ([]byte, error) {
+	return decrypt.EncryptWxapkg(data, appID)
+}
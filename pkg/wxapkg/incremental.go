@@ -0,0 +1,260 @@
+package wxapkg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	internalcmd "github.com/25smoking/Gwxapkg/internal/cmd"
+	"github.com/25smoking/Gwxapkg/internal/config"
+	packmeta "github.com/25smoking/Gwxapkg/internal/pack"
+	"github.com/25smoking/Gwxapkg/internal/packagecheck"
+	"github.com/25smoking/Gwxapkg/internal/restore"
+	"github.com/25smoking/Gwxapkg/internal/scanner"
+	"github.com/25smoking/Gwxapkg/internal/session"
+)
+
+// packageDigest 原始包文件的内容哈希与大小
+type packageDigest struct {
+	sha256 string
+	size   int64
+}
+
+// incrementalPlan 根据上次的 manifest 决定本次需要解包的包。previous 为空表示全量处理。
+type incrementalPlan struct {
+	previous *packmeta.PackageManifest
+	digests  map[string]packageDigest // 包名 -> 本次输入的哈希
+	process  []string                 // 需要解包的输入文件
+	skipped  []string                 // 内容未变化而跳过的输入文件
+	replaced []packmeta.ManifestPackage
+}
+
+func (plan *incrementalPlan) incremental() bool {
+	return plan.previous != nil
+}
+
+// manifestSettings 影响输出目录内容的参数摘要，与上次不一致时退回全量处理
+func manifestSettings(options Options) string {
+	return fmt.Sprintf("restore=%t pretty=%t noclean=%t workspace=%t ast=%s",
+		options.Restore, options.Pretty, options.NoClean, options.Workspace, options.Rewrite.ASTRename.Mode)
+}
+
+func hashPackageFile(filePath string) (packageDigest, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return packageDigest{}, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return packageDigest{}, err
+	}
+	return packageDigest{sha256: hex.EncodeToString(hash.Sum(nil)), size: size}, nil
+}
+
+// planIncremental 对比输入包与上次 manifest 中记录的哈希。以下情况退回全量处理：
+// 指定了 Full、没有可用的 manifest、处理参数变化、需要处理主包（主包变化会影响全部页面），
+// 以及需要合并扫描结果但上次没有留下 sensitive_report.json。
+func (p *pipelineRunner) planIncremental(outputDir string, inputFiles []string, collector bool) *incrementalPlan {
+	plan := &incrementalPlan{digests: make(map[string]packageDigest, len(inputFiles)), process: inputFiles}
+	for _, file := range inputFiles {
+		digest, err := hashPackageFile(file)
+		if err != nil {
+			continue
+		}
+		plan.digests[filepath.Base(file)] = digest
+	}
+
+	if p.options.Full {
+		return plan
+	}
+	previous, err := packmeta.LoadPackageManifest(outputDir)
+	if err != nil || previous.Settings != manifestSettings(p.options) {
+		return plan
+	}
+	for _, pkg := range previous.Packages {
+		if pkg.SHA256 == "" {
+			return plan
+		}
+	}
+	if collector {
+		if _, err := os.Stat(filepath.Join(outputDir, "sensitive_report.json")); err != nil {
+			return plan
+		}
+	}
+
+	var process, skipped []string
+	var replaced []packmeta.ManifestPackage
+	for _, file := range inputFiles {
+		name := filepath.Base(file)
+		digest, hashed := plan.digests[name]
+		pkg := previous.Package(name)
+		if hashed && pkg != nil && pkg.SHA256 == digest.sha256 && pkg.Size == digest.size {
+			skipped = append(skipped, file)
+			continue
+		}
+
+		inspection, err := internalcmd.InspectPackage(file, p.options.AppID, nil)
+		if err != nil || restore.IsMainPackage(&config.WxapkgInfo{WxapkgType: inspection.Type}) {
+			return plan
+		}
+		process = append(process, file)
+		if pkg != nil {
+			replaced = append(replaced, *pkg)
+		}
+	}
+
+	plan.previous = previous
+	plan.process = process
+	plan.skipped = skipped
+	plan.replaced = replaced
+	return plan
+}
+
+// replacedFiles 内容变化的包上次写出的文件（相对输出目录），包括被语义重命名后的路径
+func (plan *incrementalPlan) replacedFiles(pathMap map[string]string) map[string]bool {
+	files := make(map[string]bool)
+	for _, pkg := range plan.replaced {
+		for _, file := range pkg.Files {
+			files[file] = true
+			if next, ok := pathMap[file]; ok {
+				files[next] = true
+			}
+		}
+	}
+	return files
+}
+
+// removeReplacedOutputs 删除变化包上次的输出，避免重新解包后与旧的语义文件名并存
+func removeReplacedOutputs(outputDir string, files map[string]bool) {
+	for file := range files {
+		_ = os.Remove(filepath.Join(outputDir, filepath.FromSlash(file)))
+	}
+}
+
+// buildManifest 生成本次的 manifest：本次解包的包记录新的哈希，增量处理时保留未变化的包
+func (p *pipelineRunner) buildManifest(sess *session.Session, plan *incrementalPlan) (*packmeta.PackageManifest, error) {
+	manifest, err := packmeta.BuildPackageManifest(p.options.AppID, sess.Manager)
+	if err != nil {
+		return nil, err
+	}
+	manifest.Settings = manifestSettings(p.options)
+	// 未保留原始文件时，还原工程结构会清理包内的原始文件，这份 manifest 不能用于精确回包
+	manifest.RawRemoved = p.options.Restore && !p.options.NoClean && !p.options.Workspace
+	for i := range manifest.Packages {
+		if digest, ok := plan.digests[manifest.Packages[i].Name]; ok {
+			manifest.Packages[i].SHA256 = digest.sha256
+			manifest.Packages[i].Size = digest.size
+		}
+	}
+
+	if plan.incremental() {
+		for _, pkg := range plan.previous.Packages {
+			if manifest.Package(pkg.Name) == nil && !plan.isReplaced(pkg.Name) {
+				manifest.Packages = append(manifest.Packages, pkg)
+			}
+		}
+	}
+	return manifest, nil
+}
+
+func (plan *incrementalPlan) isReplaced(name string) bool {
+	for _, pkg := range plan.replaced {
+		if pkg.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// completenessInputs 分包完整性按已处理过的全部包判断：本次输入之外，沿用上次报告中仍保留输出的包
+func completenessInputs(outputDir string, inputFiles []string) []string {
+	files := append([]string(nil), inputFiles...)
+	previous, err := packagecheck.ReadReport(outputDir)
+	if err != nil {
+		return files
+	}
+	names := make(map[string]bool, len(inputFiles))
+	for _, file := range inputFiles {
+		names[filepath.Base(file)] = true
+	}
+	for _, pkg := range previous.PackageFiles {
+		if !names[pkg.Name] {
+			names[pkg.Name] = true
+			files = append(files, pkg.Path)
+		}
+	}
+	return files
+}
+
+func loadScanReport(outputDir string) (*scanner.ScanReport, error) {
+	data, err := os.ReadFile(filepath.Join(outputDir, "sensitive_report.json"))
+	if err != nil {
+		return nil, err
+	}
+	var report scanner.ScanReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("解析 sensitive_report.json 失败: %w", err)
+	}
+	return &report, nil
+}
+
+// fileStamp 输出文件的大小与修改时间，用于找出本次解包与还原改动过的文件
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// snapshotOutput 记录输出目录中的文件（跳过 .gwxapkg 元数据目录），键为 / 分隔的相对路径
+func snapshotOutput(outputDir string) map[string]fileStamp {
+	stamps := make(map[string]fileStamp)
+	_ = filepath.WalkDir(outputDir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel, relErr := filepath.Rel(outputDir, filePath)
+		if relErr != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if entry.IsDir() {
+			if rel == ".gwxapkg" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, infoErr := entry.Info()
+		if infoErr != nil {
+			return nil
+		}
+		stamps[rel] = fileStamp{size: info.Size(), modTime: info.ModTime()}
+		return nil
+	})
+	return stamps
+}
+
+// changedFiles 返回新增、修改与删除的文件，按路径排序
+func changedFiles(before, after map[string]fileStamp) []string {
+	var files []string
+	for rel, stamp := range after {
+		if previous, ok := before[rel]; !ok || previous.size != stamp.size || !previous.modTime.Equal(stamp.modTime) {
+			files = append(files, rel)
+		}
+	}
+	for rel := range before {
+		if _, ok := after[rel]; !ok {
+			files = append(files, rel)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
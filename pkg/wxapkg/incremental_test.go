@@ -0,0 +1,110 @@
+package wxapkg
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	packmeta "github.com/25smoking/Gwxapkg/internal/pack"
+)
+
+func writePlainPackage(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	writer := NewWriter()
+	for name, content := range files {
+		if err := writer.AddBytes(name, []byte(content)); err != nil {
+			t.Fatalf("添加文件失败: %v", err)
+		}
+	}
+	var data bytes.Buffer
+	if _, err := writer.WriteTo(&data); err != nil {
+		t.Fatalf("写出 wxapkg 失败: %v", err)
+	}
+	if err := os.WriteFile(path, data.Bytes(), 0644); err != nil {
+		t.Fatalf("写入测试包失败: %v", err)
+	}
+}
+
+func endpointURLs(report *ScanReport) []string {
+	var urls []string
+	for _, endpoint := range report.APIEndpoints {
+		urls = append(urls, endpoint.RawURL)
+	}
+	return urls
+}
+
+func TestRunProcessesOnlyNewSubpackagesAndMergesReports(t *testing.T) {
+	appID := "wx00000000000000f1"
+	input := t.TempDir()
+	mainPkg := filepath.Join(input, "__APP__.wxapkg")
+	writePlainPackage(t, mainPkg, map[string]string{
+		"/app.json":               `{"pages":["pages/index/index"],"subPackages":[{"root":"pkgA/","pages":["pages/detail"]}]}`,
+		"/pages/index/index.js":   `Page({onLoad(){wx.request({url:"https://main.example.com/api/home",method:"GET"})}})`,
+		"/pages/index/index.wxml": "<view />",
+	})
+
+	options := DefaultOptions(appID, input)
+	options.OutputDir = filepath.Join(t.TempDir(), appID)
+	options.Rewrite.ASTRename.Mode = ASTRenameOff
+
+	first, err := Run(options)
+	if err != nil {
+		t.Fatalf("首次处理失败: %v", err)
+	}
+	if first.Incremental || first.Completeness == nil || first.Completeness.IsFull() {
+		t.Fatalf("首次处理应为全量且分包缺失: %+v", first)
+	}
+
+	subPkg := filepath.Join(input, "_pkgA_.wxapkg")
+	writePlainPackage(t, subPkg, map[string]string{
+		"/pkgA/pages/detail.js":   `Page({onLoad(){wx.request({url:"https://sub.example.com/api/detail",method:"POST"})}})`,
+		"/pkgA/pages/detail.wxml": "<view />",
+	})
+	second, err := Run(options)
+	if err != nil {
+		t.Fatalf("增量处理失败: %v", err)
+	}
+	if !second.Incremental || !slices.Equal(second.SkippedFiles, []string{mainPkg}) {
+		t.Fatalf("只应处理新到达的分包: incremental=%v skipped=%v", second.Incremental, second.SkippedFiles)
+	}
+	if !second.Completeness.IsFull() {
+		t.Fatalf("补齐分包后完整性应为 full: %+v", second.Completeness)
+	}
+	urls := endpointURLs(second.Scan)
+	if !slices.Contains(urls, "https://main.example.com/api/home") || !slices.Contains(urls, "https://sub.example.com/api/detail") {
+		t.Fatalf("扫描结果应合并主包与新分包的接口: %v", urls)
+	}
+	if second.Routes == nil || second.Routes.Summary.TotalPages != 2 || second.Routes.Summary.SubPackagePages != 1 {
+		t.Fatalf("路由地图应包含新分包页面: %+v", second.Routes)
+	}
+
+	manifest, err := packmeta.LoadPackageManifest(options.OutputDir)
+	if err != nil {
+		t.Fatalf("读取 manifest 失败: %v", err)
+	}
+	if len(manifest.Packages) != 2 || manifest.Package("__APP__.wxapkg").SHA256 == "" || manifest.Package("_pkgA_.wxapkg").SHA256 == "" {
+		t.Fatalf("manifest 应记录两个包的哈希: %+v", manifest.Packages)
+	}
+
+	third, err := Run(options)
+	if err != nil {
+		t.Fatalf("重复处理失败: %v", err)
+	}
+	if !third.Incremental || len(third.SkippedFiles) != 2 || len(endpointURLs(third.Scan)) != len(urls) {
+		t.Fatalf("包未变化时应全部跳过并保留上次结果: %+v", third)
+	}
+	if third.Routes == nil || third.Routes.Summary.TotalPages != 2 {
+		t.Fatalf("全部跳过时应沿用路由结果: %+v", third.Routes)
+	}
+
+	options.Full = true
+	full, err := Run(options)
+	if err != nil {
+		t.Fatalf("全量处理失败: %v", err)
+	}
+	if full.Incremental || len(full.SkippedFiles) != 0 || !full.Completeness.IsFull() {
+		t.Fatalf("-full 应忽略 manifest 全部重新处理: %+v", full)
+	}
+}
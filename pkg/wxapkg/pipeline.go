@@ -0,0 +1,527 @@
+package wxapkg
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/25smoking/Gwxapkg/internal/analyzer"
+	internalcmd "github.com/25smoking/Gwxapkg/internal/cmd"
+	"github.com/25smoking/Gwxapkg/internal/enum"
+	"github.com/25smoking/Gwxapkg/internal/key"
+	packmeta "github.com/25smoking/Gwxapkg/internal/pack"
+	"github.com/25smoking/Gwxapkg/internal/packagecheck"
+	"github.com/25smoking/Gwxapkg/internal/reporter"
+	"github.com/25smoking/Gwxapkg/internal/restore"
+	"github.com/25smoking/Gwxapkg/internal/scanner"
+	"github.com/25smoking/Gwxapkg/internal/semantic"
+	"github.com/25smoking/Gwxapkg/internal/session"
+	"github.com/25smoking/Gwxapkg/internal/util"
+	"github.com/25smoking/Gwxapkg/internal/wxenv"
+)
+
+// PackageType wxapkg 包类型
+type PackageType = enum.WxapkgType
+
+// RewriteOptions 控制语义反混淆与 AST 重命名
+type RewriteOptions = semantic.RewriteOptions
+
+// ASTRenameOptions 控制 AST 重命名强度与审计产物
+type ASTRenameOptions = semantic.ASTRenameOptions
+
+// AST 重命名模式
+const (
+	ASTRenameOff    = semantic.ASTRenameModeOff
+	ASTRenameReport = semantic.ASTRenameModeReport
+	ASTRenameSafe   = semantic.ASTRenameModeSafe
+	ASTRenameDeep   = semantic.ASTRenameModeDeep
+)
+
+// 流水线各阶段的产物类型
+type (
+	ScanReport         = scanner.ScanReport
+	SensitiveItem      = scanner.SensitiveItem
+	APIEndpoint        = scanner.APIEndpoint
+	SemanticReport     = semantic.Report
+	RouteManifest      = analyzer.RouteManifest
+	CompletenessReport = packagecheck.Report
+	Triage             = scanner.Triage
+	Verifier           = scanner.Verifier
+	Verification       = scanner.Verification
+	HTTPVerifier       = scanner.HTTPVerifier
+)
+
+// NewHTTPVerifier 创建把发现提交到 endpoint 校验的在线校验器，ruleIDs 为空时提交全部发现
+func NewHTTPVerifier(endpoint string, ruleIDs ...string) *HTTPVerifier {
+	return scanner.NewHTTPVerifier(endpoint, ruleIDs...)
+}
+
+// ErrNoInput 输入路径下没有可处理的 wxapkg
+var ErrNoInput = errors.New("未找到任何文件")
+
+// Options 单次运行的配置；建议从 DefaultOptions 开始修改，布尔字段为零值时关闭对应阶段
+type Options struct {
+	AppID     string
+	Input     string // 单个文件、逗号分隔的文件列表或目录
+	OutputDir string // 为空时使用默认输出目录 output/<AppID>
+	FileExt   string // 目录输入时匹配的后缀，默认 .wxapkg
+
+	Restore   bool // 还原工程目录结构
+	Pretty    bool // 美化 JS 输出
+	NoClean   bool // 保留中间文件
+	Save      bool // 保存解密后的 wxapkg
+	Sensitive bool // 敏感数据扫描与报告
+	SARIF     bool // 额外导出 SARIF 2.1.0 扫描报告
+	Postman   bool // 导出 Postman Collection
+	OpenAPI   bool // 导出 OpenAPI 3 文档，与 Postman 相互独立
+	Trace     bool // 在 wx 桩环境中运行页面，记录请求并并入 api_map 与 Postman Collection
+	Workspace bool // 保留可精确回包的原始工作区
+	Full      bool // 忽略上次的 manifest，全部重新处理
+
+	Rewrite RewriteOptions
+
+	// Triage 基线与忽略规则，为空时所有发现都视为新发现
+	Triage *Triage
+
+	// Verifiers 额外的凭据校验器（例如对接本地测试桩的在线校验），只对本次运行生效，
+	// 在内置离线校验器之后执行
+	Verifiers []Verifier
+
+	// Observer 接收阶段进度与告警，为空时静默运行
+	Observer Observer
+}
+
+// DefaultOptions 返回与命令行默认参数一致的配置
+func DefaultOptions(appID, input string) Options {
+	return Options{
+		AppID:     appID,
+		Input:     input,
+		FileExt:   ".wxapkg",
+		Restore:   true,
+		Pretty:    true,
+		Sensitive: true,
+		Rewrite:   semantic.DefaultRewriteOptions(),
+	}
+}
+
+// Observer 接收流水线进度事件。PackageDone 与解包阶段的 Warning 会被多个 goroutine 并发调用。
+type Observer interface {
+	Start(appID string, inputFiles []string)
+	Stage(step, total int, title string)
+	PackageDone(file string, err error)
+	Warning(message string)
+}
+
+// Artifacts 流水线写出的报告文件路径，未生成的为空字符串
+type Artifacts struct {
+	SemanticModuleMap string
+	APIMap            string
+	APICallChain      string
+	APIPseudo         string
+	CryptoMap         string
+	RequestTrace      string
+	ASTRenameMap      string
+	Completeness      string
+	Manifest          string
+	APIEndpointMap    string
+	SensitiveJSON     string
+	SensitiveExcel    string
+	SensitiveHTML     string
+	SensitiveSARIF    string
+	Postman           string
+	OpenAPI           string
+	RouteManifest     string
+	RouteMarkdown     string
+	RouteMermaid      string
+}
+
+// Result 单次运行的结构化结果
+type Result struct {
+	AppID         string
+	OutputDir     string
+	InputFiles    []string
+	PackageErrors []error
+
+	Semantic     *SemanticReport
+	Completeness *CompletenessReport
+	Scan         *ScanReport
+	Routes       *RouteManifest
+
+	// Incremental 为 true 时本次只解包了新增或变化的包，报告与上次的结果合并；
+	// SkippedFiles 为内容与上次一致而跳过的输入文件
+	Incremental  bool
+	SkippedFiles []string
+
+	Artifacts Artifacts
+	Warnings  []string
+}
+
+// Run 执行完整的解包流水线。每次调用使用独立的运行上下文，可在同一进程内并发调用。
+func Run(options Options) (*Result, error) {
+	runner := &pipelineRunner{options: options}
+	return runner.run()
+}
+
+type pipelineRunner struct {
+	options Options
+	result  *Result
+	mu      sync.Mutex
+}
+
+func (p *pipelineRunner) warn(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	p.mu.Lock()
+	p.result.Warnings = append(p.result.Warnings, message)
+	p.mu.Unlock()
+	if p.options.Observer != nil {
+		p.options.Observer.Warning(message)
+	}
+}
+
+func (p *pipelineRunner) stage(step, total int, title string) {
+	if p.options.Observer != nil {
+		p.options.Observer.Stage(step, total, title)
+	}
+}
+
+func (p *pipelineRunner) run() (*Result, error) {
+	options := p.options
+	if options.FileExt == "" {
+		options.FileExt = ".wxapkg"
+	}
+
+	outputDir := options.OutputDir
+	if outputDir == "" {
+		outputDir = internalcmd.DetermineOutputDir(options.Input, options.AppID)
+	}
+	p.result = &Result{AppID: options.AppID}
+	if expanded, err := util.ExpandHomePath(outputDir); err != nil {
+		p.warn("展开输出目录失败，继续使用原路径: %v", err)
+	} else {
+		outputDir = expanded
+	}
+	p.result.OutputDir = outputDir
+
+	inputFiles := internalcmd.ParseInput(options.Input, options.FileExt)
+	p.result.InputFiles = inputFiles
+	if len(inputFiles) == 0 {
+		return p.result, ErrNoInput
+	}
+
+	sess := session.New(options.AppID, outputDir)
+	sess.Pretty = options.Pretty
+	sess.NoClean = options.NoClean
+	sess.Verifiers = options.Verifiers
+	sess.Warn = p.warn
+
+	// 如果需要敏感扫描、Postman 或 OpenAPI 导出，初始化规则与收集器
+	sensitive, postman, openAPI := options.Sensitive, options.Postman, options.OpenAPI
+	if sensitive || postman || openAPI {
+		rules, err := key.LoadRules()
+		if err != nil {
+			p.warn("初始化扫描规则失败: %v", err)
+			sensitive = false
+			postman = false
+			openAPI = false
+		} else {
+			sess.EnableCollector(rules).SetTriage(options.Triage)
+		}
+	}
+
+	// 对比上次 manifest 中的包哈希，只解包新增或变化的包
+	plan := p.planIncremental(outputDir, inputFiles, sess.Collector != nil)
+	p.result.Incremental = plan.incremental()
+	p.result.SkippedFiles = plan.skipped
+	var before map[string]fileStamp
+	var pathMap map[string]string
+	var replaced map[string]bool
+	if plan.incremental() {
+		if report, err := semantic.LoadReport(outputDir); err == nil {
+			pathMap = report.PathMap
+		}
+		replaced = plan.replacedFiles(pathMap)
+		before = snapshotOutput(outputDir)
+		removeReplacedOutputs(outputDir, replaced)
+	}
+
+	if options.Observer != nil {
+		options.Observer.Start(options.AppID, plan.process)
+	}
+
+	p.stage(1, 2, "解包 wxapkg 文件...")
+	p.unpackAll(sess, plan.process)
+	if plan.incremental() && sess.Collector != nil {
+		p.mergePreviousScan(sess, outputDir, before, replaced, pathMap)
+	}
+
+	// manifest 记录各包的哈希供下次增量处理；保留原始包内容时也用于精确回包
+	if manifest, err := p.buildManifest(sess, plan); err != nil {
+		p.warn("生成 manifest 失败: %v", err)
+	} else if err := packmeta.SavePackageManifest(outputDir, manifest); err != nil {
+		p.warn("写入 manifest 失败: %v", err)
+	} else if len(manifest.Packages) > 0 {
+		p.result.Artifacts.Manifest = filepath.Join(outputDir, ".gwxapkg", "manifest.json")
+	}
+
+	// 还原工程目录结构
+	p.stage(2, 2, "还原工程结构...")
+	restore.ProjectStructure(sess, options.Restore)
+
+	var affected map[string]bool
+	if options.Restore {
+		if plan.incremental() {
+			files := changedFiles(before, snapshotOutput(outputDir))
+			affected = p.rewriteSemanticsIncremental(sess, outputDir, options.Rewrite, files)
+			p.checkCompleteness(outputDir, options.AppID, completenessInputs(outputDir, inputFiles))
+		} else {
+			p.rewriteSemantics(sess, outputDir, options.Rewrite)
+			p.checkCompleteness(outputDir, options.AppID, inputFiles)
+		}
+		if options.Trace {
+			p.traceRequests(sess, outputDir)
+		}
+	}
+
+	if sess.Collector != nil {
+		sess.Collector.SetTotalFiles(len(inputFiles))
+		report := sess.Collector.GenerateReport()
+		p.result.Scan = report
+		p.writeScanReports(report, outputDir, sensitive, postman, openAPI)
+	}
+
+	if options.Restore {
+		p.analyzeRoutes(outputDir, options.AppID, affected)
+	}
+
+	return p.result, nil
+}
+
+func (p *pipelineRunner) unpackAll(sess *session.Session, inputFiles []string) {
+	var wg sync.WaitGroup
+	for _, inputFile := range inputFiles {
+		wg.Add(1)
+		go func(file string) {
+			defer wg.Done()
+			err := internalcmd.ProcessFile(sess, file, p.options.Save, p.options.Workspace)
+			if err != nil {
+				p.mu.Lock()
+				p.result.PackageErrors = append(p.result.PackageErrors, err)
+				p.mu.Unlock()
+			}
+			if p.options.Observer != nil {
+				p.options.Observer.PackageDone(file, err)
+			}
+		}(inputFile)
+	}
+	wg.Wait()
+}
+
+// mergePreviousScan 把上次的扫描结果并入收集器。变化包上次的文件与本次重新解包写出的文件
+// 已经重新扫描，对应的旧结果丢弃。
+func (p *pipelineRunner) mergePreviousScan(sess *session.Session, outputDir string, before map[string]fileStamp, replaced map[string]bool, pathMap map[string]string) {
+	previous, err := loadScanReport(outputDir)
+	if err != nil {
+		p.warn("读取上次的扫描报告失败: %v", err)
+		return
+	}
+	dropped := make(map[string]bool, len(replaced))
+	for file := range replaced {
+		dropped[file] = true
+	}
+	for _, file := range changedFiles(before, snapshotOutput(outputDir)) {
+		dropped[file] = true
+		if next, ok := pathMap[file]; ok {
+			dropped[next] = true
+		}
+	}
+	sess.Collector.MergeReport(previous, func(file string) bool { return !dropped[file] })
+}
+
+func (p *pipelineRunner) rewriteSemantics(sess *session.Session, outputDir string, options RewriteOptions) {
+	report, err := semantic.RewriteProjectWithOptions(outputDir, options)
+	if err != nil {
+		p.warn("源码级语义反混淆失败: %v", err)
+		return
+	}
+	p.attachSemantic(sess, outputDir, report)
+}
+
+// rewriteSemanticsIncremental 只对本次新增或变化的文件做语义还原，返回语义重命名后的受影响文件集合
+func (p *pipelineRunner) rewriteSemanticsIncremental(sess *session.Session, outputDir string, options RewriteOptions, files []string) map[string]bool {
+	affected := make(map[string]bool, len(files))
+	for _, file := range files {
+		affected[file] = true
+	}
+
+	var report *SemanticReport
+	var err error
+	if len(files) == 0 {
+		// 没有文件变化时沿用上次的语义报告
+		if report, err = semantic.LoadReport(outputDir); err != nil {
+			return affected
+		}
+	} else if report, err = semantic.RewriteFilesWithOptions(outputDir, files, options); err != nil {
+		p.warn("源码级语义反混淆失败: %v", err)
+		return affected
+	}
+	for _, file := range files {
+		if next, ok := report.PathMap[file]; ok {
+			affected[next] = true
+		}
+	}
+	p.attachSemantic(sess, outputDir, report)
+	return affected
+}
+
+func (p *pipelineRunner) attachSemantic(sess *session.Session, outputDir string, report *SemanticReport) {
+	p.result.Semantic = report
+
+	if sess.Collector != nil {
+		sess.Collector.RewriteFilePaths(report.PathMap)
+	}
+
+	metaDir := filepath.Join(outputDir, ".gwxapkg")
+	if report.RenamedCount > 0 || report.SourceMapRecovered > 0 {
+		p.result.Artifacts.SemanticModuleMap = filepath.Join(metaDir, "semantic_module_map.json")
+	}
+	if report.APIEndpointCount > 0 {
+		p.result.Artifacts.APIMap = filepath.Join(metaDir, "api_map.md")
+		p.result.Artifacts.APICallChain = filepath.Join(metaDir, "api_call_chain.md")
+		p.result.Artifacts.APIPseudo = filepath.Join(metaDir, "api_pseudo.md")
+	}
+	if report.CryptoUsageCount > 0 {
+		p.result.Artifacts.CryptoMap = filepath.Join(metaDir, "crypto_map.md")
+	}
+	if report.ASTRenamedCount > 0 {
+		p.result.Artifacts.ASTRenameMap = filepath.Join(metaDir, "ast_rename_map.json")
+	}
+}
+
+// traceRequests 运行页面生命周期，把追踪到的请求写入 api_map，并补充到扫描结果的接口列表
+func (p *pipelineRunner) traceRequests(sess *session.Session, outputDir string) {
+	trace, err := wxenv.TraceApp(outputDir, wxenv.TraceOptions{})
+	if err != nil {
+		p.warn("运行页面追踪请求失败: %v", err)
+		return
+	}
+	tracePath := semantic.RequestTracePath(outputDir)
+	if err := wxenv.WriteTrace(tracePath, trace); err != nil {
+		p.warn("写入请求追踪失败: %v", err)
+		return
+	}
+	p.result.Artifacts.RequestTrace = tracePath
+
+	if p.result.Artifacts.APIMap != "" {
+		if _, err := semantic.ApplyRequestTrace(outputDir, trace); err != nil {
+			p.warn("请求追踪并入 API 地图失败: %v", err)
+		}
+	}
+	if sess.Collector != nil {
+		for _, endpoint := range trace.APIEndpoints() {
+			sess.Collector.AddAPIEndpoint(endpoint)
+		}
+	}
+}
+
+func (p *pipelineRunner) checkCompleteness(outputDir, appID string, inputFiles []string) {
+	report, err := packagecheck.AnalyzeAndWrite(outputDir, appID, inputFiles)
+	if err != nil {
+		p.warn("分包完整性检测失败: %v", err)
+		return
+	}
+	if report != nil && report.Status != packagecheck.StatusUnknown {
+		p.result.Completeness = report
+		p.result.Artifacts.Completeness = filepath.Join(outputDir, ".gwxapkg", "package_completeness.md")
+	}
+}
+
+func (p *pipelineRunner) writeScanReports(report *scanner.ScanReport, outputDir string, sensitive, postman, openAPI bool) {
+	if len(report.APIEndpoints) > 0 {
+		artifacts, err := reporter.NewAPIEndpointMapReporter().Generate(report, outputDir, outputDir)
+		if err != nil {
+			p.warn("生成通用 API Endpoint 地图失败: %v", err)
+		} else {
+			p.result.Artifacts.APIEndpointMap = artifacts.MarkdownPath
+		}
+	}
+
+	if sensitive {
+		jsonPath := filepath.Join(outputDir, "sensitive_report.json")
+		if err := reporter.NewJSONReporter().Generate(report, jsonPath); err != nil {
+			p.warn("生成 JSON 报告失败: %v", err)
+		} else {
+			p.result.Artifacts.SensitiveJSON = jsonPath
+		}
+
+		excelPath := filepath.Join(outputDir, "sensitive_report.xlsx")
+		if err := reporter.NewExcelReporter().Generate(report, excelPath); err != nil {
+			p.warn("生成 Excel 报告失败: %v", err)
+		} else {
+			p.result.Artifacts.SensitiveExcel = excelPath
+		}
+
+		htmlPath := filepath.Join(outputDir, "sensitive_report.html")
+		if err := reporter.NewHTMLReporter().Generate(report, htmlPath); err != nil {
+			p.warn("生成 HTML 报告失败: %v", err)
+		} else {
+			p.result.Artifacts.SensitiveHTML = htmlPath
+		}
+
+		if p.options.SARIF {
+			sarifPath := filepath.Join(outputDir, "sensitive_report.sarif")
+			if err := reporter.NewSARIFReporter().Generate(report, sarifPath); err != nil {
+				p.warn("生成 SARIF 报告失败: %v", err)
+			} else {
+				p.result.Artifacts.SensitiveSARIF = sarifPath
+			}
+		}
+	}
+
+	if postman {
+		postmanPath := filepath.Join(outputDir, "api_collection.postman_collection.json")
+		if err := reporter.NewPostmanReporter().Generate(report, postmanPath); err != nil {
+			p.warn("生成 Postman Collection 失败: %v", err)
+		} else {
+			p.result.Artifacts.Postman = postmanPath
+		}
+	}
+
+	if openAPI {
+		// 语义还原已在此前完成，api_map 缺失时只用扫描提取的请求生成
+		apiMap, _ := semantic.LoadAPIMap(outputDir)
+		openAPIPath := filepath.Join(outputDir, reporter.OpenAPIFileName)
+		if err := reporter.NewOpenAPIReporter().Generate(report, apiMap, openAPIPath); err != nil {
+			p.warn("生成 OpenAPI 文档失败: %v", err)
+		} else {
+			p.result.Artifacts.OpenAPI = openAPIPath
+		}
+	}
+}
+
+// analyzeRoutes 生成页面与路由地图；affected 不为空时只重新分析受影响的页面
+func (p *pipelineRunner) analyzeRoutes(outputDir, appID string, affected map[string]bool) {
+	var manifest *RouteManifest
+	var err error
+	if affected != nil {
+		previous, _ := analyzer.LoadRouteManifest(outputDir)
+		manifest, err = analyzer.AnalyzeMiniProgramIncremental(outputDir, appID, previous, affected)
+	} else {
+		manifest, err = analyzer.AnalyzeMiniProgram(outputDir, appID)
+	}
+	if err != nil {
+		p.warn("生成页面与路由地图失败: %v", err)
+		return
+	}
+
+	artifacts, err := reporter.NewRouteReporter().Generate(manifest, outputDir)
+	if err != nil {
+		p.warn("写入页面与路由地图失败: %v", err)
+		return
+	}
+
+	p.result.Routes = manifest
+	p.result.Artifacts.RouteManifest = artifacts.ManifestPath
+	p.result.Artifacts.RouteMarkdown = artifacts.MarkdownPath
+	p.result.Artifacts.RouteMermaid = artifacts.MermaidPath
+}
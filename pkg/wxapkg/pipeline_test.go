@@ -0,0 +1,37 @@
+package wxapkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunExportsOpenAPIWithoutPostman(t *testing.T) {
+	appID := "wx00000000000000f2"
+	input := t.TempDir()
+	writePlainPackage(t, filepath.Join(input, "__APP__.wxapkg"), map[string]string{
+		"/app.json":               `{"pages":["pages/index/index"]}`,
+		"/pages/index/index.js":   `Page({onLoad(){wx.request({url:"https://api.example.com/user/info",method:"POST"})}})`,
+		"/pages/index/index.wxml": "<view />",
+	})
+
+	options := DefaultOptions(appID, input)
+	options.OutputDir = filepath.Join(t.TempDir(), appID)
+	options.Sensitive = false
+	options.OpenAPI = true
+	options.Rewrite.ASTRename.Mode = ASTRenameOff
+
+	result, err := Run(options)
+	if err != nil {
+		t.Fatalf("处理失败: %v", err)
+	}
+	if result.Artifacts.OpenAPI == "" || result.Artifacts.Postman != "" {
+		t.Fatalf("只开启 OpenAPI 时应只写出 openapi.yaml: %+v", result.Artifacts)
+	}
+	if _, err := os.Stat(result.Artifacts.OpenAPI); err != nil {
+		t.Fatalf("openapi.yaml 未写出: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(options.OutputDir, "api_collection.postman_collection.json")); !os.IsNotExist(err) {
+		t.Fatalf("未开启 Postman 时不应写出集合: %v", err)
+	}
+}